@@ -7,7 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
@@ -21,12 +21,15 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/config"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/covers"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/db"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/handlers"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
 	appmiddleware "github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 	gormrepo "github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository/gorm"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/services"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
 // version is set at build time via -ldflags "-X main.version=<tag>".
@@ -71,14 +74,11 @@ func main() {
 
 	db.Seed(database)
 
-	coversDir := "./data/covers"
-	if err := os.MkdirAll(coversDir, 0o750); err != nil {
-		log.Fatal().Err(err).Msg("failed to create covers dir")
-	}
-
-	backupsDir := "./data/backups"
-	if err := os.MkdirAll(backupsDir, 0o750); err != nil {
-		log.Fatal().Err(err).Msg("failed to create backups dir")
+	// Cached covers and backup archives live on local disk by default, or in
+	// an S3-compatible bucket when STORAGE_BACKEND=s3.
+	coverStore, backupStore, err := openStores(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to open object storage")
 	}
 
 	// Repositories — only this layer and db.Open ever import gorm.io/gorm.
@@ -92,6 +92,16 @@ func main() {
 	regVerificationRepo := gormrepo.NewRegistrationVerificationRepository(database)
 	announcementRepo := gormrepo.NewAnnouncementRepository(database)
 	wishlistRepo := gormrepo.NewWishlistRequestRepository(database)
+	recommendationRepo := gormrepo.NewRecommendationRepository(database)
+	readingListRepo := gormrepo.NewReadingListRepository(database)
+	authorRepo := gormrepo.NewAuthorRepository(database)
+	metadataCacheRepo := gormrepo.NewMetadataCacheRepository(database)
+	importJobRepo := gormrepo.NewImportJobRepository(database)
+	libraryRepo := gormrepo.NewLibraryRepository(database)
+	accountRepo := gormrepo.NewAccountRepository(database)
+
+	// Metadata providers, enabled and ordered by admin settings.
+	metadataProviders := metadata.NewRegistry(adminRepo, metadata.DefaultProviders()...)
 
 	// Services
 	emailSvc := services.NewEmailService(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom, cfg.Env, cfg.DevEmailOverride, cfg.FrontendOrigin)
@@ -104,12 +114,31 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to get underlying sql.DB")
 	}
-	backupSvc := services.NewBackupService(sqlDB, adminRepo, cfg.DBPath, coversDir, backupsDir)
+	backupSvc := services.NewBackupService(sqlDB, adminRepo, filepath.Dir(cfg.DBPath), coverStore, backupStore)
 	descriptionReconciliationSvc := services.NewDescriptionReconciliationService(bookRepo)
+	recommendationSvc := services.NewRecommendationService(recommendationRepo)
+	contributorSvc := services.NewContributorService(authorRepo)
+	metadataBackfillSvc := services.NewMetadataBackfillService(bookRepo, metadataProviders, cfg.GoogleBooksAPIKey)
+	coverGCSvc := services.NewCoverGCService(bookRepo, adminRepo, coverStore)
 
-	scheduler := services.NewScheduler(bookRepo, adminRepo, coversDir, cfg.MetadataRefreshInterval)
+	scheduler := services.NewScheduler(bookRepo, adminRepo, coverStore, cfg.MetadataRefreshInterval)
 	scheduler.RegisterJob("backup", "backup_interval", 24*time.Hour, backupSvc.CreateSnapshot)
 	scheduler.RegisterJob("description-reconciliation", "description_reconciliation_interval", 24*time.Hour, descriptionReconciliationSvc.Run)
+	scheduler.RegisterJob("recommendations", "recommendations_interval", 24*time.Hour, recommendationSvc.Run)
+	// Links books added before contributors existed (or whose linking failed
+	// at creation) to Author records, a batch per run.
+	scheduler.RegisterJob("contributors", "contributors_interval", 24*time.Hour, contributorSvc.Run)
+	// Re-queries the metadata providers for books missing a publisher, page
+	// count, language or ISBN (mostly CSV imports), a rate-limited batch per
+	// run. It shares metadataProviders with user searches, so a provider the
+	// circuit breaker has opened is skipped here too.
+	scheduler.RegisterJob("metadata-backfill", "metadata_backfill_interval", 24*time.Hour, metadataBackfillSvc.Run)
+	// Deletes cached cover files no book points at any more (left behind
+	// when a cover is refreshed or re-pointed), once they're past the
+	// cover_gc_grace_period setting. Its dry run lists what would go
+	// without deleting it, via POST /admin/jobs/cover-gc/dry-run.
+	scheduler.RegisterJob("cover-gc", "cover_gc_interval", 7*24*time.Hour, coverGCSvc.Run)
+	scheduler.RegisterDryRun("cover-gc", coverGCSvc.DryRun)
 	// Sweeps abandoned signups out of registration_verifications. A row is
 	// deleted as soon as its code is submitted, right or wrong, so this only
 	// catches the ones nobody ever came back to — which for the email channel
@@ -120,6 +149,12 @@ func main() {
 	// key by hand.
 	scheduler.RegisterJob("registration-prune", "registration_prune_interval", time.Hour,
 		pruneRegistrationVerifications(regVerificationRepo))
+	// Carries out self-service account deletions once their grace period is
+	// over. Hourly so an account goes close to the time the member was
+	// shown; a run with nothing due is one indexed query. Not seeded in
+	// db.Seed, same as registration-prune above.
+	accountDeletionSvc := services.NewAccountDeletionService(accountRepo, coverStore)
+	scheduler.RegisterJob("account-deletion", "account_deletion_interval", time.Hour, accountDeletionSvc.Run)
 
 	seedYAMLConfig(cfg.AppConfigPath, adminRepo)
 
@@ -134,37 +169,44 @@ func main() {
 		encryptionSecret = cfg.JWTSecret
 	}
 
+	// Metadata search results are cached in the database, so a restart
+	// doesn't re-spend the Google Books quota on queries already answered.
+	metadataCacheTTL, err := time.ParseDuration(cfg.MetadataCacheTTL)
+	if err != nil || metadataCacheTTL <= 0 {
+		log.Fatal().Str("value", cfg.MetadataCacheTTL).Msg("METADATA_CACHE_TTL must be a positive duration, e.g. 24h")
+	}
+	metadataCache := handlers.NewSQLiteMetadataCache(ctx, metadataCacheRepo, metadataCacheTTL, cfg.MetadataCacheMaxEntries)
+
 	// Handlers
 	authH := handlers.NewAuthHandler(userRepo, adminRepo, copyRepo, regVerificationRepo, cfg.JWTSecret, encryptionSecret, emailSvc, smsSvc, registrationWorkflow, cfg.Env)
-	metadataH := handlers.NewMetadataHandler(ctx, cfg.GoogleBooksAPIKey, encryptionSecret, userRepo)
-	bookH := handlers.NewBookHandler(bookRepo, userRepo, coversDir, wishlistWorkflow)
-	copyH := handlers.NewCopyHandler(copyRepo, userRepo, notifRepo, waitlistRepo, adminRepo, bookRepo, wishlistRepo, coversDir, wishlistWorkflow)
-	loanH := handlers.NewLoanRequestHandler(copyRepo, loanRepo, adminRepo, userRepo, workflow)
+	metadataH := handlers.NewMetadataHandler(metadataCache, cfg.GoogleBooksAPIKey, encryptionSecret, userRepo, metadataProviders)
+	bookH := handlers.NewBookHandler(bookRepo, userRepo, coverStore, wishlistWorkflow, contributorSvc)
+	copyH := handlers.NewCopyHandler(copyRepo, userRepo, notifRepo, waitlistRepo, adminRepo, bookRepo, wishlistRepo, coverStore, wishlistWorkflow, contributorSvc, importJobRepo)
+	loanH := handlers.NewLoanRequestHandler(copyRepo, loanRepo, adminRepo, userRepo, bookRepo, workflow)
 	notifH := handlers.NewNotificationHandler(notifRepo)
-	adminH := handlers.NewAdminHandler(adminRepo, copyRepo, loanRepo, cfg.GoogleBooksAPIKey)
+	adminH := handlers.NewAdminHandler(adminRepo, copyRepo, loanRepo, cfg.GoogleBooksAPIKey, metadataProviders, metadataCache)
 	jobsH := handlers.NewJobsHandler(scheduler)
 	backupH := handlers.NewBackupHandler(backupSvc)
 	waitlistH := handlers.NewWaitlistHandler(copyRepo, waitlistRepo)
 	announcementH := handlers.NewAnnouncementHandler(announcementRepo)
 	wishlistH := handlers.NewWishlistHandler(wishlistRepo, bookRepo, wishlistWorkflow)
+	recommendationH := handlers.NewRecommendationHandler(bookRepo, recommendationRepo)
+	readingListH := handlers.NewReadingListHandler(readingListRepo, bookRepo)
+	opdsH := handlers.NewOPDSHandler(bookRepo, cfg.FrontendOrigin)
+	feedH := handlers.NewFeedHandler(bookRepo, adminRepo, cfg.FrontendOrigin)
+	authorH := handlers.NewAuthorHandler(authorRepo, bookRepo)
+	libraryH := handlers.NewLibraryHandler(libraryRepo, bookRepo)
+	accountH := handlers.NewAccountHandler(accountRepo, userRepo, adminRepo)
 
 	// Router
 	mux := http.NewServeMux()
 
-	// Static file serving for locally cached book covers. Directory-listing
-	// requests (trailing slash, e.g. GET /covers/) are rejected with 404
-	// rather than falling through to http.FileServer's default listing.
-	coverFS := http.StripPrefix("/covers/", http.FileServer(http.Dir(coversDir)))
-	mux.Handle("/covers/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasSuffix(r.URL.Path, "/") {
-			http.NotFound(w, r)
-			return
-		}
-		// Filenames are content-addressed (SHA-256 of the source URL), so a
-		// given path's content never changes — safe to cache indefinitely.
-		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-		coverFS.ServeHTTP(w, r)
-	}))
+	// Static file serving for locally cached book covers, at the size asked
+	// for with ?size=thumb|medium|full (see covers.Handler).
+	mux.Handle("/covers/", http.StripPrefix("/covers/", covers.Handler(coverStore)))
+	// Any book's cover by ID, with a generated placeholder for books that
+	// have none — what OPDS and the Atom feed link to (see publicCoverURL).
+	mux.Handle("GET /covers/books/{id}", covers.BookHandler(coverStore, bookRepo))
 
 	// Health check (plain net/http, outside huma)
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
@@ -198,6 +240,13 @@ func main() {
 	waitlistH.RegisterRoutes(api)
 	announcementH.RegisterRoutes(api)
 	wishlistH.RegisterRoutes(api)
+	recommendationH.RegisterRoutes(api)
+	readingListH.RegisterRoutes(api)
+	opdsH.RegisterRoutes(api)
+	feedH.RegisterRoutes(api)
+	authorH.RegisterRoutes(api)
+	libraryH.RegisterRoutes(api)
+	accountH.RegisterRoutes(api)
 
 	// Middleware chain: security headers → request logging → CORS → auth enrichment → mux
 	corsHandler := cors.New(cors.Options{
@@ -219,9 +268,11 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	// Start the background scheduler.
+	// Start the background scheduler, and the runner for queued book
+	// imports (which resumes any a previous run left unfinished).
 	var wg sync.WaitGroup
 	wg.Go(func() { scheduler.Start(ctx) })
+	wg.Go(func() { copyH.RunImportJobs(ctx) })
 
 	// Graceful shutdown on SIGINT/SIGTERM.
 	done := make(chan struct{})
@@ -296,3 +347,39 @@ func pruneRegistrationVerifications(repo repository.RegistrationVerificationRepo
 		return fmt.Sprintf("pruned %d expired registration verification(s)", n)
 	}
 }
+
+// openStores returns the stores for cached covers and backup archives as
+// selected by STORAGE_BACKEND. With "s3" both share one bucket, kept apart
+// by the covers/ and backups/ key prefixes under S3_PREFIX.
+func openStores(cfg *config.Config) (coverStore, backupStore storage.Store, err error) {
+	switch cfg.StorageBackend {
+	case "local":
+		if coverStore, err = storage.NewLocal("./data/covers"); err != nil {
+			return nil, nil, err
+		}
+		if backupStore, err = storage.NewLocal("./data/backups"); err != nil {
+			return nil, nil, err
+		}
+		return coverStore, backupStore, nil
+	case "s3":
+		s3cfg := storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		}
+		s3cfg.Prefix = cfg.S3Prefix + "covers/"
+		if coverStore, err = storage.NewS3(s3cfg); err != nil {
+			return nil, nil, err
+		}
+		s3cfg.Prefix = cfg.S3Prefix + "backups/"
+		if backupStore, err = storage.NewS3(s3cfg); err != nil {
+			return nil, nil, err
+		}
+		return coverStore, backupStore, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown STORAGE_BACKEND %q (want local or s3)", cfg.StorageBackend)
+	}
+}
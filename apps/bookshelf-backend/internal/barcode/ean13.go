@@ -0,0 +1,336 @@
+// Package barcode finds EAN-13 barcodes — the barcode printed on the back of
+// a book, encoding its ISBN-13 — in photos, in pure Go.
+//
+// The decoder scans evenly spaced rows and columns of the image in both
+// directions, so a barcode is found whether the photo is upright, upside
+// down or on its side. Each scan line is thresholded against its local
+// average brightness, turned into alternating bar/space widths, and matched
+// against the EAN-13 module patterns. It doesn't correct for skew beyond
+// what a straight scan line across the bars tolerates (roughly ±30°), nor
+// for blur: a photo where a human can't make out the individual bars won't
+// decode.
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// scanLinesPerAxis is how many rows, and how many columns, are scanned.
+const scanLinesPerAxis = 160
+
+// minReads is how many scan lines must independently decode a code before
+// it's reported. A single read can be a coincidence of noise that happens
+// to pass the checksum; a real barcode crosses many lines.
+const minReads = 2
+
+// maxAvgVariance and maxIndividualVariance bound how far a run of measured
+// widths may stray from a pattern and still match it, as a fraction of the
+// pattern's width and of one module respectively.
+const (
+	maxAvgVariance        = 0.48
+	maxIndividualVariance = 0.7
+)
+
+// digitPatterns are the module widths of the "L" (odd parity) encodings of
+// digits 0-9, as space-bar-space-bar. The "R" encodings used on the right
+// half have the same widths with colors swapped, and the "G" (even parity)
+// encodings are the L widths reversed.
+var digitPatterns = [10][4]int{
+	{3, 2, 1, 1}, {2, 2, 2, 1}, {2, 1, 2, 2}, {1, 4, 1, 1}, {1, 1, 3, 2},
+	{1, 2, 3, 1}, {1, 1, 1, 4}, {1, 3, 1, 2}, {1, 2, 1, 3}, {3, 1, 1, 2},
+}
+
+// firstDigitParity maps the parity pattern of the six left-hand digits (bit
+// 5 for the first, set when G-encoded) to the implied 13th, leading digit.
+var firstDigitParity = map[int]byte{
+	0x00: 0, 0x0B: 1, 0x0D: 2, 0x0E: 3, 0x13: 4,
+	0x19: 5, 0x1C: 6, 0x15: 7, 0x16: 8, 0x1A: 9,
+}
+
+var (
+	guardPattern  = []int{1, 1, 1}
+	middlePattern = []int{1, 1, 1, 1, 1}
+)
+
+// runsPerCode is the number of bar/space runs from the first bar of the
+// start guard to the last bar of the end guard: 3 + 6×4 + 5 + 6×4 + 3.
+const runsPerCode = 59
+
+// DecodeEAN13 returns the distinct 13-digit EAN-13 codes found in img, most
+// confidently read first. A photo of several books can hold several codes.
+func DecodeEAN13(img image.Image) []string {
+	b := img.Bounds()
+	reads := map[string]int{}
+	var order []string
+	record := func(lum []uint8) {
+		for _, code := range decodeLine(lum) {
+			if reads[code] == 0 {
+				order = append(order, code)
+			}
+			reads[code]++
+		}
+	}
+
+	for _, y := range spread(b.Min.Y, b.Max.Y) {
+		lum := make([]uint8, b.Dx())
+		for x := b.Min.X; x < b.Max.X; x++ {
+			lum[x-b.Min.X] = luminance(img, x, y)
+		}
+		record(lum)
+	}
+	for _, x := range spread(b.Min.X, b.Max.X) {
+		lum := make([]uint8, b.Dy())
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			lum[y-b.Min.Y] = luminance(img, x, y)
+		}
+		record(lum)
+	}
+
+	var codes []string
+	for _, code := range order {
+		if reads[code] >= minReads {
+			codes = append(codes, code)
+		}
+	}
+	sort.SliceStable(codes, func(i, j int) bool { return reads[codes[i]] > reads[codes[j]] })
+	return codes
+}
+
+// spread returns up to scanLinesPerAxis coordinates evenly spaced across
+// [lo, hi).
+func spread(lo, hi int) []int {
+	n := hi - lo
+	if n <= 0 {
+		return nil
+	}
+	step := n / scanLinesPerAxis
+	if step < 1 {
+		step = 1
+	}
+	var out []int
+	for v := lo + step/2; v < hi; v += step {
+		out = append(out, v)
+	}
+	return out
+}
+
+// luminance returns the brightness of img at (x, y), reading the Y plane
+// directly for JPEG photos rather than converting through RGB.
+func luminance(img image.Image, x, y int) uint8 {
+	switch im := img.(type) {
+	case *image.YCbCr:
+		return im.Y[im.YOffset(x, y)]
+	case *image.Gray:
+		return im.GrayAt(x, y).Y
+	default:
+		return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+	}
+}
+
+// decodeLine returns every code readable along one scan line, in either
+// direction.
+func decodeLine(lum []uint8) []string {
+	widths, firstDark := runs(lum)
+	codes := decodeRuns(widths, firstDark)
+
+	reversed := make([]int, len(widths))
+	for i, w := range widths {
+		reversed[len(widths)-1-i] = w
+	}
+	// The last run becomes the first; it's dark if the original first run's
+	// color recurs there, i.e. if the run count's parity keeps it.
+	lastDark := firstDark == (len(widths)%2 == 1)
+	return append(codes, decodeRuns(reversed, lastDark)...)
+}
+
+// runs thresholds lum against the average over a window around each pixel
+// — so uneven lighting across a photo doesn't turn a shadowed area solid —
+// and returns the widths of the resulting alternating dark/light runs, with
+// whether the first is dark.
+func runs(lum []uint8) ([]int, bool) {
+	n := len(lum)
+	if n == 0 {
+		return nil, false
+	}
+	prefix := make([]int, n+1)
+	for i, v := range lum {
+		prefix[i+1] = prefix[i] + int(v)
+	}
+	radius := n / 16
+	if radius < 8 {
+		radius = 8
+	}
+	dark := func(i int) bool {
+		lo, hi := i-radius, i+radius+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > n {
+			hi = n
+		}
+		return int(lum[i])*(hi-lo) < prefix[hi]-prefix[lo]
+	}
+
+	firstDark := dark(0)
+	widths := []int{1}
+	current := firstDark
+	for i := 1; i < n; i++ {
+		if d := dark(i); d == current {
+			widths[len(widths)-1]++
+		} else {
+			widths = append(widths, 1)
+			current = d
+		}
+	}
+	return widths, firstDark
+}
+
+// decodeRuns finds codes in a sequence of run widths whose first run is
+// dark when firstDark is set.
+func decodeRuns(widths []int, firstDark bool) []string {
+	var codes []string
+	// Dark runs are the even indexes when the first run is dark, else odd.
+	start := 1
+	if firstDark {
+		start = 0
+	}
+	// A start guard needs a light quiet zone before it, so i >= 1.
+	if start == 0 {
+		start = 2
+	}
+	for i := start; i+runsPerCode < len(widths); i += 2 {
+		if code, ok := decodeAt(widths, i); ok {
+			codes = append(codes, code)
+			i += runsPerCode - 1
+		}
+	}
+	return codes
+}
+
+// decodeAt tries to read a code whose start guard's first bar is
+// widths[i], with quiet zones at widths[i-1] and widths[i+runsPerCode].
+func decodeAt(widths []int, i int) (string, bool) {
+	guard := widths[i : i+3]
+	if variance(guard, guardPattern) > maxAvgVariance {
+		return "", false
+	}
+	module := float64(sum(guard)) / 3
+	if float64(widths[i-1]) < 3*module || float64(widths[i+runsPerCode]) < 3*module {
+		return "", false
+	}
+
+	digits := make([]byte, 13)
+	parity := 0
+	pos := i + 3
+	for d := 0; d < 6; d++ {
+		digit, even, ok := matchDigit(widths[pos:pos+4], true)
+		if !ok {
+			return "", false
+		}
+		digits[d+1] = digit
+		if even {
+			parity |= 1 << (5 - d)
+		}
+		pos += 4
+	}
+	first, ok := firstDigitParity[parity]
+	if !ok {
+		return "", false
+	}
+	digits[0] = first
+
+	if variance(widths[pos:pos+5], middlePattern) > maxAvgVariance {
+		return "", false
+	}
+	pos += 5
+	for d := 0; d < 6; d++ {
+		digit, _, ok := matchDigit(widths[pos:pos+4], false)
+		if !ok {
+			return "", false
+		}
+		digits[d+7] = digit
+		pos += 4
+	}
+	if variance(widths[pos:pos+3], guardPattern) > maxAvgVariance {
+		return "", false
+	}
+
+	if !validCheckDigit(digits) {
+		return "", false
+	}
+	code := make([]byte, 13)
+	for k, d := range digits {
+		code[k] = '0' + d
+	}
+	return string(code), true
+}
+
+// matchDigit returns the digit whose pattern best fits four run widths.
+// Left-half digits may be L or G encoded (even reports G); right-half
+// digits are always R, which has L's widths.
+func matchDigit(widths []int, left bool) (digit byte, even bool, ok bool) {
+	best := maxAvgVariance
+	for d, p := range digitPatterns {
+		if v := variance(widths, p[:]); v < best {
+			best, digit, even, ok = v, byte(d), false, true
+		}
+		if !left {
+			continue
+		}
+		g := []int{p[3], p[2], p[1], p[0]}
+		if v := variance(widths, g); v < best {
+			best, digit, even, ok = v, byte(d), true, true
+		}
+	}
+	return digit, even, ok
+}
+
+// variance measures how far measured widths are from pattern (in modules),
+// scaled to the same total width: the summed difference as a fraction of
+// the total, or +Inf-like 2 if any single run is off by more than
+// maxIndividualVariance modules.
+func variance(widths, pattern []int) float64 {
+	total := sum(widths)
+	patternTotal := sum(pattern)
+	if total < patternTotal {
+		// Narrower than one pixel per module: too small to read.
+		return 2
+	}
+	unit := float64(total) / float64(patternTotal)
+	var diff float64
+	for k, w := range widths {
+		d := float64(w) - float64(pattern[k])*unit
+		if d < 0 {
+			d = -d
+		}
+		if d > maxIndividualVariance*unit {
+			return 2
+		}
+		diff += d
+	}
+	return diff / float64(total)
+}
+
+func sum(xs []int) int {
+	t := 0
+	for _, x := range xs {
+		t += x
+	}
+	return t
+}
+
+// validCheckDigit reports whether the last of 13 digits is the EAN-13
+// check digit of the first 12.
+func validCheckDigit(digits []byte) bool {
+	s := 0
+	for k, d := range digits[:12] {
+		if k%2 == 0 {
+			s += int(d)
+		} else {
+			s += 3 * int(d)
+		}
+	}
+	return byte((10-s%10)%10) == digits[12]
+}
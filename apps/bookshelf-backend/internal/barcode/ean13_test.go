@@ -0,0 +1,156 @@
+package barcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encode returns the 95 modules (true = bar) of the EAN-13 barcode for a
+// 13-digit code, guards included.
+func encode(t *testing.T, code string) []bool {
+	t.Helper()
+	require.Len(t, code, 13)
+	var parity int
+	for p, first := range firstDigitParity {
+		if first == code[0]-'0' {
+			parity = p
+		}
+	}
+	var modules []bool
+	put := func(widths []int, startBar bool) {
+		bar := startBar
+		for _, w := range widths {
+			for k := 0; k < w; k++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	put(guardPattern, true)
+	for d := 0; d < 6; d++ {
+		p := digitPatterns[code[d+1]-'0']
+		if parity&(1<<(5-d)) != 0 {
+			p = [4]int{p[3], p[2], p[1], p[0]}
+		}
+		put(p[:], false)
+	}
+	put(middlePattern, false)
+	for d := 0; d < 6; d++ {
+		p := digitPatterns[code[d+7]-'0']
+		put(p[:], true)
+	}
+	put(guardPattern, true)
+	return modules
+}
+
+// drawBarcode paints a barcode at (x, y) with the given module width and bar height.
+func drawBarcode(img *image.Gray, modules []bool, x, y, module, height int) {
+	for i, bar := range modules {
+		if !bar {
+			continue
+		}
+		r := image.Rect(x+i*module, y, x+(i+1)*module, y+height)
+		draw.Draw(img, r, &image.Uniform{C: color.Gray{Y: 20}}, image.Point{}, draw.Src)
+	}
+}
+
+func blank(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.Gray{Y: 235}}, image.Point{}, draw.Src)
+	return img
+}
+
+// addNoise perturbs every pixel by up to ±amount, deterministically.
+func addNoise(img *image.Gray, amount int) {
+	rng := rand.New(rand.NewSource(1))
+	for i, v := range img.Pix {
+		n := int(v) + rng.Intn(2*amount+1) - amount
+		img.Pix[i] = uint8(min(255, max(0, n)))
+	}
+}
+
+// rotate90 returns img turned a quarter turn clockwise.
+func rotate90(img *image.Gray) *image.Gray {
+	b := img.Bounds()
+	out := image.NewGray(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			out.SetGray(b.Dy()-1-y, x, img.GrayAt(x, y))
+		}
+	}
+	return out
+}
+
+func rotate180(img *image.Gray) *image.Gray {
+	return rotate90(rotate90(img))
+}
+
+func TestDecodeEAN13(t *testing.T) {
+	const isbn = "9780306406157"
+
+	single := blank(400, 200)
+	drawBarcode(single, encode(t, isbn), 40, 40, 3, 120)
+
+	t.Run("upright", func(t *testing.T) {
+		assert.Equal(t, []string{isbn}, DecodeEAN13(single))
+	})
+
+	t.Run("upside down", func(t *testing.T) {
+		assert.Equal(t, []string{isbn}, DecodeEAN13(rotate180(single)))
+	})
+
+	t.Run("on its side", func(t *testing.T) {
+		assert.Equal(t, []string{isbn}, DecodeEAN13(rotate90(single)))
+	})
+
+	t.Run("noisy JPEG with uneven lighting", func(t *testing.T) {
+		img := blank(400, 200)
+		drawBarcode(img, encode(t, isbn), 40, 40, 2, 120)
+		// Darken the right half, as a shadow across the book would.
+		for y := 0; y < 200; y++ {
+			for x := 200; x < 400; x++ {
+				img.Pix[y*img.Stride+x] = uint8(int(img.Pix[y*img.Stride+x]) * 6 / 10)
+			}
+		}
+		addNoise(img, 20)
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}))
+		decoded, _, err := image.Decode(&buf)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{isbn}, DecodeEAN13(decoded))
+	})
+
+	t.Run("several books", func(t *testing.T) {
+		img := blank(800, 500)
+		drawBarcode(img, encode(t, isbn), 30, 30, 3, 100)
+		drawBarcode(img, encode(t, "9781861972712"), 420, 30, 3, 100)
+		drawBarcode(img, encode(t, "9780141439518"), 150, 300, 2, 150)
+
+		assert.ElementsMatch(t, []string{isbn, "9781861972712", "9780141439518"}, DecodeEAN13(img))
+	})
+
+	t.Run("nothing to find", func(t *testing.T) {
+		img := blank(300, 300)
+		addNoise(img, 60)
+		assert.Empty(t, DecodeEAN13(img))
+	})
+
+	t.Run("bad check digit is rejected", func(t *testing.T) {
+		img := blank(400, 200)
+		modules := encode(t, isbn)
+		// Re-encode the last right-hand digit as 8 instead of 7.
+		bad := encode(t, "9780306406158")
+		copy(modules[85:92], bad[85:92])
+		drawBarcode(img, modules, 40, 40, 3, 120)
+		assert.Empty(t, DecodeEAN13(img))
+	})
+}
@@ -1,6 +1,9 @@
 // Package bookmatch holds normalized-title+author matching, shared by
 // internal/handlers (search-result dedup/enrichment, catalog import fuzzy
-// match) and internal/services (catalog description reconciliation).
+// match) and internal/services (catalog description reconciliation), the
+// author-name parsing and normalization behind models.Author
+// (contributors.go), and typo-tolerant catalog search and wishlist
+// near-match detection (fuzzy.go).
 // internal/handlers already depends on internal/services, so this can't live
 // in either of those packages without creating an import cycle.
 package bookmatch
@@ -18,11 +21,23 @@ var nonAlphanumSpace = regexp.MustCompile(`[^a-z0-9 ]+`)
 // (title+author equality, no similarity scoring) and to bucket distinct
 // editions of the same work for cross-edition metadata enrichment.
 func NormalizeTitleAuthor(title, author string) string {
-	norm := func(s string) string {
-		s = strings.ToLower(strings.TrimSpace(s))
-		s = nonAlphanumSpace.ReplaceAllString(s, "")
-		s = strings.Join(strings.Fields(s), " ")
-		return s
+	return normalizeField(title) + "|" + normalizeField(author)
+}
+
+// normalizeField is NormalizeTitleAuthor's normalization of a single field.
+func normalizeField(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = nonAlphanumSpace.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// WorkKey returns the key grouping editions of the same work: the
+// NormalizeTitleAuthor key, or "" when title or author is empty — such a
+// book can't be told apart from an unrelated one with the same title, so it
+// stands alone.
+func WorkKey(title, author string) string {
+	if strings.TrimSpace(title) == "" || strings.TrimSpace(author) == "" {
+		return ""
 	}
-	return norm(title) + "|" + norm(author)
+	return NormalizeTitleAuthor(title, author)
 }
@@ -0,0 +1,225 @@
+package bookmatch
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Contributor roles, as stored on models.BookContributor.
+const (
+	RoleAuthor     = "author"
+	RoleEditor     = "editor"
+	RoleTranslator = "translator"
+)
+
+// Contributor is one person credited on a book, with their role.
+type Contributor struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// rolePrefixes are leading phrases that credit everyone after them with a
+// role, e.g. "translated by Richard Pevear and Larissa Volokhonsky".
+var rolePrefixes = []struct {
+	prefix, role string
+}{
+	{"edited by ", RoleEditor},
+	{"ed. by ", RoleEditor},
+	{"translated by ", RoleTranslator},
+	{"trans. by ", RoleTranslator},
+	{"tr. by ", RoleTranslator},
+}
+
+// roleSuffix matches a trailing role marker on a single name: "(Editor)",
+// "(eds.)", ", translator", "(trans.)" and so on. Any other parenthetical
+// (e.g. "(Illustrator)") is matched too and stripped, leaving the name
+// credited as an author — only the three roles above are modelled.
+var roleSuffix = regexp.MustCompile(`(?i)\s*(?:\(([^)]*)\)|,\s*(editor|editors|eds?\.?|translator|trans\.|tr\.))\s*$`)
+
+// nameSeparator splits a group of names: "&", a standalone "and", or "with".
+var nameSeparator = regexp.MustCompile(`(?i)\s*&\s*|\s+and\s+|\s+with\s+`)
+
+// nameSuffixes are generational suffixes that follow a comma without
+// starting a new name ("Martin Luther King, Jr.").
+var nameSuffixes = map[string]bool{"jr": true, "jr.": true, "sr": true, "sr.": true, "ii": true, "iii": true, "iv": true}
+
+// ParseContributors splits a free-text credit such as "Neil Gaiman & Terry
+// Pratchett" or "Leo Tolstoy; translated by Richard Pevear and Larissa
+// Volokhonsky" into normalized names with roles. A name credited twice
+// under the same role is kept once.
+func ParseContributors(credit string) []Contributor {
+	var out []Contributor
+	seen := map[string]bool{}
+	for _, group := range strings.Split(credit, ";") {
+		group = strings.TrimSpace(group)
+		role := RoleAuthor
+		lower := strings.ToLower(group)
+		for _, p := range rolePrefixes {
+			if strings.HasPrefix(lower, p.prefix) {
+				group, role = group[len(p.prefix):], p.role
+				break
+			}
+		}
+		for _, part := range nameSeparator.Split(group, -1) {
+			for _, name := range splitCommaNames(part) {
+				c := ParseContributor(name)
+				if c.Role == RoleAuthor {
+					c.Role = role
+				}
+				key := AuthorKey(c.Name) + "|" + c.Role
+				if c.Name == "" || seen[key] {
+					continue
+				}
+				seen[key] = true
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// splitCommaNames splits "A, B, C" into separate names, except where the
+// comma is part of one name: an inverted "Lewis, C.S." (a single-word
+// surname before exactly one comma) or a "King, Jr." suffix.
+func splitCommaNames(part string) []string {
+	// A trailing role marker may itself start with a comma.
+	marker := roleSuffix.FindString(part)
+	base := strings.TrimSuffix(part, marker)
+	pieces := strings.Split(base, ",")
+	if len(pieces) == 1 {
+		return []string{part}
+	}
+	if len(pieces) == 2 && len(strings.Fields(pieces[0])) == 1 && strings.TrimSpace(pieces[1]) != "" {
+		return []string{part}
+	}
+	var names []string
+	for _, p := range pieces {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if nameSuffixes[strings.ToLower(p)] && len(names) > 0 {
+			names[len(names)-1] += ", " + p
+			continue
+		}
+		names = append(names, p)
+	}
+	if len(names) > 0 {
+		names[len(names)-1] += marker
+	}
+	return names
+}
+
+// ParseContributor parses a single credited name, which may carry a role
+// marker ("Jane Doe (Translator)", "Smith, John, editor"), into a
+// normalized name and role. Unmarked names are authors.
+func ParseContributor(raw string) Contributor {
+	role := RoleAuthor
+	name := raw
+	if m := roleSuffix.FindStringSubmatch(raw); m != nil {
+		name = strings.TrimSuffix(raw, m[0])
+		marker := strings.ToLower(strings.Trim(m[1]+m[2], " ."))
+		switch {
+		case strings.HasPrefix(marker, "ed"):
+			role = RoleEditor
+		case strings.HasPrefix(marker, "tr"):
+			role = RoleTranslator
+		}
+	}
+	return Contributor{Name: NormalizeAuthorName(name), Role: role}
+}
+
+// NormalizeAuthorName returns the display form of a person's name, so the
+// same person spelled differently by different sources collapses to one
+// Author: whitespace collapsed, an inverted "Lewis, C.S." turned around,
+// and initials spaced out ("C.S." and "CS" both become "C. S."). A name
+// written entirely in one case is title-cased.
+func NormalizeAuthorName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.Trim(name, " ,;")
+	if surname, given, ok := strings.Cut(name, ","); ok && len(strings.Fields(surname)) == 1 {
+		given = strings.TrimSpace(given)
+		if given != "" && !nameSuffixes[strings.ToLower(given)] {
+			name = given + " " + surname
+		}
+	}
+	singleCase := isSingleCase(name)
+
+	tokens := strings.Fields(name)
+	var out []string
+	for i, tok := range tokens {
+		last := i == len(tokens)-1
+		// In an all-capitals name, only a one- or two-letter token ("CS")
+		// is taken for initials; "TOM" is far more likely a name.
+		if singleCase && (last || len([]rune(tok)) > 2 || !isUpperLetters(tok)) {
+			tok = titleCase(tok)
+		}
+		out = append(out, expandInitials(tok, last)...)
+	}
+	return strings.Join(out, " ")
+}
+
+// expandInitials splits a run of initials into one "X." token each: "C.S."
+// or "C.S" always, and a bare all-capitals "CS" or "JRR" (up to three
+// letters) unless it's the surname at the end.
+func expandInitials(tok string, last bool) []string {
+	letters := strings.ReplaceAll(tok, ".", "")
+	if letters == "" || !isUpperLetters(letters) {
+		return []string{tok}
+	}
+	dotted := strings.Contains(tok, ".")
+	if !dotted && (last || len([]rune(letters)) > 3) {
+		return []string{tok}
+	}
+	if dotted && strings.Count(tok, ".") < len([]rune(letters))-1 {
+		// e.g. "St." or "Ph.D" — not a run of single-letter initials.
+		return []string{tok}
+	}
+	var out []string
+	for _, r := range letters {
+		out = append(out, string(r)+".")
+	}
+	return out
+}
+
+func isUpperLetters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSingleCase reports whether name's letters are all upper or all lower
+// case (e.g. "TOLKIEN, J.R.R." from a library catalogue).
+func isSingleCase(name string) bool {
+	hasUpper, hasLower := false, false
+	for _, r := range name {
+		hasUpper = hasUpper || unicode.IsUpper(r)
+		hasLower = hasLower || unicode.IsLower(r)
+	}
+	return !(hasUpper && hasLower)
+}
+
+func titleCase(name string) string {
+	runes := []rune(strings.ToLower(name))
+	for i, r := range runes {
+		if i == 0 || !unicode.IsLetter(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// nonLetterDigit matches runs of anything other than a letter or digit.
+var nonLetterDigit = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// AuthorKey returns the matching key for a name: its normalized form,
+// lowercased, with punctuation dropped. Unlike NormalizeTitleAuthor it keeps
+// non-ASCII letters, which are common in author names.
+func AuthorKey(name string) string {
+	key := nonLetterDigit.ReplaceAllString(strings.ToLower(NormalizeAuthorName(name)), " ")
+	return strings.TrimSpace(key)
+}
@@ -0,0 +1,57 @@
+package bookmatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAuthorName(t *testing.T) {
+	cases := map[string]string{
+		"C. S. Lewis":             "C. S. Lewis",
+		"C.S. Lewis":              "C. S. Lewis",
+		"CS Lewis":                "C. S. Lewis",
+		"Lewis, C.S.":             "C. S. Lewis",
+		"  Lewis,   C. S. ":       "C. S. Lewis",
+		"TOLKIEN, J.R.R.":         "J. R. R. Tolkien",
+		"Martin Luther King, Jr.": "Martin Luther King, Jr.",
+		"Ursula K. Le Guin":       "Ursula K. Le Guin",
+		"Malcolm X":               "Malcolm X",
+		"flannery o'connor":       "Flannery O'Connor",
+		"Gabriel García Márquez":  "Gabriel García Márquez",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, NormalizeAuthorName(in), in)
+	}
+}
+
+func TestAuthorKey_MatchesSpellingVariants(t *testing.T) {
+	assert.Equal(t, AuthorKey("C. S. Lewis"), AuthorKey("Lewis, C.S."))
+	assert.Equal(t, AuthorKey("C. S. Lewis"), AuthorKey("CS LEWIS"))
+	assert.Equal(t, "gabriel garcía márquez", AuthorKey("Gabriel García Márquez"))
+	assert.NotEqual(t, AuthorKey("C. S. Lewis"), AuthorKey("C. S. Forester"))
+}
+
+func TestParseContributors(t *testing.T) {
+	cases := []struct {
+		credit string
+		want   []Contributor
+	}{
+		{"Lewis, C.S.", []Contributor{{"C. S. Lewis", RoleAuthor}}},
+		{"Neil Gaiman & Terry Pratchett", []Contributor{{"Neil Gaiman", RoleAuthor}, {"Terry Pratchett", RoleAuthor}}},
+		{"Neil Gaiman, Terry Pratchett", []Contributor{{"Neil Gaiman", RoleAuthor}, {"Terry Pratchett", RoleAuthor}}},
+		{
+			"Leo Tolstoy; translated by Richard Pevear and Larissa Volokhonsky",
+			[]Contributor{{"Leo Tolstoy", RoleAuthor}, {"Richard Pevear", RoleTranslator}, {"Larissa Volokhonsky", RoleTranslator}},
+		},
+		{"Jane Doe (Editor)", []Contributor{{"Jane Doe", RoleEditor}}},
+		{"Smith, John, ed.", []Contributor{{"John Smith", RoleEditor}}},
+		{"Edith Grossman (Translator)", []Contributor{{"Edith Grossman", RoleTranslator}}},
+		{"Quentin Blake (Illustrator)", []Contributor{{"Quentin Blake", RoleAuthor}}},
+		{"C.S. Lewis and CS Lewis", []Contributor{{"C. S. Lewis", RoleAuthor}}},
+		{"", nil},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, ParseContributors(tc.credit), tc.credit)
+	}
+}
@@ -0,0 +1,184 @@
+package bookmatch
+
+import "strings"
+
+// suggestThreshold is the trigram similarity a catalog title needs before
+// Suggest offers it. Below this, "did you mean" suggestions are more often
+// baffling than helpful.
+const suggestThreshold = 0.3
+
+// similarWorkThreshold is the trigram similarity both the titles and the
+// authors of two books need before SimilarWork calls them the same work.
+// Far stricter than suggestThreshold: a match here is put to a member as
+// "is this the book you wanted?", so near-misses cost their attention.
+const similarWorkThreshold = 0.6
+
+// maxTypos is how many edits a query word of n letters may be from a
+// catalog word and still match it: none for short words, where a single
+// edit turns "cat" into "car", one up to seven letters, two beyond.
+func maxTypos(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// FuzzyMatch reports whether every word of query matches some word of
+// title or author, after NormalizeTitleAuthor's normalization: as a
+// substring, the way the catalog's LIKE search matches, or within maxTypos
+// edits, counting two swapped neighbouring letters as one edit — so
+// "tolkein" finds Tolkien and "narnai" finds Narnia.
+func FuzzyMatch(query, title, author string) bool {
+	qWords := strings.Fields(normalizeField(query))
+	if len(qWords) == 0 {
+		return false
+	}
+	words := strings.Fields(normalizeField(title) + " " + normalizeField(author))
+	for _, q := range qWords {
+		if !matchesAnyWord(q, words) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAnyWord(q string, words []string) bool {
+	qr := []rune(q)
+	limit := maxTypos(len(qr))
+	for _, w := range words {
+		if strings.Contains(w, q) {
+			return true
+		}
+		wr := []rune(w)
+		if limit > 0 && abs(len(wr)-len(qr)) <= limit && osaDistance(qr, wr) <= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// osaDistance is the optimal string alignment distance between a and b:
+// the Levenshtein distance, plus transpositions of adjacent runes as a
+// single edit.
+func osaDistance(a, b []rune) int {
+	// Three rolling rows: two back (for transpositions), previous, current.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Suggest returns the title from titles that query most resembles, for a
+// "did you mean" prompt when a search comes back empty, or "" if none is
+// close enough. Similarity is by shared trigrams, against the whole title
+// and against every run of the title's words as long as the query, so
+// "hobit" can suggest "The Hobbit, or There and Back Again".
+func Suggest(query string, titles []string) string {
+	qWords := strings.Fields(normalizeField(query))
+	if len(qWords) == 0 {
+		return ""
+	}
+	qGrams := trigrams(qWords)
+	best, bestScore := "", suggestThreshold
+	for _, title := range titles {
+		words := strings.Fields(normalizeField(title))
+		score := similarity(qGrams, trigrams(words))
+		for i := 0; i+len(qWords) <= len(words); i++ {
+			score = max(score, similarity(qGrams, trigrams(words[i:i+len(qWords)])))
+		}
+		if score > bestScore {
+			best, bestScore = title, score
+		}
+	}
+	return best
+}
+
+// SimilarWork reports whether two title/author pairs plausibly name the
+// same work in different editions or spellings: "The Hobbit" by Tolkien and
+// "The Hobbit, or There and Back Again" by J.R.R. Tolkien, or "Harry Poter"
+// and "Harry Potter". Both the titles and the authors must clear
+// similarWorkThreshold, each compared the way Suggest compares a query with
+// a title — the shorter against every equally long run of the longer's
+// words. Like WorkKey, a pair missing either field never matches.
+func SimilarWork(title, author, otherTitle, otherAuthor string) bool {
+	if WorkKey(title, author) == "" || WorkKey(otherTitle, otherAuthor) == "" {
+		return false
+	}
+	return fieldSimilarity(title, otherTitle) >= similarWorkThreshold &&
+		fieldSimilarity(author, otherAuthor) >= similarWorkThreshold
+}
+
+// fieldSimilarity is the best trigram similarity between the shorter of a
+// and b (in words) and either the whole of the longer or any run of its
+// words as long as the shorter.
+func fieldSimilarity(a, b string) float64 {
+	aWords, bWords := strings.Fields(normalizeField(a)), strings.Fields(normalizeField(b))
+	if len(aWords) > len(bWords) {
+		aWords, bWords = bWords, aWords
+	}
+	if len(aWords) == 0 {
+		return 0
+	}
+	aGrams := trigrams(aWords)
+	score := similarity(aGrams, trigrams(bWords))
+	for i := 0; i+len(aWords) <= len(bWords); i++ {
+		score = max(score, similarity(aGrams, trigrams(bWords[i:i+len(aWords)])))
+	}
+	return score
+}
+
+// trigrams returns the set of three-letter sequences in words, each word
+// padded as PostgreSQL's pg_trgm does (two spaces before, one after) so
+// that word starts weigh more than word ends.
+func trigrams(words []string) map[string]bool {
+	grams := map[string]bool{}
+	for _, w := range words {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			grams[string(r[i:i+3])] = true
+		}
+	}
+	return grams
+}
+
+// similarity is the Jaccard index of two trigram sets.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for g := range a {
+		if b[g] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,69 @@
+package bookmatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	cases := []struct {
+		query, title, author string
+		want                 bool
+	}{
+		{"Tolkein", "The Hobbit", "J. R. R. Tolkien", true},
+		{"Narnai", "The Chronicles of Narnia", "C. S. Lewis", true},
+		{"hobbit", "The Hobbit", "J. R. R. Tolkien", true},
+		{"lord rings", "The Lord of the Rings", "J. R. R. Tolkien", true},
+		{"Hobit Tolkein", "The Hobbit", "J. R. R. Tolkien", true},
+		{"Dostoyevsky", "Crime and Punishment", "Fyodor Dostoevsky", true},
+		{"car", "The Cat in the Hat", "Dr. Seuss", false},
+		{"Tolkein", "The Chronicles of Narnia", "C. S. Lewis", false},
+		{"hobbit dune", "The Hobbit", "J. R. R. Tolkien", false},
+		{"   ", "The Hobbit", "J. R. R. Tolkien", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, FuzzyMatch(c.query, c.title, c.author), "%q vs %q / %q", c.query, c.title, c.author)
+	}
+}
+
+func TestOSADistance(t *testing.T) {
+	assert.Equal(t, 0, osaDistance([]rune("narnia"), []rune("narnia")))
+	assert.Equal(t, 1, osaDistance([]rune("narnai"), []rune("narnia")))
+	assert.Equal(t, 1, osaDistance([]rune("tolkein"), []rune("tolkien")))
+	assert.Equal(t, 2, osaDistance([]rune("hobit"), []rune("hobbits")))
+	assert.Equal(t, 3, osaDistance([]rune(""), []rune("abc")))
+}
+
+func TestSuggest(t *testing.T) {
+	titles := []string{
+		"The Hobbit, or There and Back Again",
+		"The Lion, the Witch and the Wardrobe",
+		"Pride and Prejudice",
+	}
+
+	assert.Equal(t, "The Hobbit, or There and Back Again", Suggest("hobbbitt", titles))
+	assert.Equal(t, "The Lion, the Witch and the Wardrobe", Suggest("the wich and the wardrob", titles))
+	assert.Equal(t, "Pride and Prejudice", Suggest("pryde and predjudice", titles))
+	assert.Empty(t, Suggest("quantum chromodynamics", titles))
+	assert.Empty(t, Suggest("", titles))
+}
+
+func TestSimilarWork(t *testing.T) {
+	cases := []struct {
+		title, author, otherTitle, otherAuthor string
+		want                                   bool
+	}{
+		{"The Hobbit", "Tolkien", "The Hobbit, or There and Back Again", "J.R.R. Tolkien", true},
+		{"Harry Poter and the Philosopher's Stone", "J. K. Rowling", "Harry Potter and the Philosopher's Stone", "J.K. Rowling", true},
+		{"Pride & Prejudice", "Jane Austen", "Pride and Prejudice", "Austen, Jane", true},
+		{"The Hobbit", "J. R. R. Tolkien", "The Silmarillion", "J. R. R. Tolkien", false},
+		{"Dune", "Frank Herbert", "Dune", "Jane Smith", false},
+		{"The Hobbit", "", "The Hobbit", "J. R. R. Tolkien", false},
+		{"", "J. R. R. Tolkien", "The Hobbit", "J. R. R. Tolkien", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, SimilarWork(c.title, c.author, c.otherTitle, c.otherAuthor),
+			"%q / %q vs %q / %q", c.title, c.author, c.otherTitle, c.otherAuthor)
+	}
+}
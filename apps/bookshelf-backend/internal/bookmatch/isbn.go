@@ -0,0 +1,62 @@
+package bookmatch
+
+import "strings"
+
+// NormalizeISBN strips hyphens/spaces and converts ISBN-10 to ISBN-13.
+// Returns "" if the input doesn't produce a valid 10- or 13-digit ISBN.
+func NormalizeISBN(s string) string {
+	s = strings.ReplaceAll(s, "-", "")
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.ToUpper(s)
+
+	switch len(s) {
+	case 13:
+		return normalizedISBN13(s)
+	case 10:
+		return isbn10ToISBN13(s)
+	default:
+		return ""
+	}
+}
+
+// normalizedISBN13 returns s if it's all digits, else "".
+func normalizedISBN13(s string) string {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return s
+}
+
+// isbn10ToISBN13 validates a 10-character ISBN-10 (last char may be 'X' = 10)
+// and converts it to ISBN-13: prepend "978", drop the old check digit,
+// compute a new one. Returns "" if s isn't a valid ISBN-10.
+func isbn10ToISBN13(s string) string {
+	for i, c := range s {
+		if i == 9 {
+			if c != 'X' && (c < '0' || c > '9') {
+				return ""
+			}
+		} else if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	prefix := "978" + s[:9]
+	check := isbn13CheckDigit(prefix)
+	return prefix + string([]byte{'0' + check})
+}
+
+// isbn13CheckDigit computes the ISBN-13 check digit for a 12-digit string.
+func isbn13CheckDigit(digits string) byte {
+	sum := 0
+	for i, c := range digits {
+		d := int(c - '0')
+		if i%2 == 0 {
+			sum += d
+		} else {
+			sum += d * 3
+		}
+	}
+	return byte((10 - (sum % 10)) % 10)
+}
@@ -0,0 +1,27 @@
+package bookmatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "already ISBN-13", in: "978-0-13-468599-1", want: "9780134685991"},
+		{name: "ISBN-10 converts to ISBN-13", in: "0-13-468599-7", want: "9780134685991"},
+		{name: "ISBN-10 with X check digit", in: "080442957X", want: "9780804429573"},
+		{name: "invalid length", in: "123", want: ""},
+		{name: "non-numeric ISBN-13", in: "97801346859XX", want: ""},
+		{name: "empty string", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeISBN(tt.in))
+		})
+	}
+}
@@ -28,7 +28,17 @@ type Config struct {
 	Env                     string   `env:"ENV" envDefault:"dev"`
 	GoogleBooksAPIKey       string   `env:"GOOGLE_BOOKS_API_KEY" sensitive:"true"`
 	MetadataRefreshInterval string   `env:"METADATA_REFRESH_INTERVAL" envDefault:"24h"`
+	MetadataCacheTTL        string   `env:"METADATA_CACHE_TTL" envDefault:"24h"`
+	MetadataCacheMaxEntries int      `env:"METADATA_CACHE_MAX_ENTRIES" envDefault:"5000"`
 	AppConfigPath           string   `env:"APP_CONFIG_PATH" envDefault:"./bookshelf.yaml"`
+	StorageBackend          string   `env:"STORAGE_BACKEND" envDefault:"local"`
+	S3Endpoint              string   `env:"S3_ENDPOINT"`
+	S3Region                string   `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket                string   `env:"S3_BUCKET"`
+	S3AccessKeyID           string   `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey       string   `env:"S3_SECRET_ACCESS_KEY" sensitive:"true"`
+	S3Prefix                string   `env:"S3_PREFIX"`
+	S3PathStyle             bool     `env:"S3_PATH_STYLE"`
 }
 
 // Load reads configuration from environment variables, applying envDefault
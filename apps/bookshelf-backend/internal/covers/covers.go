@@ -0,0 +1,283 @@
+// Package covers stores downloaded book cover images and serves them back.
+//
+// A cover that decodes (JPEG, PNG or GIF) is stored as three JPEGs — a
+// thumbnail for catalog grids, a medium size for detail pages, and a
+// full size capped so a provider's print-resolution scan doesn't end up on
+// every page — plus a BlurHash placeholder computed from the thumbnail.
+// One that doesn't decode (WebP: there's no WebP codec in the standard
+// library) is stored as downloaded, with no variants or placeholder, and
+// Handler serves the original whatever size is asked for. One bigger than
+// MaxPixels is refused before it's decoded.
+package covers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder with image.Decode
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder with image.Decode
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
+)
+
+// MaxBytes is the maximum size accepted for a downloaded cover image (10 MiB).
+const MaxBytes = 10 << 20
+
+// MaxPixels caps a cover's decoded size, checked from its header before
+// decoding: a small, highly compressed file could otherwise expand into
+// gigabytes. It's well past any print-resolution scan.
+const MaxPixels = 25_000_000
+
+// ErrTooLarge is returned for an image bigger than MaxPixels.
+var ErrTooLarge = errors.New("cover image is too large")
+
+// jpegQuality is used for every variant Store writes.
+const jpegQuality = 85
+
+// Size names one of a cover's stored variants.
+type Size string
+
+const (
+	Thumb  Size = "thumb"
+	Medium Size = "medium"
+	Full   Size = "full"
+)
+
+// sizeWidths is each variant's maximum width in pixels. Covers are never
+// scaled up: a source narrower than a variant is stored at its own width.
+var sizeWidths = map[Size]int{
+	Thumb:  160,
+	Medium: 400,
+	Full:   1200,
+}
+
+// ParseSize parses a size query parameter; "" means Full.
+func ParseSize(s string) (Size, bool) {
+	if s == "" {
+		return Full, true
+	}
+	size := Size(s)
+	_, ok := sizeWidths[size]
+	return size, ok
+}
+
+// Stored describes a cover written to the covers store.
+type Stored struct {
+	// Filename is the full-size file's key in the covers store.
+	Filename string
+	// Blurhash is the cover's placeholder, or "" if it couldn't be decoded.
+	Blurhash string
+}
+
+// BaseName is the deterministic filename stem for a cover downloaded from
+// externalURL: the first 8 bytes of its SHA-256, as hex.
+func BaseName(externalURL string) string {
+	sum := sha256.Sum256([]byte(externalURL))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// VariantName returns the name of the file holding filename's variant at
+// size. Full is filename itself.
+func VariantName(filename string, size Size) string {
+	if size == Full {
+		return filename
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + "-" + string(size) + ".jpg"
+}
+
+// Download fetches externalURL and stores it in store, or returns the
+// cover already stored for it. Callers are responsible for deciding the URL
+// is safe to fetch.
+func Download(ctx context.Context, client *http.Client, externalURL string, store storage.Store) (Stored, error) {
+	base := BaseName(externalURL)
+	if stored, ok := existing(ctx, store, base); ok {
+		return stored, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, externalURL, nil)
+	if err != nil {
+		return Stored{}, err
+	}
+	resp, err := client.Do(req) //nolint:gosec
+	if err != nil {
+		return Stored{}, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return Stored{}, fmt.Errorf("cover fetch returned %d", resp.StatusCode)
+	}
+	ct := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(ct, "image/") {
+		return Stored{}, fmt.Errorf("unexpected content-type %q", ct)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxBytes))
+	if err != nil {
+		return Stored{}, err
+	}
+	return Store(ctx, store, base, data, ct)
+}
+
+// Store writes the image in data to store as base's variants, returning
+// the full-size file's name and the placeholder. Data that doesn't decode
+// is written as-is, named by contentType, with no placeholder; an image
+// bigger than MaxPixels isn't written at all, and ErrTooLarge is returned.
+func Store(ctx context.Context, store storage.Store, base string, data []byte, contentType string) (Stored, error) {
+	img, err := decode(data)
+	if errors.Is(err, ErrTooLarge) {
+		return Stored{}, err
+	}
+	if err != nil {
+		filename := base + extFor(contentType)
+		if err := store.Put(ctx, filename, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+			return Stored{}, err
+		}
+		return Stored{Filename: filename}, nil
+	}
+	filename := base + ".jpg"
+	hash, err := writeVariants(ctx, store, filename, img, true)
+	if err != nil {
+		return Stored{}, err
+	}
+	return Stored{Filename: filename, Blurhash: hash}, nil
+}
+
+// Reprocess writes the thumbnail and medium variants of a cover stored
+// before Store made them, leaving the original untouched, and returns its
+// placeholder.
+func Reprocess(ctx context.Context, store storage.Store, filename string) (Stored, error) {
+	r, _, err := store.Get(ctx, filename)
+	if err != nil {
+		return Stored{}, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxBytes))
+	_ = r.Close()
+	if err != nil {
+		return Stored{}, err
+	}
+	img, err := decode(data)
+	if err != nil {
+		return Stored{Filename: filename}, err
+	}
+	hash, err := writeVariants(ctx, store, filename, img, false)
+	if err != nil {
+		return Stored{}, err
+	}
+	return Stored{Filename: filename, Blurhash: hash}, nil
+}
+
+// decode decodes data, returning ErrTooLarge without decoding it when its
+// header says it's bigger than MaxPixels.
+func decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Remove deletes filename and its variants from store. Files that don't
+// exist are skipped.
+func Remove(ctx context.Context, store storage.Store, filename string) error {
+	var errs []error
+	for _, size := range []Size{Full, Medium, Thumb} {
+		if err := store.Delete(ctx, VariantName(filename, size)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RemoveCached removes the cached file coverURL points at, and its
+// variants, when it points into store at all; a bare external URL is left
+// alone.
+func RemoveCached(ctx context.Context, store storage.Store, coverURL string) error {
+	if store == nil || !strings.HasPrefix(coverURL, localPrefix) {
+		return nil
+	}
+	return Remove(ctx, store, strings.TrimPrefix(coverURL, localPrefix))
+}
+
+// existing returns the cover already stored under base, if there is one,
+// with its placeholder recomputed from the thumbnail.
+func existing(ctx context.Context, store storage.Store, base string) (Stored, bool) {
+	for _, ext := range []string{".jpg", ".png", ".webp", ".gif"} {
+		filename := base + ext
+		if _, err := store.Stat(ctx, filename); err != nil {
+			continue
+		}
+		stored := Stored{Filename: filename}
+		if r, _, err := store.Get(ctx, VariantName(filename, Thumb)); err == nil {
+			if thumb, err := jpeg.Decode(r); err == nil {
+				stored.Blurhash = blurhash(flatten(thumb))
+			}
+			_ = r.Close()
+		}
+		return stored, true
+	}
+	return Stored{}, false
+}
+
+// writeVariants writes img's variants for filename to store — the full
+// size to filename itself only if writeFull — and returns the placeholder.
+func writeVariants(ctx context.Context, store storage.Store, filename string, img image.Image, writeFull bool) (string, error) {
+	src := flatten(img)
+	var hash string
+	for _, size := range []Size{Full, Medium, Thumb} {
+		if size == Full && !writeFull {
+			continue
+		}
+		scaled := resize(src, sizeWidths[size])
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return "", err
+		}
+		if err := store.Put(ctx, VariantName(filename, size), bytes.NewReader(buf.Bytes()), int64(buf.Len()), "image/jpeg"); err != nil {
+			return "", err
+		}
+		if size == Thumb {
+			// Hashed from the encoded thumbnail, as existing does, so a
+			// cover's placeholder is the same however it was found.
+			thumb, err := jpeg.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				return "", err
+			}
+			hash = blurhash(flatten(thumb))
+		}
+	}
+	return hash, nil
+}
+
+// flatten draws img onto an opaque white RGBA canvas: JPEG has no alpha,
+// and the resize and blurhash loops read RGBA pixels directly.
+func flatten(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Over)
+	return out
+}
+
+func extFor(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "png"):
+		return ".png"
+	case strings.Contains(contentType, "webp"):
+		return ".webp"
+	}
+	return ".jpg"
+}
@@ -0,0 +1,219 @@
+package covers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
+)
+
+func newLocalStore(t *testing.T, dir string) storage.Store {
+	t.Helper()
+	store, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+	return store
+}
+
+// encodePNG returns a w×h PNG filled with c.
+func encodePNG(t *testing.T, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func decodeJPEGFile(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path) //nolint:gosec
+	require.NoError(t, err)
+	defer f.Close() //nolint:errcheck
+	img, err := jpeg.Decode(f)
+	require.NoError(t, err)
+	return img
+}
+
+func TestStore_WritesSizedVariantsAndBlurhash(t *testing.T) {
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+
+	stored, err := Store(context.Background(), store, "abc", encodePNG(t, 1600, 2400, color.RGBA{R: 200, G: 30, B: 30, A: 255}), "image/png")
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc.jpg", stored.Filename)
+	for size, want := range map[Size]image.Point{Full: {1200, 1800}, Medium: {400, 600}, Thumb: {160, 240}} {
+		img := decodeJPEGFile(t, filepath.Join(dir, VariantName(stored.Filename, size)))
+		assert.Equal(t, want, img.Bounds().Size(), size)
+	}
+	require.Len(t, stored.Blurhash, 28)
+	assert.Equal(t, "T", stored.Blurhash[:1], "3x4 components")
+}
+
+func TestStore_NeverScalesUp(t *testing.T) {
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+
+	stored, err := Store(context.Background(), store, "small", encodePNG(t, 100, 150, color.White), "image/png")
+	require.NoError(t, err)
+
+	for _, size := range []Size{Full, Medium, Thumb} {
+		img := decodeJPEGFile(t, filepath.Join(dir, VariantName(stored.Filename, size)))
+		assert.Equal(t, image.Pt(100, 150), img.Bounds().Size(), size)
+	}
+}
+
+func TestStore_KeepsUndecodableImageAsIs(t *testing.T) {
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+
+	stored, err := Store(context.Background(), store, "abc", []byte("RIFF....WEBPVP8 "), "image/webp")
+	require.NoError(t, err)
+
+	assert.Equal(t, Stored{Filename: "abc.webp"}, stored)
+	data, err := os.ReadFile(filepath.Join(dir, "abc.webp")) //nolint:gosec
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF....WEBPVP8 ", string(data))
+	_, err = os.Stat(filepath.Join(dir, "abc-thumb.jpg"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// hugeGIF is a GIF header claiming a 65535×65535 image, with no image data
+// behind it.
+var hugeGIF = []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00")
+
+func TestStore_RejectsImageOverPixelCap(t *testing.T) {
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+
+	_, err := Store(context.Background(), store, "abc", hugeGIF, "image/gif")
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing is written, not even as-is")
+}
+
+func TestReprocess_RejectsImageOverPixelCap(t *testing.T) {
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "legacy.gif"), hugeGIF, 0o600))
+
+	_, err := Reprocess(context.Background(), store, "legacy.gif")
+	require.ErrorIs(t, err, ErrTooLarge)
+	_, err = os.Stat(filepath.Join(dir, "legacy-thumb.jpg"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestDownload_ReusesStoredCover(t *testing.T) {
+	var fetches atomic.Int32
+	body := encodePNG(t, 300, 450, color.RGBA{B: 255, A: 255})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+
+	first, err := Download(context.Background(), srv.Client(), srv.URL+"/cover.png", store)
+	require.NoError(t, err)
+	second, err := Download(context.Background(), srv.Client(), srv.URL+"/cover.png", store)
+	require.NoError(t, err)
+
+	assert.Equal(t, BaseName(srv.URL+"/cover.png")+".jpg", first.Filename)
+	assert.NotEmpty(t, first.Blurhash)
+	assert.Equal(t, first, second, "the placeholder is recomputed from the stored thumbnail")
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestDownload_RejectsNonImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+	defer srv.Close()
+
+	_, err := Download(context.Background(), srv.Client(), srv.URL, newLocalStore(t, t.TempDir()))
+	assert.ErrorContains(t, err, "unexpected content-type")
+}
+
+func TestReprocess_AddsVariantsAndLeavesOriginal(t *testing.T) {
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+	original := encodePNG(t, 800, 1200, color.White)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "legacy.png"), original, 0o600))
+
+	stored, err := Reprocess(context.Background(), store, "legacy.png")
+	require.NoError(t, err)
+
+	assert.Equal(t, "legacy.png", stored.Filename)
+	assert.NotEmpty(t, stored.Blurhash)
+	data, err := os.ReadFile(filepath.Join(dir, "legacy.png")) //nolint:gosec
+	require.NoError(t, err)
+	assert.Equal(t, original, data)
+	assert.Equal(t, image.Pt(160, 240), decodeJPEGFile(t, filepath.Join(dir, "legacy-thumb.jpg")).Bounds().Size())
+	assert.Equal(t, image.Pt(400, 600), decodeJPEGFile(t, filepath.Join(dir, "legacy-medium.jpg")).Bounds().Size())
+}
+
+func TestRemove_DeletesEveryVariant(t *testing.T) {
+	dir := t.TempDir()
+	store := newLocalStore(t, dir)
+	stored, err := Store(context.Background(), store, "abc", encodePNG(t, 500, 750, color.White), "image/png")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.jpg"), []byte("x"), 0o600))
+
+	require.NoError(t, Remove(context.Background(), store, stored.Filename))
+	require.NoError(t, Remove(context.Background(), store, stored.Filename), "already-removed files are skipped")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"other.jpg"}, names)
+}
+
+// decodeDC reads a blurhash's average colour back out of its 4-character
+// DC component.
+func decodeDC(t *testing.T, hash string) (r, g, b int) {
+	t.Helper()
+	v := 0
+	for _, c := range hash[2:6] {
+		v = v*83 + strings.IndexRune(base83Chars, c)
+	}
+	return v >> 16, v >> 8 & 0xff, v & 0xff
+}
+
+func TestBlurhash_SolidColour(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 40))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 10, 120, 240, 255
+	}
+
+	hash := blurhash(img)
+
+	require.Len(t, hash, 28)
+	r, g, b := decodeDC(t, hash)
+	assert.Equal(t, [3]int{10, 120, 240}, [3]int{r, g, b})
+}
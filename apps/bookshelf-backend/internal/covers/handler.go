@@ -0,0 +1,173 @@
+package covers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
+)
+
+// localPrefix is how a Book.CoverURL points at a cover in the covers store.
+const localPrefix = "/api/covers/"
+
+// allowedHosts is the set of trusted external image hosts. Only URLs whose
+// host matches one of these suffixes are fetched server-side, preventing
+// SSRF attacks from user-supplied cover_url values, or redirected to by
+// BookHandler, which would otherwise be an open redirect.
+var allowedHosts = []string{
+	"covers.openlibrary.org",
+	"books.google.com",
+	"books.googleusercontent.com",
+	"cover.books.readmill.com",
+}
+
+// AllowedURL reports whether rawURL is an http(s) URL on one of the
+// trusted cover hosts, so safe to fetch or redirect to.
+func AllowedURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range allowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler serves the covers in store, to be mounted behind
+// http.StripPrefix. A ?size=thumb or ?size=medium query parameter selects
+// that variant, falling back to the original for covers stored without
+// variants; any other size is a 400. Anything but a plain file name —
+// including directory-listing requests (a trailing slash) — is a 404.
+func Handler(store storage.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filename := r.URL.Path
+		if !storage.ValidKey(filename) {
+			http.NotFound(w, r)
+			return
+		}
+		size, ok := ParseSize(r.URL.Query().Get("size"))
+		if !ok {
+			http.Error(w, "size must be one of thumb, medium, full", http.StatusBadRequest)
+			return
+		}
+		// Filenames are content-addressed (SHA-256 of the source URL), so a
+		// given path's content never changes — safe to cache indefinitely.
+		serve(w, r, store, filename, size, "public, max-age=31536000, immutable")
+	})
+}
+
+// BookHandler serves a book's cover whatever state it's in, for a route
+// pattern with an {id} wildcard: the cached cover at ?size= like Handler,
+// a redirect to an external cover not cached yet if it's on an AllowedURL
+// host, or — for a book with no cover at all, or one elsewhere — a
+// Placeholder, generated on first request and kept in store. What a
+// book's URL serves changes when it gets a real cover, so responses are
+// only cached for an hour.
+func BookHandler(store storage.Store, books repository.BookRepository) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		size, ok := ParseSize(r.URL.Query().Get("size"))
+		if !ok {
+			http.Error(w, "size must be one of thumb, medium, full", http.StatusBadRequest)
+			return
+		}
+		book, err := books.GetByID(uint(id))
+		if errors.Is(err, repository.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Uint64("book_id", id).Msg("covers: failed to load book")
+			http.Error(w, "could not load book", http.StatusInternalServerError)
+			return
+		}
+
+		const cacheControl = "public, max-age=3600"
+		switch {
+		case strings.HasPrefix(book.CoverURL, localPrefix):
+			serve(w, r, store, strings.TrimPrefix(book.CoverURL, localPrefix), size, cacheControl)
+		case AllowedURL(book.CoverURL):
+			w.Header().Set("Cache-Control", cacheControl)
+			http.Redirect(w, r, book.CoverURL, http.StatusFound)
+		default:
+			servePlaceholder(w, r, store, book.ID, book.Title, book.Author, cacheControl)
+		}
+	})
+}
+
+// serve writes filename's variant at size from store, or the original if
+// that variant doesn't exist.
+func serve(w http.ResponseWriter, r *http.Request, store storage.Store, filename string, size Size, cacheControl string) {
+	body, info, err := store.Get(r.Context(), VariantName(filename, size))
+	if errors.Is(err, storage.ErrNotFound) && size != Full {
+		// The original stands in for a variant the cover-refresh job
+		// may yet generate, so this response mustn't be pinned.
+		cacheControl = "public, max-age=3600"
+		body, info, err = store.Get(r.Context(), filename)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("filename", filename).Msg("covers: failed to read cover")
+		http.Error(w, "could not read cover", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close() //nolint:errcheck
+	write(w, r, body, info, cacheControl)
+}
+
+// servePlaceholder writes book id's placeholder from store, generating and
+// storing it first if it isn't there yet.
+func servePlaceholder(w http.ResponseWriter, r *http.Request, store storage.Store, id uint, title, author, cacheControl string) {
+	filename := PlaceholderName(id, title, author)
+	body, info, err := store.Get(r.Context(), filename)
+	if err == nil {
+		defer body.Close() //nolint:errcheck
+		write(w, r, body, info, cacheControl)
+		return
+	}
+
+	data := Placeholder(title, author)
+	if err := store.Put(r.Context(), filename, bytes.NewReader(data), int64(len(data)), PlaceholderContentType); err != nil {
+		// Still worth serving; it's generated again next time.
+		log.Warn().Err(err).Str("filename", filename).Msg("covers: failed to cache placeholder")
+	}
+	info = storage.ObjectInfo{Key: filename, Size: int64(len(data)), ContentType: PlaceholderContentType}
+	write(w, r, bytes.NewReader(data), info, cacheControl)
+}
+
+func write(w http.ResponseWriter, r *http.Request, body io.Reader, info storage.ObjectInfo, cacheControl string) {
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	if !info.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = io.Copy(w, body)
+}
@@ -0,0 +1,138 @@
+package covers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func serveCover(t *testing.T, dir, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	h := http.StripPrefix("/covers/", Handler(newLocalStore(t, dir)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHandler_ServesRequestedSize(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{"abc.jpg": "full", "abc-thumb.jpg": "thumb", "abc-medium.jpg": "medium"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600))
+	}
+
+	for target, want := range map[string]string{
+		"/covers/abc.jpg":             "full",
+		"/covers/abc.jpg?size=full":   "full",
+		"/covers/abc.jpg?size=medium": "medium",
+		"/covers/abc.jpg?size=thumb":  "thumb",
+	} {
+		rec := serveCover(t, dir, target)
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		assert.Equal(t, want, rec.Body.String(), target)
+		assert.Contains(t, rec.Header().Get("Cache-Control"), "immutable", target)
+	}
+}
+
+func TestHandler_FallsBackToOriginalWithoutVariants(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "abc.webp"), []byte("original"), 0o600))
+
+	rec := serveCover(t, dir, "/covers/abc.webp?size=thumb")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "original", rec.Body.String())
+	assert.NotContains(t, rec.Header().Get("Cache-Control"), "immutable")
+}
+
+func TestHandler_Rejects(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "abc.jpg"), []byte("full"), 0o600))
+
+	assert.Equal(t, http.StatusBadRequest, serveCover(t, dir, "/covers/abc.jpg?size=huge").Code)
+	assert.Equal(t, http.StatusNotFound, serveCover(t, dir, "/covers/").Code)
+	assert.Equal(t, http.StatusNotFound, serveCover(t, dir, "/covers/missing.jpg").Code)
+}
+
+func serveBookCover(t *testing.T, dir string, books *repotest.BookRepository, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle("GET /covers/books/{id}", BookHandler(newLocalStore(t, dir), books))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestBookHandler_GeneratesAndCachesAPlaceholder(t *testing.T) {
+	dir := t.TempDir()
+	books := repotest.NewBookRepository()
+	book := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, books.Create(&book))
+
+	rec := serveBookCover(t, dir, books, fmt.Sprintf("/covers/books/%d", book.ID))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/svg+xml", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, string(Placeholder("Dune", "Frank Herbert")), rec.Body.String())
+	cached, err := os.ReadFile(filepath.Join(dir, PlaceholderName(book.ID, "Dune", "Frank Herbert")))
+	require.NoError(t, err, "the placeholder should be kept in the covers store")
+	assert.Equal(t, rec.Body.String(), string(cached))
+
+	// Served from the store from then on.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PlaceholderName(book.ID, "Dune", "Frank Herbert")), []byte("<svg/>"), 0o600))
+	rec = serveBookCover(t, dir, books, fmt.Sprintf("/covers/books/%d", book.ID))
+	assert.Equal(t, "<svg/>", rec.Body.String())
+}
+
+func TestBookHandler_ServesTheRealCoverOnceThereIsOne(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "abc.jpg"), []byte("full"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "abc-thumb.jpg"), []byte("thumb"), 0o600))
+	books := repotest.NewBookRepository()
+	cached := models.Book{Title: "Cached", Author: "A", CoverURL: "/api/covers/abc.jpg"}
+	require.NoError(t, books.Create(&cached))
+	external := models.Book{Title: "External", Author: "A", CoverURL: "https://covers.openlibrary.org/b/id/1-L.jpg"}
+	require.NoError(t, books.Create(&external))
+
+	rec := serveBookCover(t, dir, books, fmt.Sprintf("/covers/books/%d?size=thumb", cached.ID))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "thumb", rec.Body.String())
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get("Cache-Control"),
+		"a book's cover URL can change what it serves, unlike a cover file's")
+
+	rec = serveBookCover(t, dir, books, fmt.Sprintf("/covers/books/%d", external.ID))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, external.CoverURL, rec.Header().Get("Location"))
+}
+
+func TestBookHandler_DoesNotRedirectOffTheCoverHosts(t *testing.T) {
+	books := repotest.NewBookRepository()
+	for _, coverURL := range []string{"https://evil.example.com/phish", "javascript:alert(1)", "//evil.example.com/x.jpg"} {
+		book := models.Book{Title: "Dune", Author: "Frank Herbert", CoverURL: coverURL}
+		require.NoError(t, books.Create(&book))
+
+		rec := serveBookCover(t, t.TempDir(), books, fmt.Sprintf("/covers/books/%d", book.ID))
+		assert.Equal(t, http.StatusOK, rec.Code, coverURL)
+		assert.Empty(t, rec.Header().Get("Location"), coverURL)
+		assert.Equal(t, "image/svg+xml", rec.Header().Get("Content-Type"), "a placeholder is served instead")
+	}
+}
+
+func TestBookHandler_RejectsUnknownBooksAndSizes(t *testing.T) {
+	books := repotest.NewBookRepository()
+	book := models.Book{Title: "Dune", Author: "A"}
+	require.NoError(t, books.Create(&book))
+
+	assert.Equal(t, http.StatusNotFound, serveBookCover(t, t.TempDir(), books, "/covers/books/999").Code)
+	assert.Equal(t, http.StatusNotFound, serveBookCover(t, t.TempDir(), books, "/covers/books/abc").Code)
+	assert.Equal(t, http.StatusBadRequest, serveBookCover(t, t.TempDir(), books, fmt.Sprintf("/covers/books/%d?size=huge", book.ID)).Code)
+}
@@ -0,0 +1,128 @@
+package covers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf16"
+)
+
+// PlaceholderContentType is the content type of a Placeholder.
+const PlaceholderContentType = "image/svg+xml"
+
+const (
+	placeholderWidth  = 300
+	placeholderHeight = 450
+)
+
+// PlaceholderName is the covers-store filename of the placeholder for book
+// id with the given title and author. It changes when either does, so an
+// edited book gets a fresh placeholder rather than the cached old one.
+func PlaceholderName(id uint, title, author string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + author))
+	return fmt.Sprintf("placeholder-%d-%x.svg", id, sum[:4])
+}
+
+// Placeholder renders a generated cover for a book that has none: its
+// title and author over a gradient whose hue comes from the title. It is
+// the same design, in the same colours, as the frontend draws in place of
+// a missing cover (BookCoverFallback), for clients that only take an image
+// URL, like OPDS readers.
+func Placeholder(title, author string) []byte {
+	hue := titleHash(title) % 360
+	toHue := (hue + 42) % 360
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`,
+		placeholderWidth, placeholderHeight, placeholderWidth, placeholderHeight)
+	b.WriteString(`<defs>`)
+	fmt.Fprintf(&b, `<linearGradient id="fill" x1="0" y1="0" x2="1" y2="1"><stop offset="0%%" stop-color="hsl(%d, 55%%, 62%%)"/><stop offset="100%%" stop-color="hsl(%d, 60%%, 42%%)"/></linearGradient>`, hue, toHue)
+	b.WriteString(`<linearGradient id="scrim" x1="0" y1="0" x2="0" y2="1"><stop offset="55%" stop-color="black" stop-opacity="0"/><stop offset="100%" stop-color="black" stop-opacity="0.45"/></linearGradient>`)
+	b.WriteString(`</defs>`)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="url(#fill)"/>`, placeholderWidth, placeholderHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="url(#scrim)"/>`, placeholderWidth, placeholderHeight)
+	for _, l := range placeholderLines(wrapLines(title, 15, 3), wrapLines(author, 24, 1)) {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="middle" font-size="%d" font-weight="%d" fill="white" font-family="system-ui, sans-serif">%s</text>`,
+			placeholderWidth/2, l.y, l.fontSize, l.fontWeight, html.EscapeString(l.text))
+	}
+	b.WriteString(`</svg>`)
+	return []byte(b.String())
+}
+
+// titleHash is the frontend's hashString (lib/bookCoverColors.ts): DJB2
+// with XOR over the title's UTF-16 code units, in 32 bits.
+func titleHash(title string) uint32 {
+	h := uint32(5381)
+	for _, c := range utf16.Encode([]rune(title)) {
+		h = (h * 33) ^ uint32(c)
+	}
+	return h
+}
+
+type placeholderLine struct {
+	text                    string
+	y, fontSize, fontWeight int
+}
+
+// placeholderLines stacks the title lines above the author line, anchored
+// to the bottom of the cover.
+func placeholderLines(titleLines, authorLines []string) []placeholderLine {
+	const (
+		titleLineHeight  = 34
+		authorLineHeight = 24
+		gap              = 10
+		bottomPadding    = 30
+	)
+	height := len(titleLines) * titleLineHeight
+	if len(authorLines) > 0 {
+		height += gap + len(authorLines)*authorLineHeight
+	}
+
+	var lines []placeholderLine
+	cursor := placeholderHeight - bottomPadding - height
+	for _, text := range titleLines {
+		lines = append(lines, placeholderLine{text: text, y: cursor + titleLineHeight/2, fontSize: 28, fontWeight: 700})
+		cursor += titleLineHeight
+	}
+	cursor += gap
+	for _, text := range authorLines {
+		lines = append(lines, placeholderLine{text: text, y: cursor + authorLineHeight/2, fontSize: 18, fontWeight: 500})
+		cursor += authorLineHeight
+	}
+	return lines
+}
+
+// wrapLines greedily word-wraps text into at most maxLines lines of
+// maxChars characters, ending the last with an ellipsis if text had to be
+// cut short.
+func wrapLines(text string, maxChars, maxLines int) []string {
+	words := strings.Fields(text)
+	var lines []string
+	current := ""
+	i := 0
+	for i < len(words) && len(lines) < maxLines {
+		candidate := words[i]
+		if current != "" {
+			candidate = current + " " + words[i]
+		}
+		if current == "" || len([]rune(candidate)) <= maxChars {
+			current = candidate
+			i++
+			continue
+		}
+		lines = append(lines, current)
+		current = ""
+	}
+	if current != "" && len(lines) < maxLines {
+		lines = append(lines, current)
+	}
+	if i < len(words) && len(lines) > 0 {
+		last := []rune(lines[len(lines)-1])
+		if len(last)+1 > maxChars {
+			last = []rune(strings.TrimRight(string(last[:max(0, maxChars-1)]), " "))
+		}
+		lines[len(lines)-1] = string(last) + "…"
+	}
+	return lines
+}
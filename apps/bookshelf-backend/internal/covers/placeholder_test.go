@@ -0,0 +1,47 @@
+package covers
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholder_IsDeterministicWellFormedSVG(t *testing.T) {
+	svg := Placeholder("Dune <Messiah> & More", "Frank Herbert")
+
+	assert.Equal(t, svg, Placeholder("Dune <Messiah> & More", "Frank Herbert"))
+	var doc struct {
+		XMLName xml.Name
+		Texts   []string `xml:"text"`
+	}
+	require.NoError(t, xml.Unmarshal(svg, &doc), "title text must be escaped")
+	assert.Equal(t, "svg", doc.XMLName.Local)
+	assert.Equal(t, []string{"Dune <Messiah>", "& More", "Frank Herbert"}, doc.Texts)
+}
+
+// The hues are what the frontend's gradientForTitle gives the same titles.
+func TestPlaceholder_ColourMatchesTheFrontend(t *testing.T) {
+	assert.Contains(t, string(Placeholder("Dune", "")), "hsl(23, 55%, 62%)")
+	assert.Contains(t, string(Placeholder("Dune", "Frank Herbert")), "hsl(23, 55%, 62%)", "the author doesn't count")
+	assert.Contains(t, string(Placeholder("Emma", "Jane Austen")), "hsl(97, 55%, 62%)")
+	assert.Contains(t, string(Placeholder("Café 📚", "")), "hsl(143, 55%, 62%)", "hashed as UTF-16, like JavaScript strings")
+}
+
+func TestPlaceholderName_ChangesWithTitleAndAuthor(t *testing.T) {
+	name := PlaceholderName(3, "Dune", "Frank Herbert")
+	assert.Regexp(t, `^placeholder-3-[0-9a-f]{8}\.svg$`, name)
+	assert.Equal(t, name, PlaceholderName(3, "Dune", "Frank Herbert"))
+	assert.NotEqual(t, name, PlaceholderName(3, "Dune Messiah", "Frank Herbert"))
+	assert.NotEqual(t, name, PlaceholderName(3, "Dune", "F. Herbert"))
+}
+
+func TestWrapLines(t *testing.T) {
+	assert.Empty(t, wrapLines("", 15, 3))
+	assert.Equal(t, []string{"The Left Hand", "of Darkness"}, wrapLines("The Left Hand of Darkness", 15, 3))
+	assert.Equal(t, []string{"Supercalifragilistic"}, wrapLines("Supercalifragilistic", 15, 3),
+		"a word longer than a line gets a line to itself")
+	assert.Equal(t, []string{"one two…"}, wrapLines("one two three", 8, 1))
+	assert.Equal(t, []string{"aaaaaaa…"}, wrapLines("aaaaaaaa bb", 8, 1))
+}
@@ -0,0 +1,36 @@
+package covers
+
+import "image"
+
+// resize scales src down to width pixels wide, keeping its aspect ratio,
+// by averaging the block of source pixels behind each output pixel. A src
+// no wider than width is returned as-is.
+func resize(src *image.RGBA, width int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= width {
+		return src
+	}
+	height := max(1, (sh*width+sw/2)/sw)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for dy := 0; dy < height; dy++ {
+		y0, y1 := dy*sh/height, max((dy+1)*sh/height, dy*sh/height+1)
+		for dx := 0; dx < width; dx++ {
+			x0, x1 := dx*sw/width, max((dx+1)*sw/width, dx*sw/width+1)
+			var r, g, b, a, n uint32
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4:]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+			o := dst.Pix[dy*dst.Stride+dx*4:]
+			o[0], o[1], o[2], o[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n) //nolint:gosec
+		}
+	}
+	return dst
+}
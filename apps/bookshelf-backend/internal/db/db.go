@@ -99,6 +99,7 @@ func Seed(database *gorm.DB) {
 		{Key: "verification_requires_phone", Value: "false"},
 		{Key: "verification_min_books_shared", Value: "0"},
 		{Key: "require_email_confirmation_on_change", Value: "true"},
+		{Key: "public_feed_enabled", Value: "true"},
 	}
 	for _, s := range defaults {
 		database.Where(models.AppSetting{Key: s.Key}).FirstOrCreate(&s)
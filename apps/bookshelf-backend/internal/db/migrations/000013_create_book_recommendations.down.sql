@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_recommendations_book_id;
+DROP INDEX IF EXISTS idx_book_affinities_related_book_id;
+DROP TABLE IF EXISTS user_recommendations;
+DROP TABLE IF EXISTS book_affinities;
@@ -0,0 +1,16 @@
+CREATE TABLE book_affinities (
+    book_id          INTEGER NOT NULL REFERENCES books(id),
+    related_book_id  INTEGER NOT NULL REFERENCES books(id),
+    score            INTEGER NOT NULL,
+    PRIMARY KEY (book_id, related_book_id)
+);
+
+CREATE TABLE user_recommendations (
+    user_id  INTEGER NOT NULL REFERENCES users(id),
+    book_id  INTEGER NOT NULL REFERENCES books(id),
+    score    INTEGER NOT NULL,
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_affinities_related_book_id ON book_affinities(related_book_id);
+CREATE INDEX IF NOT EXISTS idx_user_recommendations_book_id ON user_recommendations(book_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reading_list_entries_book_id;
+DROP INDEX IF EXISTS idx_reading_list_entries_list_book;
+DROP INDEX IF EXISTS idx_reading_lists_owner_id;
+DROP TABLE IF EXISTS reading_list_entries;
+DROP TABLE IF EXISTS reading_lists;
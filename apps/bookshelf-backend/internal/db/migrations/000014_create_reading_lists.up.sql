@@ -0,0 +1,22 @@
+CREATE TABLE reading_lists (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id     INTEGER NOT NULL REFERENCES users(id),
+    name         TEXT NOT NULL,
+    description  TEXT,
+    is_public    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE reading_list_entries (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    reading_list_id  INTEGER NOT NULL REFERENCES reading_lists(id),
+    book_id          INTEGER NOT NULL REFERENCES books(id),
+    position         INTEGER NOT NULL DEFAULT 0,
+    notes            TEXT,
+    created_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reading_lists_owner_id ON reading_lists(owner_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reading_list_entries_list_book ON reading_list_entries(reading_list_id, book_id);
+CREATE INDEX IF NOT EXISTS idx_reading_list_entries_book_id ON reading_list_entries(book_id);
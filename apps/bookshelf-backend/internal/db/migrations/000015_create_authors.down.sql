@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_book_contributors_author_id;
+DROP INDEX IF EXISTS idx_authors_name_key;
+DROP TABLE IF EXISTS book_contributors;
+DROP TABLE IF EXISTS authors;
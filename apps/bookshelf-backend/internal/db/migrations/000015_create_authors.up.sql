@@ -0,0 +1,17 @@
+CREATE TABLE authors (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT NOT NULL,
+    name_key    TEXT NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE book_contributors (
+    book_id    INTEGER NOT NULL REFERENCES books(id),
+    author_id  INTEGER NOT NULL REFERENCES authors(id),
+    role       TEXT NOT NULL DEFAULT 'author',
+    position   INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, author_id, role)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_authors_name_key ON authors(name_key);
+CREATE INDEX IF NOT EXISTS idx_book_contributors_author_id ON book_contributors(author_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_metadata_cache_entries_expires_at;
+DROP TABLE IF EXISTS metadata_cache_entries;
@@ -0,0 +1,8 @@
+CREATE TABLE metadata_cache_entries (
+    key         TEXT PRIMARY KEY,
+    results     TEXT NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_cache_entries_expires_at ON metadata_cache_entries(expires_at);
@@ -0,0 +1,3 @@
+-- No column drop: same rationale as 000012's down migration — the column is
+-- left in place; only its index goes.
+DROP INDEX IF EXISTS idx_books_metadata_checked_at;
@@ -0,0 +1,3 @@
+ALTER TABLE books ADD COLUMN metadata_checked_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_books_metadata_checked_at ON books(metadata_checked_at);
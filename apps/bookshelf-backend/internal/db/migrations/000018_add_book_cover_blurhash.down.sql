@@ -0,0 +1,2 @@
+-- No column drop: same rationale as 000012's down migration — the column is
+-- left in place.
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_import_job_rows_job_row;
+DROP INDEX IF EXISTS idx_import_jobs_status;
+DROP INDEX IF EXISTS idx_import_jobs_owner_id;
+DROP TABLE IF EXISTS import_job_rows;
+DROP TABLE IF EXISTS import_jobs;
@@ -0,0 +1,31 @@
+CREATE TABLE import_jobs (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id        INTEGER NOT NULL REFERENCES users(id),
+    status          TEXT NOT NULL DEFAULT 'queued',
+    format          TEXT NOT NULL,
+    source          TEXT NOT NULL,
+    content         TEXT NOT NULL,
+    decisions       TEXT NOT NULL DEFAULT '{}',
+    total_rows      INTEGER NOT NULL DEFAULT 0,
+    processed_rows  INTEGER NOT NULL DEFAULT 0,
+    error           TEXT,
+    created_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at     DATETIME
+);
+
+CREATE TABLE import_job_rows (
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    import_job_id        INTEGER NOT NULL REFERENCES import_jobs(id),
+    row                  INTEGER NOT NULL,
+    title                TEXT NOT NULL,
+    action               TEXT NOT NULL,
+    reason               TEXT,
+    matched_book_id      INTEGER,
+    matched_book_title   TEXT,
+    matched_book_author  TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_owner_id ON import_jobs(owner_id);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs(status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_import_job_rows_job_row ON import_job_rows(import_job_id, row);
@@ -0,0 +1,3 @@
+-- No column drop: same rationale as 000012's down migration — the columns are
+-- left in place; only their index goes.
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
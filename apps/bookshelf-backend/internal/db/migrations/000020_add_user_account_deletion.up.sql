@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN deletion_scheduled_at DATETIME;
+ALTER TABLE users ADD COLUMN deletion_transfer_to_id INTEGER REFERENCES users(id);
+ALTER TABLE users ADD COLUMN anonymized_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users(deletion_scheduled_at);
@@ -0,0 +1,2 @@
+-- No column drop: same rationale as 000008's down migration — SQLite can't
+-- cheaply drop notifications.book_id back out, so it's left in place here.
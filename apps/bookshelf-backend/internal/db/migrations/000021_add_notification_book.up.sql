@@ -0,0 +1 @@
+ALTER TABLE notifications ADD COLUMN book_id INTEGER REFERENCES books(id);
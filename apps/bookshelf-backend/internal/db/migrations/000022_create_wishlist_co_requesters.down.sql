@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_wishlist_co_requesters_user_id;
+DROP INDEX IF EXISTS idx_wishlist_co_requesters_request_user;
+DROP TABLE IF EXISTS wishlist_co_requesters;
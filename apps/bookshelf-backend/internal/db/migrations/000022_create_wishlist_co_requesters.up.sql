@@ -0,0 +1,9 @@
+CREATE TABLE wishlist_co_requesters (
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    wishlist_request_id  INTEGER NOT NULL REFERENCES wishlist_requests(id),
+    user_id              INTEGER NOT NULL REFERENCES users(id),
+    created_at           DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlist_co_requesters_request_user ON wishlist_co_requesters(wishlist_request_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_wishlist_co_requesters_user_id ON wishlist_co_requesters(user_id);
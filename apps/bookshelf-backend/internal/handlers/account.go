@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// A personal data export hands a member everything Bookshelf stores about
+// them, as a zip of JSON files described by the README.md inside it. Other
+// members appear only as the name the app would show this member: a
+// borrower's name on loans of their copies, an owner's name on loans they
+// borrowed unless the owner hid it. Secrets — the password hash, one-time
+// codes and the Google Books API key — are left out; the profile only says
+// whether a key is set.
+
+const accountExportVersion = 1
+
+// AccountHandler serves a member's own account as a whole: the personal
+// data export here, and self-service deletion in account_deletion.go.
+type AccountHandler struct {
+	accounts        repository.AccountRepository
+	users           repository.UserRepository
+	admin           repository.AdminRepository
+	exportLimiter   *middleware.RateLimiter
+	deletionLimiter *middleware.RateLimiter
+}
+
+// NewAccountHandler creates a new AccountHandler.
+func NewAccountHandler(accounts repository.AccountRepository, users repository.UserRepository, admin repository.AdminRepository) *AccountHandler {
+	return &AccountHandler{
+		accounts: accounts,
+		users:    users,
+		admin:    admin,
+		// 3 immediately, refilling one every 20min — an export reads every
+		// table the member appears in, and nobody needs theirs more often.
+		exportLimiter: middleware.NewRateLimiter(rate.Every(20*time.Minute), 3),
+		// 5 immediately, refilling one a minute — scheduling a deletion
+		// checks the password, so it mustn't be a way to guess it.
+		deletionLimiter: middleware.NewRateLimiter(rate.Every(time.Minute), 5),
+	}
+}
+
+// --- Document types ---
+
+type accountManifest struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	UserID     uint      `json:"user_id"`
+}
+
+type accountProfile struct {
+	ID                        uint       `json:"id"`
+	Name                      string     `json:"name"`
+	Email                     string     `json:"email"`
+	PendingEmail              string     `json:"pending_email,omitempty"`
+	Phone                     string     `json:"phone"`
+	Role                      string     `json:"role"`
+	Verified                  bool       `json:"verified"`
+	PhoneVerified             bool       `json:"phone_verified"`
+	Suspended                 bool       `json:"suspended"`
+	PendingApproval           bool       `json:"pending_approval"`
+	EmailNotificationsEnabled bool       `json:"email_notifications_enabled"`
+	TelegramUsername          string     `json:"telegram_username,omitempty"`
+	WhatsAppUsername          string     `json:"whatsapp_username,omitempty"`
+	GoogleBooksAPIKeySet      bool       `json:"google_books_api_key_set"`
+	DeletionScheduledAt       *time.Time `json:"deletion_scheduled_at"`
+	CreatedAt                 time.Time  `json:"created_at"`
+}
+
+type accountBook struct {
+	ID     uint   `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	ISBN   string `json:"isbn,omitempty"`
+}
+
+type accountCopy struct {
+	ID                 uint        `json:"id"`
+	Book               accountBook `json:"book"`
+	Condition          string      `json:"condition"`
+	Notes              string      `json:"notes"`
+	Status             string      `json:"status"`
+	AutoApprove        bool        `json:"auto_approve"`
+	ReturnDateRequired bool        `json:"return_date_required"`
+	HideOwner          bool        `json:"hide_owner"`
+}
+
+type accountLoan struct {
+	ID                 uint        `json:"id"`
+	CopyID             uint        `json:"copy_id"`
+	Book               accountBook `json:"book"`
+	Owner              string      `json:"owner,omitempty"`
+	Borrower           string      `json:"borrower,omitempty"`
+	Status             string      `json:"status"`
+	Message            string      `json:"message"`
+	RequestedAt        time.Time   `json:"requested_at"`
+	RespondedAt        *time.Time  `json:"responded_at"`
+	LoanedAt           *time.Time  `json:"loaned_at"`
+	ReturnedAt         *time.Time  `json:"returned_at"`
+	ExpectedReturnDate *time.Time  `json:"expected_return_date"`
+}
+
+type accountWaitlistEntry struct {
+	CopyID   uint        `json:"copy_id"`
+	Book     accountBook `json:"book"`
+	JoinedAt time.Time   `json:"joined_at"`
+}
+
+type accountWishlistItem struct {
+	ID            uint         `json:"id"`
+	Title         string       `json:"title"`
+	Author        string       `json:"author"`
+	ISBN          string       `json:"isbn,omitempty"`
+	Notes         string       `json:"notes"`
+	Status        string       `json:"status"`
+	IsAnonymous   bool         `json:"is_anonymous"`
+	CreatedAt     time.Time    `json:"created_at"`
+	FulfilledAt   *time.Time   `json:"fulfilled_at"`
+	FulfilledBook *accountBook `json:"fulfilled_book"`
+}
+
+type accountJoinedWishlistItem struct {
+	ID       uint      `json:"id"`
+	Title    string    `json:"title"`
+	Author   string    `json:"author"`
+	Status   string    `json:"status"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type accountNotification struct {
+	ID                uint      `json:"id"`
+	Type              string    `json:"type"`
+	LoanRequestID     *uint     `json:"loan_request_id"`
+	WishlistRequestID *uint     `json:"wishlist_request_id"`
+	PendingUserID     *uint     `json:"pending_user_id"`
+	BookID            *uint     `json:"book_id"`
+	Read              bool      `json:"read"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type accountReadingList struct {
+	ID          uint                      `json:"id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	IsPublic    bool                      `json:"is_public"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+	Books       []accountReadingListEntry `json:"books"`
+}
+
+type accountReadingListEntry struct {
+	Position int         `json:"position"`
+	Notes    string      `json:"notes"`
+	Book     accountBook `json:"book"`
+}
+
+type accountImport struct {
+	ID            uint       `json:"id"`
+	Status        string     `json:"status"`
+	Format        string     `json:"format"`
+	Source        string     `json:"source"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+// accountExportReadme is README.md in the archive. It documents every file
+// above, so keep the two in step.
+const accountExportReadme = `# Your Bookshelf data
+
+This archive holds everything Bookshelf stores about your account, as of the
+time in manifest.json. Every file is JSON; times are RFC 3339, and a
+missing time is null. Books are given as {id, title, author, isbn}.
+
+Other members appear only as the name Bookshelf shows you: the borrower of
+your copies, and the owner of copies you borrowed unless they chose to stay
+anonymous. Your password, one-time codes and Google Books API key are not
+included.
+
+- manifest.json — format ("bookshelf-account"), version, exported_at, user_id.
+- profile.json — your account: id, name, email, pending_email (an address
+  change awaiting confirmation), phone, role, verified, phone_verified,
+  suspended, pending_approval, email_notifications_enabled,
+  telegram_username, whatsapp_username, google_books_api_key_set,
+  deletion_scheduled_at (when a deletion you asked for takes effect),
+  created_at.
+- copies.json — the copies you own: id, book, condition, notes, status,
+  auto_approve, return_date_required, hide_owner.
+- loans_as_borrower.json — loan requests you made: id, copy_id, book, owner,
+  status, message, requested_at, responded_at, loaned_at, returned_at,
+  expected_return_date.
+- loans_as_owner.json — loan requests for your copies, with the same fields
+  but borrower in place of owner.
+- waitlist.json — copies you are waiting for: copy_id, book, joined_at.
+- wishlist.json — books you asked the library for: id, title, author, isbn,
+  notes, status, is_anonymous, created_at, fulfilled_at, fulfilled_book.
+- wishlist_joined.json — other members' wishlist requests you joined: id,
+  title, author, status, joined_at.
+- notifications.json — notifications sent to you: id, type,
+  loan_request_id, wishlist_request_id, pending_user_id, book_id, read,
+  created_at.
+- reading_lists.json — your reading lists: id, name, description,
+  is_public, created_at, updated_at, and books as {position, notes, book}.
+- imports.json — your book imports: id, status, format, source, total_rows,
+  processed_rows, error, created_at, finished_at.
+
+Bookshelf has no reviews or ratings, so there is no file for them.
+`
+
+func (h *AccountHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "export-me",
+		Method:      "GET",
+		Path:        "/auth/me/export",
+		Tags:        []string{"auth"},
+		Summary:     "Download everything stored about the authenticated user, as a zip of JSON files documented by the README.md inside it",
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: huma.Middlewares{middleware.RateLimit(api, h.exportLimiter, middleware.UserOrIP)},
+	}, h.exportMe)
+
+	h.registerDeletionRoutes(api)
+}
+
+// --- Handlers ---
+
+func (h *AccountHandler) exportMe(ctx context.Context, _ *struct{}) (*huma.StreamResponse, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	data, err := h.accounts.Export(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("user not found")
+		}
+		return nil, huma.Error500InternalServerError("could not gather account data")
+	}
+	now := time.Now().UTC()
+	files := accountExportFiles(data, now)
+	filename := fmt.Sprintf("bookshelf-my-data-%s.zip", now.Format("2006-01-02"))
+
+	return &huma.StreamResponse{
+		Body: func(sctx huma.Context) {
+			sctx.SetHeader("Content-Type", "application/zip")
+			sctx.SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+			if err := writeAccountZip(sctx.BodyWriter(), files, now); err != nil {
+				log.Error().Err(err).Uint("user_id", userID).Msg("account export: write failed")
+			}
+		},
+	}, nil
+}
+
+// accountFile is one entry in the export archive: its name and the value
+// marshalled as its contents (README.md's is its text).
+type accountFile struct {
+	name  string
+	value any
+}
+
+func writeAccountZip(w io.Writer, files []accountFile, modified time.Time) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if text, ok := f.value.(string); ok {
+			_, err = fw.Write([]byte(text))
+		} else {
+			enc := json.NewEncoder(fw)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(f.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// --- Conversion ---
+
+func accountExportFiles(d *repository.AccountData, now time.Time) []accountFile {
+	u := d.User
+	copies := make([]accountCopy, 0, len(d.Copies))
+	for _, c := range d.Copies {
+		copies = append(copies, accountCopy{
+			ID: c.ID, Book: toAccountBook(c.Book), Condition: c.Condition, Notes: c.Notes, Status: c.Status,
+			AutoApprove: c.AutoApprove, ReturnDateRequired: c.ReturnDateRequired, HideOwner: c.HideOwner,
+		})
+	}
+	borrowed := make([]accountLoan, 0, len(d.LoansAsBorrower))
+	for _, l := range d.LoansAsBorrower {
+		loan := toAccountLoan(l)
+		if !l.Copy.HideOwner {
+			loan.Owner = l.Copy.Owner.Name
+		}
+		borrowed = append(borrowed, loan)
+	}
+	lent := make([]accountLoan, 0, len(d.LoansAsOwner))
+	for _, l := range d.LoansAsOwner {
+		loan := toAccountLoan(l)
+		loan.Borrower = l.Borrower.Name
+		lent = append(lent, loan)
+	}
+	waitedFor := make(map[uint]models.Book, len(d.WaitlistCopies))
+	for _, c := range d.WaitlistCopies {
+		waitedFor[c.ID] = c.Book
+	}
+	waitlist := make([]accountWaitlistEntry, 0, len(d.Waitlist))
+	for _, e := range d.Waitlist {
+		waitlist = append(waitlist, accountWaitlistEntry{CopyID: e.CopyID, Book: toAccountBook(waitedFor[e.CopyID]), JoinedAt: e.CreatedAt})
+	}
+	wishlist := make([]accountWishlistItem, 0, len(d.Wishlist))
+	for _, w := range d.Wishlist {
+		item := accountWishlistItem{
+			ID: w.ID, Title: w.Title, Author: w.Author, ISBN: w.ISBN, Notes: w.Notes, Status: w.Status,
+			IsAnonymous: w.IsAnonymous, CreatedAt: w.CreatedAt, FulfilledAt: w.FulfilledAt,
+		}
+		if w.FulfilledBook != nil {
+			b := toAccountBook(*w.FulfilledBook)
+			item.FulfilledBook = &b
+		}
+		wishlist = append(wishlist, item)
+	}
+	joined := make([]accountJoinedWishlistItem, 0, len(d.WishlistJoined))
+	for _, w := range d.WishlistJoined {
+		item := accountJoinedWishlistItem{ID: w.ID, Title: w.Title, Author: w.Author, Status: w.Status}
+		if len(w.CoRequesters) > 0 {
+			item.JoinedAt = w.CoRequesters[0].CreatedAt
+		}
+		joined = append(joined, item)
+	}
+	notifications := make([]accountNotification, 0, len(d.Notifications))
+	for _, n := range d.Notifications {
+		notifications = append(notifications, accountNotification{
+			ID: n.ID, Type: n.Type, LoanRequestID: n.LoanRequestID, WishlistRequestID: n.WishlistRequestID,
+			PendingUserID: n.PendingUserID, BookID: n.BookID, Read: n.Read, CreatedAt: n.CreatedAt,
+		})
+	}
+	lists := make([]accountReadingList, 0, len(d.ReadingLists))
+	for _, rl := range d.ReadingLists {
+		list := accountReadingList{
+			ID: rl.ID, Name: rl.Name, Description: rl.Description, IsPublic: rl.IsPublic,
+			CreatedAt: rl.CreatedAt, UpdatedAt: rl.UpdatedAt, Books: make([]accountReadingListEntry, 0, len(rl.Entries)),
+		}
+		for _, e := range rl.Entries {
+			list.Books = append(list.Books, accountReadingListEntry{Position: e.Position, Notes: e.Notes, Book: toAccountBook(e.Book)})
+		}
+		lists = append(lists, list)
+	}
+	imports := make([]accountImport, 0, len(d.ImportJobs))
+	for _, j := range d.ImportJobs {
+		imports = append(imports, accountImport{
+			ID: j.ID, Status: j.Status, Format: j.Format, Source: j.Source, TotalRows: j.TotalRows,
+			ProcessedRows: j.ProcessedRows, Error: j.Error, CreatedAt: j.CreatedAt, FinishedAt: j.FinishedAt,
+		})
+	}
+
+	return []accountFile{
+		{"README.md", accountExportReadme},
+		{"manifest.json", accountManifest{Format: "bookshelf-account", Version: accountExportVersion, ExportedAt: now, UserID: u.ID}},
+		{"profile.json", accountProfile{
+			ID: u.ID, Name: u.Name, Email: u.Email, PendingEmail: u.PendingEmail, Phone: u.Phone, Role: u.Role,
+			Verified: u.Verified, PhoneVerified: u.PhoneVerified, Suspended: u.Suspended, PendingApproval: u.PendingApproval,
+			EmailNotificationsEnabled: u.EmailNotificationsEnabled, TelegramUsername: u.TelegramUsername,
+			WhatsAppUsername: u.WhatsAppUsername, GoogleBooksAPIKeySet: u.GoogleBooksAPIKey != "", DeletionScheduledAt: u.DeletionScheduledAt,
+			CreatedAt: u.CreatedAt,
+		}},
+		{"copies.json", copies},
+		{"loans_as_borrower.json", borrowed},
+		{"loans_as_owner.json", lent},
+		{"waitlist.json", waitlist},
+		{"wishlist.json", wishlist},
+		{"wishlist_joined.json", joined},
+		{"notifications.json", notifications},
+		{"reading_lists.json", lists},
+		{"imports.json", imports},
+	}
+}
+
+func toAccountBook(b models.Book) accountBook {
+	return accountBook{ID: b.ID, Title: b.Title, Author: b.Author, ISBN: b.ISBN}
+}
+
+func toAccountLoan(l models.LoanRequest) accountLoan {
+	return accountLoan{
+		ID: l.ID, CopyID: l.CopyID, Book: toAccountBook(l.Copy.Book), Status: l.Status, Message: l.Message,
+		RequestedAt: l.RequestedAt, RespondedAt: l.RespondedAt, LoanedAt: l.LoanedAt, ReturnedAt: l.ReturnedAt,
+		ExpectedReturnDate: l.ExpectedReturnDate,
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// A member deletes their own account in two steps: scheduling it here,
+// with their password, and services.AccountDeletionService carrying it out
+// once accountDeletionGracePeriod has passed. Until then they can still
+// sign in and cancel. What deletion does to their data is described on
+// repository.AccountRepository.Delete.
+const accountDeletionGracePeriod = 14 * 24 * time.Hour
+
+type scheduleDeletionInput struct {
+	Body struct {
+		Password        string `json:"password" required:"true" minLength:"1" doc:"Your current password, to confirm it's you"`
+		Copies          string `json:"copies" required:"true" enum:"transfer,delete" doc:"What happens to the copies you own: transfer them to another member, or delete them"`
+		TransferToEmail string `json:"transfer_to_email,omitempty" format:"email" doc:"Email of the member to transfer your copies to. Required when copies is transfer."`
+	}
+}
+
+type accountDeletionOutput struct {
+	Body struct {
+		DeletionScheduledAt time.Time `json:"deletion_scheduled_at" doc:"When the account will be deleted"`
+	}
+}
+
+func (h *AccountHandler) registerDeletionRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "schedule-account-deletion",
+		Method:      "POST",
+		Path:        "/auth/me/deletion",
+		Tags:        []string{"auth"},
+		Summary:     "Schedule deletion of the authenticated user's account after a 14-day grace period. Refused while a copy they borrowed or lent is out on loan.",
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: huma.Middlewares{middleware.RateLimit(api, h.deletionLimiter, middleware.UserOrIP)},
+	}, h.scheduleDeletion)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "cancel-account-deletion",
+		Method:        "DELETE",
+		Path:          "/auth/me/deletion",
+		Tags:          []string{"auth"},
+		Summary:       "Cancel the authenticated user's scheduled account deletion",
+		Security:      []map[string][]string{{"bearer": {}}},
+		DefaultStatus: 204,
+	}, h.cancelDeletion)
+}
+
+func (h *AccountHandler) scheduleDeletion(ctx context.Context, input *scheduleDeletionInput) (*accountDeletionOutput, error) {
+	user, err := h.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt != nil {
+		return nil, huma.Error409Conflict("account deletion is already scheduled")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Body.Password)); err != nil {
+		return nil, huma.Error400BadRequest("password is incorrect")
+	}
+	if user.Role == "admin" {
+		count, err := h.admin.CountByRole("admin")
+		if err != nil {
+			return nil, huma.Error500InternalServerError("could not check admin count")
+		}
+		if count <= 1 {
+			return nil, huma.Error400BadRequest("you are the last admin — make someone else an admin first")
+		}
+	}
+	active, err := h.accounts.CountActiveLoans(user.ID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not check loans")
+	}
+	if active > 0 {
+		return nil, huma.Error409Conflict("a copy you borrowed or lent is still out on loan — it must be returned first")
+	}
+
+	var transferTo *uint
+	if input.Body.Copies == "transfer" {
+		heir, err := h.copyHeir(user, input.Body.TransferToEmail)
+		if err != nil {
+			return nil, err
+		}
+		transferTo = &heir.ID
+	}
+
+	at := time.Now().Add(accountDeletionGracePeriod)
+	user.DeletionScheduledAt = &at
+	user.DeletionTransferToID = transferTo
+	if err := h.users.Save(user); err != nil {
+		return nil, huma.Error500InternalServerError("could not schedule deletion")
+	}
+	zerolog.Ctx(ctx).Info().Uint("user_id", user.ID).Time("at", at).Msg("account deletion scheduled")
+
+	out := &accountDeletionOutput{}
+	out.Body.DeletionScheduledAt = at
+	return out, nil
+}
+
+// copyHeir returns the member user's copies are to be transferred to.
+func (h *AccountHandler) copyHeir(user *models.User, email string) (*models.User, error) {
+	if email == "" {
+		return nil, huma.Error400BadRequest("transfer_to_email is required to transfer your copies")
+	}
+	heir, err := h.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("no user found with that email address")
+		}
+		return nil, huma.Error500InternalServerError("could not find user")
+	}
+	if heir.ID == user.ID {
+		return nil, huma.Error400BadRequest("choose another member to take your copies")
+	}
+	if heir.Suspended || heir.PendingApproval || heir.DeletionScheduledAt != nil {
+		return nil, huma.Error400BadRequest("that member can't take your copies")
+	}
+	return heir, nil
+}
+
+func (h *AccountHandler) cancelDeletion(ctx context.Context, _ *struct{}) (*struct{}, error) {
+	user, err := h.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt == nil {
+		return nil, huma.Error400BadRequest("no account deletion is scheduled")
+	}
+	user.DeletionScheduledAt = nil
+	user.DeletionTransferToID = nil
+	if err := h.users.Save(user); err != nil {
+		return nil, huma.Error500InternalServerError("could not cancel deletion")
+	}
+	zerolog.Ctx(ctx).Info().Uint("user_id", user.ID).Msg("account deletion cancelled")
+	return nil, nil
+}
+
+func (h *AccountHandler) currentUser(ctx context.Context) (*models.User, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	user, err := h.users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("user not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch user")
+	}
+	return user, nil
+}
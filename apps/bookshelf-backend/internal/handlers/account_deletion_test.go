@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+type accountDeletionDeps struct {
+	h        *AccountHandler
+	users    *repotest.UserRepository
+	admin    *repotest.AdminRepository
+	accounts *repotest.AccountRepository
+	me, heir *models.User
+}
+
+func newAccountDeletionDeps(t *testing.T) accountDeletionDeps {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("Passw0rd1234"), bcrypt.MinCost)
+	require.NoError(t, err)
+	users := repotest.NewUserRepository()
+	me := &models.User{Name: "Me", Email: "me@example.com", Password: string(hash), Role: "user"}
+	heir := &models.User{Name: "Heir", Email: "heir@example.com", Password: string(hash), Role: "user"}
+	require.NoError(t, users.Create(me))
+	require.NoError(t, users.Create(heir))
+	admin := repotest.NewAdminRepository()
+	accounts := repotest.NewAccountRepository()
+	return accountDeletionDeps{
+		h: NewAccountHandler(accounts, users, admin), users: users, admin: admin, accounts: accounts, me: me, heir: heir,
+	}
+}
+
+func deletionInput(password, copies, email string) *scheduleDeletionInput {
+	in := &scheduleDeletionInput{}
+	in.Body.Password = password
+	in.Body.Copies = copies
+	in.Body.TransferToEmail = email
+	return in
+}
+
+func TestAccountHandler_ScheduleDeletion(t *testing.T) {
+	t.Run("schedules after the grace period, recording who gets the copies", func(t *testing.T) {
+		d := newAccountDeletionDeps(t)
+		out, err := d.h.scheduleDeletion(fakeAuthedCtx(t, d.me.ID, "user"), deletionInput("Passw0rd1234", "transfer", "heir@example.com"))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(accountDeletionGracePeriod), out.Body.DeletionScheduledAt, time.Minute)
+
+		saved, err := d.users.FindByID(d.me.ID)
+		require.NoError(t, err)
+		require.NotNil(t, saved.DeletionScheduledAt)
+		require.NotNil(t, saved.DeletionTransferToID)
+		assert.Equal(t, d.heir.ID, *saved.DeletionTransferToID)
+
+		_, err = d.h.scheduleDeletion(fakeAuthedCtx(t, d.me.ID, "user"), deletionInput("Passw0rd1234", "delete", ""))
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("deleting copies needs no heir", func(t *testing.T) {
+		d := newAccountDeletionDeps(t)
+		_, err := d.h.scheduleDeletion(fakeAuthedCtx(t, d.me.ID, "user"), deletionInput("Passw0rd1234", "delete", ""))
+		require.NoError(t, err)
+		saved, err := d.users.FindByID(d.me.ID)
+		require.NoError(t, err)
+		assert.Nil(t, saved.DeletionTransferToID)
+	})
+
+	t.Run("wrong password is rejected", func(t *testing.T) {
+		d := newAccountDeletionDeps(t)
+		_, err := d.h.scheduleDeletion(fakeAuthedCtx(t, d.me.ID, "user"), deletionInput("wrong", "delete", ""))
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("blocked while a loan is active", func(t *testing.T) {
+		d := newAccountDeletionDeps(t)
+		d.accounts.ActiveLoans[d.me.ID] = 1
+		_, err := d.h.scheduleDeletion(fakeAuthedCtx(t, d.me.ID, "user"), deletionInput("Passw0rd1234", "delete", ""))
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("the last admin can't leave", func(t *testing.T) {
+		d := newAccountDeletionDeps(t)
+		d.me.Role = "admin"
+		require.NoError(t, d.users.Save(d.me))
+		require.NoError(t, d.admin.SaveUser(d.me))
+		_, err := d.h.scheduleDeletion(fakeAuthedCtx(t, d.me.ID, "admin"), deletionInput("Passw0rd1234", "delete", ""))
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("transfer needs a member who can take the copies", func(t *testing.T) {
+		d := newAccountDeletionDeps(t)
+		ctx := fakeAuthedCtx(t, d.me.ID, "user")
+		_, err := d.h.scheduleDeletion(ctx, deletionInput("Passw0rd1234", "transfer", ""))
+		assertStatus(t, err, 400)
+		_, err = d.h.scheduleDeletion(ctx, deletionInput("Passw0rd1234", "transfer", "nobody@example.com"))
+		assertStatus(t, err, 404)
+		_, err = d.h.scheduleDeletion(ctx, deletionInput("Passw0rd1234", "transfer", "me@example.com"))
+		assertStatus(t, err, 400)
+		d.heir.Suspended = true
+		require.NoError(t, d.users.Save(d.heir))
+		_, err = d.h.scheduleDeletion(ctx, deletionInput("Passw0rd1234", "transfer", "heir@example.com"))
+		assertStatus(t, err, 400)
+	})
+}
+
+func TestAccountHandler_CancelDeletion(t *testing.T) {
+	d := newAccountDeletionDeps(t)
+	ctx := fakeAuthedCtx(t, d.me.ID, "user")
+	_, err := d.h.cancelDeletion(ctx, nil)
+	assertStatus(t, err, 400)
+
+	_, err = d.h.scheduleDeletion(ctx, deletionInput("Passw0rd1234", "transfer", "heir@example.com"))
+	require.NoError(t, err)
+	_, err = d.h.cancelDeletion(ctx, nil)
+	require.NoError(t, err)
+	saved, err := d.users.FindByID(d.me.ID)
+	require.NoError(t, err)
+	assert.Nil(t, saved.DeletionScheduledAt)
+	assert.Nil(t, saved.DeletionTransferToID)
+}
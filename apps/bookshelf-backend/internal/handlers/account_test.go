@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+// readZip returns the archive's files by name.
+func readZip(t *testing.T, b []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	}
+	return files
+}
+
+func TestAccountHandler_ExportMe(t *testing.T) {
+	accounts := repotest.NewAccountRepository()
+	emma := models.Book{ID: 3, Title: "Emma", Author: "Jane Austen"}
+	accounts.Accounts[1] = &repository.AccountData{
+		User: models.User{ID: 1, Name: "Me", Email: "me@example.com", Password: "bcrypt-hash", OTPCode: "123456", GoogleBooksAPIKey: "encrypted"},
+		LoansAsBorrower: []models.LoanRequest{
+			{ID: 10, CopyID: 5, Status: "active", Copy: models.Copy{ID: 5, Book: emma, Owner: models.User{Name: "Open Owner"}}},
+			{ID: 11, CopyID: 6, Status: "returned", Copy: models.Copy{ID: 6, Book: emma, HideOwner: true, Owner: models.User{Name: "Hidden Owner"}}},
+		},
+		Waitlist:       []models.WaitlistEntry{{CopyID: 6, UserID: 1}},
+		WaitlistCopies: []models.Copy{{ID: 6, Book: emma}},
+	}
+	h := NewAccountHandler(accounts, repotest.NewUserRepository(), repotest.NewAdminRepository())
+
+	resp, err := h.exportMe(fakeAuthedCtx(t, 1, "user"), nil)
+	require.NoError(t, err)
+	rec := renderStream(t, resp)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), ".zip")
+
+	files := readZip(t, rec.Body.Bytes())
+	for _, name := range []string{"README.md", "manifest.json", "profile.json", "copies.json", "loans_as_borrower.json",
+		"loans_as_owner.json", "waitlist.json", "wishlist.json", "wishlist_joined.json", "notifications.json",
+		"reading_lists.json", "imports.json"} {
+		assert.Contains(t, files, name)
+	}
+	assert.Contains(t, string(files["README.md"]), "no reviews")
+
+	profile := string(files["profile.json"])
+	assert.Contains(t, profile, "me@example.com")
+	assert.Contains(t, profile, `"google_books_api_key_set": true`)
+	for _, secret := range []string{"bcrypt-hash", "123456", "encrypted"} {
+		assert.NotContains(t, profile, secret)
+	}
+
+	var loans []accountLoan
+	require.NoError(t, json.Unmarshal(files["loans_as_borrower.json"], &loans))
+	require.Len(t, loans, 2)
+	assert.Equal(t, "Open Owner", loans[0].Owner)
+	assert.Empty(t, loans[1].Owner, "an anonymous owner stays anonymous")
+	assert.Equal(t, "Emma", loans[0].Book.Title)
+
+	var waitlist []accountWaitlistEntry
+	require.NoError(t, json.Unmarshal(files["waitlist.json"], &waitlist))
+	require.Len(t, waitlist, 1)
+	assert.Equal(t, "Emma", waitlist[0].Book.Title)
+	assert.JSONEq(t, "[]", string(files["copies.json"]), "an empty kind is an empty array, not null")
+
+	t.Run("unauthenticated is rejected", func(t *testing.T) {
+		_, err := h.exportMe(fakeAuthedCtxNone(), nil)
+		assertStatus(t, err, 401)
+	})
+
+	t.Run("unknown user is not found", func(t *testing.T) {
+		_, err := h.exportMe(fakeAuthedCtx(t, 2, "user"), nil)
+		assertStatus(t, err, 404)
+	})
+}
@@ -3,13 +3,13 @@ package handlers
 import (
 	"context"
 	"errors"
-	"net/http"
 	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/rs/zerolog"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
@@ -27,11 +27,13 @@ type AdminHandler struct {
 	copies            repository.CopyRepository
 	loans             repository.LoanRequestRepository
 	googleBooksAPIKey string
+	providers         *metadata.Registry
+	metadataCache     MetadataCache
 }
 
 // NewAdminHandler creates a new AdminHandler.
-func NewAdminHandler(admin repository.AdminRepository, copies repository.CopyRepository, loans repository.LoanRequestRepository, googleBooksAPIKey string) *AdminHandler {
-	return &AdminHandler{admin: admin, copies: copies, loans: loans, googleBooksAPIKey: googleBooksAPIKey}
+func NewAdminHandler(admin repository.AdminRepository, copies repository.CopyRepository, loans repository.LoanRequestRepository, googleBooksAPIKey string, providers *metadata.Registry, metadataCache MetadataCache) *AdminHandler {
+	return &AdminHandler{admin: admin, copies: copies, loans: loans, googleBooksAPIKey: googleBooksAPIKey, providers: providers, metadataCache: metadataCache}
 }
 
 // --- Input / Output types ---
@@ -79,17 +81,38 @@ type updateSettingsInput struct {
 	}
 }
 
-// MetadataProviderStatus reports reachability of a single metadata source.
+// MetadataProviderStatus reports the settings and reachability of a single
+// metadata source. Statuses are listed in priority order.
 type MetadataProviderStatus struct {
-	Name      string `json:"name"`
-	Enabled   bool   `json:"enabled"`
-	Reachable bool   `json:"reachable"`
-	LatencyMs int64  `json:"latency_ms"`
-	Error     string `json:"error,omitempty"`
+	Name string `json:"name"`
+	// Enabled is false when the provider is Disabled, or requires an API
+	// key and the server has none.
+	Enabled bool `json:"enabled"`
+	// Disabled is set when the metadata_providers_disabled setting lists
+	// the provider.
+	Disabled     bool                  `json:"disabled"`
+	Capabilities metadata.Capabilities `json:"capabilities"`
+	TimeoutMs    int64                 `json:"timeout_ms"`
+	Reachable    bool                  `json:"reachable"`
+	LatencyMs    int64                 `json:"latency_ms"`
+	Error        string                `json:"error,omitempty"`
+	// Health is the provider's track record over real searches and its
+	// circuit breaker state; the reachability probe above bypasses the
+	// breaker and isn't counted in it.
+	Health metadata.ProviderHealth `json:"health"`
 }
 
 type metadataStatusOutput struct {
-	Body []MetadataProviderStatus
+	Body struct {
+		Providers []MetadataProviderStatus `json:"providers"`
+		Cache     MetadataCacheStats       `json:"cache"`
+	}
+}
+
+type purgeMetadataCacheOutput struct {
+	Body struct {
+		Purged int64 `json:"purged" doc:"Number of cached searches dropped"`
+	}
 }
 
 type adminDashboardOutput struct {
@@ -171,9 +194,18 @@ func (h *AdminHandler) RegisterRoutes(api huma.API) {
 		Method:      "GET",
 		Path:        "/admin/metadata/status",
 		Tags:        []string{"admin"},
-		Summary:     "Check reachability of metadata providers",
+		Summary:     "List metadata providers in priority order and check their reachability",
 		Security:    security,
 	}, h.getMetadataStatus)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-purge-metadata-cache",
+		Method:      "DELETE",
+		Path:        "/admin/metadata/cache",
+		Tags:        []string{"admin"},
+		Summary:     "Drop every cached metadata search result",
+		Security:    security,
+	}, h.purgeMetadataCache)
 }
 
 // --- Handlers ---
@@ -409,65 +441,66 @@ func (h *AdminHandler) getMetadataStatus(ctx context.Context, _ *struct{}) (*met
 		return nil, adminError(err)
 	}
 
-	type probe struct {
-		name    string
-		enabled bool
-		// url is the actual request URL; it may contain secrets and must never be
-		// surfaced in responses or logs.
-		url string
-	}
-
-	probes := []probe{
-		{
-			name:    "openlibrary",
-			enabled: true,
-			url:     "https://openlibrary.org/search.json?q=test&limit=1",
-		},
-		{
-			name:    "google_books",
-			enabled: h.googleBooksAPIKey != "",
-			url:     "https://www.googleapis.com/books/v1/volumes?q=test&maxResults=1&key=" + h.googleBooksAPIKey,
-		},
-		{
-			name:    "bookbrainz",
-			enabled: true,
-			url:     "https://api.bookbrainz.org/1/search?q=test&type=edition&size=1",
-		},
-	}
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	statuses := make([]MetadataProviderStatus, len(probes))
+	states := h.providers.Providers()
+	apiKeys := metadata.APIKeys(h.googleBooksAPIKey)
+	statuses := make([]MetadataProviderStatus, len(states))
 
 	var wg sync.WaitGroup
-	for i, p := range probes {
-		statuses[i] = MetadataProviderStatus{Name: p.name, Enabled: p.enabled}
-		if !p.enabled {
+	for i, state := range states {
+		p := state.Provider
+		caps := p.Capabilities()
+		key := apiKeys[p.Name()]
+		statuses[i] = MetadataProviderStatus{
+			Name:         p.Name(),
+			Enabled:      state.Enabled && (!caps.RequiresAPIKey || key != ""),
+			Disabled:     !state.Enabled,
+			Capabilities: caps,
+			TimeoutMs:    p.Timeout().Milliseconds(),
+			Health:       state.Health,
+		}
+		if !statuses[i].Enabled {
 			continue
 		}
 		wg.Add(1)
-		go func(idx int, p probe) {
+		go func(s *MetadataProviderStatus, p metadata.Provider) {
 			defer wg.Done()
-			s := &statuses[idx]
+			probeCtx, cancel := context.WithTimeout(ctx, p.Timeout())
+			defer cancel()
 			start := time.Now()
-			resp, err := client.Get(p.url) //nolint:noctx,gosec
+			// A real one-word search, so the probe exercises exactly what a
+			// user's search would.
+			_, err := p.Search(probeCtx, metadata.Query{Text: "test", APIKey: key})
 			s.LatencyMs = time.Since(start).Milliseconds()
 			if err != nil {
-				// Do not include the URL in the error — it may contain an API key.
-				s.Error = "connection error"
-				zerolog.Ctx(ctx).Warn().Err(err).Str("provider", p.name).Msg("metadata probe failed")
-			} else {
-				_ = resp.Body.Close()
-				if resp.StatusCode < 400 {
-					s.Reachable = true
-				} else {
-					s.Error = "HTTP " + http.StatusText(resp.StatusCode)
-				}
+				s.Error = err.Error()
+				zerolog.Ctx(ctx).Warn().Err(err).Str("provider", p.Name()).Msg("metadata probe failed")
+				return
 			}
-		}(i, p)
+			s.Reachable = true
+		}(&statuses[i], p)
 	}
 	wg.Wait()
 
-	return &metadataStatusOutput{Body: statuses}, nil
+	out := &metadataStatusOutput{}
+	out.Body.Providers = statuses
+	out.Body.Cache = h.metadataCache.Stats()
+	return out, nil
+}
+
+func (h *AdminHandler) purgeMetadataCache(ctx context.Context, _ *struct{}) (*purgeMetadataCacheOutput, error) {
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, adminError(err)
+	}
+
+	purged, err := h.metadataCache.Purge()
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not purge metadata cache")
+	}
+	zerolog.Ctx(ctx).Info().Int64("purged", purged).Msg("metadata cache purged")
+
+	out := &purgeMetadataCacheOutput{}
+	out.Body.Purged = purged
+	return out, nil
 }
 
 // adminError maps middleware sentinel errors to appropriate huma errors.
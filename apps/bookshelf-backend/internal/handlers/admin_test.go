@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
 )
@@ -19,7 +21,10 @@ func newAdminHandlerWithCopiesAndLoans() (*AdminHandler, *repotest.AdminReposito
 	admin := repotest.NewAdminRepository()
 	copies := repotest.NewCopyRepository()
 	loans := repotest.NewLoanRequestRepository(copies, repotest.NewNotificationRepository(), repotest.NewUserRepository())
-	return NewAdminHandler(admin, copies, loans, ""), admin, copies, loans
+	// Built directly rather than via NewSQLiteMetadataCache, which starts an
+	// eviction goroutine these tests have no context to stop.
+	cache := &sqliteMetadataCache{repo: repotest.NewMetadataCacheRepository(), ttl: time.Hour, maxEntries: 100}
+	return NewAdminHandler(admin, copies, loans, "", metadata.NewRegistry(admin), cache), admin, copies, loans
 }
 
 func TestAdminHandler_RequiresAdmin(t *testing.T) {
@@ -259,3 +264,26 @@ func TestUpdateSettings(t *testing.T) {
 	assert.Equal(t, "max_active_loans", out.Body[0].Key)
 	assert.Equal(t, "3", out.Body[0].Value)
 }
+
+func TestMetadataCacheAdmin_StatusReportsCountersAndPurgeEmptiesCache(t *testing.T) {
+	h, _ := newAdminHandler()
+	h.metadataCache.Set("dune", []BookMetadataResult{{Source: "openlibrary", Title: "Dune"}})
+	h.metadataCache.Set("emma", nil)
+	h.metadataCache.Get("dune")
+	h.metadataCache.Get("missing")
+
+	status, err := h.getMetadataStatus(fakeAuthedCtx(t, 1, "admin"), &struct{}{})
+	require.NoError(t, err)
+	assert.Equal(t, MetadataCacheStats{
+		Backend: "sqlite", Entries: 2, MaxEntries: 100, TTLSeconds: 3600, Hits: 1, Misses: 1,
+	}, status.Body.Cache)
+
+	_, err = h.purgeMetadataCache(fakeAuthedCtx(t, 2, "user"), &struct{}{})
+	assertStatus(t, err, 403)
+
+	purged, err := h.purgeMetadataCache(fakeAuthedCtx(t, 1, "admin"), &struct{}{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged.Body.Purged)
+	_, ok := h.metadataCache.Get("dune")
+	assert.False(t, ok)
+}
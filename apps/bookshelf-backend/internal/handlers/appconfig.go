@@ -19,6 +19,9 @@ type appConfigFile struct {
 	VerificationRequiresPhone   string `yaml:"verification_requires_phone,omitempty"`
 	VerificationMinBooksShared  string `yaml:"verification_min_books_shared,omitempty"`
 	CoverRefreshInterval        string `yaml:"cover_refresh_interval,omitempty"`
+	PublicFeedEnabled           string `yaml:"public_feed_enabled,omitempty"`
+	MetadataProviderPriority    string `yaml:"metadata_provider_priority,omitempty"`
+	MetadataProvidersDisabled   string `yaml:"metadata_providers_disabled,omitempty"`
 }
 
 var knownYAMLKeys = map[string]struct{}{
@@ -30,6 +33,9 @@ var knownYAMLKeys = map[string]struct{}{
 	"verification_requires_phone":   {},
 	"verification_min_books_shared": {},
 	"cover_refresh_interval":        {},
+	"public_feed_enabled":           {},
+	"metadata_provider_priority":    {},
+	"metadata_providers_disabled":   {},
 }
 
 // LoadYAMLConfig parses a bookshelf.yaml file and returns a flat key→value map
@@ -97,6 +103,15 @@ func flattenYAMLConfig(cfg appConfigFile) map[string]string {
 	if cfg.CoverRefreshInterval != "" {
 		kv["cover_refresh_interval"] = cfg.CoverRefreshInterval
 	}
+	if cfg.PublicFeedEnabled != "" {
+		kv["public_feed_enabled"] = cfg.PublicFeedEnabled
+	}
+	if cfg.MetadataProviderPriority != "" {
+		kv["metadata_provider_priority"] = cfg.MetadataProviderPriority
+	}
+	if cfg.MetadataProvidersDisabled != "" {
+		kv["metadata_providers_disabled"] = cfg.MetadataProvidersDisabled
+	}
 	return kv
 }
 
@@ -117,6 +132,9 @@ func settingsToYAML(settings []models.AppSetting) ([]byte, error) {
 		VerificationRequiresPhone:   m["verification_requires_phone"],
 		VerificationMinBooksShared:  m["verification_min_books_shared"],
 		CoverRefreshInterval:        m["cover_refresh_interval"],
+		PublicFeedEnabled:           m["public_feed_enabled"],
+		MetadataProviderPriority:    m["metadata_provider_priority"],
+		MetadataProvidersDisabled:   m["metadata_providers_disabled"],
 	}
 	return yaml.Marshal(cfg)
 }
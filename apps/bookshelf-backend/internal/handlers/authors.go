@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// AuthorHandler serves author pages: an Author and the catalog books they're
+// credited on. Authors are created and linked to books by
+// services.ContributorService; there's no route to edit them directly.
+type AuthorHandler struct {
+	authors repository.AuthorRepository
+	books   repository.BookRepository
+}
+
+// NewAuthorHandler creates a new AuthorHandler.
+func NewAuthorHandler(authors repository.AuthorRepository, books repository.BookRepository) *AuthorHandler {
+	return &AuthorHandler{authors: authors, books: books}
+}
+
+// authorBookResponse is a book on an author page, with the roles the author
+// is credited in on it.
+type authorBookResponse struct {
+	bookResponse
+	Roles []string `json:"roles"`
+}
+
+// --- Input / Output types ---
+
+type getAuthorInput struct {
+	ID uint `path:"id" doc:"Author ID"`
+}
+
+type getAuthorOutput struct {
+	Body struct {
+		models.Author
+		Books []authorBookResponse `json:"books"`
+	}
+}
+
+// --- Route registration ---
+
+// RegisterRoutes registers all author routes on the given huma API.
+func (h *AuthorHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-author",
+		Method:      "GET",
+		Path:        "/authors/{id}",
+		Tags:        []string{"authors"},
+		Summary:     "Get an author and the books they're credited on, with availability",
+	}, h.getAuthor)
+}
+
+// --- Handlers ---
+
+func (h *AuthorHandler) getAuthor(_ context.Context, input *getAuthorInput) (*getAuthorOutput, error) {
+	author, err := h.authors.GetByID(input.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("author not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch author")
+	}
+
+	credits, err := h.authors.ListCredits(author.ID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch author's books")
+	}
+
+	// Credits come one per (book, role), already by title; fold them into
+	// one entry per book keeping that order.
+	var books []models.Book
+	roles := map[uint][]string{}
+	for _, c := range credits {
+		if _, ok := roles[c.Book.ID]; !ok {
+			books = append(books, c.Book)
+		}
+		roles[c.Book.ID] = append(roles[c.Book.ID], c.Role)
+	}
+	withAvailability, err := booksWithAvailability(h.books, books)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+
+	out := &getAuthorOutput{}
+	out.Body.Author = *author
+	out.Body.Books = make([]authorBookResponse, len(withAvailability))
+	for i, b := range withAvailability {
+		out.Body.Books[i] = authorBookResponse{bookResponse: b, Roles: roles[b.ID]}
+	}
+	return out, nil
+}
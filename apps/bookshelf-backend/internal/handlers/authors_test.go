@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func TestGetAuthor_ListsCreditedBooksWithRolesAndAvailability(t *testing.T) {
+	books := repotest.NewBookRepository()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	authors := repotest.NewAuthorRepository(books)
+	h := NewAuthorHandler(authors, books)
+
+	pevear := models.Author{Name: "Richard Pevear", NameKey: "richard pevear"}
+	require.NoError(t, authors.FindOrCreate(&pevear))
+
+	war := models.Book{Title: "War and Peace", Author: "Leo Tolstoy"}
+	demons := models.Book{Title: "Demons", Author: "Fyodor Dostoevsky"}
+	gone := models.Book{Title: "Anna Karenina", Author: "Leo Tolstoy"}
+	for _, b := range []*models.Book{&war, &demons, &gone} {
+		require.NoError(t, books.Create(b))
+		require.NoError(t, authors.ReplaceBookContributors(b.ID, []models.BookContributor{{AuthorID: pevear.ID, Role: "translator"}}))
+	}
+	require.NoError(t, authors.ReplaceBookContributors(demons.ID, []models.BookContributor{
+		{AuthorID: pevear.ID, Role: "translator"},
+		{AuthorID: pevear.ID, Role: "editor", Position: 1},
+	}))
+	require.NoError(t, copies.Create(&models.Copy{BookID: war.ID, Status: "available"}))
+	require.NoError(t, copies.Create(&models.Copy{BookID: demons.ID, Status: "borrowed"}))
+
+	out, err := h.getAuthor(context.Background(), &getAuthorInput{ID: pevear.ID})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Richard Pevear", out.Body.Name)
+	require.Len(t, out.Body.Books, 2, "a book with no copies isn't listed")
+	assert.Equal(t, "Demons", out.Body.Books[0].Title)
+	assert.ElementsMatch(t, []string{"translator", "editor"}, out.Body.Books[0].Roles)
+	assert.Zero(t, out.Body.Books[0].AvailableCopies)
+	assert.Equal(t, "War and Peace", out.Body.Books[1].Title)
+	assert.Equal(t, int64(1), out.Body.Books[1].AvailableCopies)
+}
+
+func TestGetAuthor_UnknownAuthorReturns404(t *testing.T) {
+	books := repotest.NewBookRepository()
+	h := NewAuthorHandler(repotest.NewAuthorRepository(books), books)
+
+	_, err := h.getAuthor(context.Background(), &getAuthorInput{ID: 999})
+
+	assertStatus(t, err, http.StatusNotFound)
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
-	"os"
+	"strconv"
 
 	"github.com/danielgtaylor/huma/v2"
 
@@ -78,7 +78,7 @@ func (h *BackupHandler) listBackups(ctx context.Context, _ *struct{}) (*listBack
 	if err := middleware.RequireAdmin(ctx); err != nil {
 		return nil, backupAdminError(err)
 	}
-	snapshots, err := h.backups.ListSnapshots()
+	snapshots, err := h.backups.ListSnapshots(ctx)
 	if err != nil {
 		return nil, huma.Error500InternalServerError("could not list backups")
 	}
@@ -89,7 +89,7 @@ func (h *BackupHandler) deleteBackup(ctx context.Context, input *backupFilenameI
 	if err := middleware.RequireAdmin(ctx); err != nil {
 		return nil, backupAdminError(err)
 	}
-	if err := h.backups.DeleteSnapshot(input.Filename); err != nil {
+	if err := h.backups.DeleteSnapshot(ctx, input.Filename); err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, huma.Error404NotFound("backup not found")
 		}
@@ -102,23 +102,22 @@ func (h *BackupHandler) downloadBackup(ctx context.Context, input *backupFilenam
 	if err := middleware.RequireAdmin(ctx); err != nil {
 		return nil, backupAdminError(err)
 	}
-	path, err := h.backups.ResolvePath(input.Filename)
+	archive, info, err := h.backups.OpenSnapshot(ctx, input.Filename)
 	if err != nil {
-		return nil, huma.Error404NotFound("backup not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("backup not found")
+		}
+		return nil, huma.Error500InternalServerError("could not open backup")
 	}
 
 	return &huma.StreamResponse{
 		Body: func(sctx huma.Context) {
-			f, openErr := os.Open(path) //nolint:gosec // path validated by BackupService.ResolvePath's filename allowlist
-			if openErr != nil {
-				sctx.SetStatus(500)
-				return
-			}
-			defer f.Close() //nolint:errcheck
+			defer archive.Close() //nolint:errcheck
 
 			sctx.SetHeader("Content-Type", "application/gzip")
+			sctx.SetHeader("Content-Length", strconv.FormatInt(info.SizeBytes, 10))
 			sctx.SetHeader("Content-Disposition", `attachment; filename="`+input.Filename+`"`)
-			_, _ = io.Copy(sctx.BodyWriter(), f)
+			_, _ = io.Copy(sctx.BodyWriter(), archive)
 		},
 	}, nil
 }
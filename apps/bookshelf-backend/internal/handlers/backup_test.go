@@ -10,6 +10,7 @@ import (
 
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/services"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
 func newBackupHandler(t *testing.T) *BackupHandler {
@@ -19,10 +20,12 @@ func newBackupHandler(t *testing.T) *BackupHandler {
 	require.NoError(t, err)
 	t.Cleanup(func() { sqlDB.Close() }) //nolint:errcheck
 
-	coversDir := t.TempDir()
-	backupsDir := t.TempDir()
+	coverStore, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	backupStore, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
 	admin := repotest.NewAdminRepository()
-	backupSvc := services.NewBackupService(sqlDB, admin, dbPath, coversDir, backupsDir)
+	backupSvc := services.NewBackupService(sqlDB, admin, t.TempDir(), coverStore, backupStore)
 	return NewBackupHandler(backupSvc)
 }
 
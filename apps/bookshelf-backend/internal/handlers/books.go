@@ -8,42 +8,55 @@ import (
 
 	"github.com/rs/zerolog"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/services"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
 // BookHandler holds dependencies for book routes.
 type BookHandler struct {
-	books     repository.BookRepository
-	users     repository.UserRepository
-	coversDir string
+	books repository.BookRepository
+	users repository.UserRepository
+	// coverStore caches downloaded covers; nil leaves cover URLs external.
+	coverStore storage.Store
 	// wishlistWorkflow is optional (nil-safe) — see createBook — so
 	// existing tests that construct a BookHandler without one keep working.
 	wishlistWorkflow *services.WishlistWorkflow
+	// contributors is optional (nil-safe) for the same reason; a book
+	// created without it is linked to authors by the nightly job instead.
+	contributors *services.ContributorService
 }
 
 // NewBookHandler creates a new BookHandler.
-func NewBookHandler(books repository.BookRepository, users repository.UserRepository, coversDir string, wishlistWorkflow *services.WishlistWorkflow) *BookHandler {
-	return &BookHandler{books: books, users: users, coversDir: coversDir, wishlistWorkflow: wishlistWorkflow}
+func NewBookHandler(books repository.BookRepository, users repository.UserRepository, coverStore storage.Store, wishlistWorkflow *services.WishlistWorkflow, contributors *services.ContributorService) *BookHandler {
+	return &BookHandler{books: books, users: users, coverStore: coverStore, wishlistWorkflow: wishlistWorkflow, contributors: contributors}
 }
 
 // bookResponse wraps a Book and adds the computed available_copies count.
 type bookResponse struct {
 	models.Book
 	AvailableCopies int64 `json:"available_copies"`
+	// Editions is only set by the work-grouped listing (group_by=work): every
+	// edition of the work in the catalog, each with its own count. The
+	// enclosing entry is the first edition in sort order, with
+	// AvailableCopies totalled across all of them.
+	Editions []bookResponse `json:"editions,omitempty"`
 }
 
 // --- Input / Output types ---
 
 type listBooksInput struct {
-	Q             string `query:"q" doc:"Search by title or author"`
+	Q             string `query:"q" doc:"Search by title or author; tolerates small typos"`
 	OLKey         string `query:"ol_key" doc:"Filter by exact Open Library key (returns single book)"`
 	Sort          string `query:"sort" doc:"Sort order: title (default), author, newest, relevance (best-match, only meaningful with q)"`
 	AvailableOnly bool   `query:"available_only" doc:"Only return books with at least one available copy"`
 	Page          int    `query:"page" minimum:"1" doc:"Page number (default 1)"`
 	PageSize      int    `query:"page_size" minimum:"1" maximum:"100" doc:"Items per page (default 20)"`
+	Cursor        string `query:"cursor" doc:"Continue after a previous response's next_cursor instead of using page; total, page and total_pages are then 0"`
+	GroupBy       string `query:"group_by" enum:"work" doc:"work: one entry per work, grouping editions by normalized title and author, with availability summed across them (page numbers only)"`
 }
 
 type listBooksOutput struct {
@@ -53,6 +66,8 @@ type listBooksOutput struct {
 		Page       int            `json:"page"`
 		PageSize   int            `json:"page_size"`
 		TotalPages int            `json:"total_pages"`
+		NextCursor string         `json:"next_cursor,omitempty" doc:"Pass as cursor to fetch the next page; absent on the last page"`
+		DidYouMean string         `json:"did_you_mean,omitempty" doc:"Set when a search finds nothing: the catalog title q most resembles, to offer as a corrected search"`
 	}
 }
 
@@ -81,6 +96,8 @@ type createBookInput struct {
 		PageCount     int    `json:"page_count,omitempty" doc:"Number of pages"`
 		Language      string `json:"language,omitempty" doc:"Language code"`
 		GoogleBooksID string `json:"google_books_id,omitempty" doc:"Google Books volume ID for deduplication"`
+		// Contributors is the metadata result's contributors, passed through.
+		Contributors []bookmatch.Contributor `json:"contributors,omitempty" doc:"People credited on the book, with roles; parsed from author when omitted"`
 	}
 }
 
@@ -127,7 +144,7 @@ func (h *BookHandler) RegisterRoutes(api huma.API) {
 
 // --- Handlers ---
 
-func (h *BookHandler) listBooks(_ context.Context, input *listBooksInput) (*listBooksOutput, error) {
+func (h *BookHandler) listBooks(ctx context.Context, input *listBooksInput) (*listBooksOutput, error) {
 	if input.OLKey != "" {
 		book, err := h.books.FindByOLKey(input.OLKey)
 		if err != nil {
@@ -150,10 +167,23 @@ func (h *BookHandler) listBooks(_ context.Context, input *listBooksInput) (*list
 	if pageSize < 1 {
 		pageSize = 20
 	}
-
-	result, err := h.books.ListPaginated(input.Q, input.Sort, input.AvailableOnly, page, pageSize)
+	if input.GroupBy == "work" {
+		out, err := h.listWorks(input, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		out.Body.DidYouMean = h.didYouMean(ctx, input, len(out.Body.Items))
+		return out, nil
+	}
+	result, err := fetchListPage("books:"+input.Sort, input.Cursor, page, pageSize, "could not fetch books",
+		func(page, pageSize int) (*repository.PaginatedResult[models.Book], error) {
+			return h.books.ListPaginated(input.Q, input.Sort, input.AvailableOnly, page, pageSize)
+		},
+		func(after *repository.Cursor, limit int) (*repository.CursorResult[models.Book], error) {
+			return h.books.ListAfter(input.Q, input.Sort, input.AvailableOnly, after, limit)
+		})
 	if err != nil {
-		return nil, huma.Error500InternalServerError("could not fetch books")
+		return nil, err
 	}
 
 	items, err := h.toBooksResponse(result.Items)
@@ -167,6 +197,80 @@ func (h *BookHandler) listBooks(_ context.Context, input *listBooksInput) (*list
 	out.Body.Page = result.Page
 	out.Body.PageSize = result.PageSize
 	out.Body.TotalPages = result.TotalPages
+	out.Body.NextCursor = result.NextCursor
+	out.Body.DidYouMean = h.didYouMean(ctx, input, len(items))
+	return &out, nil
+}
+
+// didYouMean returns the catalog title to suggest when a search's first
+// page comes back empty (found == 0), or "". The suggestion is best-effort:
+// if the catalog can't be listed, the search just has none.
+func (h *BookHandler) didYouMean(ctx context.Context, input *listBooksInput, found int) string {
+	if input.Q == "" || found > 0 || input.Cursor != "" || input.Page > 1 {
+		return ""
+	}
+	books, err := h.books.List("", "title", input.AvailableOnly)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("could not list catalog for search suggestion")
+		return ""
+	}
+	titles := make([]string, len(books))
+	for i, b := range books {
+		titles[i] = b.Title
+	}
+	return bookmatch.Suggest(input.Q, titles)
+}
+
+// listWorks is listBooks' group_by=work mode. Works are grouped in memory
+// over the whole filtered catalog — the grouping key is computed in Go, not
+// stored — so it only supports page numbers, not cursors.
+func (h *BookHandler) listWorks(input *listBooksInput, page, pageSize int) (*listBooksOutput, error) {
+	if input.Cursor != "" {
+		return nil, huma.Error400BadRequest("cursor is not supported with group_by=work; use page")
+	}
+	books, err := h.books.List(input.Q, input.Sort, input.AvailableOnly)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch books")
+	}
+	works := groupByWork(books)
+
+	start := (page - 1) * pageSize
+	if start > len(works) {
+		start = len(works)
+	}
+	end := start + pageSize
+	if end > len(works) {
+		end = len(works)
+	}
+	pageWorks := works[start:end]
+
+	var editions []models.Book
+	for _, w := range pageWorks {
+		editions = append(editions, w...)
+	}
+	withCounts, err := h.toBooksResponse(editions)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+
+	var out listBooksOutput
+	out.Body.Items = make([]bookResponse, len(pageWorks))
+	offset := 0
+	for i, w := range pageWorks {
+		work := withCounts[offset : offset+len(w)]
+		offset += len(w)
+		item := work[0]
+		item.AvailableCopies = 0
+		for _, e := range work {
+			item.AvailableCopies += e.AvailableCopies
+		}
+		item.Editions = work
+		out.Body.Items[i] = item
+	}
+	out.Body.Total = int64(len(works))
+	out.Body.Page = page
+	out.Body.PageSize = pageSize
+	out.Body.TotalPages = (len(works) + pageSize - 1) / pageSize
 	return &out, nil
 }
 
@@ -230,12 +334,12 @@ func (h *BookHandler) createBook(ctx context.Context, input *createBookInput) (*
 		return &createBookOutput{Body: *existing}, nil
 	}
 
-	coverURL := input.Body.CoverURL
-	if h.coversDir != "" && coverURL != "" {
-		if local, err := downloadCover(ctx, coverURL, h.coversDir); err != nil {
+	coverURL, coverBlurhash := input.Body.CoverURL, ""
+	if h.coverStore != nil && coverURL != "" {
+		if local, blurhash, err := downloadCover(ctx, coverURL, h.coverStore); err != nil {
 			zerolog.Ctx(ctx).Warn().Err(err).Msg("cover download failed, keeping external url")
 		} else if local != "" {
-			coverURL = local
+			coverURL, coverBlurhash = local, blurhash
 		}
 	}
 
@@ -245,6 +349,7 @@ func (h *BookHandler) createBook(ctx context.Context, input *createBookInput) (*
 		ISBN:          input.Body.ISBN,
 		OLKey:         input.Body.OLKey,
 		CoverURL:      coverURL,
+		CoverBlurhash: coverBlurhash,
 		Description:   input.Body.Description,
 		Publisher:     input.Body.Publisher,
 		PublishedDate: input.Body.PublishedDate,
@@ -256,6 +361,9 @@ func (h *BookHandler) createBook(ctx context.Context, input *createBookInput) (*
 		return nil, huma.Error500InternalServerError("could not create book")
 	}
 
+	if h.contributors != nil {
+		h.contributors.LinkBook(ctx, &book, input.Body.Contributors) // log-and-continue
+	}
 	if h.wishlistWorkflow != nil {
 		h.wishlistWorkflow.OnBookCreated(ctx, &book) // log-and-continue; never blocks book creation
 	}
@@ -263,6 +371,27 @@ func (h *BookHandler) createBook(ctx context.Context, input *createBookInput) (*
 	return &createBookOutput{Body: book}, nil
 }
 
+// groupByWork groups books into works by bookmatch.WorkKey — the same key
+// the search-time edition bucketing and the description reconciliation job
+// use — keeping each work at its first edition's position in books and its
+// editions in order. A book with no work key is a work of its own.
+func groupByWork(books []models.Book) [][]models.Book {
+	var works [][]models.Book
+	index := map[string]int{}
+	for _, b := range books {
+		key := bookmatch.WorkKey(b.Title, b.Author)
+		if i, ok := index[key]; ok && key != "" {
+			works[i] = append(works[i], b)
+			continue
+		}
+		if key != "" {
+			index[key] = len(works)
+		}
+		works = append(works, []models.Book{b})
+	}
+	return works
+}
+
 // findExistingBook implements createBook's upsert precedence — also reused
 // by CopyHandler's book-import path (copies_import.go) so both entry points
 // dedup against the catalog identically. A strong external key (OL key or
@@ -307,11 +436,17 @@ func (h *BookHandler) toBookResponse(book models.Book) bookResponse {
 // toBooksResponse fetches available copy counts for all books in a single
 // batch query and returns the assembled responses.
 func (h *BookHandler) toBooksResponse(books []models.Book) ([]bookResponse, error) {
+	return booksWithAvailability(h.books, books)
+}
+
+// booksWithAvailability is toBooksResponse for handlers that aren't a
+// BookHandler but list books too (e.g. RecommendationHandler).
+func booksWithAvailability(repo repository.BookRepository, books []models.Book) ([]bookResponse, error) {
 	ids := make([]uint, len(books))
 	for i, b := range books {
 		ids[i] = b.ID
 	}
-	counts, err := h.books.CountAvailableCopiesBatch(ids)
+	counts, err := repo.CountAvailableCopiesBatch(ids)
 	if err != nil {
 		return nil, err
 	}
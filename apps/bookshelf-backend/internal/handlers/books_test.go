@@ -1,18 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
 )
 
 func newBookHandler() (*BookHandler, *repotest.BookRepository) {
 	books := repotest.NewBookRepository()
 	users := repotest.NewUserRepository()
-	return NewBookHandler(books, users, "", nil), books
+	return NewBookHandler(books, users, nil, nil, nil), books
 }
 
 func createBookBody(title, olKey, googleBooksID, isbn string) *createBookInput {
@@ -95,3 +98,120 @@ func TestCreateBook_Unauthenticated(t *testing.T) {
 	_, err := h.createBook(fakeAuthedCtxNone(), createBookBody("T1", "OL1", "", ""))
 	assertStatus(t, err, 401)
 }
+
+func TestListBooks_CursorPagination(t *testing.T) {
+	h, books := newBookHandler()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	for _, title := range []string{"A", "B", "C", "D", "E"} {
+		b := models.Book{Title: title}
+		require.NoError(t, books.Create(&b))
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, Status: "available"}))
+	}
+
+	first, err := h.listBooks(context.Background(), &listBooksInput{PageSize: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, first.Body.Total)
+	require.NotEmpty(t, first.Body.NextCursor, "the page-number form also hands out a cursor")
+
+	var titles []string
+	for _, b := range first.Body.Items {
+		titles = append(titles, b.Title)
+	}
+	cursor := first.Body.NextCursor
+	for cursor != "" {
+		out, err := h.listBooks(context.Background(), &listBooksInput{PageSize: 2, Cursor: cursor})
+		require.NoError(t, err)
+		assert.Zero(t, out.Body.Total, "no COUNT in cursor mode")
+		for _, b := range out.Body.Items {
+			titles = append(titles, b.Title)
+		}
+		cursor = out.Body.NextCursor
+	}
+	assert.Equal(t, []string{"A", "B", "C", "D", "E"}, titles)
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		_, err := h.listBooks(context.Background(), &listBooksInput{Cursor: "not a cursor"})
+		assertStatus(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("cursor from another sort order", func(t *testing.T) {
+		_, err := h.listBooks(context.Background(), &listBooksInput{Sort: "author", Cursor: first.Body.NextCursor})
+		assertStatus(t, err, http.StatusBadRequest)
+	})
+}
+
+func TestListBooks_DidYouMean(t *testing.T) {
+	h, books := newBookHandler()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	for _, title := range []string{"The Hobbit", "Pride and Prejudice"} {
+		b := models.Book{Title: title}
+		require.NoError(t, books.Create(&b))
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, Status: "available"}))
+	}
+
+	t.Run("empty search suggests the closest title", func(t *testing.T) {
+		out, err := h.listBooks(context.Background(), &listBooksInput{Q: "pryde and predjudice"})
+		require.NoError(t, err)
+		assert.Empty(t, out.Body.Items)
+		assert.Equal(t, "Pride and Prejudice", out.Body.DidYouMean)
+	})
+
+	t.Run("no suggestion when the search finds something", func(t *testing.T) {
+		out, err := h.listBooks(context.Background(), &listBooksInput{Q: "hobbit"})
+		require.NoError(t, err)
+		assert.Len(t, out.Body.Items, 1)
+		assert.Empty(t, out.Body.DidYouMean)
+	})
+
+	t.Run("no suggestion when nothing is close", func(t *testing.T) {
+		out, err := h.listBooks(context.Background(), &listBooksInput{Q: "quantum chromodynamics"})
+		require.NoError(t, err)
+		assert.Empty(t, out.Body.DidYouMean)
+	})
+}
+
+func TestListBooks_GroupByWork(t *testing.T) {
+	h, books := newBookHandler()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	add := func(title, author string, statuses ...string) models.Book {
+		b := models.Book{Title: title, Author: author}
+		require.NoError(t, books.Create(&b))
+		for _, s := range statuses {
+			require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, Status: s}))
+		}
+		return b
+	}
+	hardback := add("Dune", "Frank Herbert", "available", "borrowed")
+	paperback := add("DUNE.", "Frank  Herbert", "available")
+	other := add("Emma", "Jane Austen", "available")
+
+	out, err := h.listBooks(context.Background(), &listBooksInput{GroupBy: "work"})
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, out.Body.Total)
+	require.Len(t, out.Body.Items, 2)
+	dune := out.Body.Items[0]
+	assert.Equal(t, hardback.ID, dune.ID, "the work is represented by its first edition")
+	assert.EqualValues(t, 2, dune.AvailableCopies, "availability is summed across editions")
+	require.Len(t, dune.Editions, 2)
+	assert.Equal(t, paperback.ID, dune.Editions[1].ID)
+	assert.EqualValues(t, 1, dune.Editions[1].AvailableCopies)
+	assert.Equal(t, other.ID, out.Body.Items[1].ID)
+	assert.Len(t, out.Body.Items[1].Editions, 1)
+
+	t.Run("paginates by work", func(t *testing.T) {
+		out, err := h.listBooks(context.Background(), &listBooksInput{GroupBy: "work", Page: 2, PageSize: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, out.Body.TotalPages)
+		require.Len(t, out.Body.Items, 1)
+		assert.Equal(t, other.ID, out.Body.Items[0].ID)
+	})
+
+	t.Run("cursor is rejected", func(t *testing.T) {
+		_, err := h.listBooks(context.Background(), &listBooksInput{GroupBy: "work", Cursor: "x"})
+		assertStatus(t, err, http.StatusBadRequest)
+	})
+}
@@ -4,18 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
 
 	"github.com/rs/zerolog"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/covers"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/services"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
 // CopyHandler holds dependencies for copy routes.
@@ -27,10 +26,16 @@ type CopyHandler struct {
 	admin     repository.AdminRepository
 	books     repository.BookRepository
 	wishlists repository.WishlistRequestRepository
-	coversDir string
+	// coverStore holds cached covers; nil if they aren't cached.
+	coverStore storage.Store
 	// wishlistWorkflow is optional (nil-safe) — see importBooks — so
 	// existing tests that construct a CopyHandler without one keep working.
 	wishlistWorkflow *services.WishlistWorkflow
+	// contributors is optional (nil-safe) likewise.
+	contributors *services.ContributorService
+	importJobs   repository.ImportJobRepository
+	// importWake signals RunImportJobs that importBooks queued a job.
+	importWake chan struct{}
 }
 
 // NewCopyHandler creates a new CopyHandler.
@@ -42,12 +47,15 @@ func NewCopyHandler(
 	admin repository.AdminRepository,
 	books repository.BookRepository,
 	wishlists repository.WishlistRequestRepository,
-	coversDir string,
+	coverStore storage.Store,
 	wishlistWorkflow *services.WishlistWorkflow,
+	contributors *services.ContributorService,
+	importJobs repository.ImportJobRepository,
 ) *CopyHandler {
 	return &CopyHandler{
 		copies: copies, users: users, notifs: notifs, waitlists: waitlists, admin: admin,
-		books: books, wishlists: wishlists, coversDir: coversDir, wishlistWorkflow: wishlistWorkflow,
+		books: books, wishlists: wishlists, coverStore: coverStore, wishlistWorkflow: wishlistWorkflow,
+		contributors: contributors, importJobs: importJobs, importWake: make(chan struct{}, 1),
 	}
 }
 
@@ -397,18 +405,13 @@ func (h *CopyHandler) maybeDeleteOrphanedBook(ctx context.Context, bookID uint)
 	log.Info().Msg("deleted orphaned keyless book")
 }
 
-// deleteCachedCover best-effort removes coverURL's locally-cached file, if
-// it points inside h.coversDir (i.e. was downloaded via downloadCover —
-// see internal/handlers/covers.go). A bare external URL, or any removal
-// failure, is silently ignored.
+// deleteCachedCover best-effort removes coverURL's cached file and its size
+// variants, if it points into h.coverStore (i.e. was downloaded via
+// downloadCover — see internal/handlers/covers.go). A bare external URL,
+// or any removal failure, is silently ignored.
 func (h *CopyHandler) deleteCachedCover(ctx context.Context, coverURL string) {
-	const localPrefix = "/api/covers/"
-	if h.coversDir == "" || !strings.HasPrefix(coverURL, localPrefix) {
-		return
-	}
-	filename := strings.TrimPrefix(coverURL, localPrefix)
-	if err := os.Remove(filepath.Join(h.coversDir, filename)); err != nil && !os.IsNotExist(err) {
-		zerolog.Ctx(ctx).Warn().Err(err).Str("filename", filename).Msg("could not delete cached cover for orphaned book")
+	if err := covers.RemoveCached(ctx, h.coverStore, coverURL); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("cover_url", coverURL).Msg("could not delete cached cover for orphaned book")
 	}
 }
 
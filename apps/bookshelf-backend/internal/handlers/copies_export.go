@@ -30,6 +30,11 @@ type exportRow struct {
 	Condition     string `json:"condition" yaml:"condition"`
 	Notes         string `json:"notes" yaml:"notes"`
 	Status        string `json:"status" yaml:"status"`
+
+	// skipReason is set by an import source's row mapper for a row it
+	// recognises but that mustn't be imported (e.g. a book the member
+	// doesn't own); classifyImportRow skips the row with it as the reason.
+	skipReason string
 }
 
 // exportRowHeader/exportRowValues keep the CSV column order in sync with
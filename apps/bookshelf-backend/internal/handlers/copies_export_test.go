@@ -64,7 +64,7 @@ func TestEncodeExportRows(t *testing.T) {
 }
 
 func TestCopyHandler_ExportMyCopies(t *testing.T) {
-	h, copies, _, _ := newCopyHandler("")
+	h, copies, _, _ := newCopyHandler(nil)
 	require.NoError(t, copies.Create(&models.Copy{
 		OwnerID:   1,
 		Condition: "good",
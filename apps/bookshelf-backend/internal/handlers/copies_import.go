@@ -51,8 +51,8 @@ const (
 
 type importInput struct {
 	Body struct {
-		Format  string `json:"format" required:"true" doc:"Import format: json, yaml, or csv"`
-		Content string `json:"content" required:"true" maxLength:"2000000" doc:"Raw file contents, as produced by GET /copies/mine/export"`
+		Format  string `json:"format" required:"true" doc:"Import format: json, yaml, csv, or tsv"`
+		Content string `json:"content" required:"true" maxLength:"2000000" doc:"Raw file contents, as produced by GET /copies/mine/export, or a Goodreads, StoryGraph or LibraryThing export (recognised by its header row)"`
 		// Decisions resolves possible_match rows on commit: 1-based row
 		// number (as a string, since JSON object keys are always strings) →
 		// "accept_match" or "create_new". A possible_match row with no entry
@@ -81,6 +81,7 @@ type importSummary struct {
 
 type importOutput struct {
 	Body struct {
+		Source  string            `json:"source" doc:"Where the file came from, as detected: bookshelf, goodreads, storygraph, or librarything"`
 		Summary importSummary     `json:"summary"`
 		Rows    []importRowResult `json:"rows"`
 	}
@@ -92,28 +93,38 @@ type importOutput struct {
 // server-side (the frontend just reads the uploaded file as text) so this
 // is the single place format-specific parsing/validation logic lives,
 // rather than duplicating a second JSON/YAML/CSV parser in the browser.
-func decodeImportRows(format, content string) ([]exportRow, error) {
+// It also returns the file's source (see detectImportSource): a csv or tsv
+// file may be another app's export, mapped into exportRow here so the rest
+// of the import treats it like our own.
+func decodeImportRows(format, content string) ([]exportRow, string, error) {
 	switch format {
 	case "json":
 		var rows []exportRow
 		if err := json.Unmarshal([]byte(content), &rows); err != nil {
-			return nil, fmt.Errorf("invalid json: %w", err)
+			return nil, "", fmt.Errorf("invalid json: %w", err)
 		}
-		return capImportRows(rows)
+		rows, err := capImportRows(rows)
+		return rows, sourceBookshelf, err
 	case "yaml":
 		var rows []exportRow
 		if err := yaml.Unmarshal([]byte(content), &rows); err != nil {
-			return nil, fmt.Errorf("invalid yaml: %w", err)
+			return nil, "", fmt.Errorf("invalid yaml: %w", err)
 		}
-		return capImportRows(rows)
-	case "csv":
-		rows, err := decodeImportCSV(content)
+		rows, err := capImportRows(rows)
+		return rows, sourceBookshelf, err
+	case "csv", "tsv":
+		comma := ','
+		if format == "tsv" {
+			comma = '\t'
+		}
+		rows, source, err := decodeImportDelimited(format, content, comma)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		return capImportRows(rows)
+		rows, err = capImportRows(rows)
+		return rows, source, err
 	default:
-		return nil, errors.New("format must be one of: json, yaml, csv")
+		return nil, "", errors.New("format must be one of: json, yaml, csv, tsv")
 	}
 }
 
@@ -124,52 +135,45 @@ func capImportRows(rows []exportRow) ([]exportRow, error) {
 	return rows, nil
 }
 
-// decodeImportCSV maps columns by header name rather than position, so a
-// reordered or partially-edited header row still parses correctly — the
-// same tolerance a hand-edited export file needs.
-func decodeImportCSV(content string) ([]exportRow, error) {
-	r := csv.NewReader(strings.NewReader(content))
-	r.FieldsPerRecord = -1
-	records, err := r.ReadAll()
+// decodeImportDelimited maps columns by header name rather than position,
+// so a reordered or partially-edited header row still parses correctly —
+// the same tolerance a hand-edited export file needs — and the header
+// decides which source's columns to read.
+func decodeImportDelimited(format, content string, comma rune) ([]exportRow, string, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	records, err := readDelimited(content, comma, false)
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrBareQuote) {
+		// Goodreads writes ISBNs as a bare ="0441013597" and LibraryThing
+		// doesn't quote its fields at all, so a quote mid-field is read
+		// literally rather than failing the whole file. An unterminated
+		// quoted field is still an error.
+		records, err = readDelimited(content, comma, true)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("invalid csv: %w", err)
+		return nil, "", fmt.Errorf("invalid %s: %w", format, err)
 	}
 	if len(records) == 0 {
-		return nil, errors.New("csv file is empty")
-	}
-
-	colIdx := make(map[string]int, len(records[0]))
-	for i, name := range records[0] {
-		colIdx[strings.TrimSpace(strings.ToLower(name))] = i
-	}
-	get := func(row []string, name string) string {
-		idx, ok := colIdx[name]
-		if !ok || idx >= len(row) {
-			return ""
-		}
-		return row[idx]
+		return nil, "", fmt.Errorf("%s file is empty", format)
 	}
 
+	header := newImportHeader(records[0])
+	source := detectImportSource(header)
+	mapRow := importRowMappers[source]
 	dataRows := records[1:]
 	rows := make([]exportRow, len(dataRows))
 	for i, rec := range dataRows {
-		pageCount, _ := strconv.Atoi(get(rec, "page_count"))
-		rows[i] = exportRow{
-			Title:         get(rec, "title"),
-			Author:        get(rec, "author"),
-			ISBN:          get(rec, "isbn"),
-			OLKey:         get(rec, "ol_key"),
-			GoogleBooksID: get(rec, "google_books_id"),
-			Publisher:     get(rec, "publisher"),
-			PublishedDate: get(rec, "published_date"),
-			PageCount:     pageCount,
-			Language:      get(rec, "language"),
-			Condition:     get(rec, "condition"),
-			Notes:         get(rec, "notes"),
-			Status:        get(rec, "status"),
-		}
+		rows[i] = mapRow(header, rec)
 	}
-	return rows, nil
+	return rows, source, nil
+}
+
+func readDelimited(content string, comma rune, lazyQuotes bool) ([][]string, error) {
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = lazyQuotes
+	return r.ReadAll()
 }
 
 // sanitizeImportRow trims and caps free-text fields, and drops Status
@@ -221,6 +225,9 @@ func (h *CopyHandler) classifyImportRow(row exportRow) rowPlan {
 	if row.Title == "" {
 		return rowPlan{row: row, action: actionSkipped, reason: "missing title"}
 	}
+	if row.skipReason != "" {
+		return rowPlan{row: row, action: actionSkipped, reason: row.skipReason}
+	}
 	if book, err := findExistingBook(h.books, row.OLKey, row.GoogleBooksID, row.ISBN); err == nil {
 		return rowPlan{row: row, action: actionMatchBook, book: book}
 	}
@@ -268,13 +275,16 @@ func (h *CopyHandler) registerImportRoutes(api huma.API) {
 	}, h.previewImportBooks)
 
 	huma.Register(api, huma.Operation{
-		OperationID: "import-books",
-		Method:      "POST",
-		Path:        "/copies/mine/import",
-		Tags:        []string{"copies"},
-		Summary:     "Import books from a JSON, YAML, or CSV file into the authenticated user's shelf",
-		Security:    []map[string][]string{{"bearer": {}}},
+		OperationID:   "import-books",
+		Method:        "POST",
+		Path:          "/copies/mine/import",
+		Tags:          []string{"copies"},
+		Summary:       "Queue an import of books from a JSON, YAML, CSV, or TSV file into the authenticated user's shelf",
+		Security:      []map[string][]string{{"bearer": {}}},
+		DefaultStatus: 202,
 	}, h.importBooks)
+
+	h.registerImportJobRoutes(api)
 }
 
 // --- Handlers ---
@@ -283,12 +293,13 @@ func (h *CopyHandler) previewImportBooks(ctx context.Context, input *importInput
 	if _, err := middleware.GetRequiredUserID(ctx); err != nil {
 		return nil, huma.Error401Unauthorized("authentication required")
 	}
-	rows, err := decodeImportRows(input.Body.Format, input.Body.Content)
+	rows, source, err := decodeImportRows(input.Body.Format, input.Body.Content)
 	if err != nil {
 		return nil, huma.Error400BadRequest(err.Error())
 	}
 
 	out := &importOutput{}
+	out.Body.Source = source
 	out.Body.Rows = make([]importRowResult, len(rows))
 	for i, row := range rows {
 		plan := h.classifyImportRow(row)
@@ -310,40 +321,61 @@ func importRowResultFor(row int, title string, action importAction, reason strin
 	return result
 }
 
-func (h *CopyHandler) importBooks(ctx context.Context, input *importInput) (*importOutput, error) {
+// importBooks queues the file as an ImportJob and returns at once; the
+// rows are committed in the background by RunImportJobs, and the caller
+// polls GET /copies/mine/imports/{id} for progress. The file is still
+// decoded here first, so a malformed one is rejected up front rather than
+// failing later as a job.
+func (h *CopyHandler) importBooks(ctx context.Context, input *importInput) (*importJobOutput, error) {
 	ownerID, err := middleware.GetRequiredUserID(ctx)
 	if err != nil {
 		return nil, huma.Error401Unauthorized("authentication required")
 	}
-	rows, err := decodeImportRows(input.Body.Format, input.Body.Content)
+	rows, source, err := decodeImportRows(input.Body.Format, input.Body.Content)
 	if err != nil {
 		return nil, huma.Error400BadRequest(err.Error())
 	}
-
-	maxCopies := h.maxCopiesPerUser()
-	currentCount, err := h.copies.CountByOwnerID(ownerID)
+	// Jobs run one at a time for everyone, each holding its upload until
+	// it's done, so a member gets one in the queue at once.
+	busy, err := h.importJobs.HasUnfinished(ownerID)
 	if err != nil {
-		return nil, huma.Error500InternalServerError("could not check existing copies")
+		return nil, huma.Error500InternalServerError("could not queue import")
+	}
+	if busy {
+		return nil, huma.Error409Conflict("you already have an import in progress; wait for it to finish or cancel it")
 	}
 
-	out := &importOutput{}
-	out.Body.Rows = make([]importRowResult, len(rows))
-	for i, row := range rows {
-		plan := h.classifyImportRow(row)
-		action, reason, matchedBook := h.commitImportPlan(ctx, ownerID, plan, i+1, input.Body.Decisions, maxCopies, &currentCount)
-		out.Body.Rows[i] = importRowResultFor(i+1, plan.row.Title, action, reason, matchedBook)
-		tallyImportResult(&out.Body.Summary, action)
+	decisions := []byte("{}")
+	if len(input.Body.Decisions) > 0 {
+		decisions, _ = json.Marshal(input.Body.Decisions) // a map[string]string always marshals
 	}
-	return out, nil
+
+	job := models.ImportJob{
+		OwnerID:   ownerID,
+		Status:    importJobQueued,
+		Format:    input.Body.Format,
+		Source:    source,
+		Content:   input.Body.Content,
+		Decisions: string(decisions),
+		TotalRows: len(rows),
+	}
+	if err := h.importJobs.Create(&job); err != nil {
+		return nil, huma.Error500InternalServerError("could not queue import")
+	}
+	h.wakeImportRunner()
+	return &importJobOutput{Body: toImportJobBody(&job, nil, 0)}, nil
 }
 
 // commitImportPlan performs the write(s) for one already-classified import
-// row and returns its resolved action, reason, and (for a row that ends up
-// attached to an existing book) that book. A problem with one row is
-// recorded as a skip and never aborts the rest of the batch — a hostile or
-// malformed row must not be able to block the good rows around it.
-// currentCount is advanced on success so max_copies_per_user stays accurate
-// across the whole import.
+// row and returns its resolved action, reason, (for a row that ends up
+// attached to an existing book) that book, and the copy to add. The copy
+// isn't created here: the caller stores it together with the row's outcome
+// (see ImportJobRepository.RecordRow), so a job resumed after a crash
+// never adds it twice. A problem with one row is recorded as a skip and
+// never aborts the rest of the batch — a hostile or malformed row must not
+// be able to block the good rows around it. The caller advances
+// currentCount once the copy is stored, so max_copies_per_user stays
+// accurate across the whole import.
 //
 // A possible_match row is resolved here against decisions[row] (1-based row
 // number, matching importRowResult.Row): "accept_match" attaches to
@@ -354,13 +386,13 @@ func (h *CopyHandler) importBooks(ctx context.Context, input *importInput) (*imp
 // possible_match — a commit always actually does one or the other.
 func (h *CopyHandler) commitImportPlan(
 	ctx context.Context, ownerID uint, plan rowPlan, row int, decisions map[string]string,
-	maxCopies int64, currentCount *int64,
-) (importAction, string, *models.Book) {
+	maxCopies, currentCount int64,
+) (importAction, string, *models.Book, *models.Copy) {
 	if plan.action == actionSkipped {
-		return actionSkipped, plan.reason, nil
+		return actionSkipped, plan.reason, nil, nil
 	}
-	if maxCopies > 0 && *currentCount >= maxCopies {
-		return actionSkipped, fmt.Sprintf("reached the maximum of %d shared copy/copies", maxCopies), nil
+	if maxCopies > 0 && currentCount >= maxCopies {
+		return actionSkipped, fmt.Sprintf("reached the maximum of %d shared copy/copies", maxCopies), nil, nil
 	}
 
 	action := plan.action
@@ -376,26 +408,22 @@ func (h *CopyHandler) commitImportPlan(
 	if action == actionCreateBook {
 		created, err := h.createImportedBook(ctx, plan.row)
 		if err != nil {
-			return actionSkipped, "could not create book", nil
+			return actionSkipped, "could not create book", nil, nil
 		}
 		book = created
 	}
 
-	bookCopy := models.Copy{
+	bookCopy := &models.Copy{
 		BookID:    book.ID,
 		OwnerID:   ownerID,
 		Condition: plan.row.Condition,
 		Notes:     plan.row.Notes,
 		Status:    "available",
 	}
-	if err := h.copies.Create(&bookCopy); err != nil {
-		return actionSkipped, "could not create copy", nil
-	}
-	*currentCount++
 	if action == actionMatchBook {
-		return action, "", book
+		return action, "", book, bookCopy
 	}
-	return action, "", nil
+	return action, "", nil, bookCopy
 }
 
 // createImportedBook creates a new Book from an import row. No cover URL or
@@ -416,6 +444,9 @@ func (h *CopyHandler) createImportedBook(ctx context.Context, row exportRow) (*m
 	if err := h.books.Create(&book); err != nil {
 		return nil, err
 	}
+	if h.contributors != nil {
+		h.contributors.LinkBook(ctx, &book, nil) // log-and-continue; parsed from Author
+	}
 	if h.wishlistWorkflow != nil {
 		h.wishlistWorkflow.OnBookCreated(ctx, &book) // log-and-continue; never blocks import
 	}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// An import commits each row through the same createBook path a member
+// would take by hand — catalog matching, contributor linking, wishlist
+// fulfillment — which for a few hundred rows takes far longer than one
+// request may. So POST /copies/mine/import only queues an ImportJob, and
+// RunImportJobs works through the queue in the background, recording each
+// row's outcome as it goes. That record is what a member polls for
+// progress, and what lets a job interrupted by a restart pick up at the
+// first row it hadn't finished.
+
+// Import job statuses; see models.ImportJob.
+const (
+	importJobQueued    = "queued"
+	importJobRunning   = "running"
+	importJobCompleted = "completed"
+	importJobFailed    = "failed"
+	importJobCancelled = "cancelled"
+)
+
+// importJobUnfinished are the statuses a job can still be run or cancelled
+// from.
+var importJobUnfinished = []string{importJobQueued, importJobRunning}
+
+// maxListedImportJobs caps GET /copies/mine/imports to a member's most
+// recent jobs.
+const maxListedImportJobs = 10
+
+type importJobBody struct {
+	ID            uint              `json:"id"`
+	Status        string            `json:"status" doc:"queued, running, completed, failed, or cancelled"`
+	Source        string            `json:"source" doc:"Where the file came from, as detected: bookshelf, goodreads, storygraph, or librarything"`
+	TotalRows     int               `json:"total_rows"`
+	ProcessedRows int               `json:"processed_rows"`
+	Error         string            `json:"error,omitempty" doc:"Why the job failed, if status is failed"`
+	Summary       importSummary     `json:"summary" doc:"Totals over the rows processed so far"`
+	Rows          []importRowResult `json:"rows" doc:"Outcomes of the rows processed so far, after the requested row"`
+	CreatedAt     time.Time         `json:"created_at"`
+	FinishedAt    *time.Time        `json:"finished_at,omitempty"`
+}
+
+type importJobOutput struct {
+	Body importJobBody
+}
+
+type listImportJobsOutput struct {
+	Body []importJobBody
+}
+
+type importJobIDInput struct {
+	ID uint `path:"id"`
+}
+
+type getImportJobInput struct {
+	ID    uint `path:"id"`
+	After int  `query:"after" minimum:"0" doc:"Only return rows after this 1-based row number, so polling fetches just the new ones"`
+}
+
+// toImportJobBody assembles a job's response from its recorded rows,
+// returning only those after row after.
+func toImportJobBody(job *models.ImportJob, rows []models.ImportJobRow, after int) importJobBody {
+	body := importJobBody{
+		ID:            job.ID,
+		Status:        job.Status,
+		Source:        job.Source,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		Error:         job.Error,
+		Rows:          []importRowResult{},
+		CreatedAt:     job.CreatedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+	for _, r := range rows {
+		action := importAction(r.Action)
+		tallyImportResult(&body.Summary, action)
+		if r.Row > after {
+			body.Rows = append(body.Rows, importRowResult{
+				Row:               r.Row,
+				Title:             r.Title,
+				Action:            action,
+				Reason:            r.Reason,
+				MatchedBookID:     r.MatchedBookID,
+				MatchedBookTitle:  r.MatchedBookTitle,
+				MatchedBookAuthor: r.MatchedBookAuthor,
+			})
+		}
+	}
+	return body
+}
+
+// --- Route registration ---
+
+func (h *CopyHandler) registerImportJobRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-import-jobs",
+		Method:      "GET",
+		Path:        "/copies/mine/imports",
+		Tags:        []string{"copies"},
+		Summary:     "List the authenticated user's most recent import jobs, without their rows",
+		Security:    []map[string][]string{{"bearer": {}}},
+	}, h.listImportJobs)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-import-job",
+		Method:      "GET",
+		Path:        "/copies/mine/imports/{id}",
+		Tags:        []string{"copies"},
+		Summary:     "Get an import job's progress and the outcome of each row processed so far",
+		Security:    []map[string][]string{{"bearer": {}}},
+	}, h.getImportJob)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "cancel-import-job",
+		Method:      "POST",
+		Path:        "/copies/mine/imports/{id}/cancel",
+		Tags:        []string{"copies"},
+		Summary:     "Cancel a queued or running import job; rows already imported are kept",
+		Security:    []map[string][]string{{"bearer": {}}},
+	}, h.cancelImportJob)
+}
+
+// --- Handlers ---
+
+func (h *CopyHandler) listImportJobs(ctx context.Context, _ *struct{}) (*listImportJobsOutput, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	jobs, err := h.importJobs.ListByOwnerID(userID, maxListedImportJobs)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not list imports")
+	}
+	out := &listImportJobsOutput{Body: make([]importJobBody, len(jobs))}
+	for i := range jobs {
+		rows, err := h.importJobs.ListRows(jobs[i].ID)
+		if err != nil {
+			return nil, huma.Error500InternalServerError("could not list imports")
+		}
+		// No job has rows after its last one, so none are listed; only the
+		// summary is.
+		out.Body[i] = toImportJobBody(&jobs[i], rows, jobs[i].TotalRows)
+	}
+	return out, nil
+}
+
+func (h *CopyHandler) getImportJob(ctx context.Context, input *getImportJobInput) (*importJobOutput, error) {
+	job, err := h.ownedImportJob(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.importJobs.ListRows(job.ID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch import rows")
+	}
+	return &importJobOutput{Body: toImportJobBody(job, rows, input.After)}, nil
+}
+
+// cancelImportJob stops a job before its next row. Copies already created
+// stay: the member can see exactly which from the job's rows, and remove
+// any they didn't want.
+func (h *CopyHandler) cancelImportJob(ctx context.Context, input *importJobIDInput) (*importJobOutput, error) {
+	job, err := h.ownedImportJob(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := h.importJobs.SetStatus(job.ID, importJobUnfinished, importJobCancelled, "")
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not cancel import")
+	}
+	if !ok {
+		return nil, huma.Error409Conflict("import has already finished")
+	}
+	return h.getImportJob(ctx, &getImportJobInput{ID: job.ID})
+}
+
+// ownedImportJob fetches job id, reporting someone else's job as missing so
+// its existence isn't revealed.
+func (h *CopyHandler) ownedImportJob(ctx context.Context, id uint) (*models.ImportJob, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	job, err := h.importJobs.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("import not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch import")
+	}
+	if job.OwnerID != userID {
+		return nil, huma.Error404NotFound("import not found")
+	}
+	return job, nil
+}
+
+// --- Background runner ---
+
+// RunImportJobs works through queued import jobs one at a time, oldest
+// first, until ctx is cancelled. It starts with any job a previous run of
+// the server left unfinished, then waits for importBooks to queue more.
+// main runs it in its own goroutine.
+func (h *CopyHandler) RunImportJobs(ctx context.Context) {
+	for {
+		h.drainImportJobs(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.importWake:
+		}
+	}
+}
+
+// wakeImportRunner tells RunImportJobs a job was queued. The channel holds
+// one wake-up, which is all a runner that drains the whole queue needs.
+func (h *CopyHandler) wakeImportRunner() {
+	select {
+	case h.importWake <- struct{}{}:
+	default:
+	}
+}
+
+// drainImportJobs runs unfinished jobs until there are none left or ctx is
+// cancelled. A job that errors is failed so the jobs queued behind it still
+// run; if even that can't be recorded, the database is likely down, and it
+// stops until the next wake-up rather than retrying in a tight loop.
+func (h *CopyHandler) drainImportJobs(ctx context.Context) {
+	for ctx.Err() == nil {
+		jobs, err := h.importJobs.ListUnfinished()
+		if err != nil {
+			log.Error().Err(err).Msg("import: failed to list unfinished jobs")
+			return
+		}
+		if len(jobs) == 0 {
+			return
+		}
+		job := &jobs[0]
+		if err := h.runImportJob(ctx, job); err != nil {
+			log.Error().Err(err).Uint("job_id", job.ID).Msg("import: job failed")
+			reason := "import stopped on an internal error"
+			if _, err := h.importJobs.SetStatus(job.ID, importJobUnfinished, importJobFailed, reason); err != nil {
+				log.Error().Err(err).Uint("job_id", job.ID).Msg("import: failed to mark job failed")
+				return
+			}
+		}
+	}
+}
+
+// runImportJob commits job's rows from the first one not yet recorded. It
+// returns an error only when the job couldn't be moved on (a database
+// failure); problems with the job itself fail the job instead.
+//
+// A row's copy is created in the same transaction that records its
+// outcome, so a resumed job never adds a copy twice. A book created for
+// the row is written first, though: if the server stops before the row is
+// recorded, the book stays, and the row matches it by key on resume (a
+// keyless one, never listed without a copy, is created again).
+func (h *CopyHandler) runImportJob(ctx context.Context, job *models.ImportJob) error {
+	if job.Status == importJobQueued {
+		ok, err := h.importJobs.SetStatus(job.ID, []string{importJobQueued}, importJobRunning, "")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil // cancelled before it started
+		}
+	}
+
+	rows, _, err := decodeImportRows(job.Format, job.Content)
+	if err != nil {
+		return h.finishImportJob(job.ID, importJobFailed, err.Error())
+	}
+	var decisions map[string]string
+	if err := json.Unmarshal([]byte(job.Decisions), &decisions); err != nil {
+		return h.finishImportJob(job.ID, importJobFailed, "invalid decisions: "+err.Error())
+	}
+	maxCopies := h.maxCopiesPerUser()
+	currentCount, err := h.copies.CountByOwnerID(job.OwnerID)
+	if err != nil {
+		return fmt.Errorf("count existing copies: %w", err)
+	}
+
+	for i := job.ProcessedRows; i < len(rows); i++ {
+		if ctx.Err() != nil {
+			return nil // left running, to resume on the next start
+		}
+		status, err := h.importJobs.GetStatus(job.ID)
+		if err != nil {
+			return err
+		}
+		if status != importJobRunning {
+			return nil // cancelled
+		}
+
+		plan := h.classifyImportRow(rows[i])
+		action, reason, matchedBook, bookCopy := h.commitImportPlan(ctx, job.OwnerID, plan, i+1, decisions, maxCopies, currentCount)
+		result := importRowResultFor(i+1, plan.row.Title, action, reason, matchedBook)
+		if err := h.importJobs.RecordRow(&models.ImportJobRow{
+			ImportJobID:       job.ID,
+			Row:               result.Row,
+			Title:             result.Title,
+			Action:            string(result.Action),
+			Reason:            result.Reason,
+			MatchedBookID:     result.MatchedBookID,
+			MatchedBookTitle:  result.MatchedBookTitle,
+			MatchedBookAuthor: result.MatchedBookAuthor,
+		}, bookCopy); err != nil {
+			return fmt.Errorf("record row %d: %w", i+1, err)
+		}
+		if bookCopy != nil {
+			currentCount++
+		}
+	}
+
+	log.Info().Uint("job_id", job.ID).Uint("owner_id", job.OwnerID).Int("rows", len(rows)).Msg("import: job completed")
+	return h.finishImportJob(job.ID, importJobCompleted, "")
+}
+
+// finishImportJob moves a running job to status. A job cancelled meanwhile
+// stays cancelled.
+func (h *CopyHandler) finishImportJob(id uint, status, errMsg string) error {
+	_, err := h.importJobs.SetStatus(id, []string{importJobRunning}, status, errMsg)
+	return err
+}
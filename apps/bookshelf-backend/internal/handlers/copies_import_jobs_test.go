@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+)
+
+// runImport queues input as an import job, runs the queue to completion as
+// RunImportJobs would, and returns the finished job.
+func runImport(t *testing.T, h *CopyHandler, ctx context.Context, input *importInput) (*importJobOutput, error) {
+	t.Helper()
+	queued, err := h.importBooks(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	h.drainImportJobs(context.Background())
+	return h.getImportJob(ctx, &getImportJobInput{ID: queued.Body.ID})
+}
+
+func TestCopyHandler_ImportBooks_QueuesAJob(t *testing.T) {
+	h, copies, _, _ := newCopyHandler(nil)
+	input := &importInput{}
+	input.Body.Format = "json"
+	input.Body.Content = `[{"title": "First"}, {"title": "Second"}, {"title": "Third"}]`
+
+	out, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+	require.NoError(t, err)
+	assert.Equal(t, importJobQueued, out.Body.Status)
+	assert.Equal(t, 3, out.Body.TotalRows)
+	assert.Equal(t, 0, out.Body.ProcessedRows)
+	mine, err := copies.ListByOwnerID(1)
+	require.NoError(t, err)
+	assert.Empty(t, mine, "nothing is imported until the runner gets to it")
+
+	h.drainImportJobs(context.Background())
+
+	done, err := h.getImportJob(fakeAuthedCtx(t, 1, "user"), &getImportJobInput{ID: out.Body.ID})
+	require.NoError(t, err)
+	assert.Equal(t, importJobCompleted, done.Body.Status)
+	assert.Equal(t, 3, done.Body.ProcessedRows)
+	assert.Equal(t, 3, done.Body.Summary.BooksCreated)
+	assert.NotNil(t, done.Body.FinishedAt)
+
+	stored, err := h.importJobs.GetByID(out.Body.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Content, "the upload isn't kept once the job is done")
+}
+
+func TestCopyHandler_ImportBooks_OneUnfinishedJobPerMember(t *testing.T) {
+	h, _, _, _ := newCopyHandler(nil)
+	input := &importInput{}
+	input.Body.Format = "json"
+	input.Body.Content = `[{"title": "First"}]`
+
+	_, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+	require.NoError(t, err)
+	_, err = h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+	assertStatus(t, err, 409)
+	_, err = h.importBooks(fakeAuthedCtx(t, 2, "user"), input)
+	require.NoError(t, err, "another member's queued job doesn't count")
+
+	h.drainImportJobs(context.Background())
+	_, err = h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+	require.NoError(t, err, "a finished job frees the slot")
+}
+
+func TestCopyHandler_GetImportJob(t *testing.T) {
+	h, _, _, _ := newCopyHandler(nil)
+	input := &importInput{}
+	input.Body.Format = "json"
+	input.Body.Content = `[{"title": "First"}, {"title": ""}, {"title": "Third"}]`
+	out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
+	require.NoError(t, err)
+	require.Len(t, out.Body.Rows, 3)
+
+	t.Run("after returns only later rows, but the summary still covers them all", func(t *testing.T) {
+		later, err := h.getImportJob(fakeAuthedCtx(t, 1, "user"), &getImportJobInput{ID: out.Body.ID, After: 2})
+		require.NoError(t, err)
+		require.Len(t, later.Body.Rows, 1)
+		assert.Equal(t, 3, later.Body.Rows[0].Row)
+		assert.Equal(t, 2, later.Body.Summary.BooksCreated)
+		assert.Equal(t, 1, later.Body.Summary.Skipped)
+	})
+
+	t.Run("someone else's job is not found", func(t *testing.T) {
+		_, err := h.getImportJob(fakeAuthedCtx(t, 2, "user"), &getImportJobInput{ID: out.Body.ID})
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("unauthenticated is unauthorized", func(t *testing.T) {
+		_, err := h.getImportJob(fakeAuthedCtxNone(), &getImportJobInput{ID: out.Body.ID})
+		assertStatus(t, err, 401)
+	})
+}
+
+func TestCopyHandler_ListImportJobs(t *testing.T) {
+	h, _, _, _ := newCopyHandler(nil)
+	for _, content := range []string{`[{"title": "First"}]`, `[{"title": "Second"}, {"title": "Third"}]`} {
+		input := &importInput{}
+		input.Body.Format = "json"
+		input.Body.Content = content
+		_, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
+		require.NoError(t, err)
+	}
+
+	out, err := h.listImportJobs(fakeAuthedCtx(t, 1, "user"), nil)
+	require.NoError(t, err)
+	require.Len(t, out.Body, 2)
+	assert.Equal(t, 2, out.Body[0].Summary.BooksCreated, "newest first")
+	assert.Empty(t, out.Body[0].Rows, "rows are only returned per job")
+
+	others, err := h.listImportJobs(fakeAuthedCtx(t, 2, "user"), nil)
+	require.NoError(t, err)
+	assert.Empty(t, others.Body)
+}
+
+func TestCopyHandler_CancelImportJob(t *testing.T) {
+	t.Run("a queued job is never run", func(t *testing.T) {
+		h, copies, _, _ := newCopyHandler(nil)
+		input := &importInput{}
+		input.Body.Format = "json"
+		input.Body.Content = `[{"title": "First"}]`
+		queued, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		require.NoError(t, err)
+
+		out, err := h.cancelImportJob(fakeAuthedCtx(t, 1, "user"), &importJobIDInput{ID: queued.Body.ID})
+		require.NoError(t, err)
+		assert.Equal(t, importJobCancelled, out.Body.Status)
+
+		h.drainImportJobs(context.Background())
+		mine, err := copies.ListByOwnerID(1)
+		require.NoError(t, err)
+		assert.Empty(t, mine)
+	})
+
+	t.Run("a running job stops before its next row, keeping the rows already imported", func(t *testing.T) {
+		h, copies, _, _ := newCopyHandler(nil)
+		job := models.ImportJob{
+			OwnerID: 1, Status: importJobRunning, Format: "json", Source: sourceBookshelf,
+			Content: `[{"title": "First"}, {"title": "Second"}]`, Decisions: "{}", TotalRows: 2,
+		}
+		require.NoError(t, h.importJobs.Create(&job))
+		require.NoError(t, h.importJobs.RecordRow(&models.ImportJobRow{ImportJobID: job.ID, Row: 1, Title: "First", Action: string(actionCreateBook)}, nil))
+
+		_, err := h.cancelImportJob(fakeAuthedCtx(t, 1, "user"), &importJobIDInput{ID: job.ID})
+		require.NoError(t, err)
+		h.drainImportJobs(context.Background())
+
+		out, err := h.getImportJob(fakeAuthedCtx(t, 1, "user"), &getImportJobInput{ID: job.ID})
+		require.NoError(t, err)
+		assert.Equal(t, importJobCancelled, out.Body.Status)
+		assert.Equal(t, 1, out.Body.ProcessedRows)
+		mine, err := copies.ListByOwnerID(1)
+		require.NoError(t, err)
+		assert.Empty(t, mine, "row 2 must not have been imported")
+	})
+
+	t.Run("a finished job can't be cancelled", func(t *testing.T) {
+		h, _, _, _ := newCopyHandler(nil)
+		input := &importInput{}
+		input.Body.Format = "json"
+		input.Body.Content = `[{"title": "First"}]`
+		out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
+		require.NoError(t, err)
+
+		_, err = h.cancelImportJob(fakeAuthedCtx(t, 1, "user"), &importJobIDInput{ID: out.Body.ID})
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("someone else's job is not found", func(t *testing.T) {
+		h, _, _, _ := newCopyHandler(nil)
+		input := &importInput{}
+		input.Body.Format = "json"
+		input.Body.Content = `[{"title": "First"}]`
+		queued, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		require.NoError(t, err)
+
+		_, err = h.cancelImportJob(fakeAuthedCtx(t, 2, "user"), &importJobIDInput{ID: queued.Body.ID})
+		assertStatus(t, err, 404)
+	})
+}
+
+func TestCopyHandler_RunImportJobs_ResumesAnInterruptedJob(t *testing.T) {
+	h, copies, books, _ := newCopyHandler(nil)
+	// As left by a server stopped after the first row: still running, with
+	// that row recorded and its copy created.
+	first := models.Book{Title: "First"}
+	require.NoError(t, books.Create(&first))
+	job := models.ImportJob{
+		OwnerID: 1, Status: importJobRunning, Format: "json", Source: sourceBookshelf,
+		Content: `[{"title": "First"}, {"title": "Second"}]`, Decisions: "{}", TotalRows: 2,
+	}
+	require.NoError(t, h.importJobs.Create(&job))
+	require.NoError(t, h.importJobs.RecordRow(&models.ImportJobRow{ImportJobID: job.ID, Row: 1, Title: "First", Action: string(actionCreateBook)},
+		&models.Copy{BookID: first.ID, OwnerID: 1, Status: "available"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.RunImportJobs(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		j, err := h.importJobs.GetByID(job.ID)
+		return err == nil && j.Status == importJobCompleted
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	mine, err := copies.ListByOwnerID(1)
+	require.NoError(t, err)
+	require.Len(t, mine, 2, "only the unfinished row is imported")
+	out, err := h.getImportJob(fakeAuthedCtx(t, 1, "user"), &getImportJobInput{ID: job.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, out.Body.Summary.BooksCreated)
+}
+
+func TestCopyHandler_RunImportJobs_PicksUpNewlyQueuedJobs(t *testing.T) {
+	h, copies, _, _ := newCopyHandler(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.RunImportJobs(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	input := &importInput{}
+	input.Body.Format = "json"
+	input.Body.Content = `[{"title": "First"}]`
+	_, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		mine, err := copies.ListByOwnerID(1)
+		return err == nil && len(mine) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCopyHandler_RunImportJobs_FailsAJobThatKeepsErroring(t *testing.T) {
+	h, copies, _, _ := newCopyHandler(nil)
+	// Row 1 is already recorded without the job having advanced past it, so
+	// recording it again fails every time the job is run.
+	require.NoError(t, h.importJobs.RecordRow(&models.ImportJobRow{ImportJobID: 1, Row: 1, Title: "Stuck"}, nil))
+	stuck := models.ImportJob{
+		OwnerID: 1, Status: importJobRunning, Format: "json", Source: sourceBookshelf,
+		Content: `[{"title": "Stuck"}]`, Decisions: "{}", TotalRows: 1,
+	}
+	require.NoError(t, h.importJobs.Create(&stuck))
+	require.Equal(t, uint(1), stuck.ID)
+	next := models.ImportJob{
+		OwnerID: 2, Status: importJobQueued, Format: "json", Source: sourceBookshelf,
+		Content: `[{"title": "Next"}]`, Decisions: "{}", TotalRows: 1,
+	}
+	require.NoError(t, h.importJobs.Create(&next))
+
+	h.drainImportJobs(context.Background())
+
+	j, err := h.importJobs.GetByID(stuck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, importJobFailed, j.Status)
+	assert.NotEmpty(t, j.Error)
+	j, err = h.importJobs.GetByID(next.ID)
+	require.NoError(t, err)
+	assert.Equal(t, importJobCompleted, j.Status, "the job queued behind it still runs")
+	mine, err := copies.ListByOwnerID(2)
+	require.NoError(t, err)
+	assert.Len(t, mine, 1)
+}
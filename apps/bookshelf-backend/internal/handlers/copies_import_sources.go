@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+)
+
+// Where an imported csv/tsv file came from, as detectImportSource reads it
+// off the header row — so a member can upload the file another app gave
+// them as-is, without saying which app that was.
+const (
+	sourceBookshelf    = "bookshelf"
+	sourceGoodreads    = "goodreads"
+	sourceStoryGraph   = "storygraph"
+	sourceLibraryThing = "librarything"
+)
+
+// importHeader maps a delimited file's header names, trimmed and
+// lowercased, to their column index.
+type importHeader map[string]int
+
+func newImportHeader(names []string) importHeader {
+	h := make(importHeader, len(names))
+	for i, name := range names {
+		h[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	return h
+}
+
+func (h importHeader) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := h[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (h importHeader) get(rec []string, name string) string {
+	idx, ok := h[name]
+	if !ok || idx >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[idx])
+}
+
+func (h importHeader) getInt(rec []string, name string) int {
+	n, _ := strconv.Atoi(h.get(rec, name))
+	return n
+}
+
+// detectImportSource recognises the other apps' exports by columns only
+// they have; anything else is read as our own export.
+func detectImportSource(h importHeader) string {
+	switch {
+	case h.has("book id", "exclusive shelf"):
+		return sourceGoodreads
+	case h.has("isbn/uid", "read status"):
+		return sourceStoryGraph
+	case h.has("primary author"):
+		return sourceLibraryThing
+	default:
+		return sourceBookshelf
+	}
+}
+
+// importRowMappers turns one record of each source's export into an
+// exportRow. Only the columns that describe the book carry over; ratings,
+// reviews and reading dates have nowhere to go, and private notes stay
+// private rather than becoming a copy's public notes.
+var importRowMappers = map[string]func(importHeader, []string) exportRow{
+	sourceBookshelf:    bookshelfImportRow,
+	sourceGoodreads:    goodreadsImportRow,
+	sourceStoryGraph:   storyGraphImportRow,
+	sourceLibraryThing: libraryThingImportRow,
+}
+
+func bookshelfImportRow(h importHeader, rec []string) exportRow {
+	return exportRow{
+		Title:         h.get(rec, "title"),
+		Author:        h.get(rec, "author"),
+		ISBN:          h.get(rec, "isbn"),
+		OLKey:         h.get(rec, "ol_key"),
+		GoogleBooksID: h.get(rec, "google_books_id"),
+		Publisher:     h.get(rec, "publisher"),
+		PublishedDate: h.get(rec, "published_date"),
+		PageCount:     h.getInt(rec, "page_count"),
+		Language:      h.get(rec, "language"),
+		Condition:     h.get(rec, "condition"),
+		Notes:         h.get(rec, "notes"),
+		Status:        h.get(rec, "status"),
+	}
+}
+
+// goodreadsSeries matches the series Goodreads appends to a title, e.g.
+// "Dune (Dune Chronicles, #1)".
+var goodreadsSeries = regexp.MustCompile(`\s*\([^()]*#\d+(\.\d+)?\)$`)
+
+// goodreadsImportRow reads Goodreads' "Export Library" CSV. A book counts
+// as owned if it has owned copies recorded or is on an "owned" shelf.
+func goodreadsImportRow(h importHeader, rec []string) exportRow {
+	publishedDate := h.get(rec, "year published")
+	if publishedDate == "" {
+		publishedDate = h.get(rec, "original publication year")
+	}
+	row := exportRow{
+		Title:         goodreadsSeries.ReplaceAllString(h.get(rec, "title"), ""),
+		Author:        h.get(rec, "author"),
+		ISBN:          firstISBN(h.get(rec, "isbn13"), h.get(rec, "isbn")),
+		Publisher:     h.get(rec, "publisher"),
+		PublishedDate: publishedDate,
+		PageCount:     h.getInt(rec, "number of pages"),
+	}
+	owned := h.getInt(rec, "owned copies") > 0
+	for _, shelf := range strings.Split(h.get(rec, "bookshelves"), ",") {
+		if s := strings.TrimSpace(shelf); s == "owned" || s == "owned-books" {
+			owned = true
+		}
+	}
+	if !owned {
+		row.skipReason = "not marked as owned in Goodreads"
+	}
+	return row
+}
+
+// storyGraphImportRow reads The StoryGraph's CSV export, whose "Owned?"
+// column says whether a book is owned.
+func storyGraphImportRow(h importHeader, rec []string) exportRow {
+	row := exportRow{
+		Title:  h.get(rec, "title"),
+		Author: h.get(rec, "authors"),
+		// Holds a StoryGraph ID instead for books with no ISBN, which
+		// firstISBN rejects.
+		ISBN: firstISBN(h.get(rec, "isbn/uid")),
+	}
+	if !strings.EqualFold(h.get(rec, "owned?"), "yes") {
+		row.skipReason = "not marked as owned in StoryGraph"
+	}
+	return row
+}
+
+// libraryThingImportRow reads LibraryThing's tab-delimited export. Books in
+// no collection are in "Your library", LibraryThing's default; ones only in
+// others ("Wishlist", "Read but unowned", ...) aren't owned.
+func libraryThingImportRow(h importHeader, rec []string) exportRow {
+	isbns := append([]string{h.get(rec, "isbn")}, strings.Split(h.get(rec, "isbns"), ",")...)
+	// "Publisher (2000), Edition: Reprint, Paperback, 336 pages"
+	publisher, _, _ := strings.Cut(h.get(rec, "publication"), " (")
+	row := exportRow{
+		Title:         h.get(rec, "title"),
+		Author:        invertAuthorName(h.get(rec, "primary author")),
+		ISBN:          firstISBN(isbns...),
+		Publisher:     strings.TrimSpace(publisher),
+		PublishedDate: h.get(rec, "date"),
+		PageCount:     h.getInt(rec, "page count"),
+	}
+	collections := h.get(rec, "collections")
+	if collections != "" && !strings.Contains(strings.ToLower(collections), "your library") {
+		row.skipReason = "not in your LibraryThing library"
+	}
+	return row
+}
+
+// firstISBN returns the first of candidates that is a valid ISBN,
+// normalized to ISBN-13. Exports wrap ISBNs to stop spreadsheets
+// mangling them (Goodreads' ="0441013597", LibraryThing's [0441013597]);
+// the wrapping is stripped first.
+func firstISBN(candidates ...string) string {
+	for _, c := range candidates {
+		c = strings.Trim(c, `=" []`)
+		if isbn := bookmatch.NormalizeISBN(c); isbn != "" {
+			return isbn
+		}
+	}
+	return ""
+}
+
+// invertAuthorName turns LibraryThing's "Herbert, Frank" into "Frank
+// Herbert", the order the rest of the catalog uses.
+func invertAuthorName(name string) string {
+	last, first, ok := strings.Cut(name, ",")
+	if !ok {
+		return name
+	}
+	return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+)
+
+// Trimmed from real exports: each keeps its app's header row, quoting and
+// ISBN wrapping, with fewer rows.
+const (
+	goodreadsExport = "\ufeffBook Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Average Rating,Publisher,Binding,Number of Pages,Year Published,Original Publication Year,Date Read,Date Added,Bookshelves,Bookshelves with positions,Exclusive Shelf,My Review,Spoiler,Private Notes,Read Count,Owned Copies\n" +
+		`44767458,"Dune (Dune, #1)",Frank Herbert,"Herbert, Frank",,="0441013597",="9780441013593",5,4.27,Ace,Paperback,658,2005,1965,2024/01/02,2023/12/01,,,read,,,private thoughts,1,1` + "\n" +
+		`18144590,The Left Hand of Darkness,Ursula K. Le Guin,"Le Guin, Ursula K.",,="",="",4,4.10,Ace,Paperback,304,2000,1969,,2023/12/01,"owned, favourites","owned (#1), favourites (#3)",read,,,,1,0` + "\n" +
+		`7126,The Count of Monte Cristo,Alexandre Dumas,"Dumas, Alexandre",,="0140449264",="9780140449266",0,4.29,Penguin,Paperback,1276,2003,1844,,2023/12/01,to-read,to-read (#1),to-read,,,,0,0` + "\n"
+
+	storyGraphExport = "Title,Authors,Contributors,ISBN/UID,Format,Read Status,Date Added,Last Date Read,Dates Read,Read Count,Moods,Pace,Character- or Plot-Driven?,Strong Character Development?,Loveable Characters?,Diverse Characters?,Flawed Characters?,Star Rating,Review,Content Warnings,Content Warning Description,Tags,Owned?\n" +
+		"Piranesi,Susanna Clarke,,9781635575637,hardcover,read,2024/01/05,2024/01/20,2024/01/05-2024/01/20,1,mysterious,medium,Character,Yes,Yes,No,Yes,5.0,,,,,Yes\n" +
+		"Circe,Madeline Miller,,a1b2c3d4-storygraph-uid,digital,to-read,2024/01/05,,,0,,,,,,,,,,,,,No\n"
+
+	libraryThingExport = "Book Id\tTitle\tSort Character\tPrimary Author\tPrimary Author Role\tSecondary Author\tSecondary Author Roles\tPublication\tDate\tReview\tRating\tComment\tPrivate Comment\tSummary\tMedia\tPhysical Description\tPage Count\tCollections\tISBN\tISBNs\tLanguages\n" +
+		"123\tA Wizard of Earthsea\t1\tLe Guin, Ursula K.\tAuthor\t\t\tBantam (2004), Edition: Reissue, Paperback, 183 pages\t2004\t\t\t\t\tA Wizard of Earthsea by Ursula K. Le Guin (2004)\tPaperback\t183 p.\t183\tYour library\t[0553383043]\t0553383043, 9780553383041\tEnglish\n" +
+		"124\tThe \"Real\" Hobbit\t1\tTolkien, J.R.R.\tAuthor\t\t\tHoughton Mifflin (1999)\t1999\t\t\t\t\t\tPaperback\t\t310\t\t\t\tEnglish\n" +
+		"125\tDune Messiah\t1\tHerbert, Frank\tAuthor\t\t\tAce (1987)\t1987\t\t\t\t\t\tPaperback\t\t\tWishlist\t[0441172695]\t\tEnglish\n"
+)
+
+func TestDecodeImportRows_Goodreads(t *testing.T) {
+	rows, source, err := decodeImportRows("csv", goodreadsExport)
+	require.NoError(t, err)
+	assert.Equal(t, sourceGoodreads, source)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, exportRow{
+		Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593", Publisher: "Ace",
+		PublishedDate: "2005", PageCount: 658,
+	}, rows[0], "the series suffix is dropped, the ISBN unwrapped, and private notes left behind")
+	assert.Equal(t, "The Left Hand of Darkness", rows[1].Title)
+	assert.Empty(t, rows[1].ISBN)
+	assert.Empty(t, rows[1].skipReason, "a book on the owned shelf is owned")
+	assert.Equal(t, "not marked as owned in Goodreads", rows[2].skipReason)
+}
+
+func TestDecodeImportRows_StoryGraph(t *testing.T) {
+	rows, source, err := decodeImportRows("csv", storyGraphExport)
+	require.NoError(t, err)
+	assert.Equal(t, sourceStoryGraph, source)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, exportRow{Title: "Piranesi", Author: "Susanna Clarke", ISBN: "9781635575637"}, rows[0])
+	assert.Empty(t, rows[1].ISBN, "a StoryGraph ID is not an ISBN")
+	assert.Equal(t, "not marked as owned in StoryGraph", rows[1].skipReason)
+}
+
+func TestDecodeImportRows_LibraryThing(t *testing.T) {
+	rows, source, err := decodeImportRows("tsv", libraryThingExport)
+	require.NoError(t, err)
+	assert.Equal(t, sourceLibraryThing, source)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, exportRow{
+		Title: "A Wizard of Earthsea", Author: "Ursula K. Le Guin", ISBN: "9780553383041", Publisher: "Bantam",
+		PublishedDate: "2004", PageCount: 183,
+	}, rows[0])
+	assert.Equal(t, `The "Real" Hobbit`, rows[1].Title, "unquoted fields may contain quotes")
+	assert.Equal(t, "J.R.R. Tolkien", rows[1].Author)
+	assert.Empty(t, rows[1].skipReason, "a book in no collection is in the default library")
+	assert.Equal(t, "not in your LibraryThing library", rows[2].skipReason)
+}
+
+func TestDecodeImportRows_OwnCSVIsStillOurs(t *testing.T) {
+	_, source, err := decodeImportRows("csv", "title,author,isbn\nDune,Frank Herbert,9780441013593\n")
+	require.NoError(t, err)
+	assert.Equal(t, sourceBookshelf, source)
+}
+
+func TestCopyHandler_PreviewImportBooks_Goodreads(t *testing.T) {
+	h, copies, books, _ := newCopyHandler(nil)
+	dune := models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593"}
+	require.NoError(t, books.Create(&dune))
+	lhod := models.Book{Title: "The Left Hand of Darkness", Author: "Ursula K. Le Guin"}
+	require.NoError(t, books.Create(&lhod))
+	require.NoError(t, copies.Create(&models.Copy{BookID: lhod.ID, OwnerID: 2, Status: "available"}))
+
+	input := &importInput{}
+	input.Body.Format = "csv"
+	input.Body.Content = goodreadsExport
+	out, err := h.previewImportBooks(fakeAuthedCtx(t, 1, "user"), input)
+	require.NoError(t, err)
+
+	assert.Equal(t, sourceGoodreads, out.Body.Source)
+	require.Len(t, out.Body.Rows, 3)
+	assert.Equal(t, actionMatchBook, out.Body.Rows[0].Action, "matched on ISBN-13")
+	assert.Equal(t, actionPossibleMatch, out.Body.Rows[1].Action, "no ISBN, so the fuzzy title+author match applies")
+	assert.Equal(t, actionSkipped, out.Body.Rows[2].Action)
+	assert.Equal(t, "not marked as owned in Goodreads", out.Body.Rows[2].Reason)
+}
@@ -16,7 +16,7 @@ const validImportJSON = `[
 
 func TestDecodeImportRows(t *testing.T) {
 	t.Run("json round-trips the export shape", func(t *testing.T) {
-		rows, err := decodeImportRows("json", `[{"title":"Dune","ol_key":"OL893415W"}]`)
+		rows, _, err := decodeImportRows("json", `[{"title":"Dune","ol_key":"OL893415W"}]`)
 		require.NoError(t, err)
 		require.Len(t, rows, 1)
 		require.Equal(t, "Dune", rows[0].Title)
@@ -24,7 +24,7 @@ func TestDecodeImportRows(t *testing.T) {
 	})
 
 	t.Run("yaml", func(t *testing.T) {
-		rows, err := decodeImportRows("yaml", "- title: Dune\n  author: Frank Herbert\n")
+		rows, _, err := decodeImportRows("yaml", "- title: Dune\n  author: Frank Herbert\n")
 		require.NoError(t, err)
 		require.Len(t, rows, 1)
 		require.Equal(t, "Dune", rows[0].Title)
@@ -32,7 +32,7 @@ func TestDecodeImportRows(t *testing.T) {
 
 	t.Run("csv maps columns by header name, tolerating reordering", func(t *testing.T) {
 		csv := "author,title\nFrank Herbert,Dune\n"
-		rows, err := decodeImportRows("csv", csv)
+		rows, _, err := decodeImportRows("csv", csv)
 		require.NoError(t, err)
 		require.Len(t, rows, 1)
 		require.Equal(t, "Dune", rows[0].Title)
@@ -40,17 +40,17 @@ func TestDecodeImportRows(t *testing.T) {
 	})
 
 	t.Run("unknown format is rejected", func(t *testing.T) {
-		_, err := decodeImportRows("xml", "<a/>")
+		_, _, err := decodeImportRows("xml", "<a/>")
 		require.Error(t, err)
 	})
 
 	t.Run("malformed json is rejected, not partially parsed", func(t *testing.T) {
-		_, err := decodeImportRows("json", `[{"title": "Dune"`)
+		_, _, err := decodeImportRows("json", `[{"title": "Dune"`)
 		require.Error(t, err)
 	})
 
 	t.Run("malformed csv is rejected", func(t *testing.T) {
-		_, err := decodeImportRows("csv", "title,author\n\"unterminated")
+		_, _, err := decodeImportRows("csv", "title,author\n\"unterminated")
 		require.Error(t, err)
 	})
 
@@ -64,7 +64,7 @@ func TestDecodeImportRows(t *testing.T) {
 			b.WriteString(`{"title":"Book"}`)
 		}
 		b.WriteString("]")
-		_, err := decodeImportRows("json", b.String())
+		_, _, err := decodeImportRows("json", b.String())
 		require.Error(t, err)
 	})
 }
@@ -79,7 +79,7 @@ func TestSanitizeImportRow(t *testing.T) {
 }
 
 func TestCopyHandler_ClassifyImportRow(t *testing.T) {
-	h, copies, books, _ := newCopyHandler("")
+	h, copies, books, _ := newCopyHandler(nil)
 
 	existing := models.Book{Title: "Dune", Author: "Frank Herbert", OLKey: "OL893415W"}
 	require.NoError(t, books.Create(&existing))
@@ -130,7 +130,7 @@ func TestCopyHandler_ClassifyImportRow(t *testing.T) {
 }
 
 func TestCopyHandler_PreviewImportBooks(t *testing.T) {
-	h, _, _, _ := newCopyHandler("")
+	h, _, _, _ := newCopyHandler(nil)
 
 	t.Run("unauthenticated is unauthorized", func(t *testing.T) {
 		_, err := h.previewImportBooks(fakeAuthedCtxNone(), &importInput{})
@@ -165,7 +165,7 @@ func TestCopyHandler_PreviewImportBooks(t *testing.T) {
 	})
 
 	t.Run("surfaces a possible_match row with candidate details, without writing anything", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 		existing := models.Book{Title: "Dune", Author: "Frank Herbert"}
 		require.NoError(t, books.Create(&existing))
 		require.NoError(t, copies.Create(&models.Copy{BookID: existing.ID, Status: "available"}))
@@ -190,19 +190,19 @@ func TestCopyHandler_PreviewImportBooks(t *testing.T) {
 
 func TestCopyHandler_ImportBooks(t *testing.T) {
 	t.Run("unauthenticated is unauthorized", func(t *testing.T) {
-		h, _, _, _ := newCopyHandler("")
+		h, _, _, _ := newCopyHandler(nil)
 		_, err := h.importBooks(fakeAuthedCtxNone(), &importInput{})
 		require.Error(t, err)
 		assertStatus(t, err, 401)
 	})
 
 	t.Run("creates new books and copies owned by the caller, skipping invalid rows", func(t *testing.T) {
-		h, copies, _, _ := newCopyHandler("")
+		h, copies, _, _ := newCopyHandler(nil)
 		input := &importInput{}
 		input.Body.Format = "json"
 		input.Body.Content = validImportJSON
 
-		out, err := h.importBooks(fakeAuthedCtx(t, 7, "user"), input)
+		out, err := runImport(t, h, fakeAuthedCtx(t, 7, "user"), input)
 		require.NoError(t, err)
 		require.Equal(t, 1, out.Body.Summary.BooksCreated)
 		require.Equal(t, 1, out.Body.Summary.CopiesCreated)
@@ -216,12 +216,12 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("status from the file is never trusted, even if set to loaned", func(t *testing.T) {
-		h, copies, _, _ := newCopyHandler("")
+		h, copies, _, _ := newCopyHandler(nil)
 		input := &importInput{}
 		input.Body.Format = "json"
 		input.Body.Content = `[{"title": "Dune", "status": "loaned"}]`
 
-		_, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		_, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.NoError(t, err)
 
 		mine, err := copies.ListByOwnerID(1)
@@ -231,7 +231,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("matches an existing book by isbn and adds a new copy to it", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 		existing := models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593"}
 		require.NoError(t, books.Create(&existing))
 
@@ -239,7 +239,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 		input.Body.Format = "json"
 		input.Body.Content = `[{"title": "Dune", "isbn": "9780441013593"}]`
 
-		out, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.NoError(t, err)
 		require.Equal(t, 1, out.Body.Summary.BooksMatched)
 		require.Equal(t, 0, out.Body.Summary.BooksCreated)
@@ -251,7 +251,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("possible_match row with an accept_match decision attaches to the existing book", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 		existing := models.Book{Title: "Dune", Author: "Frank Herbert"}
 		require.NoError(t, books.Create(&existing))
 		require.NoError(t, copies.Create(&models.Copy{BookID: existing.ID, Status: "available"}))
@@ -261,7 +261,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 		input.Body.Content = `[{"title": "Dune", "author": "Frank Herbert"}]`
 		input.Body.Decisions = map[string]string{"1": decisionAcceptMatch}
 
-		out, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.NoError(t, err)
 		require.Equal(t, actionMatchBook, out.Body.Rows[0].Action, "resolved action is reported, not possible_match")
 		require.Equal(t, existing.ID, *out.Body.Rows[0].MatchedBookID)
@@ -275,7 +275,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("possible_match row defaults to create_new when no decision is given", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 		existing := models.Book{Title: "Dune", Author: "Frank Herbert"}
 		require.NoError(t, books.Create(&existing))
 		require.NoError(t, copies.Create(&models.Copy{BookID: existing.ID, Status: "available"}))
@@ -284,7 +284,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 		input.Body.Format = "json"
 		input.Body.Content = `[{"title": "Dune", "author": "Frank Herbert"}]`
 
-		out, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.NoError(t, err)
 		require.Equal(t, actionCreateBook, out.Body.Rows[0].Action)
 		require.Nil(t, out.Body.Rows[0].MatchedBookID)
@@ -298,7 +298,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("possible_match row with an explicit create_new decision also creates a new book", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 		existing := models.Book{Title: "Dune", Author: "Frank Herbert"}
 		require.NoError(t, books.Create(&existing))
 		require.NoError(t, copies.Create(&models.Copy{BookID: existing.ID, Status: "available"}))
@@ -308,7 +308,7 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 		input.Body.Content = `[{"title": "Dune", "author": "Frank Herbert"}]`
 		input.Body.Decisions = map[string]string{"1": decisionCreateNew}
 
-		out, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.NoError(t, err)
 		require.Equal(t, actionCreateBook, out.Body.Rows[0].Action)
 
@@ -319,12 +319,12 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("one bad row does not block the rest of the batch", func(t *testing.T) {
-		h, copies, _, _ := newCopyHandler("")
+		h, copies, _, _ := newCopyHandler(nil)
 		input := &importInput{}
 		input.Body.Format = "json"
 		input.Body.Content = `[{"title": ""}, {"title": "Good Book"}]`
 
-		out, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.NoError(t, err)
 		require.Equal(t, actionSkipped, out.Body.Rows[0].Action)
 		require.Equal(t, actionCreateBook, out.Body.Rows[1].Action)
@@ -335,14 +335,14 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("stops adding copies once max_copies_per_user is reached", func(t *testing.T) {
-		h, copies, _, _ := newCopyHandler("")
+		h, copies, _, _ := newCopyHandler(nil)
 		require.NoError(t, h.admin.UpsertSetting("max_copies_per_user", "1"))
 
 		input := &importInput{}
 		input.Body.Format = "json"
 		input.Body.Content = `[{"title": "First"}, {"title": "Second"}]`
 
-		out, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		out, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.NoError(t, err)
 		require.Equal(t, actionCreateBook, out.Body.Rows[0].Action)
 		require.Equal(t, actionSkipped, out.Body.Rows[1].Action)
@@ -353,12 +353,12 @@ func TestCopyHandler_ImportBooks(t *testing.T) {
 	})
 
 	t.Run("malformed file is rejected wholesale, not partially imported", func(t *testing.T) {
-		h, copies, _, _ := newCopyHandler("")
+		h, copies, _, _ := newCopyHandler(nil)
 		input := &importInput{}
 		input.Body.Format = "json"
 		input.Body.Content = `[{"title": "Dune"`
 
-		_, err := h.importBooks(fakeAuthedCtx(t, 1, "user"), input)
+		_, err := runImport(t, h, fakeAuthedCtx(t, 1, "user"), input)
 		require.Error(t, err)
 		assertStatus(t, err, 400)
 
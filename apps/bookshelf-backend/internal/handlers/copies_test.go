@@ -10,9 +10,10 @@ import (
 
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
-func newCopyHandler(coversDir string) (*CopyHandler, *repotest.CopyRepository, *repotest.BookRepository, *repotest.WishlistRequestRepository) {
+func newCopyHandler(coverStore storage.Store) (*CopyHandler, *repotest.CopyRepository, *repotest.BookRepository, *repotest.WishlistRequestRepository) {
 	copies := repotest.NewCopyRepository()
 	users := repotest.NewUserRepository()
 	notifs := repotest.NewNotificationRepository()
@@ -21,12 +22,14 @@ func newCopyHandler(coversDir string) (*CopyHandler, *repotest.CopyRepository, *
 	books := repotest.NewBookRepository()
 	books.SetCopies(copies)
 	wishlists := repotest.NewWishlistRequestRepository()
-	return NewCopyHandler(copies, users, notifs, waitlists, admin, books, wishlists, coversDir, nil), copies, books, wishlists
+	importJobs := repotest.NewImportJobRepository()
+	importJobs.SetCopies(copies)
+	return NewCopyHandler(copies, users, notifs, waitlists, admin, books, wishlists, coverStore, nil, nil, importJobs), copies, books, wishlists
 }
 
 func TestDeleteCopy_OrphanedKeylessBookCleanup(t *testing.T) {
 	t.Run("deletes the book when its last copy was keyless", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 
 		book := models.Book{Title: "Handwritten Notes", Author: "A"}
 		require.NoError(t, books.Create(&book))
@@ -41,7 +44,7 @@ func TestDeleteCopy_OrphanedKeylessBookCleanup(t *testing.T) {
 	})
 
 	t.Run("keeps the book when it carries an OLKey", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 
 		book := models.Book{Title: "Cataloged Book", Author: "A", OLKey: "OL123"}
 		require.NoError(t, books.Create(&book))
@@ -57,7 +60,7 @@ func TestDeleteCopy_OrphanedKeylessBookCleanup(t *testing.T) {
 	})
 
 	t.Run("keeps a keyless book that still has another copy", func(t *testing.T) {
-		h, copies, books, _ := newCopyHandler("")
+		h, copies, books, _ := newCopyHandler(nil)
 
 		book := models.Book{Title: "Shared By Two", Author: "A"}
 		require.NoError(t, books.Create(&book))
@@ -75,7 +78,7 @@ func TestDeleteCopy_OrphanedKeylessBookCleanup(t *testing.T) {
 	})
 
 	t.Run("nulls a wishlist request's fulfilled_book_id rather than leaving a dangling reference", func(t *testing.T) {
-		h, copies, books, wishlists := newCopyHandler("")
+		h, copies, books, wishlists := newCopyHandler(nil)
 
 		book := models.Book{Title: "Manually Linked", Author: "A"}
 		require.NoError(t, books.Create(&book))
@@ -98,7 +101,9 @@ func TestDeleteCopy_OrphanedKeylessBookCleanup(t *testing.T) {
 
 	t.Run("removes the cached cover file for a deleted orphaned book", func(t *testing.T) {
 		coversDir := t.TempDir()
-		h, copies, books, _ := newCopyHandler(coversDir)
+		coverStore, err := storage.NewLocal(coversDir)
+		require.NoError(t, err)
+		h, copies, books, _ := newCopyHandler(coverStore)
 
 		coverPath := filepath.Join(coversDir, "abc123.jpg")
 		require.NoError(t, os.WriteFile(coverPath, []byte("fake image"), 0o600))
@@ -108,7 +113,7 @@ func TestDeleteCopy_OrphanedKeylessBookCleanup(t *testing.T) {
 		bookCopy := models.Copy{BookID: book.ID, OwnerID: 1, Status: "available"}
 		require.NoError(t, copies.Create(&bookCopy))
 
-		_, err := h.deleteCopy(fakeAuthedCtx(t, 1, "user"), &deleteCopyInput{ID: bookCopy.ID})
+		_, err = h.deleteCopy(fakeAuthedCtx(t, 1, "user"), &deleteCopyInput{ID: bookCopy.ID})
 		require.NoError(t, err)
 
 		_, statErr := os.Stat(coverPath)
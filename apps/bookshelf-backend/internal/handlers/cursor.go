@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// cursorToken is the JSON inside the opaque ?cursor= token. Scope names the
+// list and its sort order, so a token from one list is rejected by another
+// rather than silently skipping or repeating items.
+type cursorToken struct {
+	Scope string   `json:"s"`
+	Keys  []string `json:"k,omitempty"`
+	ID    uint     `json:"id"`
+}
+
+// encodeCursor turns a repository cursor into the token clients pass back as
+// ?cursor=. A nil cursor (the last page) encodes as "".
+func encodeCursor(scope string, c *repository.Cursor) string {
+	if c == nil {
+		return ""
+	}
+	data, _ := json.Marshal(cursorToken{Scope: scope, Keys: c.Keys, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a ?cursor= token issued for scope, returning a 400
+// error if it's malformed or belongs to another list.
+func decodeCursor(scope, token string) (*repository.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid cursor")
+	}
+	var t cursorToken
+	if err := json.Unmarshal(data, &t); err != nil || t.Scope != scope {
+		return nil, huma.Error400BadRequest("invalid cursor")
+	}
+	return &repository.Cursor{Keys: t.Keys, ID: t.ID}, nil
+}
+
+// listPage is one page of a list in either pagination form. Total, Page and
+// TotalPages are only set for page numbers; NextCursor for both.
+type listPage[T any] struct {
+	Items      []T
+	Total      int64
+	Page       int
+	PageSize   int
+	TotalPages int
+	NextCursor string
+}
+
+// fetchListPage fetches one page of a list that supports both pagination
+// forms: by cursor (via after) when one is passed, otherwise by page number
+// (via byPage). Failures are returned as huma errors, with errMsg for
+// anything other than a bad cursor.
+func fetchListPage[T any](
+	scope, cursor string, page, pageSize int, errMsg string,
+	byPage func(page, pageSize int) (*repository.PaginatedResult[T], error),
+	after func(after *repository.Cursor, limit int) (*repository.CursorResult[T], error),
+) (*listPage[T], error) {
+	if cursor == "" {
+		result, err := byPage(page, pageSize)
+		if err != nil {
+			return nil, huma.Error500InternalServerError(errMsg)
+		}
+		return &listPage[T]{
+			Items: result.Items, Total: result.Total, Page: result.Page, PageSize: result.PageSize,
+			TotalPages: result.TotalPages, NextCursor: encodeCursor(scope, result.Next),
+		}, nil
+	}
+
+	c, err := decodeCursor(scope, cursor)
+	if err != nil {
+		return nil, err
+	}
+	result, err := after(c, pageSize)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, huma.Error400BadRequest("invalid cursor")
+		}
+		return nil, huma.Error500InternalServerError(errMsg)
+	}
+	return &listPage[T]{Items: result.Items, PageSize: pageSize, NextCursor: encodeCursor(scope, result.Next)}, nil
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+const atomType = "application/atom+xml; charset=utf-8"
+
+// feedRecentLimit matches the OPDS "New arrivals" feed and the largest limit
+// /books/recent accepts.
+const feedRecentLimit = 50
+
+// feedCacheControl lets feed readers and intermediate caches reuse a copy
+// for a few minutes before revalidating with If-None-Match.
+const feedCacheControl = "public, max-age=300"
+
+// FeedHandler serves a plain Atom feed of newly added books, for following
+// new additions in a feed reader without an account. Like the OPDS catalog,
+// every link is absolute and built from the public frontend origin. The feed
+// can be switched off with the public_feed_enabled setting.
+type FeedHandler struct {
+	books          repository.BookRepository
+	admin          repository.AdminRepository
+	frontendOrigin string
+}
+
+// NewFeedHandler creates a new FeedHandler. frontendOrigin is the public
+// origin members browse the site at (Config.FrontendOrigin).
+func NewFeedHandler(books repository.BookRepository, admin repository.AdminRepository, frontendOrigin string) *FeedHandler {
+	return &FeedHandler{books: books, admin: admin, frontendOrigin: strings.TrimRight(frontendOrigin, "/")}
+}
+
+// --- Input / Output types ---
+
+type newBooksFeedInput struct {
+	IfNoneMatch     string `header:"If-None-Match" doc:"ETag of a previously fetched copy of the feed"`
+	IfModifiedSince string `header:"If-Modified-Since" doc:"Last-Modified of a previously fetched copy of the feed"`
+}
+
+// --- Route registration ---
+
+// RegisterRoutes registers all feed routes on the given huma API.
+func (h *FeedHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "feed-new-books",
+		Method:      "GET",
+		Path:        "/feeds/new-books.atom",
+		Tags:        []string{"feeds"},
+		Summary:     "Atom feed of recently added books (supports conditional GET)",
+	}, h.newBooks)
+}
+
+// --- Handlers ---
+
+func (h *FeedHandler) newBooks(_ context.Context, input *newBooksFeedInput) (*huma.StreamResponse, error) {
+	if val, _ := h.admin.GetSetting("public_feed_enabled"); val == "false" {
+		return nil, huma.Error404NotFound("the public feed is disabled")
+	}
+
+	books, err := h.books.ListRecent(feedRecentLimit)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch recent books")
+	}
+
+	// The feed's updated time is the newest first-added date rather than the
+	// current time, so rendering the same books twice produces identical
+	// bytes and the ETag below only changes when the feed's content does.
+	var modified time.Time
+	for _, b := range books {
+		if b.CreatedAt.After(modified) {
+			modified = b.CreatedAt
+		}
+	}
+	modified = modified.UTC().Truncate(time.Second)
+	updated := modified
+	if len(books) == 0 {
+		updated = time.Unix(0, 0).UTC()
+	}
+
+	feed := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		ID:      "urn:bookshelf:feed:new-books",
+		Title:   "Bookshelf — New arrivals",
+		Updated: updated.Format(time.RFC3339),
+		Author:  atomAuthor{Name: "Bookshelf"},
+		Links: []atomLink{
+			{Rel: "self", Href: h.frontendOrigin + "/api/feeds/new-books.atom", Type: "application/atom+xml"},
+			{Rel: "alternate", Href: h.frontendOrigin + "/catalog", Type: "text/html"},
+		},
+		Entries: h.entries(books),
+	}
+	var buf bytes.Buffer
+	if err := encodeXML(&buf, feed); err != nil {
+		return nil, huma.Error500InternalServerError("could not render feed")
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	notModified := feedNotModified(input.IfNoneMatch, input.IfModifiedSince, etag, modified)
+	return &huma.StreamResponse{
+		Body: func(sctx huma.Context) {
+			sctx.SetHeader("ETag", etag)
+			sctx.SetHeader("Cache-Control", feedCacheControl)
+			if len(books) > 0 {
+				sctx.SetHeader("Last-Modified", modified.Format(http.TimeFormat))
+			}
+			if notModified {
+				sctx.SetStatus(http.StatusNotModified)
+				return
+			}
+			sctx.SetHeader("Content-Type", atomType)
+			_, _ = sctx.BodyWriter().Write(buf.Bytes())
+		},
+	}, nil
+}
+
+// --- Helpers ---
+
+// entries renders books as Atom entries. Both updated and published are the
+// date the book was first added — a book's metadata has no modification
+// time of its own, and readers sort and de-duplicate on these.
+func (h *FeedHandler) entries(books []models.Book) []atomEntry {
+	entries := make([]atomEntry, len(books))
+	for i, b := range books {
+		added := b.CreatedAt.UTC().Format(time.RFC3339)
+		e := atomEntry{
+			ID:        "urn:bookshelf:book:" + strconv.FormatUint(uint64(b.ID), 10),
+			Title:     b.Title,
+			Updated:   added,
+			Published: added,
+			Links: []atomLink{
+				{Rel: "alternate", Href: h.frontendOrigin + "/catalog/" + strconv.FormatUint(uint64(b.ID), 10), Type: "text/html"},
+			},
+		}
+		if b.Author != "" {
+			e.Authors = []atomAuthor{{Name: b.Author}}
+		}
+		if b.Description != "" {
+			e.Summary = &atomText{Type: "text", Value: b.Description}
+		}
+		cover := publicCoverURL(h.frontendOrigin, b)
+		e.Links = append(e.Links, atomLink{Rel: "enclosure", Href: cover})
+		if content := entryHTML(b, cover); content != "" {
+			e.Content = &atomText{Type: "html", Value: content}
+		}
+		entries[i] = e
+	}
+	return entries
+}
+
+// entryHTML renders the cover and description as an HTML fragment, which is
+// what most feed readers actually display for an entry.
+func entryHTML(b models.Book, cover string) string {
+	var sb strings.Builder
+	if cover != "" {
+		sb.WriteString(`<p><img src="` + html.EscapeString(cover) + `" alt="` + html.EscapeString(b.Title) + `"></p>`)
+	}
+	if b.Author != "" {
+		sb.WriteString("<p>by " + html.EscapeString(b.Author) + "</p>")
+	}
+	if b.Description != "" {
+		sb.WriteString("<p>" + html.EscapeString(b.Description) + "</p>")
+	}
+	return sb.String()
+}
+
+// feedNotModified reports whether a conditional GET can be answered with 304
+// Not Modified. As RFC 9110 requires, If-Modified-Since is ignored whenever
+// If-None-Match is sent: the ETag also changes when a book drops out of the
+// feed, which the newest first-added date alone can't show.
+func feedNotModified(ifNoneMatch, ifModifiedSince, etag string, modified time.Time) bool {
+	if ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		return err == nil && !modified.After(since)
+	}
+	return false
+}
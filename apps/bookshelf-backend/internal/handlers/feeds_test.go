@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+var feedAddedAt = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+func newFeedHandler(t *testing.T) (*FeedHandler, *repotest.AdminRepository) {
+	t.Helper()
+	books := repotest.NewBookRepository()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	b := models.Book{
+		Title:       "Dune",
+		Author:      "Frank Herbert",
+		Description: "Spice & sand.",
+		CoverURL:    "/api/covers/dune.jpg",
+		CreatedAt:   feedAddedAt,
+	}
+	require.NoError(t, books.Create(&b))
+	require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, Status: "available"}))
+	admin := repotest.NewAdminRepository()
+	return NewFeedHandler(books, admin, "https://books.example.org/"), admin
+}
+
+func TestFeed_NewBooksRendersAtomEntries(t *testing.T) {
+	h, _ := newFeedHandler(t)
+
+	resp, err := h.newBooks(context.Background(), &newBooksFeedInput{})
+	require.NoError(t, err)
+	rec := renderStream(t, resp)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, atomType, rec.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	assert.Equal(t, feedAddedAt.Format(http.TimeFormat), rec.Header().Get("Last-Modified"))
+	assert.NotContains(t, rec.Body.String(), "xmlns:opds", "the plain feed carries no OPDS namespaces")
+
+	feed := decodeFeed(t, rec)
+	require.Len(t, feed.Entries, 1)
+	entry := feed.Entries[0]
+	assert.Equal(t, "Dune", entry.Title)
+	assert.Equal(t, "2026-03-14T09:30:00Z", entry.Published)
+	require.Len(t, entry.Authors, 1)
+	assert.Equal(t, "Frank Herbert", entry.Authors[0].Name)
+	require.NotNil(t, entry.Summary)
+	assert.Equal(t, "Spice & sand.", entry.Summary.Value)
+	assert.Equal(t, "https://books.example.org/api/covers/dune.jpg", linkHref(entry.Links, "enclosure"))
+	require.NotNil(t, entry.Content)
+	assert.Contains(t, entry.Content.Value, `<img src="https://books.example.org/api/covers/dune.jpg"`)
+	assert.Contains(t, entry.Content.Value, "Spice &amp; sand.")
+}
+
+func TestFeed_ConditionalGet(t *testing.T) {
+	h, _ := newFeedHandler(t)
+
+	resp, err := h.newBooks(context.Background(), &newBooksFeedInput{})
+	require.NoError(t, err)
+	etag := renderStream(t, resp).Header().Get("ETag")
+
+	cases := []struct {
+		name  string
+		input newBooksFeedInput
+		want  int
+	}{
+		{"matching etag", newBooksFeedInput{IfNoneMatch: etag}, http.StatusNotModified},
+		{"weak matching etag in a list", newBooksFeedInput{IfNoneMatch: `"stale", W/` + etag}, http.StatusNotModified},
+		{"stale etag", newBooksFeedInput{IfNoneMatch: `"stale"`}, http.StatusOK},
+		{"modified since", newBooksFeedInput{IfModifiedSince: feedAddedAt.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"not modified since", newBooksFeedInput{IfModifiedSince: feedAddedAt.Format(http.TimeFormat)}, http.StatusNotModified},
+		{
+			"etag wins over date",
+			newBooksFeedInput{IfNoneMatch: `"stale"`, IfModifiedSince: feedAddedAt.Format(http.TimeFormat)},
+			http.StatusOK,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := h.newBooks(context.Background(), &tc.input)
+			require.NoError(t, err)
+			rec := renderStream(t, resp)
+			assert.Equal(t, tc.want, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tc.want == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestFeed_DisabledReturns404(t *testing.T) {
+	h, admin := newFeedHandler(t)
+	require.NoError(t, admin.UpsertSetting("public_feed_enabled", "false"))
+
+	_, err := h.newBooks(context.Background(), &newBooksFeedInput{})
+	assertStatus(t, err, http.StatusNotFound)
+}
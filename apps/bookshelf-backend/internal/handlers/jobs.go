@@ -30,6 +30,12 @@ type runJobInput struct {
 	Job string `path:"job" doc:"Job name (e.g. cover-refresh, backup)"`
 }
 
+type dryRunJobOutput struct {
+	Body struct {
+		Result string `json:"result" doc:"What a run would do, in the form of JobStatus.last_result"`
+	}
+}
+
 // --- Route registration ---
 
 // RegisterRoutes registers the admin jobs endpoints on the given API.
@@ -54,6 +60,16 @@ func (h *JobsHandler) RegisterRoutes(api huma.API) {
 		Security:      security,
 		DefaultStatus: 202,
 	}, h.runJob)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-dry-run-job",
+		Method:      "POST",
+		Path:        "/admin/jobs/{job}/dry-run",
+		Tags:        []string{"admin"},
+		Summary:     "Report what a background job would do, without doing it",
+		Description: "Runs synchronously and leaves the job's status untouched. Only for jobs whose status has supports_dry_run.",
+		Security:    security,
+	}, h.dryRunJob)
 }
 
 // --- Handlers ---
@@ -75,6 +91,22 @@ func (h *JobsHandler) runJob(ctx context.Context, input *runJobInput) (*struct{}
 	return nil, nil
 }
 
+func (h *JobsHandler) dryRunJob(ctx context.Context, input *runJobInput) (*dryRunJobOutput, error) {
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, jobsAdminError(err)
+	}
+	result, err := h.scheduler.DryRun(ctx, input.Job)
+	switch {
+	case errors.Is(err, services.ErrUnknownJob):
+		return nil, huma.Error404NotFound("unknown job: " + input.Job)
+	case errors.Is(err, services.ErrNoDryRun):
+		return nil, huma.Error400BadRequest("job has no dry-run mode: " + input.Job)
+	}
+	out := &dryRunJobOutput{}
+	out.Body.Result = result
+	return out, nil
+}
+
 func jobsAdminError(err error) error {
 	if errors.Is(err, middleware.ErrUnauthorized) {
 		return huma.Error401Unauthorized("authentication required")
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/services"
+)
+
+func newJobsHandler() *JobsHandler {
+	sched := services.NewScheduler(repotest.NewBookRepository(), repotest.NewAdminRepository(), nil, "24h")
+	sched.RegisterJob("backup", "backup_interval", time.Hour, func(context.Context) string { return "ran" })
+	sched.RegisterJob("cover-gc", "cover_gc_interval", time.Hour, func(context.Context) string { return "ran" })
+	sched.RegisterDryRun("cover-gc", func(context.Context) string { return "dry run: would delete 1" })
+	return NewJobsHandler(sched)
+}
+
+func TestDryRunJob(t *testing.T) {
+	h := newJobsHandler()
+	ctx := fakeAuthedCtx(t, 1, "admin")
+
+	out, err := h.dryRunJob(ctx, &runJobInput{Job: "cover-gc"})
+	require.NoError(t, err)
+	assert.Equal(t, "dry run: would delete 1", out.Body.Result)
+
+	_, err = h.dryRunJob(ctx, &runJobInput{Job: "backup"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = h.dryRunJob(ctx, &runJobInput{Job: "no-such-job"})
+	assertStatus(t, err, http.StatusNotFound)
+
+	_, err = h.dryRunJob(fakeAuthedCtx(t, 2, "user"), &runJobInput{Job: "cover-gc"})
+	assertStatus(t, err, http.StatusForbidden)
+}
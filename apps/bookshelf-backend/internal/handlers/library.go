@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// A library export moves a whole community between deployments: members,
+// the catalog, who owns which copy, loan history and the wishlist, in a
+// JSON document independent of this instance's IDs and schema. Records
+// refer to members by email and to books and copies by IDs local to the
+// file, so importing into an instance that already has some of them (or
+// importing the same file twice) matches and updates rather than
+// duplicates; see repository.LibraryRepository.Restore.
+//
+// The file includes each member's bcrypt password hash, so accounts work
+// as before on the new instance. That makes it as sensitive as a backup.
+
+const (
+	libraryExportFormat  = "bookshelf-library"
+	libraryExportVersion = 1
+	// maxLibraryImportBytes is well above any church library's export; the
+	// default body limit is sized for ordinary requests.
+	maxLibraryImportBytes = 64 << 20
+)
+
+// LibraryHandler exposes the admin whole-library exports: the migration
+// export and import here, and the MARC catalog export in library_marc.go.
+type LibraryHandler struct {
+	library repository.LibraryRepository
+	books   repository.BookRepository
+}
+
+// NewLibraryHandler creates a new LibraryHandler.
+func NewLibraryHandler(library repository.LibraryRepository, books repository.BookRepository) *LibraryHandler {
+	return &LibraryHandler{library: library, books: books}
+}
+
+// --- Document types ---
+
+type libraryDocument struct {
+	Format     string                `json:"format" enum:"bookshelf-library"`
+	Version    int                   `json:"version" minimum:"1"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Users      []libraryUser         `json:"users"`
+	Books      []libraryBook         `json:"books"`
+	Copies     []libraryCopy         `json:"copies"`
+	Loans      []libraryLoan         `json:"loans"`
+	Wishlist   []libraryWishlistItem `json:"wishlist"`
+}
+
+type libraryUser struct {
+	Email                     string    `json:"email"`
+	Name                      string    `json:"name"`
+	Phone                     string    `json:"phone"`
+	PasswordHash              string    `json:"password_hash" doc:"bcrypt hash of the member's password"`
+	Role                      string    `json:"role"`
+	Verified                  bool      `json:"verified"`
+	PhoneVerified             bool      `json:"phone_verified"`
+	Suspended                 bool      `json:"suspended"`
+	PendingApproval           bool      `json:"pending_approval"`
+	EmailNotificationsEnabled bool      `json:"email_notifications_enabled"`
+	TelegramUsername          string    `json:"telegram_username"`
+	WhatsAppUsername          string    `json:"whatsapp_username"`
+	CreatedAt                 time.Time `json:"created_at"`
+}
+
+type libraryBook struct {
+	ID                  uint      `json:"id" doc:"Identifies the book within this file only"`
+	Title               string    `json:"title"`
+	Author              string    `json:"author"`
+	ISBN                string    `json:"isbn"`
+	OLKey               string    `json:"ol_key"`
+	GoogleBooksID       string    `json:"google_books_id"`
+	CoverURL            string    `json:"cover_url"`
+	Description         string    `json:"description"`
+	DescriptionEnriched bool      `json:"description_enriched"`
+	Publisher           string    `json:"publisher"`
+	PublishedDate       string    `json:"published_date"`
+	PageCount           int       `json:"page_count"`
+	Language            string    `json:"language"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type libraryCopy struct {
+	ID                 uint   `json:"id" doc:"Identifies the copy within this file only"`
+	BookID             uint   `json:"book_id"`
+	OwnerEmail         string `json:"owner_email"`
+	Condition          string `json:"condition"`
+	Notes              string `json:"notes"`
+	Status             string `json:"status"`
+	AutoApprove        bool   `json:"auto_approve"`
+	ReturnDateRequired bool   `json:"return_date_required"`
+	HideOwner          bool   `json:"hide_owner"`
+}
+
+type libraryLoan struct {
+	CopyID             uint       `json:"copy_id"`
+	BorrowerEmail      string     `json:"borrower_email"`
+	Message            string     `json:"message"`
+	Status             string     `json:"status"`
+	RequestedAt        time.Time  `json:"requested_at"`
+	RespondedAt        *time.Time `json:"responded_at,omitempty"`
+	LoanedAt           *time.Time `json:"loaned_at,omitempty"`
+	ReturnedAt         *time.Time `json:"returned_at,omitempty"`
+	ReturnedByEmail    string     `json:"returned_by_email,omitempty"`
+	ExpectedReturnDate *time.Time `json:"expected_return_date,omitempty"`
+}
+
+type libraryWishlistItem struct {
+	RequesterEmail  string     `json:"requester_email"`
+	Title           string     `json:"title"`
+	Author          string     `json:"author"`
+	ISBN            string     `json:"isbn"`
+	OLKey           string     `json:"ol_key"`
+	GoogleBooksID   string     `json:"google_books_id"`
+	CoverURL        string     `json:"cover_url"`
+	Notes           string     `json:"notes"`
+	Status          string     `json:"status"`
+	IsAnonymous     bool       `json:"is_anonymous"`
+	FulfilledBookID *uint      `json:"fulfilled_book_id,omitempty"`
+	FulfilledAt     *time.Time `json:"fulfilled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	// CoRequesterEmails are the members who joined the request, in the
+	// order they joined.
+	CoRequesterEmails []string `json:"co_requester_emails,omitempty"`
+}
+
+// --- Input / Output types ---
+
+type importLibraryInput struct {
+	Body libraryDocument
+}
+
+type importLibraryOutput struct {
+	Body repository.LibraryRestoreStats
+}
+
+// --- Route registration ---
+
+// RegisterRoutes registers the admin library export/import and MARC export
+// endpoints on the given API.
+func (h *LibraryHandler) RegisterRoutes(api huma.API) {
+	security := []map[string][]string{{"bearer": {}}}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-export-library",
+		Method:      "GET",
+		Path:        "/admin/library/export",
+		Tags:        []string{"admin"},
+		Summary:     "Download the whole library — members, books, copies, loans and wishlist — for moving to another instance",
+		Security:    security,
+	}, h.exportLibrary)
+
+	huma.Register(api, huma.Operation{
+		OperationID:  "admin-import-library",
+		Method:       "POST",
+		Path:         "/admin/library/import",
+		Tags:         []string{"admin"},
+		Summary:      "Import a library export, adding what this instance doesn't have and updating what it does",
+		Security:     security,
+		MaxBodyBytes: maxLibraryImportBytes,
+	}, h.importLibrary)
+
+	h.registerMARCRoutes(api)
+}
+
+// --- Handlers ---
+
+func (h *LibraryHandler) exportLibrary(ctx context.Context, _ *struct{}) (*huma.StreamResponse, error) {
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, adminError(err)
+	}
+	snapshot, err := h.library.Snapshot()
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not read library")
+	}
+	doc := toLibraryDocument(snapshot, time.Now().UTC())
+	filename := fmt.Sprintf("bookshelf-library-%s.json", doc.ExportedAt.Format("2006-01-02"))
+
+	return &huma.StreamResponse{
+		Body: func(sctx huma.Context) {
+			sctx.SetHeader("Content-Type", "application/json")
+			sctx.SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+			enc := json.NewEncoder(sctx.BodyWriter())
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(doc)
+		},
+	}, nil
+}
+
+func (h *LibraryHandler) importLibrary(ctx context.Context, input *importLibraryInput) (*importLibraryOutput, error) {
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, adminError(err)
+	}
+	if input.Body.Version > libraryExportVersion {
+		return nil, huma.Error400BadRequest(fmt.Sprintf(
+			"library export version %d is newer than this server supports (%d)", input.Body.Version, libraryExportVersion))
+	}
+	snapshot, skipped, err := fromLibraryDocument(&input.Body)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	stats, err := h.library.Restore(snapshot)
+	if err != nil {
+		log.Error().Err(err).Msg("library import failed")
+		return nil, huma.Error500InternalServerError("could not import library")
+	}
+	addSkipped(&stats, skipped)
+	log.Info().Interface("stats", stats).Msg("library imported")
+	return &importLibraryOutput{Body: stats}, nil
+}
+
+// --- Conversion ---
+
+// toLibraryDocument replaces s's user references with emails. Book and copy
+// IDs are kept as they are: they're only references within the file.
+//
+// Deleting a member or a copy doesn't cascade, so a live library can have
+// copies owned by members who are gone and loans of copies that are. Those
+// rows are left out rather than written with references no importer could
+// resolve; a loan's returner or a wishlist request's fulfilling book that
+// no longer exists is just dropped from the row.
+func toLibraryDocument(s *repository.LibrarySnapshot, exportedAt time.Time) *libraryDocument {
+	doc := &libraryDocument{
+		Format:     libraryExportFormat,
+		Version:    libraryExportVersion,
+		ExportedAt: exportedAt,
+		Users:      make([]libraryUser, len(s.Users)),
+		Books:      make([]libraryBook, len(s.Books)),
+		Copies:     make([]libraryCopy, 0, len(s.Copies)),
+		Loans:      make([]libraryLoan, 0, len(s.LoanRequests)),
+		Wishlist:   make([]libraryWishlistItem, 0, len(s.WishlistRequests)),
+	}
+	emails := make(map[uint]string, len(s.Users))
+	for i, u := range s.Users {
+		emails[u.ID] = u.Email
+		doc.Users[i] = libraryUser{
+			Email:                     u.Email,
+			Name:                      u.Name,
+			Phone:                     u.Phone,
+			PasswordHash:              u.Password,
+			Role:                      u.Role,
+			Verified:                  u.Verified,
+			PhoneVerified:             u.PhoneVerified,
+			Suspended:                 u.Suspended,
+			PendingApproval:           u.PendingApproval,
+			EmailNotificationsEnabled: u.EmailNotificationsEnabled,
+			TelegramUsername:          u.TelegramUsername,
+			WhatsAppUsername:          u.WhatsAppUsername,
+			CreatedAt:                 u.CreatedAt,
+		}
+	}
+	books := make(map[uint]bool, len(s.Books))
+	for i, b := range s.Books {
+		books[b.ID] = true
+		doc.Books[i] = libraryBook{
+			ID:                  b.ID,
+			Title:               b.Title,
+			Author:              b.Author,
+			ISBN:                b.ISBN,
+			OLKey:               b.OLKey,
+			GoogleBooksID:       b.GoogleBooksID,
+			CoverURL:            b.CoverURL,
+			Description:         b.Description,
+			DescriptionEnriched: b.DescriptionEnriched,
+			Publisher:           b.Publisher,
+			PublishedDate:       b.PublishedDate,
+			PageCount:           b.PageCount,
+			Language:            b.Language,
+			CreatedAt:           b.CreatedAt,
+		}
+	}
+	copies := make(map[uint]bool, len(s.Copies))
+	for _, c := range s.Copies {
+		owner, ok := emails[c.OwnerID]
+		if !ok || !books[c.BookID] {
+			continue
+		}
+		copies[c.ID] = true
+		doc.Copies = append(doc.Copies, libraryCopy{
+			ID:                 c.ID,
+			BookID:             c.BookID,
+			OwnerEmail:         owner,
+			Condition:          c.Condition,
+			Notes:              c.Notes,
+			Status:             c.Status,
+			AutoApprove:        c.AutoApprove,
+			ReturnDateRequired: c.ReturnDateRequired,
+			HideOwner:          c.HideOwner,
+		})
+	}
+	for _, l := range s.LoanRequests {
+		borrower, ok := emails[l.BorrowerID]
+		if !ok || !copies[l.CopyID] {
+			continue
+		}
+		loan := libraryLoan{
+			CopyID:             l.CopyID,
+			BorrowerEmail:      borrower,
+			Message:            l.Message,
+			Status:             l.Status,
+			RequestedAt:        l.RequestedAt,
+			RespondedAt:        l.RespondedAt,
+			LoanedAt:           l.LoanedAt,
+			ReturnedAt:         l.ReturnedAt,
+			ExpectedReturnDate: l.ExpectedReturnDate,
+		}
+		if l.ReturnedBy != nil {
+			loan.ReturnedByEmail = emails[*l.ReturnedBy]
+		}
+		doc.Loans = append(doc.Loans, loan)
+	}
+	for _, w := range s.WishlistRequests {
+		requester, ok := emails[w.RequesterID]
+		if !ok {
+			continue
+		}
+		item := libraryWishlistItem{
+			RequesterEmail: requester,
+			Title:          w.Title,
+			Author:         w.Author,
+			ISBN:           w.ISBN,
+			OLKey:          w.OLKey,
+			GoogleBooksID:  w.GoogleBooksID,
+			CoverURL:       w.CoverURL,
+			Notes:          w.Notes,
+			Status:         w.Status,
+			IsAnonymous:    w.IsAnonymous,
+			FulfilledAt:    w.FulfilledAt,
+			CreatedAt:      w.CreatedAt,
+		}
+		if w.FulfilledBookID != nil && books[*w.FulfilledBookID] {
+			item.FulfilledBookID = w.FulfilledBookID
+		}
+		for _, co := range w.CoRequesters {
+			if email, ok := emails[co.UserID]; ok {
+				item.CoRequesterEmails = append(item.CoRequesterEmails, email)
+			}
+		}
+		doc.Wishlist = append(doc.Wishlist, item)
+	}
+	return doc
+}
+
+// fromLibraryDocument turns doc into a snapshot for Restore, numbering its
+// users in file order. A malformed document — a member without an email
+// or with an unknown role, or a repeated email, book ID or copy ID — is
+// rejected. A row whose
+// references don't resolve within the file (a copy of a book or owner not
+// in it, a loan of a copy not in it, say) is left out and counted in the
+// returned skipped stats, as is anything that depended on it; a loan's
+// unknown returner or a wishlist request's unknown fulfilling book is just
+// dropped from the row.
+//
+// Covers cached by the exporting instance are served from its own storage,
+// which doesn't come along, so those books are imported without a cover
+// (and get a placeholder). External cover URLs are kept.
+func fromLibraryDocument(doc *libraryDocument) (*repository.LibrarySnapshot, repository.LibraryRestoreStats, error) {
+	var skipped repository.LibraryRestoreStats
+	s := &repository.LibrarySnapshot{
+		Users:            make([]models.User, len(doc.Users)),
+		Books:            make([]models.Book, len(doc.Books)),
+		Copies:           make([]models.Copy, 0, len(doc.Copies)),
+		LoanRequests:     make([]models.LoanRequest, 0, len(doc.Loans)),
+		WishlistRequests: make([]models.WishlistRequest, 0, len(doc.Wishlist)),
+	}
+
+	userIDs := make(map[string]uint, len(doc.Users))
+	userID := func(email string) (uint, bool) {
+		id, ok := userIDs[strings.ToLower(email)]
+		return id, ok
+	}
+	for i, u := range doc.Users {
+		key := strings.ToLower(u.Email)
+		if key == "" {
+			return nil, skipped, fmt.Errorf("users[%d]: email is required", i)
+		}
+		if _, dup := userIDs[key]; dup {
+			return nil, skipped, fmt.Errorf("users[%d]: duplicate email %s", i, u.Email)
+		}
+		role := u.Role
+		if role == "" {
+			role = "user" // the column's default
+		}
+		if role != "admin" && role != "user" {
+			return nil, skipped, fmt.Errorf("users[%d]: role must be 'admin' or 'user'", i)
+		}
+		userIDs[key] = uint(i + 1)
+		s.Users[i] = models.User{
+			ID:                        uint(i + 1),
+			Email:                     u.Email,
+			Name:                      u.Name,
+			Phone:                     u.Phone,
+			Password:                  u.PasswordHash,
+			Role:                      role,
+			Verified:                  u.Verified,
+			PhoneVerified:             u.PhoneVerified,
+			Suspended:                 u.Suspended,
+			PendingApproval:           u.PendingApproval,
+			EmailNotificationsEnabled: u.EmailNotificationsEnabled,
+			TelegramUsername:          u.TelegramUsername,
+			WhatsAppUsername:          u.WhatsAppUsername,
+			CreatedAt:                 u.CreatedAt,
+		}
+	}
+
+	bookIDs := make(map[uint]bool, len(doc.Books))
+	for i, b := range doc.Books {
+		if b.ID == 0 || bookIDs[b.ID] {
+			return nil, skipped, fmt.Errorf("books[%d]: id must be unique and non-zero", i)
+		}
+		bookIDs[b.ID] = true
+		coverURL := b.CoverURL
+		if strings.HasPrefix(coverURL, "/api/covers/") {
+			coverURL = ""
+		}
+		s.Books[i] = models.Book{
+			ID:                  b.ID,
+			Title:               b.Title,
+			Author:              b.Author,
+			ISBN:                b.ISBN,
+			OLKey:               b.OLKey,
+			GoogleBooksID:       b.GoogleBooksID,
+			CoverURL:            coverURL,
+			Description:         b.Description,
+			DescriptionEnriched: b.DescriptionEnriched,
+			Publisher:           b.Publisher,
+			PublishedDate:       b.PublishedDate,
+			PageCount:           b.PageCount,
+			Language:            b.Language,
+			CreatedAt:           b.CreatedAt,
+		}
+	}
+
+	// seenCopies catches repeated IDs; copyIDs holds only the copies kept.
+	seenCopies := make(map[uint]bool, len(doc.Copies))
+	copyIDs := make(map[uint]bool, len(doc.Copies))
+	for i, c := range doc.Copies {
+		if c.ID == 0 || seenCopies[c.ID] {
+			return nil, skipped, fmt.Errorf("copies[%d]: id must be unique and non-zero", i)
+		}
+		seenCopies[c.ID] = true
+		ownerID, ok := userID(c.OwnerEmail)
+		if !ok || !bookIDs[c.BookID] {
+			skipped.Copies.Skipped++
+			continue
+		}
+		copyIDs[c.ID] = true
+		s.Copies = append(s.Copies, models.Copy{
+			ID:                 c.ID,
+			BookID:             c.BookID,
+			OwnerID:            ownerID,
+			Condition:          c.Condition,
+			Notes:              c.Notes,
+			Status:             c.Status,
+			AutoApprove:        c.AutoApprove,
+			ReturnDateRequired: c.ReturnDateRequired,
+			HideOwner:          c.HideOwner,
+		})
+	}
+
+	for i, l := range doc.Loans {
+		borrowerID, ok := userID(l.BorrowerEmail)
+		if !ok || !copyIDs[l.CopyID] {
+			skipped.LoanRequests.Skipped++
+			continue
+		}
+		var returnedBy *uint
+		if id, ok := userID(l.ReturnedByEmail); ok {
+			returnedBy = &id
+		}
+		s.LoanRequests = append(s.LoanRequests, models.LoanRequest{
+			ID:                 uint(i + 1),
+			CopyID:             l.CopyID,
+			BorrowerID:         borrowerID,
+			Message:            l.Message,
+			Status:             l.Status,
+			RequestedAt:        l.RequestedAt,
+			RespondedAt:        l.RespondedAt,
+			LoanedAt:           l.LoanedAt,
+			ReturnedAt:         l.ReturnedAt,
+			ReturnedBy:         returnedBy,
+			ExpectedReturnDate: l.ExpectedReturnDate,
+		})
+	}
+
+	for i, w := range doc.Wishlist {
+		requesterID, ok := userID(w.RequesterEmail)
+		if !ok {
+			skipped.WishlistRequests.Skipped++
+			skipped.WishlistCoRequesters.Skipped += len(w.CoRequesterEmails)
+			continue
+		}
+		fulfilledBookID := w.FulfilledBookID
+		if fulfilledBookID != nil && !bookIDs[*fulfilledBookID] {
+			fulfilledBookID = nil
+		}
+		request := models.WishlistRequest{
+			ID:              uint(i + 1),
+			RequesterID:     requesterID,
+			Title:           w.Title,
+			Author:          w.Author,
+			ISBN:            w.ISBN,
+			OLKey:           w.OLKey,
+			GoogleBooksID:   w.GoogleBooksID,
+			CoverURL:        w.CoverURL,
+			Notes:           w.Notes,
+			Status:          w.Status,
+			IsAnonymous:     w.IsAnonymous,
+			FulfilledBookID: fulfilledBookID,
+			FulfilledAt:     w.FulfilledAt,
+			CreatedAt:       w.CreatedAt,
+		}
+		for _, email := range w.CoRequesterEmails {
+			coID, ok := userID(email)
+			if !ok {
+				skipped.WishlistCoRequesters.Skipped++
+				continue
+			}
+			request.CoRequesters = append(request.CoRequesters,
+				models.WishlistCoRequester{WishlistRequestID: request.ID, UserID: coID})
+		}
+		s.WishlistRequests = append(s.WishlistRequests, request)
+	}
+	return s, skipped, nil
+}
+
+// addSkipped adds the rows fromLibraryDocument left out to Restore's stats.
+// Members and books are never skipped.
+func addSkipped(stats *repository.LibraryRestoreStats, skipped repository.LibraryRestoreStats) {
+	stats.Copies.Skipped += skipped.Copies.Skipped
+	stats.LoanRequests.Skipped += skipped.LoanRequests.Skipped
+	stats.WishlistRequests.Skipped += skipped.WishlistRequests.Skipped
+	stats.WishlistCoRequesters.Skipped += skipped.WishlistCoRequesters.Skipped
+}
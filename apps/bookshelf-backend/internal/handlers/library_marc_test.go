@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func newMARCHandler(t *testing.T) *LibraryHandler {
+	t.Helper()
+	books := repotest.NewBookRepository()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	dune := models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593"}
+	require.NoError(t, books.Create(&dune))
+	require.NoError(t, copies.Create(&models.Copy{BookID: dune.ID, OwnerID: 1, Condition: "good", Status: "available"}))
+	require.NoError(t, books.Create(&models.Book{Title: "No copies", Author: "Nobody"}))
+	return NewLibraryHandler(repotest.NewLibraryRepository(), books)
+}
+
+func TestLibraryHandler_ExportMARC(t *testing.T) {
+	h := newMARCHandler(t)
+
+	t.Run("defaults to MARCXML, with each copy as a holding", func(t *testing.T) {
+		resp, err := h.exportMARC(fakeAuthedCtx(t, 1, "admin"), &exportMARCInput{Location: "Grace Church"})
+		require.NoError(t, err)
+		rec := renderStream(t, resp)
+
+		assert.Equal(t, "application/marcxml+xml", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Content-Disposition"), ".xml")
+		body := rec.Body.String()
+		assert.Contains(t, body, `<collection xmlns="http://www.loc.gov/MARC21/slim">`)
+		assert.Contains(t, body, `<subfield code="a">Herbert, Frank</subfield>`)
+		assert.Contains(t, body, `<subfield code="a">Grace Church</subfield>`)
+		assert.NotContains(t, body, "No copies", "a book nobody has a copy of isn't held")
+	})
+
+	t.Run("marc21 is binary ISO 2709", func(t *testing.T) {
+		resp, err := h.exportMARC(fakeAuthedCtx(t, 1, "admin"), &exportMARCInput{Format: "marc21"})
+		require.NoError(t, err)
+		rec := renderStream(t, resp)
+
+		assert.Equal(t, "application/marc", rec.Header().Get("Content-Type"))
+		b := rec.Body.Bytes()
+		assert.Equal(t, 1, bytes.Count(b, []byte{0x1D}), "one record")
+		assert.Contains(t, string(b), "\x1faBookshelf", "the default location")
+	})
+
+	t.Run("invalid format is rejected", func(t *testing.T) {
+		_, err := h.exportMARC(fakeAuthedCtx(t, 1, "admin"), &exportMARCInput{Format: "csv"})
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("non-admin is forbidden", func(t *testing.T) {
+		_, err := h.exportMARC(fakeAuthedCtx(t, 2, "user"), &exportMARCInput{})
+		assertStatus(t, err, 403)
+	})
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func TestLibraryDocument_RoundTrip(t *testing.T) {
+	requested := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	owner, borrower, book := uint(40), uint(41), uint(70)
+	s := &repository.LibrarySnapshot{
+		Users: []models.User{
+			{ID: owner, Email: "owner@example.com", Name: "Owner", Password: "hash", Role: "admin"},
+			{ID: borrower, Email: "borrower@example.com", Name: "Borrower", Password: "hash", Role: "user"},
+		},
+		Books: []models.Book{
+			{ID: book, Title: "Dune", Author: "Frank Herbert", CoverURL: "/api/covers/abc.jpg", CoverBlurhash: "LKO2?U%2Tw=w"},
+			{ID: 71, Title: "Zine", Author: "Anon", CoverURL: "https://covers.example.com/zine.jpg"},
+		},
+		Copies: []models.Copy{{ID: 90, BookID: book, OwnerID: owner, Status: "loaned"}},
+		LoanRequests: []models.LoanRequest{
+			{ID: 5, CopyID: 90, BorrowerID: borrower, Status: "returned", RequestedAt: requested, ReturnedBy: &owner},
+		},
+		WishlistRequests: []models.WishlistRequest{
+			{
+				ID: 8, RequesterID: borrower, Title: "Dune", Author: "Frank Herbert", FulfilledBookID: &book, CreatedAt: requested,
+				CoRequesters: []models.WishlistCoRequester{{WishlistRequestID: 8, UserID: owner}},
+			},
+		},
+	}
+
+	doc := toLibraryDocument(s, requested)
+	assert.Equal(t, libraryExportFormat, doc.Format)
+	assert.Equal(t, "owner@example.com", doc.Copies[0].OwnerEmail)
+	assert.Equal(t, "borrower@example.com", doc.Loans[0].BorrowerEmail)
+	assert.Equal(t, "owner@example.com", doc.Loans[0].ReturnedByEmail)
+	assert.Equal(t, "hash", doc.Users[0].PasswordHash)
+	assert.Equal(t, []string{"owner@example.com"}, doc.Wishlist[0].CoRequesterEmails)
+
+	back, skipped, err := fromLibraryDocument(doc)
+	require.NoError(t, err)
+	assert.Zero(t, skipped)
+	require.Len(t, back.Users, 2)
+	assert.Equal(t, uint(1), back.Copies[0].OwnerID, "users are numbered in file order")
+	assert.Equal(t, uint(2), back.LoanRequests[0].BorrowerID)
+	require.NotNil(t, back.LoanRequests[0].ReturnedBy)
+	assert.Equal(t, uint(1), *back.LoanRequests[0].ReturnedBy)
+	assert.Equal(t, book, back.Copies[0].BookID, "book IDs are file-local and kept")
+	assert.Empty(t, back.Books[0].CoverURL, "a locally cached cover doesn't come along")
+	assert.Empty(t, back.Books[0].CoverBlurhash)
+	assert.Equal(t, "https://covers.example.com/zine.jpg", back.Books[1].CoverURL)
+	assert.Equal(t, uint(2), back.WishlistRequests[0].RequesterID)
+	require.Len(t, back.WishlistRequests[0].CoRequesters, 1)
+	assert.Equal(t, uint(1), back.WishlistRequests[0].CoRequesters[0].UserID)
+}
+
+func TestLibraryDocument_RoundTripsRowsLeftByDeletions(t *testing.T) {
+	requested := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	owner, gone, book, goneBook := uint(40), uint(41), uint(70), uint(71)
+	// Deleting a member, copy or book doesn't cascade: member 41, copy 91
+	// and book 71 are gone, but rows still point at them.
+	s := &repository.LibrarySnapshot{
+		Users: []models.User{{ID: owner, Email: "owner@example.com", Name: "Owner", Password: "hash"}},
+		Books: []models.Book{{ID: book, Title: "Dune", Author: "Frank Herbert"}},
+		Copies: []models.Copy{
+			{ID: 90, BookID: book, OwnerID: owner, Status: "available"},
+			{ID: 92, BookID: book, OwnerID: gone, Status: "available"},
+		},
+		LoanRequests: []models.LoanRequest{
+			{ID: 5, CopyID: 91, BorrowerID: owner, Status: "returned", RequestedAt: requested},
+			{ID: 6, CopyID: 90, BorrowerID: owner, Status: "returned", RequestedAt: requested, ReturnedBy: &gone},
+			{ID: 7, CopyID: 90, BorrowerID: gone, Status: "returned", RequestedAt: requested},
+		},
+		WishlistRequests: []models.WishlistRequest{
+			{ID: 8, RequesterID: gone, Title: "Emma", CreatedAt: requested},
+			{
+				ID: 9, RequesterID: owner, Title: "Dune", Status: "fulfilled", FulfilledBookID: &goneBook, CreatedAt: requested,
+				CoRequesters: []models.WishlistCoRequester{{WishlistRequestID: 9, UserID: gone}},
+			},
+		},
+	}
+
+	doc := toLibraryDocument(s, requested)
+	require.Len(t, doc.Copies, 1, "a copy whose owner is gone is left out")
+	require.Len(t, doc.Loans, 1, "so are loans of deleted copies and by deleted members")
+	assert.Equal(t, uint(90), doc.Loans[0].CopyID)
+	assert.Empty(t, doc.Loans[0].ReturnedByEmail)
+	require.Len(t, doc.Wishlist, 1)
+	assert.Nil(t, doc.Wishlist[0].FulfilledBookID)
+	assert.Empty(t, doc.Wishlist[0].CoRequesterEmails)
+
+	back, skipped, err := fromLibraryDocument(doc)
+	require.NoError(t, err, "an instance's own export always imports")
+	assert.Zero(t, skipped)
+	require.Len(t, back.LoanRequests, 1)
+	assert.Nil(t, back.LoanRequests[0].ReturnedBy)
+}
+
+func TestFromLibraryDocument_SkipsDanglingReferences(t *testing.T) {
+	missingBook := uint(9)
+	doc := &libraryDocument{
+		Format:  libraryExportFormat,
+		Version: libraryExportVersion,
+		Users:   []libraryUser{{Email: "owner@example.com"}},
+		Books:   []libraryBook{{ID: 1, Title: "Dune"}},
+		Copies: []libraryCopy{
+			{ID: 1, BookID: 1, OwnerEmail: "OWNER@example.com"},
+			{ID: 2, BookID: 2, OwnerEmail: "owner@example.com"},
+			{ID: 3, BookID: 1, OwnerEmail: ""},
+		},
+		Loans: []libraryLoan{
+			{CopyID: 1, BorrowerEmail: "owner@example.com", ReturnedByEmail: "x@y.z"},
+			{CopyID: 2, BorrowerEmail: "owner@example.com"},
+			{CopyID: 1, BorrowerEmail: ""},
+		},
+		Wishlist: []libraryWishlistItem{
+			{RequesterEmail: "x@y.z", CoRequesterEmails: []string{"owner@example.com"}},
+			{RequesterEmail: "owner@example.com", FulfilledBookID: &missingBook, CoRequesterEmails: []string{"x@y.z"}},
+		},
+	}
+
+	s, skipped, err := fromLibraryDocument(doc)
+	require.NoError(t, err)
+	assert.Equal(t, repository.LibraryRestoreStats{
+		Copies:               repository.RestoreCount{Skipped: 2},
+		LoanRequests:         repository.RestoreCount{Skipped: 2},
+		WishlistRequests:     repository.RestoreCount{Skipped: 1},
+		WishlistCoRequesters: repository.RestoreCount{Skipped: 2},
+	}, skipped)
+	require.Len(t, s.Copies, 1, "emails match regardless of case")
+	require.Len(t, s.LoanRequests, 1)
+	assert.Nil(t, s.LoanRequests[0].ReturnedBy)
+	require.Len(t, s.WishlistRequests, 1)
+	assert.Nil(t, s.WishlistRequests[0].FulfilledBookID)
+	assert.Empty(t, s.WishlistRequests[0].CoRequesters)
+}
+
+func TestFromLibraryDocument_RejectsMalformedDocuments(t *testing.T) {
+	base := func() *libraryDocument {
+		return &libraryDocument{
+			Format:  libraryExportFormat,
+			Version: libraryExportVersion,
+			Users:   []libraryUser{{Email: "owner@example.com"}},
+			Books:   []libraryBook{{ID: 1, Title: "Dune"}},
+			Copies:  []libraryCopy{{ID: 1, BookID: 1, OwnerEmail: "owner@example.com"}},
+		}
+	}
+	cases := map[string]func(d *libraryDocument){
+		"missing email":     func(d *libraryDocument) { d.Users = append(d.Users, libraryUser{}) },
+		"duplicate email":   func(d *libraryDocument) { d.Users = append(d.Users, libraryUser{Email: "Owner@Example.com"}) },
+		"unknown role":      func(d *libraryDocument) { d.Users[0].Role = "superuser" },
+		"duplicate book id": func(d *libraryDocument) { d.Books = append(d.Books, libraryBook{ID: 1}) },
+		"duplicate copy id": func(d *libraryDocument) { d.Copies = append(d.Copies, libraryCopy{ID: 1}) },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := base()
+			mutate(d)
+			_, _, err := fromLibraryDocument(d)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLibraryHandler_ImportLibrary(t *testing.T) {
+	library := repotest.NewLibraryRepository()
+	library.Stats = repository.LibraryRestoreStats{Users: repository.RestoreCount{Created: 1}}
+	h := NewLibraryHandler(library, repotest.NewBookRepository())
+	input := &importLibraryInput{Body: libraryDocument{
+		Format: libraryExportFormat, Version: libraryExportVersion,
+		Users: []libraryUser{{Email: "owner@example.com"}},
+	}}
+
+	t.Run("admin imports and gets the restore stats", func(t *testing.T) {
+		out, err := h.importLibrary(fakeAuthedCtx(t, 1, "admin"), input)
+		require.NoError(t, err)
+		assert.Equal(t, 1, out.Body.Users.Created)
+		require.Len(t, library.Restored, 1)
+		assert.Equal(t, "owner@example.com", library.Restored[0].Users[0].Email)
+	})
+
+	t.Run("a newer version is rejected", func(t *testing.T) {
+		newer := *input
+		newer.Body.Version = libraryExportVersion + 1
+		_, err := h.importLibrary(fakeAuthedCtx(t, 1, "admin"), &newer)
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("a dangling reference is skipped and counted", func(t *testing.T) {
+		dangling := *input
+		dangling.Body.Copies = []libraryCopy{{ID: 1, BookID: 1, OwnerEmail: "owner@example.com"}}
+		library.Restored = nil
+		out, err := h.importLibrary(fakeAuthedCtx(t, 1, "admin"), &dangling)
+		require.NoError(t, err)
+		assert.Equal(t, 1, out.Body.Copies.Skipped)
+		require.Len(t, library.Restored, 1)
+		assert.Empty(t, library.Restored[0].Copies)
+	})
+
+	t.Run("a malformed document is rejected before anything is restored", func(t *testing.T) {
+		bad := *input
+		bad.Body.Users = append([]libraryUser{}, input.Body.Users[0], input.Body.Users[0])
+		library.Restored = nil
+		_, err := h.importLibrary(fakeAuthedCtx(t, 1, "admin"), &bad)
+		assertStatus(t, err, 400)
+		assert.Empty(t, library.Restored)
+	})
+
+	t.Run("non-admin is forbidden", func(t *testing.T) {
+		_, err := h.importLibrary(fakeAuthedCtx(t, 2, "user"), input)
+		assertStatus(t, err, 403)
+	})
+}
+
+func TestLibraryHandler_ExportLibrary_RequiresAdmin(t *testing.T) {
+	h := NewLibraryHandler(repotest.NewLibraryRepository(), repotest.NewBookRepository())
+	_, err := h.exportLibrary(fakeAuthedCtx(t, 2, "user"), nil)
+	assertStatus(t, err, 403)
+	_, err = h.exportLibrary(fakeAuthedCtxNone(), nil)
+	assertStatus(t, err, 401)
+
+	out, err := h.exportLibrary(fakeAuthedCtx(t, 1, "admin"), nil)
+	require.NoError(t, err)
+	assert.NotNil(t, out.Body)
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-
+	"sort"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 
 	"github.com/rs/zerolog"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
@@ -23,6 +24,7 @@ type LoanRequestHandler struct {
 	loanReqs repository.LoanRequestRepository
 	admin    repository.AdminRepository
 	users    repository.UserRepository
+	books    repository.BookRepository
 	workflow *services.LoanWorkflow
 }
 
@@ -32,16 +34,19 @@ func NewLoanRequestHandler(
 	loanReqs repository.LoanRequestRepository,
 	admin repository.AdminRepository,
 	users repository.UserRepository,
+	books repository.BookRepository,
 	workflow *services.LoanWorkflow,
 ) *LoanRequestHandler {
-	return &LoanRequestHandler{copies: copies, loanReqs: loanReqs, admin: admin, users: users, workflow: workflow}
+	return &LoanRequestHandler{copies: copies, loanReqs: loanReqs, admin: admin, users: users, books: books, workflow: workflow}
 }
 
 // --- Input / Output types ---
 
 type createLoanRequestInput struct {
 	Body struct {
-		CopyID             uint    `json:"copy_id" required:"true" minimum:"1" doc:"ID of the copy to borrow"`
+		CopyID             uint    `json:"copy_id,omitempty" doc:"ID of the copy to borrow; required unless any_edition is set"`
+		BookID             uint    `json:"book_id,omitempty" doc:"With any_edition: the book (edition) the borrower is looking at"`
+		AnyEdition         bool    `json:"any_edition,omitempty" doc:"Borrow an available copy of any edition of book_id's work instead of a specific copy, preferring book_id itself"`
 		Message            string  `json:"message,omitempty" maxLength:"500" doc:"Optional message to the owner"`
 		ExpectedReturnDate *string `json:"expected_return_date,omitempty" doc:"Expected return date (YYYY-MM-DD), required when copy has return_date_required"`
 	}
@@ -100,6 +105,7 @@ type listMineInput struct {
 	Page     int    `query:"page" minimum:"1" doc:"Page number (default 1)"`
 	PageSize int    `query:"page_size" minimum:"1" maximum:"100" doc:"Items per page (default 20)"`
 	View     string `query:"view" doc:"Filter: current (pending+accepted) or history (returned+rejected+cancelled); omit for all"`
+	Cursor   string `query:"cursor" doc:"Continue after a previous response's next_cursor instead of using page; total, page and total_pages are then 0"`
 }
 
 type listMineOutput struct {
@@ -109,6 +115,7 @@ type listMineOutput struct {
 		Page       int                  `json:"page"`
 		PageSize   int                  `json:"page_size"`
 		TotalPages int                  `json:"total_pages"`
+		NextCursor string               `json:"next_cursor,omitempty" doc:"Pass as cursor to fetch the next page; absent on the last page"`
 	}
 }
 
@@ -273,9 +280,16 @@ func (h *LoanRequestHandler) listMine(ctx context.Context, input *listMineInput)
 		pageSize = 20
 	}
 
-	result, err := h.loanReqs.ListByBorrowerIDPaginated(callerID, statusesForView(input.View), page, pageSize)
+	statuses := statusesForView(input.View)
+	result, err := fetchListPage("loan-requests:"+input.View, input.Cursor, page, pageSize, "could not fetch loan requests",
+		func(page, pageSize int) (*repository.PaginatedResult[models.LoanRequest], error) {
+			return h.loanReqs.ListByBorrowerIDPaginated(callerID, statuses, page, pageSize)
+		},
+		func(after *repository.Cursor, limit int) (*repository.CursorResult[models.LoanRequest], error) {
+			return h.loanReqs.ListByBorrowerIDAfter(callerID, statuses, after, limit)
+		})
 	if err != nil {
-		return nil, huma.Error500InternalServerError("could not fetch loan requests")
+		return nil, err
 	}
 
 	bodies := make([]getLoanRequestBody, len(result.Items))
@@ -289,6 +303,7 @@ func (h *LoanRequestHandler) listMine(ctx context.Context, input *listMineInput)
 	out.Body.Page = result.Page
 	out.Body.PageSize = result.PageSize
 	out.Body.TotalPages = result.TotalPages
+	out.Body.NextCursor = result.NextCursor
 	return &out, nil
 }
 
@@ -379,7 +394,7 @@ func (h *LoanRequestHandler) createLoanRequest(ctx context.Context, input *creat
 		return nil, huma.Error401Unauthorized("authentication required")
 	}
 
-	bookCopy, err := h.getRequestableCopy(borrowerID, input.Body.CopyID)
+	bookCopy, err := h.resolveRequestedCopy(borrowerID, input)
 	if err != nil {
 		return nil, err
 	}
@@ -393,7 +408,7 @@ func (h *LoanRequestHandler) createLoanRequest(ctx context.Context, input *creat
 		return nil, huma.Error400BadRequest("return date is required by the sharer")
 	}
 
-	lr, err := buildLoanRequest(borrowerID, input)
+	lr, err := buildLoanRequest(borrowerID, bookCopy.ID, input)
 	if err != nil {
 		return nil, err
 	}
@@ -419,6 +434,68 @@ func (h *LoanRequestHandler) createLoanRequest(ctx context.Context, input *creat
 	return &createLoanRequestOutput{Body: lr}, nil
 }
 
+// resolveRequestedCopy returns the copy a create request is for: the named
+// copy_id, or — for an any_edition request — one picked by anyEditionCopy.
+func (h *LoanRequestHandler) resolveRequestedCopy(borrowerID uint, input *createLoanRequestInput) (*models.Copy, error) {
+	switch {
+	case input.Body.AnyEdition && input.Body.CopyID != 0:
+		return nil, huma.Error400BadRequest("send either copy_id or book_id with any_edition, not both")
+	case input.Body.AnyEdition:
+		if input.Body.BookID == 0 {
+			return nil, huma.Error400BadRequest("book_id is required with any_edition")
+		}
+		return h.anyEditionCopy(borrowerID, input.Body.BookID, input.Body.ExpectedReturnDate != nil)
+	case input.Body.CopyID == 0:
+		return nil, huma.Error400BadRequest("copy_id is required")
+	default:
+		return h.getRequestableCopy(borrowerID, input.Body.CopyID)
+	}
+}
+
+// anyEditionCopy picks an available copy of bookID or of another edition of
+// the same work (matched by bookmatch.WorkKey, as the work-grouped catalog
+// does). Copies of bookID itself come first, then the other editions in ID
+// order. The borrower's own copies are skipped, as are copies that require a
+// return date when the borrower didn't give one.
+func (h *LoanRequestHandler) anyEditionCopy(borrowerID, bookID uint, hasReturnDate bool) (*models.Copy, error) {
+	book, err := h.books.GetByIDWithCopies(bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("book not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch book")
+	}
+
+	bookIDs := []uint{book.ID}
+	if key := bookmatch.WorkKey(book.Title, book.Author); key != "" {
+		available, err := h.books.List("", "", true)
+		if err != nil {
+			return nil, huma.Error500InternalServerError("could not list editions")
+		}
+		for _, b := range available {
+			if b.ID != book.ID && bookmatch.WorkKey(b.Title, b.Author) == key {
+				bookIDs = append(bookIDs, b.ID)
+			}
+		}
+	}
+
+	copies, err := h.copies.ListAvailableByBookIDs(bookIDs)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch copies")
+	}
+	sort.SliceStable(copies, func(i, j int) bool {
+		return copies[i].BookID == book.ID && copies[j].BookID != book.ID
+	})
+	for i := range copies {
+		c := &copies[i]
+		if c.OwnerID == borrowerID || (c.ReturnDateRequired && !hasReturnDate) {
+			continue
+		}
+		return c, nil
+	}
+	return nil, huma.Error400BadRequest("no edition of this book has a copy available")
+}
+
 // getRequestableCopy fetches a copy and verifies it can be requested by borrowerID.
 func (h *LoanRequestHandler) getRequestableCopy(borrowerID, copyID uint) (*models.Copy, error) {
 	bookCopy, err := h.copies.GetByID(copyID)
@@ -532,11 +609,11 @@ func (h *LoanRequestHandler) checkMinBooksSharedRequirement(borrowerID uint, sm
 	)
 }
 
-// buildLoanRequest constructs a pending LoanRequest from the request body,
+// buildLoanRequest constructs a pending LoanRequest for copyID from the request body,
 // parsing the optional expected return date.
-func buildLoanRequest(borrowerID uint, input *createLoanRequestInput) (models.LoanRequest, error) {
+func buildLoanRequest(borrowerID, copyID uint, input *createLoanRequestInput) (models.LoanRequest, error) {
 	lr := models.LoanRequest{
-		CopyID:      input.Body.CopyID,
+		CopyID:      copyID,
 		BorrowerID:  borrowerID,
 		Message:     input.Body.Message,
 		Status:      "pending",
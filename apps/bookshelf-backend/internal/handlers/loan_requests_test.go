@@ -17,6 +17,7 @@ type loanTestDeps struct {
 	loanReqs *repotest.LoanRequestRepository
 	admin    *repotest.AdminRepository
 	users    *repotest.UserRepository
+	books    *repotest.BookRepository
 	notifs   *repotest.NotificationRepository
 }
 
@@ -27,9 +28,11 @@ func newLoanRequestHandler() *loanTestDeps {
 	loanReqs := repotest.NewLoanRequestRepository(copies, notifs, users)
 	admin := repotest.NewAdminRepository()
 	waitlists := repotest.NewWaitlistRepository()
+	books := repotest.NewBookRepository()
+	books.SetCopies(copies)
 	workflow := services.NewLoanWorkflow(copies, loanReqs, notifs, users, waitlists, noopEmail())
-	handler := NewLoanRequestHandler(copies, loanReqs, admin, users, workflow)
-	return &loanTestDeps{handler: handler, copies: copies, loanReqs: loanReqs, admin: admin, users: users, notifs: notifs}
+	handler := NewLoanRequestHandler(copies, loanReqs, admin, users, books, workflow)
+	return &loanTestDeps{handler: handler, copies: copies, loanReqs: loanReqs, admin: admin, users: users, books: books, notifs: notifs}
 }
 
 // seedOwnerAndBorrower creates an owner + an available copy they own, and a
@@ -185,6 +188,98 @@ func TestCreateLoanRequest(t *testing.T) {
 	})
 }
 
+// seedEditions creates two editions of one work, each with one available
+// copy owned by owner, returning the books and their copies in that order.
+func seedEditions(t *testing.T, d *loanTestDeps, owner *models.User) (hardback, paperback models.Book, hardbackCopy, paperbackCopy *models.Copy) {
+	t.Helper()
+	hardback = models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593"}
+	require.NoError(t, d.books.Create(&hardback))
+	paperback = models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780593099322"}
+	require.NoError(t, d.books.Create(&paperback))
+	hardbackCopy = &models.Copy{BookID: hardback.ID, OwnerID: owner.ID, Status: "available"}
+	require.NoError(t, d.copies.Create(hardbackCopy))
+	paperbackCopy = &models.Copy{BookID: paperback.ID, OwnerID: owner.ID, Status: "available"}
+	require.NoError(t, d.copies.Create(paperbackCopy))
+	return hardback, paperback, hardbackCopy, paperbackCopy
+}
+
+func TestCreateLoanRequest_AnyEdition(t *testing.T) {
+	t.Run("prefers a copy of the requested edition", func(t *testing.T) {
+		d := newLoanRequestHandler()
+		owner, borrower, _ := seedOwnerAndBorrower(t, d)
+		_, paperback, _, paperbackCopy := seedEditions(t, d, owner)
+
+		input := &createLoanRequestInput{}
+		input.Body.BookID = paperback.ID
+		input.Body.AnyEdition = true
+
+		out, err := d.handler.createLoanRequest(fakeAuthedCtx(t, borrower.ID, "user"), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, paperbackCopy.ID, out.Body.CopyID)
+	})
+
+	t.Run("falls back to another edition of the same work", func(t *testing.T) {
+		d := newLoanRequestHandler()
+		owner, borrower, _ := seedOwnerAndBorrower(t, d)
+		hardback, _, hardbackCopy, paperbackCopy := seedEditions(t, d, owner)
+		require.NoError(t, d.copies.UpdateStatus(hardbackCopy.ID, "borrowed"))
+
+		input := &createLoanRequestInput{}
+		input.Body.BookID = hardback.ID
+		input.Body.AnyEdition = true
+
+		out, err := d.handler.createLoanRequest(fakeAuthedCtx(t, borrower.ID, "user"), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, paperbackCopy.ID, out.Body.CopyID)
+		updated, findErr := d.copies.GetByID(paperbackCopy.ID)
+		require.NoError(t, findErr)
+		assert.Equal(t, "requested", updated.Status)
+	})
+
+	t.Run("skips the borrower's own copies", func(t *testing.T) {
+		d := newLoanRequestHandler()
+		owner, _, _ := seedOwnerAndBorrower(t, d)
+		hardback, _, _, _ := seedEditions(t, d, owner)
+
+		input := &createLoanRequestInput{}
+		input.Body.BookID = hardback.ID
+		input.Body.AnyEdition = true
+
+		_, err := d.handler.createLoanRequest(fakeAuthedCtx(t, owner.ID, "user"), input)
+
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("unknown book is not found", func(t *testing.T) {
+		d := newLoanRequestHandler()
+		_, borrower, _ := seedOwnerAndBorrower(t, d)
+
+		input := &createLoanRequestInput{}
+		input.Body.BookID = 999
+		input.Body.AnyEdition = true
+
+		_, err := d.handler.createLoanRequest(fakeAuthedCtx(t, borrower.ID, "user"), input)
+
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("copy_id and any_edition together are rejected", func(t *testing.T) {
+		d := newLoanRequestHandler()
+		_, borrower, bookCopy := seedOwnerAndBorrower(t, d)
+
+		input := &createLoanRequestInput{}
+		input.Body.CopyID = bookCopy.ID
+		input.Body.BookID = bookCopy.BookID
+		input.Body.AnyEdition = true
+
+		_, err := d.handler.createLoanRequest(fakeAuthedCtx(t, borrower.ID, "user"), input)
+
+		assertStatus(t, err, 400)
+	})
+}
+
 func TestUpdateLoanRequest_AcceptRejectsCompetingRequests(t *testing.T) {
 	d := newLoanRequestHandler()
 	owner, borrower1, bookCopy := seedOwnerAndBorrower(t, d)
@@ -8,12 +8,13 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/rs/zerolog"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 )
@@ -21,47 +22,28 @@ import (
 // metadataClient is a shared HTTP client with a timeout for all metadata fetches.
 var metadataClient = &http.Client{Timeout: 10 * time.Second}
 
-// BookMetadataResult is a normalised search result from any metadata source.
-type BookMetadataResult struct {
-	Source        string `json:"source"`
-	Title         string `json:"title"`
-	Author        string `json:"author"`
-	ISBN          string `json:"isbn"`
-	CoverURL      string `json:"cover_url"`
-	Description   string `json:"description"`
-	Publisher     string `json:"publisher"`
-	PublishedDate string `json:"published_date"`
-	PageCount     int    `json:"page_count"`
-	Language      string `json:"language"`
-	OLKey         string `json:"ol_key"`
-	GoogleBooksID string `json:"google_books_id"`
-	BookBrainzID  string `json:"bookbrainz_id,omitempty"`
-	// EnrichedFields lists fields on this result that were backfilled from a
-	// sibling edition of the same work, rather than from this result's own source.
-	EnrichedFields []string `json:"enriched_fields,omitempty"`
-	// WorkKey is the normalizeTitleAuthor bucket key for this result, used by the
-	// frontend to cluster distinct editions of the same work for display. Empty
-	// when Title or Author is empty (never bucketed).
-	WorkKey string `json:"work_key,omitempty"`
-}
-
-const searchCacheTTL = 1 * time.Hour
+// BookMetadataResult is a normalised search result from any metadata source,
+// defined alongside the providers that produce it.
+type BookMetadataResult = metadata.BookMetadataResult
 
 // MetadataHandler handles book metadata search routes.
 type MetadataHandler struct {
 	googleBooksAPIKey string
 	encryptionSecret  string
 	users             repository.UserRepository
+	providers         *metadata.Registry
 	cache             MetadataCache
 }
 
-// NewMetadataHandler creates a MetadataHandler.
-func NewMetadataHandler(ctx context.Context, googleBooksAPIKey, encryptionSecret string, users repository.UserRepository) *MetadataHandler {
+// NewMetadataHandler creates a MetadataHandler that searches providers'
+// enabled sources, caching consolidated results in cache.
+func NewMetadataHandler(cache MetadataCache, googleBooksAPIKey, encryptionSecret string, users repository.UserRepository, providers *metadata.Registry) *MetadataHandler {
 	return &MetadataHandler{
 		googleBooksAPIKey: googleBooksAPIKey,
 		encryptionSecret:  encryptionSecret,
 		users:             users,
-		cache:             NewInMemoryMetadataCache(ctx, searchCacheTTL),
+		providers:         providers,
+		cache:             cache,
 	}
 }
 
@@ -72,7 +54,7 @@ func (h *MetadataHandler) RegisterRoutes(api huma.API) {
 		Method:      "GET",
 		Path:        "/books/metadata/search",
 		Tags:        []string{"books"},
-		Summary:     "Fan-out metadata search across Open Library and Google Books",
+		Summary:     "Fan-out metadata search across the enabled metadata providers",
 		Security:    []map[string][]string{{"bearer": {}}},
 	}, h.searchMetadata)
 
@@ -84,6 +66,15 @@ func (h *MetadataHandler) RegisterRoutes(api huma.API) {
 		Summary:     "Fetch work description from Open Library (lazy)",
 		Security:    []map[string][]string{{"bearer": {}}},
 	}, h.getOLDescription)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "scan-book-barcodes",
+		Method:      "POST",
+		Path:        "/books/metadata/scan",
+		Tags:        []string{"books"},
+		Summary:     "Decode ISBN barcodes in a photo and look each one up",
+		Security:    []map[string][]string{{"bearer": {}}},
+	}, h.scanBarcodes)
 }
 
 type searchMetadataInput struct {
@@ -109,30 +100,47 @@ func (h *MetadataHandler) searchMetadata(ctx context.Context, input *searchMetad
 	if q == "" {
 		return &searchMetadataOutput{Body: []BookMetadataResult{}}, nil
 	}
+	return &searchMetadataOutput{Body: h.search(ctx, q, h.resolveGoogleBooksAPIKey(ctx))}, nil
+}
 
-	apiKey := h.resolveGoogleBooksAPIKey(ctx)
-
+// search runs the cached, consolidated metadata search for q — shared by
+// searchMetadata and scanBarcodes.
+func (h *MetadataHandler) search(ctx context.Context, q, apiKey string) []BookMetadataResult {
 	// Cache key incorporates whether Google Books is active so that users with
-	// and without a Google Books key do not share cache entries.
-	cacheKey := strings.ToLower(q)
+	// and without a Google Books key do not share cache entries, and the
+	// enabled providers' order, so a change to the provider settings takes
+	// effect at once rather than when the old entries expire.
+	cacheKey := strings.ToLower(q) + "|" + h.providers.Fingerprint()
 	if apiKey != "" {
 		cacheKey += "|gbooks"
 	}
 	if cached, ok := h.cache.Get(cacheKey); ok {
 		zerolog.Ctx(ctx).Debug().Str("query", q).Msg("metadata search cache hit")
-		return &searchMetadataOutput{Body: cached}, nil
+		return cached
 	}
 
-	queriedISBN := normalizeISBN(q)
-	results := fetchAllSources(ctx, q, apiKey)
+	queriedISBN := bookmatch.NormalizeISBN(q)
+	rank := h.providers.Rank()
+	apiKeys := metadata.APIKeys(apiKey)
+	results, report := h.providers.Search(ctx, metadata.Query{Text: q, ISBN: queriedISBN}, apiKeys)
 	if queriedISBN != "" {
-		results = append(results, expandSiblingEditions(ctx, results, apiKey)...)
+		siblings, siblingReport := h.expandSiblingEditions(ctx, results, apiKeys, rank)
+		results = append(results, siblings...)
+		report = report.Merge(siblingReport)
 	}
 
-	consolidated := consolidateResults(results)
+	consolidated := consolidateResults(results, rank)
 	consolidated = promoteQueriedEdition(consolidated, queriedISBN)
+	// Results missing a provider that failed or was skipped aren't cached:
+	// the cache outlives an outage by far, and the next search should get
+	// the full answer once the provider is back.
+	if !report.Complete() {
+		zerolog.Ctx(ctx).Debug().Str("query", q).Strs("unanswered", report.Unanswered).
+			Msg("metadata search incomplete, not cached")
+		return consolidated
+	}
 	h.cache.Set(cacheKey, consolidated)
-	return &searchMetadataOutput{Body: consolidated}, nil
+	return consolidated
 }
 
 // resolveGoogleBooksAPIKey prefers the authenticated user's stored key,
@@ -157,42 +165,7 @@ func (h *MetadataHandler) resolveGoogleBooksAPIKey(ctx context.Context) string {
 	return decrypted
 }
 
-// fetchAllSources queries Open Library, Google Books (if apiKey is set), and
-// BookBrainz concurrently, merging all results. A per-source failure is
-// logged and otherwise ignored.
-func fetchAllSources(ctx context.Context, q, apiKey string) []BookMetadataResult {
-	var mu sync.Mutex
-	var results []BookMetadataResult
-	var wg sync.WaitGroup
-
-	fetch := func(name string, fn func() ([]BookMetadataResult, error)) {
-		defer wg.Done()
-		items, err := fn()
-		if err != nil {
-			zerolog.Ctx(ctx).Warn().Err(err).Msg(name + " search failed")
-			return
-		}
-		mu.Lock()
-		results = append(results, items...)
-		mu.Unlock()
-	}
-
-	wg.Add(1)
-	go fetch("open library", func() ([]BookMetadataResult, error) { return fetchOpenLibrary(ctx, q) })
-
-	if apiKey != "" {
-		wg.Add(1)
-		go fetch("google books", func() ([]BookMetadataResult, error) { return fetchGoogleBooks(ctx, q, apiKey) })
-	}
-
-	wg.Add(1)
-	go fetch("bookbrainz", func() ([]BookMetadataResult, error) { return fetchBookBrainz(ctx, q) })
-
-	wg.Wait()
-	return results
-}
-
-// expandSiblingEditions re-queries all sources by title+author when the
+// expandSiblingEditions re-queries all providers by title+author when the
 // original query was an ISBN. An ISBN-only query only ever surfaces the
 // exact edition indexed under that ISBN — sibling editions (different
 // printing, hardcover vs. paperback, different territory) carry different
@@ -200,12 +173,12 @@ func fetchAllSources(ctx context.Context, q, apiKey string) []BookMetadataResult
 // dedup/enrich/bucket pipeline (see docs/metadata-search.md) never gets a
 // chance to see them as the same work. Returns nil if the ISBN hit(s) didn't
 // carry a usable Title/Author to search by.
-func expandSiblingEditions(ctx context.Context, isbnResults []BookMetadataResult, apiKey string) []BookMetadataResult {
-	title, author := bestTitleAuthorForExpansion(isbnResults)
+func (h *MetadataHandler) expandSiblingEditions(ctx context.Context, isbnResults []BookMetadataResult, apiKeys map[string]string, rank func(string) int) ([]BookMetadataResult, metadata.SearchReport) {
+	title, author := bestTitleAuthorForExpansion(isbnResults, rank)
 	if title == "" || author == "" {
-		return nil
+		return nil, metadata.SearchReport{}
 	}
-	return fetchAllSources(ctx, title+" "+author, apiKey)
+	return h.providers.Search(ctx, metadata.Query{Text: title + " " + author}, apiKeys)
 }
 
 func (h *MetadataHandler) getOLDescription(_ context.Context, input *olDescriptionInput) (*olDescriptionOutput, error) {
@@ -250,63 +223,6 @@ func (h *MetadataHandler) getOLDescription(_ context.Context, input *olDescripti
 	return &out, nil
 }
 
-// fetchOpenLibrary calls the OL search API and returns normalised results.
-func fetchOpenLibrary(ctx context.Context, q string) ([]BookMetadataResult, error) {
-	zerolog.Ctx(ctx).Debug().Str("query", q).Msg("searching Open Library")
-	apiURL := fmt.Sprintf(
-		"https://openlibrary.org/search.json?q=%s&fields=key,title,author_name,isbn,cover_i&limit=10",
-		url.QueryEscape(q),
-	)
-	resp, err := metadataClient.Get(apiURL) //nolint:noctx,gosec
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close() //nolint:errcheck
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("open library returned %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var payload struct {
-		Docs []struct {
-			Key        string   `json:"key"`
-			Title      string   `json:"title"`
-			AuthorName []string `json:"author_name"`
-			ISBN       []string `json:"isbn"`
-			CoverI     int64    `json:"cover_i"`
-		} `json:"docs"`
-	}
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, err
-	}
-
-	zerolog.Ctx(ctx).Debug().Str("query", q).Int("results", len(payload.Docs)).Msg("Open Library search complete")
-	results := make([]BookMetadataResult, 0, len(payload.Docs))
-	for _, doc := range payload.Docs {
-		r := BookMetadataResult{
-			Source: "openlibrary",
-			Title:  doc.Title,
-			OLKey:  doc.Key,
-		}
-		if len(doc.AuthorName) > 0 {
-			r.Author = doc.AuthorName[0]
-		}
-		if len(doc.ISBN) > 0 {
-			r.ISBN = doc.ISBN[0]
-		}
-		if doc.CoverI > 0 {
-			r.CoverURL = fmt.Sprintf("https://covers.openlibrary.org/b/id/%d-M.jpg", doc.CoverI)
-		}
-		results = append(results, r)
-	}
-	return results, nil
-}
-
 // validateGoogleBooksAPIKey makes a minimal test call to verify the key is accepted by Google Books.
 func validateGoogleBooksAPIKey(key string) error {
 	apiURL := fmt.Sprintf(
@@ -323,180 +239,3 @@ func validateGoogleBooksAPIKey(key string) error {
 	}
 	return nil
 }
-
-type googleBooksIndustryIdentifier struct {
-	Type       string `json:"type"`
-	Identifier string `json:"identifier"`
-}
-
-type googleBooksImageLinks struct {
-	Thumbnail string `json:"thumbnail"`
-}
-
-type googleBooksVolumeInfo struct {
-	Title               string                          `json:"title"`
-	Authors             []string                        `json:"authors"`
-	Publisher           string                          `json:"publisher"`
-	PublishedDate       string                          `json:"publishedDate"`
-	Description         string                          `json:"description"`
-	PageCount           int                             `json:"pageCount"`
-	Language            string                          `json:"language"`
-	IndustryIdentifiers []googleBooksIndustryIdentifier `json:"industryIdentifiers"`
-	ImageLinks          googleBooksImageLinks           `json:"imageLinks"`
-}
-
-type googleBooksItem struct {
-	ID         string                `json:"id"`
-	VolumeInfo googleBooksVolumeInfo `json:"volumeInfo"`
-}
-
-type googleBooksSearchResponse struct {
-	Items []googleBooksItem `json:"items"`
-}
-
-// googleVolumeToResult converts a single Google Books API item into a normalised result.
-func googleVolumeToResult(item googleBooksItem) BookMetadataResult {
-	vi := item.VolumeInfo
-	r := BookMetadataResult{
-		Source:        "google_books",
-		GoogleBooksID: item.ID,
-		Title:         vi.Title,
-		Publisher:     vi.Publisher,
-		PublishedDate: vi.PublishedDate,
-		Description:   vi.Description,
-		PageCount:     vi.PageCount,
-		Language:      vi.Language,
-		ISBN:          preferredISBN(vi.IndustryIdentifiers),
-	}
-	if len(vi.Authors) > 0 {
-		r.Author = vi.Authors[0]
-	}
-	if thumb := vi.ImageLinks.Thumbnail; thumb != "" {
-		r.CoverURL = strings.Replace(thumb, "http://", "https://", 1)
-	}
-	return r
-}
-
-// preferredISBN returns the ISBN-13 identifier if present, else ISBN-10, else "".
-func preferredISBN(ids []googleBooksIndustryIdentifier) string {
-	for _, id := range ids {
-		if id.Type == "ISBN_13" {
-			return id.Identifier
-		}
-	}
-	for _, id := range ids {
-		if id.Type == "ISBN_10" {
-			return id.Identifier
-		}
-	}
-	return ""
-}
-
-// googleBooksQueryFor returns the query string to send to Google Books for
-// q. Google Books' free-text search does not reliably match a bare ISBN
-// string — a valid, indexed ISBN can return zero results without the
-// "isbn:" search operator — so ISBN-shaped queries are rewritten to use it.
-// Non-ISBN queries (title/author, including expandSiblingEditions' re-fetch)
-// pass through unchanged.
-func googleBooksQueryFor(q string) string {
-	if isbn := normalizeISBN(q); isbn != "" {
-		return "isbn:" + isbn
-	}
-	return q
-}
-
-// fetchGoogleBooks calls the Google Books API and returns normalised results.
-func fetchGoogleBooks(ctx context.Context, q, apiKey string) ([]BookMetadataResult, error) {
-	query := googleBooksQueryFor(q)
-	zerolog.Ctx(ctx).Debug().Str("query", query).Msg("searching Google Books")
-	apiURL := fmt.Sprintf(
-		"https://www.googleapis.com/books/v1/volumes?q=%s&key=%s&maxResults=10",
-		url.QueryEscape(query),
-		url.QueryEscape(apiKey),
-	)
-	resp, err := metadataClient.Get(apiURL) //nolint:noctx,gosec
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close() //nolint:errcheck
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("google books returned %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var payload googleBooksSearchResponse
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, err
-	}
-
-	zerolog.Ctx(ctx).Debug().Str("query", q).Int("results", len(payload.Items)).Msg("Google Books search complete")
-	results := make([]BookMetadataResult, 0, len(payload.Items))
-	for _, item := range payload.Items {
-		results = append(results, googleVolumeToResult(item))
-	}
-	return results, nil
-}
-
-// fetchBookBrainz calls the BookBrainz search API and returns normalised results.
-// BookBrainz does not provide cover images.
-func fetchBookBrainz(ctx context.Context, q string) ([]BookMetadataResult, error) {
-	zerolog.Ctx(ctx).Debug().Str("query", q).Msg("searching BookBrainz")
-	apiURL := fmt.Sprintf(
-		"https://api.bookbrainz.org/1/search?q=%s&type=edition&size=10",
-		url.QueryEscape(q),
-	)
-	resp, err := metadataClient.Get(apiURL) //nolint:noctx,gosec
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close() //nolint:errcheck
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bookbrainz returned %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var payload struct {
-		Results []struct {
-			BBID         string `json:"bbid"`
-			DefaultAlias struct {
-				Name string `json:"name"`
-			} `json:"default-alias"`
-			AuthorCredit struct {
-				Names []struct {
-					Name string `json:"name"`
-				} `json:"names"`
-			} `json:"author-credit"`
-		} `json:"search-results"`
-	}
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, err
-	}
-
-	zerolog.Ctx(ctx).Debug().Str("query", q).Int("results", len(payload.Results)).Msg("BookBrainz search complete")
-	results := make([]BookMetadataResult, 0, len(payload.Results))
-	for _, item := range payload.Results {
-		if item.DefaultAlias.Name == "" {
-			continue
-		}
-		r := BookMetadataResult{
-			Source:       "bookbrainz",
-			Title:        item.DefaultAlias.Name,
-			BookBrainzID: item.BBID,
-		}
-		if len(item.AuthorCredit.Names) > 0 {
-			r.Author = item.AuthorCredit.Names[0].Name
-		}
-		results = append(results, r)
-	}
-	return results, nil
-}
@@ -2,8 +2,16 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 )
 
 // MetadataCache is the interface for caching metadata search results.
@@ -11,6 +19,38 @@ import (
 type MetadataCache interface {
 	Get(key string) ([]BookMetadataResult, bool)
 	Set(key string, results []BookMetadataResult)
+	// Stats reports the cache's size and its hit/miss counts since startup.
+	Stats() MetadataCacheStats
+	// Purge empties the cache, returning how many entries it dropped.
+	Purge() (int64, error)
+}
+
+// MetadataCacheStats is a MetadataCache's current size and effectiveness,
+// shown on the admin metadata status page.
+type MetadataCacheStats struct {
+	// Backend is "memory" or "sqlite".
+	Backend string `json:"backend"`
+	Entries int64  `json:"entries"`
+	// MaxEntries is the size the cache is trimmed back to; 0 is unbounded.
+	MaxEntries int   `json:"max_entries"`
+	TTLSeconds int64 `json:"ttl_seconds"`
+	// Hits and Misses count Get calls since the server started.
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// cacheCounters counts a cache's hits and misses.
+type cacheCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func (c *cacheCounters) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
 }
 
 type cacheEntry struct {
@@ -19,6 +59,7 @@ type cacheEntry struct {
 }
 
 type inMemoryMetadataCache struct {
+	cacheCounters
 	mu      sync.RWMutex
 	entries map[string]cacheEntry
 	ttl     time.Duration
@@ -32,7 +73,7 @@ func NewInMemoryMetadataCache(ctx context.Context, ttl time.Duration) MetadataCa
 		entries: make(map[string]cacheEntry),
 		ttl:     ttl,
 	}
-	go c.runEviction(ctx)
+	go runEvictionLoop(ctx, c.evictExpired)
 	return c
 }
 
@@ -40,7 +81,9 @@ func (c *inMemoryMetadataCache) Get(key string) ([]BookMetadataResult, bool) {
 	c.mu.RLock()
 	e, ok := c.entries[key]
 	c.mu.RUnlock()
-	if !ok || time.Now().After(e.expiresAt) {
+	hit := ok && !time.Now().After(e.expiresAt)
+	c.record(hit)
+	if !hit {
 		return nil, false
 	}
 	return e.results, true
@@ -55,6 +98,27 @@ func (c *inMemoryMetadataCache) Set(key string, results []BookMetadataResult) {
 	c.mu.Unlock()
 }
 
+func (c *inMemoryMetadataCache) Stats() MetadataCacheStats {
+	c.mu.RLock()
+	entries := len(c.entries)
+	c.mu.RUnlock()
+	return MetadataCacheStats{
+		Backend:    "memory",
+		Entries:    int64(entries),
+		TTLSeconds: int64(c.ttl.Seconds()),
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+	}
+}
+
+func (c *inMemoryMetadataCache) Purge() (int64, error) {
+	c.mu.Lock()
+	n := int64(len(c.entries))
+	c.entries = make(map[string]cacheEntry)
+	c.mu.Unlock()
+	return n, nil
+}
+
 func (c *inMemoryMetadataCache) evictExpired() {
 	now := time.Now()
 	c.mu.Lock()
@@ -66,15 +130,108 @@ func (c *inMemoryMetadataCache) evictExpired() {
 	c.mu.Unlock()
 }
 
-func (c *inMemoryMetadataCache) runEviction(ctx context.Context) {
+// runEvictionLoop calls evict every 10 minutes until ctx is cancelled.
+func runEvictionLoop(ctx context.Context, evict func()) {
 	ticker := time.NewTicker(10 * time.Minute)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
-			c.evictExpired()
+			evict()
 		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+// sqliteMetadataCache persists search results through a
+// MetadataCacheRepository, so the cache survives restarts and a deploy
+// doesn't re-spend the Google Books quota on queries it already answered.
+type sqliteMetadataCache struct {
+	cacheCounters
+	repo       repository.MetadataCacheRepository
+	ttl        time.Duration
+	maxEntries int
+}
+
+// NewSQLiteMetadataCache returns a MetadataCache stored in the database via
+// repo. Entries expire after ttl; once there are more than maxEntries (0 is
+// unbounded), each Set evicts those soonest to expire. A background
+// goroutine deletes expired entries every 10 minutes; it stops when ctx is
+// cancelled.
+//
+// A cache read or write that fails is logged and treated as a miss: the
+// cache only ever saves work, so it must never fail a search.
+func NewSQLiteMetadataCache(ctx context.Context, repo repository.MetadataCacheRepository, ttl time.Duration, maxEntries int) MetadataCache {
+	c := &sqliteMetadataCache{repo: repo, ttl: ttl, maxEntries: maxEntries}
+	go runEvictionLoop(ctx, c.evictExpired)
+	return c
+}
+
+func (c *sqliteMetadataCache) Get(key string) ([]BookMetadataResult, bool) {
+	results, ok := c.lookup(key)
+	c.record(ok)
+	return results, ok
+}
+
+func (c *sqliteMetadataCache) lookup(key string) ([]BookMetadataResult, bool) {
+	e, err := c.repo.Get(key)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Warn().Err(err).Msg("metadata cache: read failed")
+		}
+		return nil, false
+	}
+	if time.Now().After(e.ExpiresAt) {
+		return nil, false
+	}
+	var results []BookMetadataResult
+	if err := json.Unmarshal([]byte(e.Results), &results); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("metadata cache: undecodable entry ignored")
+		return nil, false
+	}
+	return results, true
+}
+
+func (c *sqliteMetadataCache) Set(key string, results []BookMetadataResult) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		log.Warn().Err(err).Msg("metadata cache: could not encode results")
+		return
+	}
+	entry := &models.MetadataCacheEntry{Key: key, Results: string(data), ExpiresAt: time.Now().Add(c.ttl)}
+	if err := c.repo.Put(entry); err != nil {
+		log.Warn().Err(err).Msg("metadata cache: write failed")
+		return
+	}
+	if c.maxEntries > 0 {
+		if _, err := c.repo.Trim(c.maxEntries); err != nil {
+			log.Warn().Err(err).Msg("metadata cache: trim failed")
+		}
+	}
+}
+
+func (c *sqliteMetadataCache) Stats() MetadataCacheStats {
+	entries, err := c.repo.Count()
+	if err != nil {
+		log.Warn().Err(err).Msg("metadata cache: count failed")
+	}
+	return MetadataCacheStats{
+		Backend:    "sqlite",
+		Entries:    entries,
+		MaxEntries: c.maxEntries,
+		TTLSeconds: int64(c.ttl.Seconds()),
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+	}
+}
+
+func (c *sqliteMetadataCache) Purge() (int64, error) {
+	return c.repo.DeleteAll()
+}
+
+func (c *sqliteMetadataCache) evictExpired() {
+	if _, err := c.repo.DeleteExpired(time.Now()); err != nil {
+		log.Warn().Err(err).Msg("metadata cache: evicting expired entries failed")
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func newTestSQLiteCache(t *testing.T, ttl time.Duration, maxEntries int) (MetadataCache, *repotest.MetadataCacheRepository) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	repo := repotest.NewMetadataCacheRepository()
+	return NewSQLiteMetadataCache(ctx, repo, ttl, maxEntries), repo
+}
+
+func TestSQLiteMetadataCache_RoundTripsResultsAndCountsHits(t *testing.T) {
+	cache, _ := newTestSQLiteCache(t, time.Hour, 0)
+	results := []BookMetadataResult{{Source: "google_books", Title: "Dune", Author: "Frank Herbert", PageCount: 412}}
+
+	_, ok := cache.Get("dune")
+	assert.False(t, ok)
+	cache.Set("dune", results)
+	got, ok := cache.Get("dune")
+	require.True(t, ok)
+	assert.Equal(t, results, got)
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(1), stats.Entries)
+}
+
+// A restart is a new cache over the same table: what the old one stored is
+// still a hit.
+func TestSQLiteMetadataCache_SurvivesRestart(t *testing.T) {
+	cache, repo := newTestSQLiteCache(t, time.Hour, 0)
+	cache.Set("dune", []BookMetadataResult{{Title: "Dune"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	restarted := NewSQLiteMetadataCache(ctx, repo, time.Hour, 0)
+	got, ok := restarted.Get("dune")
+	require.True(t, ok)
+	assert.Equal(t, "Dune", got[0].Title)
+}
+
+func TestSQLiteMetadataCache_ExpiredEntryIsAMiss(t *testing.T) {
+	cache, repo := newTestSQLiteCache(t, time.Hour, 0)
+	require.NoError(t, repo.Put(&models.MetadataCacheEntry{Key: "dune", Results: "[]", ExpiresAt: time.Now().Add(-time.Second)}))
+
+	_, ok := cache.Get("dune")
+	assert.False(t, ok)
+	assert.Equal(t, int64(1), cache.Stats().Misses)
+}
+
+func TestSQLiteMetadataCache_UndecodableEntryIsAMiss(t *testing.T) {
+	cache, repo := newTestSQLiteCache(t, time.Hour, 0)
+	require.NoError(t, repo.Put(&models.MetadataCacheEntry{Key: "dune", Results: "not json", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	_, ok := cache.Get("dune")
+	assert.False(t, ok)
+}
+
+func TestSQLiteMetadataCache_SetTrimsToMaxEntries(t *testing.T) {
+	cache, repo := newTestSQLiteCache(t, time.Hour, 2)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, nil)
+		// Distinct expiries, so "a" is unambiguously the soonest to expire.
+		time.Sleep(time.Millisecond)
+	}
+
+	count, err := repo.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	_, ok := cache.Get("a")
+	assert.False(t, ok, "the entry soonest to expire is evicted")
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+}
+
+func TestInMemoryMetadataCache_StatsAndPurge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache := NewInMemoryMetadataCache(ctx, time.Minute)
+	cache.Set("dune", nil)
+	cache.Get("dune")
+	cache.Get("emma")
+
+	assert.Equal(t, MetadataCacheStats{Backend: "memory", Entries: 1, TTLSeconds: 60, Hits: 1, Misses: 1}, cache.Stats())
+
+	purged, err := cache.Purge()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Zero(t, cache.Stats().Entries)
+}
+
+// stubMetadataProvider answers every search with results, or fails with err.
+type stubMetadataProvider struct {
+	name    string
+	results []BookMetadataResult
+	err     error
+}
+
+func (p *stubMetadataProvider) Name() string           { return p.name }
+func (p *stubMetadataProvider) Timeout() time.Duration { return time.Second }
+func (p *stubMetadataProvider) Capabilities() metadata.Capabilities {
+	return metadata.Capabilities{ISBN: true, FreeText: true}
+}
+func (p *stubMetadataProvider) Search(context.Context, metadata.Query) ([]BookMetadataResult, error) {
+	return p.results, p.err
+}
+
+func TestMetadataHandler_Search_CachesOnlyCompleteResults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ok := &stubMetadataProvider{name: "ok", results: []BookMetadataResult{{Source: "ok", Title: "Dune", Author: "Frank Herbert"}}}
+	flaky := &stubMetadataProvider{name: "flaky", err: errors.New("503")}
+	cache := NewInMemoryMetadataCache(ctx, time.Hour)
+	h := NewMetadataHandler(cache, "", "", repotest.NewUserRepository(), metadata.NewRegistry(nil, ok, flaky))
+
+	results := h.search(ctx, "dune", "")
+	require.Len(t, results, 1)
+	assert.Zero(t, cache.Stats().Entries, "results missing a failed provider aren't cached")
+
+	flaky.err = nil
+	h.search(ctx, "dune", "")
+	assert.Equal(t, int64(1), cache.Stats().Entries)
+}
+
+func TestMetadataHandler_Search_ProviderSettingsChangeMissesTheCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := &stubMetadataProvider{name: "a", results: []BookMetadataResult{{Source: "a", Title: "Dune", Author: "Frank Herbert"}}}
+	b := &stubMetadataProvider{name: "b", results: []BookMetadataResult{{Source: "b", Title: "Emma", Author: "Jane Austen"}}}
+	settings := repotest.NewAdminRepository()
+	cache := NewInMemoryMetadataCache(ctx, time.Hour)
+	h := NewMetadataHandler(cache, "", "", repotest.NewUserRepository(), metadata.NewRegistry(settings, a, b))
+
+	require.Len(t, h.search(ctx, "dune", ""), 2)
+	require.NoError(t, settings.UpsertSetting(metadata.SettingDisabled, "b"))
+	results := h.search(ctx, "dune", "")
+	require.Len(t, results, 1, "results cached before b was disabled aren't served")
+	assert.Equal(t, "a", results[0].Source)
+}
@@ -4,82 +4,13 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
 )
 
 // nonAlphanumSpace matches any character that is not a lowercase letter, digit, or space.
 var nonAlphanumSpace = regexp.MustCompile(`[^a-z0-9 ]+`)
 
-// sourcePriority returns a numeric priority for a source (lower = higher priority).
-func sourcePriority(source string) int {
-	switch source {
-	case "google_books":
-		return 0
-	case "openlibrary":
-		return 1
-	default:
-		return 2
-	}
-}
-
-// normalizeISBN strips hyphens/spaces and converts ISBN-10 to ISBN-13.
-// Returns "" if the input doesn't produce a valid 10- or 13-digit ISBN.
-func normalizeISBN(s string) string {
-	s = strings.ReplaceAll(s, "-", "")
-	s = strings.ReplaceAll(s, " ", "")
-	s = strings.ToUpper(s)
-
-	switch len(s) {
-	case 13:
-		return normalizedISBN13(s)
-	case 10:
-		return isbn10ToISBN13(s)
-	default:
-		return ""
-	}
-}
-
-// normalizedISBN13 returns s if it's all digits, else "".
-func normalizedISBN13(s string) string {
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return ""
-		}
-	}
-	return s
-}
-
-// isbn10ToISBN13 validates a 10-character ISBN-10 (last char may be 'X' = 10)
-// and converts it to ISBN-13: prepend "978", drop the old check digit,
-// compute a new one. Returns "" if s isn't a valid ISBN-10.
-func isbn10ToISBN13(s string) string {
-	for i, c := range s {
-		if i == 9 {
-			if c != 'X' && (c < '0' || c > '9') {
-				return ""
-			}
-		} else if c < '0' || c > '9' {
-			return ""
-		}
-	}
-	prefix := "978" + s[:9]
-	check := isbn13CheckDigit(prefix)
-	return prefix + string([]byte{'0' + check})
-}
-
-// isbn13CheckDigit computes the ISBN-13 check digit for a 12-digit string.
-func isbn13CheckDigit(digits string) byte {
-	sum := 0
-	for i, c := range digits {
-		d := int(c - '0')
-		if i%2 == 0 {
-			sum += d
-		} else {
-			sum += d * 3
-		}
-	}
-	return byte((10 - (sum % 10)) % 10)
-}
-
 // normalizeTitleAuthor returns a deduplication key from title and author.
 func normalizeTitleAuthor(title, author string) string {
 	norm := func(s string) string {
@@ -107,7 +38,7 @@ func deduplicateIntoGroups(results []BookMetadataResult) [][]BookMetadataResult
 	groupHasISBN := map[int]bool{}
 
 	for _, r := range results {
-		normISBN := normalizeISBN(r.ISBN)
+		normISBN := bookmatch.NormalizeISBN(r.ISBN)
 		normTA := normalizeTitleAuthor(r.Title, r.Author)
 
 		idx, found := findExistingGroup(isbnIndex, titleAuthorIndex, groupHasISBN, normISBN, normTA)
@@ -170,13 +101,14 @@ func registerISBN(isbnIndex map[string]int, normISBN string, idx int) {
 }
 
 // mergeGroup merges a group of results (same book, multiple sources) into one.
-// Google Books fields take priority, then Open Library, then BookBrainz.
-func mergeGroup(group []BookMetadataResult) BookMetadataResult {
+// Fields come from the source rank puts first (by default Google Books, then
+// Open Library, then BookBrainz), falling back down the ranking.
+func mergeGroup(group []BookMetadataResult, rank func(string) int) BookMetadataResult {
 	// Sort by source priority so we pick fields from the best source first
 	sorted := make([]BookMetadataResult, len(group))
 	copy(sorted, group)
 	sort.SliceStable(sorted, func(i, j int) bool {
-		return sourcePriority(sorted[i].Source) < sourcePriority(sorted[j].Source)
+		return rank(sorted[i].Source) < rank(sorted[j].Source)
 	})
 
 	// For each field, take the first non-empty/non-zero value in source-priority order.
@@ -195,7 +127,21 @@ func mergeGroup(group []BookMetadataResult) BookMetadataResult {
 		OLKey:         firstNonEmpty(sorted, func(r BookMetadataResult) string { return r.OLKey }),
 		GoogleBooksID: firstNonEmpty(sorted, func(r BookMetadataResult) string { return r.GoogleBooksID }),
 		BookBrainzID:  firstNonEmpty(sorted, func(r BookMetadataResult) string { return r.BookBrainzID }),
+		Contributors:  firstContributors(sorted),
+	}
+}
+
+// firstContributors returns the first non-empty Contributors in
+// source-priority order. Lists aren't merged across sources: each spells
+// names its own way, and a union would double-credit anyone spelled two
+// ways that don't normalize alike.
+func firstContributors(sorted []BookMetadataResult) []bookmatch.Contributor {
+	for _, r := range sorted {
+		if len(r.Contributors) > 0 {
+			return r.Contributors
+		}
 	}
+	return nil
 }
 
 // firstNonEmpty returns get(r) for the first r where it's non-empty, else "".
@@ -255,18 +201,18 @@ func scoreResult(r BookMetadataResult) int {
 
 // enrichAcrossEditions buckets already-merged, one-per-edition results by
 // normalizeTitleAuthor and backfills empty Description fields from the best
-// other member of the bucket (by sourcePriority then scoreResult). Never
+// other member of the bucket (by source rank then scoreResult). Never
 // overwrites an already-non-empty field, never touches edition-specific
 // fields (Publisher/PublishedDate/PageCount) or identity keys, and skips a
 // donor whose Language differs from the target's when both are set. Also
 // stamps WorkKey on every result with a non-empty bucket key.
-func enrichAcrossEditions(merged []BookMetadataResult) []BookMetadataResult {
+func enrichAcrossEditions(merged []BookMetadataResult, rank func(string) int) []BookMetadataResult {
 	buckets := bucketByWorkKey(merged)
 	for _, idxs := range buckets {
 		if len(idxs) < 2 {
 			continue
 		}
-		fillBucketDescriptions(merged, idxs)
+		fillBucketDescriptions(merged, idxs, rank)
 	}
 	return merged
 }
@@ -291,13 +237,13 @@ func bucketByWorkKey(merged []BookMetadataResult) map[string][]int {
 // fillBucketDescriptions backfills each empty-Description member of the
 // bucket (indices into merged) from the best other member, computed against
 // a stable snapshot so fills don't cascade or depend on iteration order.
-func fillBucketDescriptions(merged []BookMetadataResult, idxs []int) {
+func fillBucketDescriptions(merged []BookMetadataResult, idxs []int, rank func(string) int) {
 	snapshot := make([]BookMetadataResult, len(idxs))
 	for i, idx := range idxs {
 		snapshot[i] = merged[idx]
 	}
 	sort.SliceStable(snapshot, func(i, j int) bool {
-		pi, pj := sourcePriority(snapshot[i].Source), sourcePriority(snapshot[j].Source)
+		pi, pj := rank(snapshot[i].Source), rank(snapshot[j].Source)
 		if pi != pj {
 			return pi < pj
 		}
@@ -334,16 +280,16 @@ func bestDescriptionDonor(snapshot []BookMetadataResult, targetLanguage string)
 }
 
 // bestTitleAuthorForExpansion returns the Title/Author pair from results with
-// the best source-priority/completeness score, for use as a follow-up search
+// the best source-rank/completeness score, for use as a follow-up search
 // query when the original query was an ISBN (see expandSiblingEditions in
 // metadata.go). Returns "", "" if no result carries both fields.
-func bestTitleAuthorForExpansion(results []BookMetadataResult) (title, author string) {
+func bestTitleAuthorForExpansion(results []BookMetadataResult, rank func(string) int) (title, author string) {
 	var best *BookMetadataResult
 	for i, r := range results {
 		if r.Title == "" || r.Author == "" {
 			continue
 		}
-		if best == nil || isBetterExpansionCandidate(r, *best) {
+		if best == nil || isBetterExpansionCandidate(r, *best, rank) {
 			best = &results[i]
 		}
 	}
@@ -354,18 +300,20 @@ func bestTitleAuthorForExpansion(results []BookMetadataResult) (title, author st
 }
 
 // isBetterExpansionCandidate reports whether a is a better source of
-// Title/Author than b, by the same sourcePriority-then-scoreResult ordering
-// used everywhere else in this file.
-func isBetterExpansionCandidate(a, b BookMetadataResult) bool {
-	pa, pb := sourcePriority(a.Source), sourcePriority(b.Source)
+// Title/Author than b, by the same rank-then-scoreResult ordering used
+// everywhere else in this file.
+func isBetterExpansionCandidate(a, b BookMetadataResult, rank func(string) int) bool {
+	pa, pb := rank(a.Source), rank(b.Source)
 	if pa != pb {
 		return pa < pb
 	}
 	return scoreResult(a) > scoreResult(b)
 }
 
-// consolidateResults deduplicates, merges, and ranks results from all sources.
-func consolidateResults(results []BookMetadataResult) []BookMetadataResult {
+// consolidateResults deduplicates, merges, and ranks results from all
+// sources. rank orders sources by priority, lower first — see
+// metadata.Registry.Rank.
+func consolidateResults(results []BookMetadataResult, rank func(string) int) []BookMetadataResult {
 	if len(results) == 0 {
 		return []BookMetadataResult{}
 	}
@@ -374,10 +322,10 @@ func consolidateResults(results []BookMetadataResult) []BookMetadataResult {
 
 	merged := make([]BookMetadataResult, 0, len(groups))
 	for _, group := range groups {
-		merged = append(merged, mergeGroup(group))
+		merged = append(merged, mergeGroup(group, rank))
 	}
 
-	merged = enrichAcrossEditions(merged)
+	merged = enrichAcrossEditions(merged, rank)
 
 	sort.SliceStable(merged, func(i, j int) bool {
 		si, sj := scoreResult(merged[i]), scoreResult(merged[j])
@@ -402,7 +350,7 @@ func promoteQueriedEdition(sorted []BookMetadataResult, queriedISBN string) []Bo
 		return sorted
 	}
 	for i, r := range sorted {
-		if normalizeISBN(r.ISBN) != queriedISBN {
+		if bookmatch.NormalizeISBN(r.ISBN) != queriedISBN {
 			continue
 		}
 		if i == 0 {
@@ -5,27 +5,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
 )
 
-func TestNormalizeISBN(t *testing.T) {
-	tests := []struct {
-		name string
-		in   string
-		want string
-	}{
-		{name: "already ISBN-13", in: "978-0-13-468599-1", want: "9780134685991"},
-		{name: "ISBN-10 converts to ISBN-13", in: "0-13-468599-7", want: "9780134685991"},
-		{name: "ISBN-10 with X check digit", in: "080442957X", want: "9780804429573"},
-		{name: "invalid length", in: "123", want: ""},
-		{name: "non-numeric ISBN-13", in: "97801346859XX", want: ""},
-		{name: "empty string", in: "", want: ""},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, normalizeISBN(tt.in))
-		})
-	}
-}
+// defaultRank is the source ranking with no admin settings applied.
+var defaultRank = metadata.NewRegistry(nil, metadata.DefaultProviders()...).Rank()
 
 func TestNormalizeTitleAuthor(t *testing.T) {
 	tests := []struct {
@@ -58,7 +45,7 @@ func TestConsolidateResults_DeduplicatesByISBN(t *testing.T) {
 		{Source: "google_books", Title: "Go in Action", Author: "William Kennedy", ISBN: "9781617291769", Description: "A great book"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 1, "same ISBN across sources should merge into one result")
 	assert.Equal(t, "google_books", got[0].Source, "google_books should win the source-priority tiebreak")
@@ -67,13 +54,29 @@ func TestConsolidateResults_DeduplicatesByISBN(t *testing.T) {
 	assert.Equal(t, "A great book", got[0].Description)
 }
 
+func TestConsolidateResults_FollowsConfiguredPriority(t *testing.T) {
+	results := []BookMetadataResult{
+		{Source: "openlibrary", Title: "Go in Action", Author: "Kennedy", ISBN: "9781617291769"},
+		{Source: "google_books", Title: "Go in Action", Author: "William Kennedy", ISBN: "9781617291769"},
+	}
+	settings := repotest.NewAdminRepository()
+	require.NoError(t, settings.UpsertSetting(metadata.SettingPriority, "openlibrary"))
+	rank := metadata.NewRegistry(settings, metadata.DefaultProviders()...).Rank()
+
+	got := consolidateResults(results, rank)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "openlibrary", got[0].Source)
+	assert.Equal(t, "Kennedy", got[0].Author)
+}
+
 func TestConsolidateResults_DeduplicatesByTitleAuthorWhenNoISBN(t *testing.T) {
 	results := []BookMetadataResult{
 		{Source: "openlibrary", Title: "Go in Action", Author: "Kennedy", PageCount: 300},
 		{Source: "bookbrainz", Title: "go in action", Author: "kennedy", PageCount: 0},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 1, "matching normalized title+author should merge even without an ISBN")
 	assert.Equal(t, 300, got[0].PageCount)
@@ -85,7 +88,7 @@ func TestConsolidateResults_KeepsDistinctISBNEditionsSeparateEvenWithMatchingTit
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 2, "two results with distinct, valid ISBNs must never be folded together via the title+author fallback, even when title+author match")
 }
@@ -102,7 +105,7 @@ func TestConsolidateResults_ISBNLessResultStillMergesWithSameEditionCarryingAnIS
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9781617291769", Description: "A great book"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 1, "an ISBN-less hit for the same edition must still merge with an ISBN-bearing hit for it")
 	assert.Equal(t, "9781617291769", got[0].ISBN)
@@ -120,7 +123,7 @@ func TestConsolidateResults_ISBNLessResultThenTwoDistinctISBNEditionsStayThreeSe
 		{Source: "openlibrary", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 2)
 }
@@ -131,7 +134,7 @@ func TestConsolidateResults_KeepsDistinctBooksSeparate(t *testing.T) {
 		{Source: "openlibrary", Title: "The Go Programming Language", Author: "Donovan", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 2)
 }
@@ -142,7 +145,7 @@ func TestConsolidateResults_RanksByCompletenessThenTitle(t *testing.T) {
 		{Source: "google_books", Title: "Complete Book", Author: "B", CoverURL: "c.jpg", Description: "d", ISBN: "1", Publisher: "p", PageCount: 100},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 2)
 	assert.Equal(t, "Complete Book", got[0].Title, "more complete result should rank first")
@@ -164,7 +167,7 @@ func TestConsolidateResults_RankTieBreakIsCaseInsensitive(t *testing.T) {
 		{Source: "openlibrary", Title: "Church discipline", Author: "Jonathan Leeman", ISBN: "9781433532368"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	require.Len(t, got, 2)
 	assert.Equal(t, "Church discipline", got[0].Title, "the genuine English edition must rank first, not a differently-cased translated sibling")
@@ -172,7 +175,7 @@ func TestConsolidateResults_RankTieBreakIsCaseInsensitive(t *testing.T) {
 }
 
 func TestConsolidateResults_EmptyInput(t *testing.T) {
-	got := consolidateResults(nil)
+	got := consolidateResults(nil, defaultRank)
 	assert.Empty(t, got)
 }
 
@@ -182,7 +185,7 @@ func TestConsolidateResults_EnrichesSparseEditionFromRicherEdition(t *testing.T)
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	assert.Len(t, got, 2)
 	var rich, sparse BookMetadataResult
@@ -206,7 +209,7 @@ func TestConsolidateResults_DoesNotCrossFillEditionSpecificFields(t *testing.T)
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		if r.ISBN == "9780134190440" {
@@ -223,7 +226,7 @@ func TestConsolidateResults_NeverOverwritesAlreadyPopulatedField(t *testing.T) {
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440", Description: "Own description"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		if r.ISBN == "9780134190440" {
@@ -239,7 +242,7 @@ func TestConsolidateResults_DoesNotCopyIdentityFieldsAcrossEditions(t *testing.T
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		if r.ISBN == "9780134190440" {
@@ -256,7 +259,7 @@ func TestConsolidateResults_ExcludesEmptyTitleOrAuthorFromBucketing(t *testing.T
 		{Source: "openlibrary", Title: "Go in Action", Author: "", ISBN: "9780134190440", Description: "d2"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		assert.Empty(t, r.WorkKey, "entries with an empty Title or Author must not be bucketed")
@@ -270,7 +273,7 @@ func TestConsolidateResults_SkipsDescriptionOnLanguageMismatch(t *testing.T) {
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440", Language: "en"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		if r.ISBN == "9780134190440" {
@@ -286,7 +289,7 @@ func TestConsolidateResults_FillsDescriptionWhenLanguageUnsetOnEitherSide(t *tes
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		if r.ISBN == "9780134190440" {
@@ -302,7 +305,7 @@ func TestConsolidateResults_MultipleDonorsPicksDeterministically(t *testing.T) {
 		{Source: "openlibrary", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134685991"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		if r.ISBN == "9780134685991" {
@@ -318,7 +321,7 @@ func TestConsolidateResults_ThreeEditionsFillIndependentlyWithoutCascading(t *te
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134685991"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		if r.ISBN != "9781617291769" {
@@ -334,7 +337,7 @@ func TestConsolidateResults_SingleEditionBucketIsNoop(t *testing.T) {
 	}
 
 	assert.NotPanics(t, func() {
-		got := consolidateResults(results)
+		got := consolidateResults(results, defaultRank)
 		assert.Len(t, got, 1)
 		assert.Empty(t, got[0].Description)
 		assert.NotEmpty(t, got[0].WorkKey)
@@ -347,7 +350,7 @@ func TestConsolidateResults_EnrichedDescriptionIsAlwaysLabeled(t *testing.T) {
 		{Source: "google_books", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	for _, r := range got {
 		hasBackfilledDescription := r.Description != "" && r.ISBN == "9780134190440"
@@ -367,7 +370,7 @@ func TestConsolidateResults_WorkKeyMatchesBucketKey(t *testing.T) {
 		{Source: "openlibrary", Title: "go in action", Author: "kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	want := normalizeTitleAuthor("Go in Action", "Kennedy")
 	for _, r := range got {
@@ -388,7 +391,7 @@ func TestConsolidateResults_FullPipeline_MultiSourceMergePlusCrossEditionBackfil
 		{Source: "openlibrary", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	require.Len(t, got, 2, "hardcover's two source hits merge by ISBN into one; paperback stays its own distinct-ISBN result")
 
@@ -414,7 +417,7 @@ func TestConsolidateResults_FullPipeline_MultiSourceMergePlusCrossEditionBackfil
 
 func TestConsolidateResults_KnownLimitation_AuthorFormatDivergenceFromMergeBlocksBackfill(t *testing.T) {
 	// enrichAcrossEditions buckets by the *merged* result's chosen Title/
-	// Author (whichever source won by sourcePriority), not each source's raw
+	// Author (whichever source won by rank), not each source's raw
 	// value. If a multi-source edition's winning source formats the author
 	// differently than a sparse edition's only source does (e.g. "William
 	// Kennedy" vs "Kennedy" — a real-world provider inconsistency, not a
@@ -429,7 +432,7 @@ func TestConsolidateResults_KnownLimitation_AuthorFormatDivergenceFromMergeBlock
 		{Source: "openlibrary", Title: "Go in Action", Author: "Kennedy", ISBN: "9780134190440"},
 	}
 
-	got := consolidateResults(results)
+	got := consolidateResults(results, defaultRank)
 
 	require.Len(t, got, 2)
 	for _, r := range got {
@@ -446,7 +449,7 @@ func TestBestTitleAuthorForExpansion_PicksHighestPriorityCompleteResult(t *testi
 		{Source: "google_books", Title: "Church Discipline", Author: "Jonathan Leeman", Description: "..."},
 	}
 
-	title, author := bestTitleAuthorForExpansion(results)
+	title, author := bestTitleAuthorForExpansion(results, defaultRank)
 
 	assert.Equal(t, "Church Discipline", title)
 	assert.Equal(t, "Jonathan Leeman", author, "google_books outranks bookbrainz by source priority")
@@ -459,7 +462,7 @@ func TestBestTitleAuthorForExpansion_SkipsResultsMissingEitherField(t *testing.T
 		{Source: "bookbrainz", Title: "Church Discipline", Author: "Jonathan Leeman"},
 	}
 
-	title, author := bestTitleAuthorForExpansion(results)
+	title, author := bestTitleAuthorForExpansion(results, defaultRank)
 
 	assert.Equal(t, "Church Discipline", title)
 	assert.Equal(t, "Jonathan Leeman", author)
@@ -471,14 +474,14 @@ func TestBestTitleAuthorForExpansion_ReturnsEmptyWhenNoResultHasBothFields(t *te
 		{Source: "openlibrary", Title: "", ISBN: "9781433532337"},
 	}
 
-	title, author := bestTitleAuthorForExpansion(results)
+	title, author := bestTitleAuthorForExpansion(results, defaultRank)
 
 	assert.Empty(t, title)
 	assert.Empty(t, author)
 }
 
 func TestBestTitleAuthorForExpansion_EmptyInput(t *testing.T) {
-	title, author := bestTitleAuthorForExpansion(nil)
+	title, author := bestTitleAuthorForExpansion(nil, defaultRank)
 
 	assert.Empty(t, title)
 	assert.Empty(t, author)
@@ -542,7 +545,7 @@ func TestPromoteQueriedEdition_MatchesAcrossISBNFormats(t *testing.T) {
 		{Title: "Queried Edition", ISBN: "978-1-433532-33-7"},
 	}
 
-	got := promoteQueriedEdition(sorted, normalizeISBN("9781433532337"))
+	got := promoteQueriedEdition(sorted, bookmatch.NormalizeISBN("9781433532337"))
 
 	assert.Equal(t, "Queried Edition", got[0].Title)
 }
@@ -570,11 +573,11 @@ func TestConsolidateResults_PromoteQueriedEdition_ExactISBNBeatsHigherScoringSib
 		},
 	}
 
-	consolidated := consolidateResults(results)
+	consolidated := consolidateResults(results, defaultRank)
 	require.Len(t, consolidated, 2)
 	require.Equal(t, "Church Discipline (Burmese)", consolidated[0].Title, "sanity check: the Burmese edition does genuinely outscore the exact match on completeness alone")
 
-	got := promoteQueriedEdition(consolidated, normalizeISBN("9781433532337"))
+	got := promoteQueriedEdition(consolidated, bookmatch.NormalizeISBN("9781433532337"))
 
 	assert.Equal(t, "Church Discipline", got[0].Title, "the exact-ISBN edition must win the top slot regardless of completeness score")
 }
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"image"
+	_ "image/gif" // registers GIF decoding for image.Decode
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/barcode"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
+)
+
+// scanMaxBytes caps an uploaded barcode photo. Phone cameras produce 3-8 MB
+// JPEGs; anything much larger isn't a photo.
+const scanMaxBytes = 15 << 20
+
+// scanMaxPixels caps the decoded image size, checked from the header before
+// decoding so a small, highly compressed file can't expand into gigabytes.
+const scanMaxPixels = 50_000_000
+
+// scanMaxCodes bounds how many barcodes from one photo are looked up, since
+// each is a full metadata search across every source.
+const scanMaxCodes = 12
+
+// scanLookupConcurrency is how many of a photo's barcodes are looked up at
+// once.
+const scanLookupConcurrency = 4
+
+type scanBarcodesForm struct {
+	Image huma.FormFile `form:"image" contentType:"image/jpeg,image/png,image/gif" required:"true" doc:"Photo of one or more book barcodes (JPEG, PNG or GIF)"`
+}
+
+type scanBarcodesInput struct {
+	RawBody huma.MultipartFormFiles[scanBarcodesForm]
+}
+
+// scannedBarcode is one barcode read from the photo. Only Bookland EANs
+// (978/979 prefixes) are ISBNs; any other EAN-13 — a shop's price label,
+// say — is reported with no ISBN and no candidates, so the caller can tell
+// "no barcode found" apart from "found one, but it isn't a book".
+type scannedBarcode struct {
+	Code       string               `json:"code" doc:"The 13 digits encoded by the barcode"`
+	ISBN       string               `json:"isbn,omitempty" doc:"Normalized ISBN-13, when the barcode is one"`
+	Candidates []BookMetadataResult `json:"candidates" doc:"Metadata search results for the ISBN, the exact edition first; pass one to POST /books to add it"`
+}
+
+type scanBarcodesOutput struct {
+	Body struct {
+		Barcodes []scannedBarcode `json:"barcodes"`
+	}
+}
+
+func (h *MetadataHandler) scanBarcodes(ctx context.Context, input *scanBarcodesInput) (*scanBarcodesOutput, error) {
+	if _, err := middleware.GetRequiredUserID(ctx); err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	file := input.RawBody.Data().Image
+	if file.Size > scanMaxBytes {
+		return nil, huma.Error413RequestEntityTooLarge("image must be 15 MB or smaller")
+	}
+	return h.scanImage(ctx, file)
+}
+
+// scanImage decodes the barcodes in an image and looks each ISBN up. Split
+// from scanBarcodes so tests can call it without building a multipart form.
+func (h *MetadataHandler) scanImage(ctx context.Context, r io.ReadSeeker) (*scanBarcodesOutput, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity("could not read image; upload a JPEG, PNG or GIF")
+	}
+	if cfg.Width*cfg.Height > scanMaxPixels {
+		return nil, huma.Error422UnprocessableEntity("image is too large to scan; resize it below 50 megapixels")
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, huma.Error500InternalServerError("could not read image")
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity("could not read image; upload a JPEG, PNG or GIF")
+	}
+
+	codes := barcode.DecodeEAN13(img)
+	if len(codes) > scanMaxCodes {
+		codes = codes[:scanMaxCodes]
+	}
+	zerolog.Ctx(ctx).Debug().Strs("codes", codes).Msg("barcode scan complete")
+
+	out := &scanBarcodesOutput{}
+	out.Body.Barcodes = make([]scannedBarcode, len(codes))
+	apiKey := h.resolveGoogleBooksAPIKey(ctx)
+	sem := make(chan struct{}, scanLookupConcurrency)
+	var wg sync.WaitGroup
+	for i, code := range codes {
+		out.Body.Barcodes[i] = scannedBarcode{Code: code, Candidates: []BookMetadataResult{}}
+		if !isBooklandEAN(code) {
+			continue
+		}
+		isbn := bookmatch.NormalizeISBN(code)
+		out.Body.Barcodes[i].ISBN = isbn
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			out.Body.Barcodes[i].Candidates = h.search(ctx, isbn, apiKey)
+		}(i)
+	}
+	wg.Wait()
+	return out, nil
+}
+
+// isBooklandEAN reports whether an EAN-13 is an ISBN-13: those are the
+// 978 and 979 "Bookland" prefixes.
+func isBooklandEAN(code string) bool {
+	return strings.HasPrefix(code, "978") || strings.HasPrefix(code, "979")
+}
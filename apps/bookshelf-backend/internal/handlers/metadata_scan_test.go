@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+// newScanHandler returns a MetadataHandler whose cache already holds the
+// search results for isbn, so scanImage never reaches a real source.
+func newScanHandler(t *testing.T, isbn string, cached []BookMetadataResult) *MetadataHandler {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	h := NewMetadataHandler(NewInMemoryMetadataCache(ctx, time.Hour), "", "", repotest.NewUserRepository(), metadata.NewRegistry(nil))
+	h.cache.Set(isbn+"|", cached)
+	return h
+}
+
+func openFixture(t *testing.T, name string) *bytes.Reader {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return bytes.NewReader(data)
+}
+
+func TestScanImage_LooksUpDecodedISBN(t *testing.T) {
+	result := BookMetadataResult{Source: "openlibrary", Title: "The Cathedral and the Bazaar", ISBN: "9780306406157"}
+	h := newScanHandler(t, "9780306406157", []BookMetadataResult{result})
+
+	out, err := h.scanImage(context.Background(), openFixture(t, "isbn-barcode.png"))
+
+	require.NoError(t, err)
+	require.Len(t, out.Body.Barcodes, 1)
+	got := out.Body.Barcodes[0]
+	assert.Equal(t, "9780306406157", got.Code)
+	assert.Equal(t, "9780306406157", got.ISBN)
+	assert.Equal(t, []BookMetadataResult{result}, got.Candidates)
+}
+
+func TestScanImage_NonISBNBarcodeHasNoCandidates(t *testing.T) {
+	h := newScanHandler(t, "unused", nil)
+
+	out, err := h.scanImage(context.Background(), openFixture(t, "non-isbn-barcode.png"))
+
+	require.NoError(t, err)
+	require.Len(t, out.Body.Barcodes, 1)
+	assert.Equal(t, "5901234123457", out.Body.Barcodes[0].Code)
+	assert.Empty(t, out.Body.Barcodes[0].ISBN)
+	assert.Empty(t, out.Body.Barcodes[0].Candidates)
+}
+
+func TestScanImage_RejectsNonImage(t *testing.T) {
+	h := newScanHandler(t, "unused", nil)
+
+	_, err := h.scanImage(context.Background(), bytes.NewReader([]byte("not an image")))
+
+	assertStatus(t, err, http.StatusUnprocessableEntity)
+}
@@ -23,9 +23,10 @@ func NewNotificationHandler(notifs repository.NotificationRepository) *Notificat
 // --- Input / Output types ---
 
 type listNotificationsInput struct {
-	Unread   bool `query:"unread" doc:"When true, return only unread notifications"`
-	Page     int  `query:"page" minimum:"1" doc:"Page number (default 1)"`
-	PageSize int  `query:"page_size" minimum:"1" maximum:"100" doc:"Items per page (default 20)"`
+	Unread   bool   `query:"unread" doc:"When true, return only unread notifications"`
+	Page     int    `query:"page" minimum:"1" doc:"Page number (default 1)"`
+	PageSize int    `query:"page_size" minimum:"1" maximum:"100" doc:"Items per page (default 20)"`
+	Cursor   string `query:"cursor" doc:"Continue after a previous response's next_cursor instead of using page; total, page and total_pages are then 0"`
 }
 
 type listNotificationsOutput struct {
@@ -35,6 +36,7 @@ type listNotificationsOutput struct {
 		Page       int                   `json:"page"`
 		PageSize   int                   `json:"page_size"`
 		TotalPages int                   `json:"total_pages"`
+		NextCursor string                `json:"next_cursor,omitempty" doc:"Pass as cursor to fetch the next page; absent on the last page"`
 	}
 }
 
@@ -101,9 +103,15 @@ func (h *NotificationHandler) listNotifications(ctx context.Context, input *list
 		pageSize = 20
 	}
 
-	result, err := h.notifs.FindByRecipientPaginated(userID, input.Unread, page, pageSize)
+	result, err := fetchListPage("notifications", input.Cursor, page, pageSize, "could not fetch notifications",
+		func(page, pageSize int) (*repository.PaginatedResult[models.Notification], error) {
+			return h.notifs.FindByRecipientPaginated(userID, input.Unread, page, pageSize)
+		},
+		func(after *repository.Cursor, limit int) (*repository.CursorResult[models.Notification], error) {
+			return h.notifs.FindByRecipientAfter(userID, input.Unread, after, limit)
+		})
 	if err != nil {
-		return nil, huma.Error500InternalServerError("could not fetch notifications")
+		return nil, err
 	}
 
 	var out listNotificationsOutput
@@ -112,6 +120,7 @@ func (h *NotificationHandler) listNotifications(ctx context.Context, input *list
 	out.Body.Page = result.Page
 	out.Body.PageSize = result.PageSize
 	out.Body.TotalPages = result.TotalPages
+	out.Body.NextCursor = result.NextCursor
 	return &out, nil
 }
 
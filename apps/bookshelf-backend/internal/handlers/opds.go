@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// OPDS 1.2 content types. Navigation feeds list other feeds; acquisition
+// feeds list books.
+const (
+	opdsNavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	opdsAcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	openSearchType      = "application/opensearchdescription+xml"
+)
+
+// opdsPageSize is fixed rather than client-chosen: OPDS readers follow
+// rel="next" links and never send a page size of their own.
+const opdsPageSize = 20
+
+// opdsRecentLimit caps the "New arrivals" feed, matching the largest limit
+// /books/recent accepts.
+const opdsRecentLimit = 50
+
+// OPDSHandler serves the catalog as an OPDS 1.2 feed for e-reader apps.
+// Every link it emits is absolute, built from the public frontend origin:
+// readers reach the backend through the frontend's /api proxy, so a
+// backend-relative URL would resolve to the wrong place.
+type OPDSHandler struct {
+	books          repository.BookRepository
+	frontendOrigin string
+}
+
+// NewOPDSHandler creates a new OPDSHandler. frontendOrigin is the public
+// origin members browse the site at (Config.FrontendOrigin).
+func NewOPDSHandler(books repository.BookRepository, frontendOrigin string) *OPDSHandler {
+	return &OPDSHandler{books: books, frontendOrigin: strings.TrimRight(frontendOrigin, "/")}
+}
+
+// --- Input / Output types ---
+
+type opdsBooksInput struct {
+	Q    string `query:"q" doc:"Search by title or author"`
+	Page int    `query:"page" minimum:"1" doc:"Page number (default 1)"`
+}
+
+// atomFeed is an Atom feed, optionally carrying the OPDS, Dublin Core and
+// OpenSearch namespaces. encoding/xml writes a prefixed tag name (e.g.
+// "dc:language") verbatim, which is all these extension elements need. The
+// plain Atom feed in feeds.go shares it and leaves the extensions unset.
+type atomFeed struct {
+	XMLName         xml.Name    `xml:"feed"`
+	Xmlns           string      `xml:"xmlns,attr"`
+	XmlnsDC         string      `xml:"xmlns:dc,attr,omitempty"`
+	XmlnsOpenSearch string      `xml:"xmlns:opensearch,attr,omitempty"`
+	XmlnsOPDS       string      `xml:"xmlns:opds,attr,omitempty"`
+	ID              string      `xml:"id"`
+	Title           string      `xml:"title"`
+	Updated         string      `xml:"updated"`
+	Author          atomAuthor  `xml:"author"`
+	Links           []atomLink  `xml:"link"`
+	TotalResults    *int64      `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage    *int        `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex      *int        `xml:"opensearch:startIndex,omitempty"`
+	Entries         []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string       `xml:"id"`
+	Title      string       `xml:"title"`
+	Updated    string       `xml:"updated"`
+	Published  string       `xml:"published,omitempty"`
+	Authors    []atomAuthor `xml:"author,omitempty"`
+	Identifier string       `xml:"dc:identifier,omitempty"`
+	Language   string       `xml:"dc:language,omitempty"`
+	Publisher  string       `xml:"dc:publisher,omitempty"`
+	Issued     string       `xml:"dc:issued,omitempty"`
+	Summary    *atomText    `xml:"summary,omitempty"`
+	Content    *atomText    `xml:"content,omitempty"`
+	Links      []atomLink   `xml:"link"`
+}
+
+// openSearchDescription is the document an OPDS reader fetches (via the
+// feed's rel="search" link) to learn how to build a search URL.
+type openSearchDescription struct {
+	XMLName     xml.Name        `xml:"OpenSearchDescription"`
+	Xmlns       string          `xml:"xmlns,attr"`
+	ShortName   string          `xml:"ShortName"`
+	Description string          `xml:"Description"`
+	InputEnc    string          `xml:"InputEncoding"`
+	OutputEnc   string          `xml:"OutputEncoding"`
+	URL         openSearchURL   `xml:"Url"`
+	Query       openSearchQuery `xml:"Query"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+type openSearchQuery struct {
+	Role        string `xml:"role,attr"`
+	SearchTerms string `xml:"searchTerms,attr"`
+}
+
+// --- Route registration ---
+
+// RegisterRoutes registers all OPDS routes on the given huma API. None
+// require authentication — the same catalog is already public via /books.
+func (h *OPDSHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "opds-root",
+		Method:      "GET",
+		Path:        "/opds",
+		Tags:        []string{"opds"},
+		Summary:     "OPDS navigation feed (catalog root for e-reader apps)",
+	}, h.root)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "opds-books",
+		Method:      "GET",
+		Path:        "/opds/books",
+		Tags:        []string{"opds"},
+		Summary:     "OPDS acquisition feed of the whole catalog, paginated, optionally filtered by search",
+	}, h.listBooks)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "opds-new",
+		Method:      "GET",
+		Path:        "/opds/new",
+		Tags:        []string{"opds"},
+		Summary:     "OPDS acquisition feed of recently added books",
+	}, h.listRecent)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "opds-opensearch",
+		Method:      "GET",
+		Path:        "/opds/opensearch.xml",
+		Tags:        []string{"opds"},
+		Summary:     "OpenSearch description for searching the OPDS catalog",
+	}, h.openSearch)
+}
+
+// --- Handlers ---
+
+func (h *OPDSHandler) root(_ context.Context, _ *struct{}) (*huma.StreamResponse, error) {
+	feed := h.newFeed("urn:bookshelf:opds:root", "Bookshelf", h.apiURL("/opds"), opdsNavigationType)
+	now := feed.Updated
+	feed.Entries = []atomEntry{
+		{
+			ID:      "urn:bookshelf:opds:new",
+			Title:   "New arrivals",
+			Updated: now,
+			Content: &atomText{Type: "text", Value: "Books most recently added to the library"},
+			Links:   []atomLink{{Rel: "subsection", Href: h.apiURL("/opds/new"), Type: opdsAcquisitionType}},
+		},
+		{
+			ID:      "urn:bookshelf:opds:books",
+			Title:   "All books",
+			Updated: now,
+			Content: &atomText{Type: "text", Value: "The whole catalog, by title"},
+			Links:   []atomLink{{Rel: "subsection", Href: h.apiURL("/opds/books"), Type: opdsAcquisitionType}},
+		},
+	}
+	return xmlStream(opdsNavigationType, feed), nil
+}
+
+func (h *OPDSHandler) listBooks(_ context.Context, input *opdsBooksInput) (*huma.StreamResponse, error) {
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	sort := "title"
+	if input.Q != "" {
+		sort = "relevance"
+	}
+	result, err := h.books.ListPaginated(input.Q, sort, false, page, opdsPageSize)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch books")
+	}
+
+	title := "All books"
+	if input.Q != "" {
+		title = fmt.Sprintf("Search results for %q", input.Q)
+	}
+	feed := h.newFeed("urn:bookshelf:opds:books", title, h.booksPageURL(input.Q, page), opdsAcquisitionType)
+	feed.Links = append(feed.Links, h.paginationLinks(input.Q, page, result.TotalPages)...)
+	startIndex := (page-1)*opdsPageSize + 1
+	itemsPerPage := opdsPageSize
+	feed.TotalResults = &result.Total
+	feed.ItemsPerPage = &itemsPerPage
+	feed.StartIndex = &startIndex
+	feed.Entries = h.bookEntries(result.Items)
+	return xmlStream(opdsAcquisitionType, feed), nil
+}
+
+func (h *OPDSHandler) listRecent(_ context.Context, _ *struct{}) (*huma.StreamResponse, error) {
+	books, err := h.books.ListRecent(opdsRecentLimit)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch recent books")
+	}
+	feed := h.newFeed("urn:bookshelf:opds:new", "New arrivals", h.apiURL("/opds/new"), opdsAcquisitionType)
+	feed.Entries = h.bookEntries(books)
+	return xmlStream(opdsAcquisitionType, feed), nil
+}
+
+func (h *OPDSHandler) openSearch(_ context.Context, _ *struct{}) (*huma.StreamResponse, error) {
+	doc := openSearchDescription{
+		Xmlns:       "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:   "Bookshelf",
+		Description: "Search the library catalog by title or author",
+		InputEnc:    "UTF-8",
+		OutputEnc:   "UTF-8",
+		URL: openSearchURL{
+			Type:     opdsAcquisitionType,
+			Template: h.apiURL("/opds/books") + "?q={searchTerms}",
+		},
+		Query: openSearchQuery{Role: "example", SearchTerms: "tolkien"},
+	}
+	return xmlStream(openSearchType, doc), nil
+}
+
+// --- Helpers ---
+
+// apiURL returns the public URL of a backend path, as reached through the
+// frontend's /api proxy.
+func (h *OPDSHandler) apiURL(path string) string {
+	return h.frontendOrigin + "/api" + path
+}
+
+// booksPageURL returns the public URL of one page of /opds/books.
+func (h *OPDSHandler) booksPageURL(q string, page int) string {
+	params := url.Values{}
+	if q != "" {
+		params.Set("q", q)
+	}
+	if page > 1 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	if len(params) == 0 {
+		return h.apiURL("/opds/books")
+	}
+	return h.apiURL("/opds/books") + "?" + params.Encode()
+}
+
+// newFeed returns a feed with the links every OPDS feed here carries: self,
+// start (the root navigation feed) and search.
+func (h *OPDSHandler) newFeed(id, title, selfURL, selfType string) atomFeed {
+	return atomFeed{
+		Xmlns:           "http://www.w3.org/2005/Atom",
+		XmlnsDC:         "http://purl.org/dc/terms/",
+		XmlnsOpenSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		XmlnsOPDS:       "http://opds-spec.org/2010/catalog",
+		ID:              id,
+		Title:           title,
+		Updated:         time.Now().UTC().Format(time.RFC3339),
+		Author:          atomAuthor{Name: "Bookshelf"},
+		Links: []atomLink{
+			{Rel: "self", Href: selfURL, Type: selfType},
+			{Rel: "start", Href: h.apiURL("/opds"), Type: opdsNavigationType},
+			{Rel: "search", Href: h.apiURL("/opds/opensearch.xml"), Type: openSearchType},
+		},
+	}
+}
+
+// paginationLinks returns first/previous/next/last links for page of
+// totalPages. A catalog with a single page gets none.
+func (h *OPDSHandler) paginationLinks(q string, page, totalPages int) []atomLink {
+	if totalPages <= 1 {
+		return nil
+	}
+	links := []atomLink{{Rel: "first", Href: h.booksPageURL(q, 1), Type: opdsAcquisitionType}}
+	if page > 1 {
+		links = append(links, atomLink{Rel: "previous", Href: h.booksPageURL(q, page-1), Type: opdsAcquisitionType})
+	}
+	if page < totalPages {
+		links = append(links, atomLink{Rel: "next", Href: h.booksPageURL(q, page+1), Type: opdsAcquisitionType})
+	}
+	return append(links, atomLink{Rel: "last", Href: h.booksPageURL(q, totalPages), Type: opdsAcquisitionType})
+}
+
+// bookEntries renders books as OPDS acquisition entries. Copies here are
+// physical, so there's no file to download: the acquisition link is a
+// "borrow" pointing at the book's web page, where a member requests a copy.
+func (h *OPDSHandler) bookEntries(books []models.Book) []atomEntry {
+	entries := make([]atomEntry, len(books))
+	for i, b := range books {
+		added := b.CreatedAt.UTC().Format(time.RFC3339)
+		page := h.frontendOrigin + "/catalog/" + strconv.FormatUint(uint64(b.ID), 10)
+		e := atomEntry{
+			ID:        "urn:bookshelf:book:" + strconv.FormatUint(uint64(b.ID), 10),
+			Title:     b.Title,
+			Updated:   added,
+			Published: added,
+			Language:  b.Language,
+			Publisher: b.Publisher,
+			Issued:    b.PublishedDate,
+			Links: []atomLink{
+				{Rel: "http://opds-spec.org/acquisition/borrow", Href: page, Type: "text/html", Title: "Request a copy"},
+				{Rel: "alternate", Href: page, Type: "text/html"},
+			},
+		}
+		if b.Author != "" {
+			e.Authors = []atomAuthor{{Name: b.Author}}
+		}
+		if b.ISBN != "" {
+			e.Identifier = "urn:isbn:" + b.ISBN
+		}
+		if b.Description != "" {
+			e.Summary = &atomText{Type: "text", Value: b.Description}
+		}
+		cover := h.coverURL(b)
+		e.Links = append(e.Links,
+			atomLink{Rel: "http://opds-spec.org/image", Href: cover},
+			atomLink{Rel: "http://opds-spec.org/image/thumbnail", Href: cover},
+		)
+		entries[i] = e
+	}
+	return entries
+}
+
+// coverURL returns the absolute URL of b's cover.
+func (h *OPDSHandler) coverURL(b models.Book) string {
+	return publicCoverURL(h.frontendOrigin, b)
+}
+
+// publicCoverURL returns the absolute URL of b's cover. A locally cached
+// cover is stored as a frontend-relative "/api/covers/..." path (see
+// downloadCover). Anything else — an external URL not cached yet, or no
+// cover at all — goes through the book's own cover URL (see
+// covers.BookHandler), which redirects only to an allowlisted host and
+// otherwise serves a generated placeholder, so feed readers are never sent
+// to a URL a member typed in and always have an image to show.
+func publicCoverURL(frontendOrigin string, b models.Book) string {
+	if strings.HasPrefix(b.CoverURL, "/api/covers/") {
+		return frontendOrigin + b.CoverURL
+	}
+	return frontendOrigin + "/api/covers/books/" + strconv.FormatUint(uint64(b.ID), 10)
+}
+
+// xmlStream returns a StreamResponse writing v as an XML document with the
+// given content type.
+func xmlStream(contentType string, v any) *huma.StreamResponse {
+	return &huma.StreamResponse{
+		Body: func(sctx huma.Context) {
+			sctx.SetHeader("Content-Type", contentType)
+			_ = encodeXML(sctx.BodyWriter(), v)
+		},
+	}
+}
+
+// encodeXML writes v to w as an indented XML document with a declaration.
+func encodeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func newOPDSHandler(t *testing.T, titles ...string) (*OPDSHandler, []models.Book) {
+	t.Helper()
+	books := repotest.NewBookRepository()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	created := make([]models.Book, len(titles))
+	for i, title := range titles {
+		b := models.Book{Title: title, Author: "Author " + title, ISBN: fmt.Sprintf("97800000%05d", i), CoverURL: "/api/covers/abc.jpg"}
+		require.NoError(t, books.Create(&b))
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, Status: "available"}))
+		created[i] = b
+	}
+	return NewOPDSHandler(books, "https://books.example.org/"), created
+}
+
+// renderStream runs a StreamResponse body and returns the recorded response.
+func renderStream(t *testing.T, resp *huma.StreamResponse) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	resp.Body(humatest.NewContext(&huma.Operation{}, req, rec))
+	return rec
+}
+
+func decodeFeed(t *testing.T, rec *httptest.ResponseRecorder) atomFeed {
+	t.Helper()
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &feed))
+	return feed
+}
+
+func linkHref(links []atomLink, rel string) string {
+	for _, l := range links {
+		if l.Rel == rel {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+func TestOPDS_RootIsNavigationFeed(t *testing.T) {
+	h, _ := newOPDSHandler(t)
+
+	resp, err := h.root(context.Background(), &struct{}{})
+	require.NoError(t, err)
+	rec := renderStream(t, resp)
+
+	assert.Equal(t, opdsNavigationType, rec.Header().Get("Content-Type"))
+	feed := decodeFeed(t, rec)
+	assert.Equal(t, "https://books.example.org/api/opds/opensearch.xml", linkHref(feed.Links, "search"))
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "https://books.example.org/api/opds/new", linkHref(feed.Entries[0].Links, "subsection"))
+}
+
+func TestOPDS_BookEntriesLinkCoverAndWebPage(t *testing.T) {
+	h, books := newOPDSHandler(t, "Dune")
+
+	resp, err := h.listBooks(context.Background(), &opdsBooksInput{})
+	require.NoError(t, err)
+	rec := renderStream(t, resp)
+
+	assert.Equal(t, opdsAcquisitionType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<dc:identifier>urn:isbn:9780000000000</dc:identifier>")
+	feed := decodeFeed(t, rec)
+	require.Len(t, feed.Entries, 1)
+	entry := feed.Entries[0]
+	assert.Equal(t, "Dune", entry.Title)
+	assert.Equal(t, "https://books.example.org/api/covers/abc.jpg", linkHref(entry.Links, "http://opds-spec.org/image"))
+	assert.Equal(t, "https://books.example.org/catalog/"+fmt.Sprint(books[0].ID), linkHref(entry.Links, "alternate"))
+	assert.Empty(t, linkHref(feed.Links, "next"), "a single page has no pagination links")
+}
+
+func TestOPDS_Pagination(t *testing.T) {
+	titles := make([]string, opdsPageSize+5)
+	for i := range titles {
+		titles[i] = "Book " + fmt.Sprint(i)
+	}
+	h, _ := newOPDSHandler(t, titles...)
+
+	resp, err := h.listBooks(context.Background(), &opdsBooksInput{Page: 2})
+	require.NoError(t, err)
+	feed := decodeFeed(t, renderStream(t, resp))
+
+	assert.Len(t, feed.Entries, 5)
+	assert.Equal(t, "https://books.example.org/api/opds/books", linkHref(feed.Links, "previous"))
+	assert.Empty(t, linkHref(feed.Links, "next"))
+	assert.Equal(t, "https://books.example.org/api/opds/books?page=2", linkHref(feed.Links, "last"))
+}
+
+func TestOPDS_SearchCarriesQueryIntoLinks(t *testing.T) {
+	titles := make([]string, opdsPageSize+1)
+	for i := range titles {
+		titles[i] = "Hobbit " + fmt.Sprint(i)
+	}
+	h, _ := newOPDSHandler(t, titles...)
+
+	resp, err := h.listBooks(context.Background(), &opdsBooksInput{Q: "hobbit"})
+	require.NoError(t, err)
+	feed := decodeFeed(t, renderStream(t, resp))
+
+	assert.Equal(t, "https://books.example.org/api/opds/books?page=2&q=hobbit", linkHref(feed.Links, "next"))
+}
+
+func TestOPDS_OpenSearchTemplate(t *testing.T) {
+	h, _ := newOPDSHandler(t)
+
+	resp, err := h.openSearch(context.Background(), &struct{}{})
+	require.NoError(t, err)
+	rec := renderStream(t, resp)
+
+	var doc openSearchDescription
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, "https://books.example.org/api/opds/books?q={searchTerms}", doc.URL.Template)
+}
+
+func TestOPDS_CoverlessBookLinksItsPlaceholder(t *testing.T) {
+	h, books := newOPDSHandler(t, "Dune")
+	book := books[0]
+	book.CoverURL = ""
+	require.NoError(t, h.books.Save(&book))
+
+	resp, err := h.listBooks(context.Background(), &opdsBooksInput{})
+	require.NoError(t, err)
+	feed := decodeFeed(t, renderStream(t, resp))
+	require.Len(t, feed.Entries, 1)
+	assert.Equal(t, "https://books.example.org/api/covers/books/"+fmt.Sprint(book.ID), linkHref(feed.Entries[0].Links, "http://opds-spec.org/image"))
+}
+
+func TestOPDS_ExternalCoverGoesThroughTheBookCoverURL(t *testing.T) {
+	h, books := newOPDSHandler(t, "Dune")
+	book := books[0]
+	book.CoverURL = "https://attacker.example.com/track.png"
+	require.NoError(t, h.books.Save(&book))
+
+	resp, err := h.listBooks(context.Background(), &opdsBooksInput{})
+	require.NoError(t, err)
+	feed := decodeFeed(t, renderStream(t, resp))
+	require.Len(t, feed.Entries, 1)
+	assert.Equal(t, "https://books.example.org/api/covers/books/"+fmt.Sprint(book.ID), linkHref(feed.Entries[0].Links, "http://opds-spec.org/image"),
+		"where it may be redirected to only if its host is allowlisted")
+}
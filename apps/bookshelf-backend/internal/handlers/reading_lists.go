@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// ReadingListHandler holds dependencies for personal reading list routes.
+type ReadingListHandler struct {
+	lists repository.ReadingListRepository
+	books repository.BookRepository
+}
+
+// NewReadingListHandler creates a new ReadingListHandler.
+func NewReadingListHandler(lists repository.ReadingListRepository, books repository.BookRepository) *ReadingListHandler {
+	return &ReadingListHandler{lists: lists, books: books}
+}
+
+// --- Input / Output types ---
+
+type createReadingListInput struct {
+	Body struct {
+		Name        string `json:"name" required:"true" minLength:"1" maxLength:"100" doc:"List name, e.g. \"To read\""`
+		Description string `json:"description,omitempty" maxLength:"1000" doc:"Optional description"`
+		IsPublic    bool   `json:"is_public,omitempty" doc:"Let other members see this list (default private)"`
+	}
+}
+
+type updateReadingListInput struct {
+	ID   uint `path:"id" doc:"Reading list ID"`
+	Body struct {
+		Name        *string `json:"name,omitempty" minLength:"1" maxLength:"100" doc:"List name"`
+		Description *string `json:"description,omitempty" maxLength:"1000" doc:"Description"`
+		IsPublic    *bool   `json:"is_public,omitempty" doc:"Let other members see this list"`
+	}
+}
+
+type readingListIDInput struct {
+	ID uint `path:"id" doc:"Reading list ID"`
+}
+
+type listUserReadingListsInput struct {
+	ID uint `path:"id" doc:"User ID"`
+}
+
+type addReadingListEntryInput struct {
+	ID   uint `path:"id" doc:"Reading list ID"`
+	Body struct {
+		BookID uint   `json:"book_id" required:"true" doc:"Catalog book to add"`
+		Notes  string `json:"notes,omitempty" maxLength:"1000" doc:"Optional note about this book"`
+	}
+}
+
+type updateReadingListEntryInput struct {
+	ID     uint `path:"id" doc:"Reading list ID"`
+	BookID uint `path:"book_id" doc:"Book ID"`
+	Body   struct {
+		Notes string `json:"notes" maxLength:"1000" doc:"Note about this book (empty clears it)"`
+	}
+}
+
+type readingListEntryInput struct {
+	ID     uint `path:"id" doc:"Reading list ID"`
+	BookID uint `path:"book_id" doc:"Book ID"`
+}
+
+type reorderReadingListInput struct {
+	ID   uint `path:"id" doc:"Reading list ID"`
+	Body struct {
+		BookIDs []uint `json:"book_ids" required:"true" doc:"Every book on the list, in the new order"`
+	}
+}
+
+// readingListSummary is a list without its entries, as returned by the
+// list-of-lists routes.
+type readingListSummary struct {
+	ID          uint      `json:"id"`
+	OwnerID     uint      `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsPublic    bool      `json:"is_public"`
+	EntryCount  int64     `json:"entry_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// readingListEntryResponse is one book on a list, with whether any copy of
+// it can currently be requested.
+type readingListEntryResponse struct {
+	BookID    uint         `json:"book_id"`
+	Position  int          `json:"position"`
+	Notes     string       `json:"notes"`
+	AddedAt   time.Time    `json:"added_at"`
+	Available bool         `json:"available"`
+	Book      bookResponse `json:"book"`
+}
+
+type readingListResponse struct {
+	readingListSummary
+	Entries []readingListEntryResponse `json:"entries"`
+}
+
+type readingListOutput struct{ Body readingListResponse }
+
+type readingListsOutput struct{ Body []readingListSummary }
+
+type readingListEntryOutput struct{ Body readingListEntryResponse }
+
+// --- Route registration ---
+
+// RegisterRoutes registers all reading list routes on the given huma API.
+func (h *ReadingListHandler) RegisterRoutes(api huma.API) {
+	security := []map[string][]string{{"bearer": {}}}
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-reading-list",
+		Method:        "POST",
+		Path:          "/reading-lists",
+		Tags:          []string{"reading-lists"},
+		Summary:       "Create a personal reading list",
+		Security:      security,
+		DefaultStatus: 201,
+	}, h.create)
+
+	// Registered before the /reading-lists/{id} wildcard so this literal
+	// path isn't swallowed by it — same ordering care as wishlist.go.
+	huma.Register(api, huma.Operation{
+		OperationID: "list-my-reading-lists",
+		Method:      "GET",
+		Path:        "/reading-lists/mine",
+		Tags:        []string{"reading-lists"},
+		Summary:     "List the caller's reading lists, public and private",
+		Security:    security,
+	}, h.listMine)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-user-reading-lists",
+		Method:      "GET",
+		Path:        "/users/{id}/reading-lists",
+		Tags:        []string{"reading-lists"},
+		Summary:     "List a member's public reading lists",
+		Security:    security,
+	}, h.listForUser)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-reading-list",
+		Method:      "GET",
+		Path:        "/reading-lists/{id}",
+		Tags:        []string{"reading-lists"},
+		Summary:     "Get a reading list with its books",
+		Security:    security,
+	}, h.get)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-reading-list",
+		Method:      "PATCH",
+		Path:        "/reading-lists/{id}",
+		Tags:        []string{"reading-lists"},
+		Summary:     "Rename a reading list or change its description or visibility",
+		Security:    security,
+	}, h.update)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "delete-reading-list",
+		Method:        "DELETE",
+		Path:          "/reading-lists/{id}",
+		Tags:          []string{"reading-lists"},
+		Summary:       "Delete a reading list",
+		Security:      security,
+		DefaultStatus: 204,
+	}, h.delete)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "add-reading-list-entry",
+		Method:        "POST",
+		Path:          "/reading-lists/{id}/entries",
+		Tags:          []string{"reading-lists"},
+		Summary:       "Add a catalog book to the end of a reading list",
+		Security:      security,
+		DefaultStatus: 201,
+	}, h.addEntry)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "reorder-reading-list",
+		Method:      "POST",
+		Path:        "/reading-lists/{id}/reorder",
+		Tags:        []string{"reading-lists"},
+		Summary:     "Reorder a reading list's books",
+		Security:    security,
+	}, h.reorder)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-reading-list-entry",
+		Method:      "PATCH",
+		Path:        "/reading-lists/{id}/entries/{book_id}",
+		Tags:        []string{"reading-lists"},
+		Summary:     "Edit the note on a reading list entry",
+		Security:    security,
+	}, h.updateEntry)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "remove-reading-list-entry",
+		Method:        "DELETE",
+		Path:          "/reading-lists/{id}/entries/{book_id}",
+		Tags:          []string{"reading-lists"},
+		Summary:       "Remove a book from a reading list",
+		Security:      security,
+		DefaultStatus: 204,
+	}, h.removeEntry)
+}
+
+// --- Handlers ---
+
+func (h *ReadingListHandler) create(ctx context.Context, input *createReadingListInput) (*readingListOutput, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	name := strings.TrimSpace(input.Body.Name)
+	if name == "" {
+		return nil, huma.Error400BadRequest("name must not be blank")
+	}
+	l := &models.ReadingList{
+		OwnerID:     userID,
+		Name:        name,
+		Description: input.Body.Description,
+		IsPublic:    input.Body.IsPublic,
+	}
+	if err := h.lists.Create(l); err != nil {
+		return nil, huma.Error500InternalServerError("could not create reading list")
+	}
+	return &readingListOutput{Body: readingListResponse{
+		readingListSummary: toReadingListSummary(*l, 0),
+		Entries:            []readingListEntryResponse{},
+	}}, nil
+}
+
+func (h *ReadingListHandler) listMine(ctx context.Context, _ *struct{}) (*readingListsOutput, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	return h.summaries(userID, false)
+}
+
+func (h *ReadingListHandler) listForUser(ctx context.Context, input *listUserReadingListsInput) (*readingListsOutput, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	publicOnly := input.ID != userID && middleware.GetUserRole(ctx) != "admin"
+	return h.summaries(input.ID, publicOnly)
+}
+
+func (h *ReadingListHandler) get(ctx context.Context, input *readingListIDInput) (*readingListOutput, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	l, err := h.fetchList(input.ID)
+	if err != nil {
+		return nil, err
+	}
+	// A private list is reported as missing rather than forbidden, so its
+	// existence isn't revealed to anyone it isn't shared with.
+	if !l.IsPublic && l.OwnerID != userID && middleware.GetUserRole(ctx) != "admin" {
+		return nil, huma.Error404NotFound("reading list not found")
+	}
+	resp, err := h.toReadingListResponse(l)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+	return &readingListOutput{Body: resp}, nil
+}
+
+func (h *ReadingListHandler) update(ctx context.Context, input *updateReadingListInput) (*readingListOutput, error) {
+	l, err := h.ownedList(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Body.Name != nil {
+		name := strings.TrimSpace(*input.Body.Name)
+		if name == "" {
+			return nil, huma.Error400BadRequest("name must not be blank")
+		}
+		l.Name = name
+	}
+	if input.Body.Description != nil {
+		l.Description = *input.Body.Description
+	}
+	if input.Body.IsPublic != nil {
+		l.IsPublic = *input.Body.IsPublic
+	}
+	if err := h.lists.Save(l); err != nil {
+		return nil, huma.Error500InternalServerError("could not update reading list")
+	}
+	resp, err := h.toReadingListResponse(l)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+	return &readingListOutput{Body: resp}, nil
+}
+
+func (h *ReadingListHandler) delete(ctx context.Context, input *readingListIDInput) (*struct{}, error) {
+	if _, err := h.ownedList(ctx, input.ID); err != nil {
+		return nil, err
+	}
+	if err := h.lists.Delete(input.ID); err != nil {
+		return nil, huma.Error500InternalServerError("could not delete reading list")
+	}
+	return nil, nil
+}
+
+func (h *ReadingListHandler) addEntry(ctx context.Context, input *addReadingListEntryInput) (*readingListEntryOutput, error) {
+	l, err := h.ownedList(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	book, err := h.books.GetByIDWithCopies(input.Body.BookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("book not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch book")
+	}
+	book.Copies = nil
+
+	entry := &models.ReadingListEntry{ReadingListID: l.ID, BookID: book.ID, Notes: input.Body.Notes}
+	if err := h.lists.AddEntry(entry); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, huma.Error409Conflict("book is already on this list")
+		}
+		return nil, huma.Error500InternalServerError("could not add book to reading list")
+	}
+	entry.Book = *book
+
+	resp, err := h.toEntryResponses([]models.ReadingListEntry{*entry})
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+	return &readingListEntryOutput{Body: resp[0]}, nil
+}
+
+func (h *ReadingListHandler) updateEntry(ctx context.Context, input *updateReadingListEntryInput) (*readingListEntryOutput, error) {
+	if _, err := h.ownedList(ctx, input.ID); err != nil {
+		return nil, err
+	}
+	entry, err := h.lists.FindEntry(input.ID, input.BookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("book is not on this list")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch reading list entry")
+	}
+	entry.Notes = input.Body.Notes
+	if err := h.lists.SaveEntry(entry); err != nil {
+		return nil, huma.Error500InternalServerError("could not update reading list entry")
+	}
+	if book, err := h.books.GetByIDWithCopies(entry.BookID); err == nil {
+		book.Copies = nil
+		entry.Book = *book
+	}
+	resp, err := h.toEntryResponses([]models.ReadingListEntry{*entry})
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+	return &readingListEntryOutput{Body: resp[0]}, nil
+}
+
+func (h *ReadingListHandler) removeEntry(ctx context.Context, input *readingListEntryInput) (*struct{}, error) {
+	if _, err := h.ownedList(ctx, input.ID); err != nil {
+		return nil, err
+	}
+	if err := h.lists.RemoveEntry(input.ID, input.BookID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("book is not on this list")
+		}
+		return nil, huma.Error500InternalServerError("could not remove book from reading list")
+	}
+	return nil, nil
+}
+
+func (h *ReadingListHandler) reorder(ctx context.Context, input *reorderReadingListInput) (*readingListOutput, error) {
+	l, err := h.ownedList(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameBookSet(l.Entries, input.Body.BookIDs) {
+		return nil, huma.Error400BadRequest("book_ids must list every book on the list exactly once")
+	}
+	if err := h.lists.Reorder(l.ID, input.Body.BookIDs); err != nil {
+		return nil, huma.Error500InternalServerError("could not reorder reading list")
+	}
+	l, err = h.fetchList(l.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.toReadingListResponse(l)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+	return &readingListOutput{Body: resp}, nil
+}
+
+// --- Helpers ---
+
+// fetchList loads a list with its entries, mapping repository errors to
+// API errors.
+func (h *ReadingListHandler) fetchList(id uint) (*models.ReadingList, error) {
+	l, err := h.lists.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("reading list not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch reading list")
+	}
+	return l, nil
+}
+
+// ownedList loads a list the caller is allowed to modify — only its owner
+// can, admins included: a reading list is personal, not community content
+// needing moderation. Someone else's list is reported as missing, same as
+// get does for a private one.
+func (h *ReadingListHandler) ownedList(ctx context.Context, id uint) (*models.ReadingList, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	l, err := h.fetchList(id)
+	if err != nil {
+		return nil, err
+	}
+	if l.OwnerID != userID {
+		if l.IsPublic {
+			return nil, huma.Error403Forbidden("only the list's owner can change it")
+		}
+		return nil, huma.Error404NotFound("reading list not found")
+	}
+	return l, nil
+}
+
+func (h *ReadingListHandler) summaries(ownerID uint, publicOnly bool) (*readingListsOutput, error) {
+	lists, err := h.lists.ListByOwnerID(ownerID, publicOnly)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not list reading lists")
+	}
+	ids := make([]uint, len(lists))
+	for i, l := range lists {
+		ids[i] = l.ID
+	}
+	counts, err := h.lists.CountEntriesBatch(ids)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not count reading list entries")
+	}
+	out := make([]readingListSummary, len(lists))
+	for i, l := range lists {
+		out[i] = toReadingListSummary(l, counts[l.ID])
+	}
+	return &readingListsOutput{Body: out}, nil
+}
+
+func toReadingListSummary(l models.ReadingList, entryCount int64) readingListSummary {
+	return readingListSummary{
+		ID:          l.ID,
+		OwnerID:     l.OwnerID,
+		Name:        l.Name,
+		Description: l.Description,
+		IsPublic:    l.IsPublic,
+		EntryCount:  entryCount,
+		CreatedAt:   l.CreatedAt,
+		UpdatedAt:   l.UpdatedAt,
+	}
+}
+
+func (h *ReadingListHandler) toReadingListResponse(l *models.ReadingList) (readingListResponse, error) {
+	entries, err := h.toEntryResponses(l.Entries)
+	if err != nil {
+		return readingListResponse{}, err
+	}
+	return readingListResponse{
+		readingListSummary: toReadingListSummary(*l, int64(len(entries))),
+		Entries:            entries,
+	}, nil
+}
+
+// toEntryResponses attaches available-copy counts to entries in one batch
+// query. BookRepository.Delete removes a book's entries with it, but one
+// left from before it did preloads as a zero Book and is dropped rather
+// than shown as a blank row.
+func (h *ReadingListHandler) toEntryResponses(entries []models.ReadingListEntry) ([]readingListEntryResponse, error) {
+	var live []models.ReadingListEntry
+	books := make([]models.Book, 0, len(entries))
+	for _, e := range entries {
+		if e.Book.ID == 0 {
+			continue
+		}
+		live = append(live, e)
+		books = append(books, e.Book)
+	}
+	withCounts, err := booksWithAvailability(h.books, books)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]readingListEntryResponse, len(live))
+	for i, e := range live {
+		out[i] = readingListEntryResponse{
+			BookID:    e.BookID,
+			Position:  e.Position,
+			Notes:     e.Notes,
+			AddedAt:   e.CreatedAt,
+			Available: withCounts[i].AvailableCopies > 0,
+			Book:      withCounts[i],
+		}
+	}
+	return out, nil
+}
+
+// sameBookSet reports whether bookIDs names exactly the books in entries,
+// each once. Entries whose Book is gone are left out, as toEntryResponses
+// leaves them out of what the client was shown.
+func sameBookSet(entries []models.ReadingListEntry, bookIDs []uint) bool {
+	want := make(map[uint]bool, len(entries))
+	for _, e := range entries {
+		if e.Book.ID != 0 {
+			want[e.BookID] = true
+		}
+	}
+	if len(want) != len(bookIDs) {
+		return false
+	}
+	for _, id := range bookIDs {
+		if !want[id] {
+			return false
+		}
+		delete(want, id)
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func newReadingListHandler() (*ReadingListHandler, *repotest.BookRepository, *repotest.CopyRepository) {
+	books := repotest.NewBookRepository()
+	copies := repotest.NewCopyRepository()
+	books.SetCopies(copies)
+	return NewReadingListHandler(repotest.NewReadingListRepository(books), books), books, copies
+}
+
+func createReadingList(t *testing.T, h *ReadingListHandler, ctx context.Context, name string, public bool) readingListResponse {
+	t.Helper()
+	input := &createReadingListInput{}
+	input.Body.Name = name
+	input.Body.IsPublic = public
+	out, err := h.create(ctx, input)
+	require.NoError(t, err)
+	return out.Body
+}
+
+func addReadingListEntry(t *testing.T, h *ReadingListHandler, ctx context.Context, listID, bookID uint) readingListEntryResponse {
+	t.Helper()
+	input := &addReadingListEntryInput{ID: listID}
+	input.Body.BookID = bookID
+	out, err := h.addEntry(ctx, input)
+	require.NoError(t, err)
+	return out.Body
+}
+
+func TestReadingList_AddEntryReportsAvailability(t *testing.T) {
+	h, books, copies := newReadingListHandler()
+	ctx := fakeAuthedCtx(t, 1, "user")
+	onShelf := models.Book{Title: "On Shelf", Author: "A"}
+	loanedOut := models.Book{Title: "Loaned Out", Author: "B"}
+	require.NoError(t, books.Create(&onShelf))
+	require.NoError(t, books.Create(&loanedOut))
+	require.NoError(t, copies.Create(&models.Copy{BookID: onShelf.ID, OwnerID: 2, Status: "available"}))
+	require.NoError(t, copies.Create(&models.Copy{BookID: loanedOut.ID, OwnerID: 2, Status: "loaned"}))
+	list := createReadingList(t, h, ctx, "To read", false)
+
+	first := addReadingListEntry(t, h, ctx, list.ID, onShelf.ID)
+	second := addReadingListEntry(t, h, ctx, list.ID, loanedOut.ID)
+
+	assert.True(t, first.Available)
+	assert.Equal(t, int64(1), first.Book.AvailableCopies)
+	assert.False(t, second.Available)
+	assert.Equal(t, 0, first.Position)
+	assert.Equal(t, 1, second.Position)
+}
+
+func TestReadingList_AddDuplicateBookConflicts(t *testing.T) {
+	h, books, _ := newReadingListHandler()
+	ctx := fakeAuthedCtx(t, 1, "user")
+	book := models.Book{Title: "T", Author: "A"}
+	require.NoError(t, books.Create(&book))
+	list := createReadingList(t, h, ctx, "Favourites", false)
+	addReadingListEntry(t, h, ctx, list.ID, book.ID)
+
+	input := &addReadingListEntryInput{ID: list.ID}
+	input.Body.BookID = book.ID
+	_, err := h.addEntry(ctx, input)
+
+	assertStatus(t, err, http.StatusConflict)
+}
+
+func TestReadingList_PrivateListHiddenFromOthers(t *testing.T) {
+	h, _, _ := newReadingListHandler()
+	list := createReadingList(t, h, fakeAuthedCtx(t, 1, "user"), "Secret", false)
+
+	_, err := h.get(fakeAuthedCtx(t, 2, "user"), &readingListIDInput{ID: list.ID})
+	assertStatus(t, err, http.StatusNotFound)
+
+	_, err = h.get(fakeAuthedCtx(t, 3, "admin"), &readingListIDInput{ID: list.ID})
+	assert.NoError(t, err, "admins can still see private lists")
+}
+
+func TestReadingList_PublicListReadableButNotEditableByOthers(t *testing.T) {
+	h, _, _ := newReadingListHandler()
+	list := createReadingList(t, h, fakeAuthedCtx(t, 1, "user"), "Recommended", true)
+	other := fakeAuthedCtx(t, 2, "user")
+
+	_, err := h.get(other, &readingListIDInput{ID: list.ID})
+	require.NoError(t, err)
+
+	_, err = h.delete(other, &readingListIDInput{ID: list.ID})
+	assertStatus(t, err, http.StatusForbidden)
+}
+
+func TestReadingList_ListForUserOnlyShowsPublicListsToOthers(t *testing.T) {
+	h, _, _ := newReadingListHandler()
+	owner := fakeAuthedCtx(t, 1, "user")
+	createReadingList(t, h, owner, "Public", true)
+	createReadingList(t, h, owner, "Private", false)
+
+	others, err := h.listForUser(fakeAuthedCtx(t, 2, "user"), &listUserReadingListsInput{ID: 1})
+	require.NoError(t, err)
+	require.Len(t, others.Body, 1)
+	assert.Equal(t, "Public", others.Body[0].Name)
+
+	mine, err := h.listMine(owner, &struct{}{})
+	require.NoError(t, err)
+	assert.Len(t, mine.Body, 2)
+}
+
+func TestReadingList_Reorder(t *testing.T) {
+	h, books, _ := newReadingListHandler()
+	ctx := fakeAuthedCtx(t, 1, "user")
+	a := models.Book{Title: "A", Author: "X"}
+	b := models.Book{Title: "B", Author: "X"}
+	require.NoError(t, books.Create(&a))
+	require.NoError(t, books.Create(&b))
+	list := createReadingList(t, h, ctx, "Queue", false)
+	addReadingListEntry(t, h, ctx, list.ID, a.ID)
+	addReadingListEntry(t, h, ctx, list.ID, b.ID)
+
+	t.Run("rejects an order that doesn't name every book", func(t *testing.T) {
+		input := &reorderReadingListInput{ID: list.ID}
+		input.Body.BookIDs = []uint{b.ID}
+		_, err := h.reorder(ctx, input)
+		assertStatus(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("applies a full permutation", func(t *testing.T) {
+		input := &reorderReadingListInput{ID: list.ID}
+		input.Body.BookIDs = []uint{b.ID, a.ID}
+		out, err := h.reorder(ctx, input)
+		require.NoError(t, err)
+		require.Len(t, out.Body.Entries, 2)
+		assert.Equal(t, b.ID, out.Body.Entries[0].BookID)
+		assert.Equal(t, a.ID, out.Body.Entries[1].BookID)
+	})
+
+	t.Run("ignores an entry whose book is gone, as the list does", func(t *testing.T) {
+		c := models.Book{Title: "C", Author: "X"}
+		require.NoError(t, books.Create(&c))
+		addReadingListEntry(t, h, ctx, list.ID, c.ID)
+		require.NoError(t, books.Delete(&c))
+
+		input := &reorderReadingListInput{ID: list.ID}
+		input.Body.BookIDs = []uint{a.ID, b.ID}
+		out, err := h.reorder(ctx, input)
+		require.NoError(t, err)
+		require.Len(t, out.Body.Entries, 2)
+		assert.Equal(t, a.ID, out.Body.Entries[0].BookID)
+
+		input.Body.BookIDs = []uint{a.ID, b.ID, c.ID}
+		_, err = h.reorder(ctx, input)
+		assertStatus(t, err, http.StatusBadRequest)
+	})
+}
+
+func TestReadingList_RemoveEntryNotOnListReturns404(t *testing.T) {
+	h, _, _ := newReadingListHandler()
+	ctx := fakeAuthedCtx(t, 1, "user")
+	list := createReadingList(t, h, ctx, "Empty", false)
+
+	_, err := h.removeEntry(ctx, &readingListEntryInput{ID: list.ID, BookID: 42})
+
+	assertStatus(t, err, http.StatusNotFound)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/middleware"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// RecommendationHandler holds dependencies for the "borrowed together" and
+// "you might like" routes. Both lists are precomputed by the recommendations
+// scheduler job (internal/services/recommendations.go); these routes only
+// read them back.
+type RecommendationHandler struct {
+	books repository.BookRepository
+	recs  repository.RecommendationRepository
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler.
+func NewRecommendationHandler(books repository.BookRepository, recs repository.RecommendationRepository) *RecommendationHandler {
+	return &RecommendationHandler{books: books, recs: recs}
+}
+
+// --- Input / Output types ---
+
+type listRelatedBooksInput struct {
+	ID    uint `path:"id" doc:"Book ID"`
+	Limit int  `query:"limit" minimum:"1" maximum:"20" doc:"Max books to return (default 8)"`
+}
+
+type listRecommendedBooksInput struct {
+	Limit int `query:"limit" minimum:"1" maximum:"20" doc:"Max books to return (default 8)"`
+}
+
+type recommendedBooksOutput struct{ Body []bookResponse }
+
+// --- Route registration ---
+
+// RegisterRoutes registers all recommendation routes on the given huma API.
+func (h *RecommendationHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-recommended-books",
+		Method:      "GET",
+		Path:        "/books/recommended",
+		Tags:        []string{"books"},
+		Summary:     "List books the caller might like, based on what members with similar borrowing history borrowed",
+		Security:    []map[string][]string{{"bearer": {}}},
+	}, h.listRecommended)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-related-books",
+		Method:      "GET",
+		Path:        "/books/{id}/related",
+		Tags:        []string{"books"},
+		Summary:     "List books most often borrowed by members who also borrowed this one",
+	}, h.listRelated)
+}
+
+// --- Handlers ---
+
+func (h *RecommendationHandler) listRelated(_ context.Context, input *listRelatedBooksInput) (*recommendedBooksOutput, error) {
+	if _, err := h.books.GetByIDWithCopies(input.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("book not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch book")
+	}
+
+	books, err := h.recs.ListRelated(input.ID, recommendationLimit(input.Limit))
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch related books")
+	}
+	resp, err := booksWithAvailability(h.books, books)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+	return &recommendedBooksOutput{Body: resp}, nil
+}
+
+func (h *RecommendationHandler) listRecommended(ctx context.Context, input *listRecommendedBooksInput) (*recommendedBooksOutput, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+
+	books, err := h.recs.ListForUser(userID, recommendationLimit(input.Limit))
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch recommendations")
+	}
+	resp, err := booksWithAvailability(h.books, books)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch book counts")
+	}
+	return &recommendedBooksOutput{Body: resp}, nil
+}
+
+// recommendationLimit applies the default list length when limit is unset.
+func recommendationLimit(limit int) int {
+	if limit < 1 {
+		return 8
+	}
+	return limit
+}
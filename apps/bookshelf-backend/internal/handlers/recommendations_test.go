@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func newRecommendationHandler() (*RecommendationHandler, *repotest.BookRepository, *repotest.RecommendationRepository) {
+	books := repotest.NewBookRepository()
+	recs := repotest.NewRecommendationRepository(books)
+	return NewRecommendationHandler(books, recs), books, recs
+}
+
+func TestListRelated_ReturnsRelatedBooksByScore(t *testing.T) {
+	h, books, recs := newRecommendationHandler()
+	target := models.Book{Title: "Target", Author: "A"}
+	weak := models.Book{Title: "Weak", Author: "B"}
+	strong := models.Book{Title: "Strong", Author: "C"}
+	require.NoError(t, books.Create(&target))
+	require.NoError(t, books.Create(&weak))
+	require.NoError(t, books.Create(&strong))
+	require.NoError(t, recs.ReplaceAll([]models.BookAffinity{
+		{BookID: target.ID, RelatedBookID: weak.ID, Score: 1},
+		{BookID: target.ID, RelatedBookID: strong.ID, Score: 3},
+	}, nil))
+
+	out, err := h.listRelated(context.Background(), &listRelatedBooksInput{ID: target.ID})
+
+	require.NoError(t, err)
+	require.Len(t, out.Body, 2)
+	assert.Equal(t, strong.ID, out.Body[0].ID)
+	assert.Equal(t, weak.ID, out.Body[1].ID)
+}
+
+func TestListRelated_UnknownBookReturns404(t *testing.T) {
+	h, _, _ := newRecommendationHandler()
+
+	_, err := h.listRelated(context.Background(), &listRelatedBooksInput{ID: 999})
+
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestListRecommended_RequiresAuth(t *testing.T) {
+	h, _, _ := newRecommendationHandler()
+
+	_, err := h.listRecommended(fakeAuthedCtxNone(), &listRecommendedBooksInput{})
+
+	assertStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestListRecommended_ReturnsOnlyCallersRecommendations(t *testing.T) {
+	h, books, recs := newRecommendationHandler()
+	mine := models.Book{Title: "Mine", Author: "A"}
+	theirs := models.Book{Title: "Theirs", Author: "B"}
+	require.NoError(t, books.Create(&mine))
+	require.NoError(t, books.Create(&theirs))
+	require.NoError(t, recs.ReplaceAll(nil, []models.UserRecommendation{
+		{UserID: 1, BookID: mine.ID, Score: 1},
+		{UserID: 2, BookID: theirs.ID, Score: 5},
+	}))
+
+	out, err := h.listRecommended(fakeAuthedCtx(t, 1, "user"), &listRecommendedBooksInput{})
+
+	require.NoError(t, err)
+	require.Len(t, out.Body, 1)
+	assert.Equal(t, mine.ID, out.Body[0].ID)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -66,6 +67,10 @@ type wishlistResponse struct {
 	CreatedAt       time.Time    `json:"created_at"`
 	Requester       safeUser     `json:"requester"`
 	FulfilledBook   *models.Book `json:"fulfilled_book,omitempty"`
+	// RequesterCount is the requester plus everyone who joined the request.
+	RequesterCount int `json:"requester_count"`
+	// Joined reports whether the viewer is one of the co-requesters.
+	Joined bool `json:"joined"`
 }
 
 // toWishlistResponse redacts the requester's name when the request is
@@ -76,6 +81,9 @@ func toWishlistResponse(req models.WishlistRequest, viewerID uint, isAdmin bool)
 	if req.IsAnonymous && req.RequesterID != viewerID && !isAdmin {
 		requester = safeUser{Name: "Anonymous member"}
 	}
+	joined := slices.ContainsFunc(req.CoRequesters, func(co models.WishlistCoRequester) bool {
+		return co.UserID == viewerID
+	})
 	return wishlistResponse{
 		ID:              req.ID,
 		RequesterID:     req.RequesterID,
@@ -93,6 +101,8 @@ func toWishlistResponse(req models.WishlistRequest, viewerID uint, isAdmin bool)
 		CreatedAt:       req.CreatedAt,
 		Requester:       requester,
 		FulfilledBook:   req.FulfilledBook,
+		RequesterCount:  1 + len(req.CoRequesters),
+		Joined:          joined,
 	}
 }
 
@@ -102,6 +112,7 @@ type listWishlistInput struct {
 	Q        string `query:"q" doc:"Search by title or author"`
 	Page     int    `query:"page" minimum:"1" doc:"Page number (default 1)"`
 	PageSize int    `query:"page_size" minimum:"1" maximum:"100" doc:"Items per page (default 20)"`
+	Cursor   string `query:"cursor" doc:"Continue after a previous response's next_cursor instead of using page; total, page and total_pages are then 0"`
 }
 
 type listWishlistOutput struct {
@@ -111,6 +122,7 @@ type listWishlistOutput struct {
 		Page       int                `json:"page"`
 		PageSize   int                `json:"page_size"`
 		TotalPages int                `json:"total_pages"`
+		NextCursor string             `json:"next_cursor,omitempty" doc:"Pass as cursor to fetch the next page; absent on the last page"`
 	}
 }
 
@@ -216,6 +228,25 @@ func (h *WishlistHandler) RegisterRoutes(api huma.API) {
 		Summary:     "Manually link an open wishlist request to an existing catalog book",
 		Security:    security,
 	}, h.fulfill)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "join-wishlist-request",
+		Method:      "POST",
+		Path:        "/wishlist/{id}/join",
+		Tags:        []string{"wishlist"},
+		Summary:     "Join another member's open wishlist request, to be notified too when it's fulfilled",
+		Security:    security,
+	}, h.join)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "leave-wishlist-request",
+		Method:        "DELETE",
+		Path:          "/wishlist/{id}/join",
+		Tags:          []string{"wishlist"},
+		Summary:       "Leave a wishlist request the caller joined",
+		Security:      security,
+		DefaultStatus: 204,
+	}, h.leave)
 }
 
 // --- Handlers ---
@@ -264,9 +295,15 @@ func (h *WishlistHandler) list(ctx context.Context, input *listWishlistInput) (*
 		pageSize = 20
 	}
 
-	result, err := h.requests.ListOpenPaginated(input.Q, page, pageSize)
+	result, err := fetchListPage("wishlist", input.Cursor, page, pageSize, "could not list wishlist requests",
+		func(page, pageSize int) (*repository.PaginatedResult[models.WishlistRequest], error) {
+			return h.requests.ListOpenPaginated(input.Q, page, pageSize)
+		},
+		func(after *repository.Cursor, limit int) (*repository.CursorResult[models.WishlistRequest], error) {
+			return h.requests.ListOpenAfter(input.Q, after, limit)
+		})
 	if err != nil {
-		return nil, huma.Error500InternalServerError("could not list wishlist requests")
+		return nil, err
 	}
 	var out listWishlistOutput
 	out.Body.Items = make([]wishlistResponse, len(result.Items))
@@ -277,6 +314,7 @@ func (h *WishlistHandler) list(ctx context.Context, input *listWishlistInput) (*
 	out.Body.Page = result.Page
 	out.Body.PageSize = result.PageSize
 	out.Body.TotalPages = result.TotalPages
+	out.Body.NextCursor = result.NextCursor
 	return &out, nil
 }
 
@@ -354,6 +392,16 @@ func (h *WishlistHandler) cancel(ctx context.Context, input *wishlistIDInput) (*
 		return nil, huma.Error400BadRequest("only an open request can be cancelled")
 	}
 
+	// A requester withdrawing only withdraws themselves: whoever joined
+	// the request still wants the book, so it passes to the earliest of
+	// them, as it does when the requester's account is deleted. An admin
+	// cancelling someone else's request takes it down for everyone.
+	if req.RequesterID == userID {
+		if _, err := h.requests.HandOver(req.ID); err != nil {
+			return nil, huma.Error500InternalServerError("could not cancel wishlist request")
+		}
+		return nil, nil
+	}
 	req.Status = "cancelled"
 	if err := h.requests.Save(req); err != nil {
 		return nil, huma.Error500InternalServerError("could not cancel wishlist request")
@@ -391,3 +439,64 @@ func (h *WishlistHandler) fulfill(ctx context.Context, input *fulfillWishlistInp
 
 	return &wishlistRequestOutput{Body: toWishlistResponse(*req, userID, isAdmin)}, nil
 }
+
+func (h *WishlistHandler) join(ctx context.Context, input *wishlistIDInput) (*wishlistRequestOutput, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+	isAdmin := middleware.GetUserRole(ctx) == "admin"
+
+	req, err := h.requests.GetByID(input.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("wishlist request not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch wishlist request")
+	}
+	if req.Status != "open" {
+		return nil, huma.Error400BadRequest("only an open request can be joined")
+	}
+	if req.RequesterID == userID {
+		return nil, huma.Error400BadRequest("you can't join your own request")
+	}
+
+	if err := h.requests.Join(req.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, huma.Error409Conflict("you have already joined this request")
+		}
+		return nil, huma.Error500InternalServerError("could not join wishlist request")
+	}
+
+	req, err = h.requests.GetByID(req.ID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("could not fetch wishlist request")
+	}
+	return &wishlistRequestOutput{Body: toWishlistResponse(*req, userID, isAdmin)}, nil
+}
+
+func (h *WishlistHandler) leave(ctx context.Context, input *wishlistIDInput) (*struct{}, error) {
+	userID, err := middleware.GetRequiredUserID(ctx)
+	if err != nil {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+
+	req, err := h.requests.GetByID(input.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("wishlist request not found")
+		}
+		return nil, huma.Error500InternalServerError("could not fetch wishlist request")
+	}
+	if req.Status != "open" {
+		return nil, huma.Error400BadRequest("only an open request can be left")
+	}
+
+	if err := h.requests.Leave(req.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, huma.Error404NotFound("you haven't joined this request")
+		}
+		return nil, huma.Error500InternalServerError("could not leave wishlist request")
+	}
+	return nil, nil
+}
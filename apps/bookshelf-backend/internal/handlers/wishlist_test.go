@@ -126,8 +126,32 @@ func TestCancelWishlistRequest(t *testing.T) {
 		req := &models.WishlistRequest{RequesterID: 5, Title: "T", Author: "A", OLKey: "OL1", Status: "open"}
 		require.NoError(t, repo.Create(req))
 
+		require.NoError(t, repo.Join(req.ID, 6))
+
 		_, err := h.cancel(fakeAuthedCtx(t, 99, "admin"), &wishlistIDInput{ID: req.ID})
 		require.NoError(t, err)
+
+		reloaded, err := repo.GetByID(req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", reloaded.Status, "an admin takes it down for everyone who joined too")
+	})
+
+	t.Run("a requester cancelling hands the request to the earliest co-requester", func(t *testing.T) {
+		req := &models.WishlistRequest{RequesterID: 5, Title: "T", Author: "A", OLKey: "OL1", Status: "open"}
+		require.NoError(t, repo.Create(req))
+		require.NoError(t, repo.Join(req.ID, 7))
+		require.NoError(t, repo.Join(req.ID, 8))
+
+		_, err := h.cancel(fakeAuthedCtx(t, 5, "user"), &wishlistIDInput{ID: req.ID})
+		require.NoError(t, err)
+
+		reloaded, err := repo.GetByID(req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "open", reloaded.Status)
+		assert.Equal(t, uint(7), reloaded.RequesterID)
+		assert.True(t, reloaded.IsAnonymous, "the new requester never chose to be named")
+		require.Len(t, reloaded.CoRequesters, 1)
+		assert.Equal(t, uint(8), reloaded.CoRequesters[0].UserID)
 	})
 
 	t.Run("cannot cancel an already-fulfilled request", func(t *testing.T) {
@@ -144,6 +168,61 @@ func TestCancelWishlistRequest(t *testing.T) {
 	})
 }
 
+func TestJoinWishlistRequest(t *testing.T) {
+	h, repo, _, _, _ := newWishlistHandler()
+	req := &models.WishlistRequest{RequesterID: 5, Title: "T", Author: "A", OLKey: "OL1", Status: "open"}
+	require.NoError(t, repo.Create(req))
+
+	t.Run("another member joins and is counted", func(t *testing.T) {
+		out, err := h.join(fakeAuthedCtx(t, 6, "user"), &wishlistIDInput{ID: req.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, out.Body.RequesterCount)
+		assert.True(t, out.Body.Joined)
+
+		got, err := h.get(fakeAuthedCtx(t, 7, "user"), &wishlistIDInput{ID: req.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.Body.RequesterCount)
+		assert.False(t, got.Body.Joined)
+	})
+
+	t.Run("joining twice conflicts", func(t *testing.T) {
+		_, err := h.join(fakeAuthedCtx(t, 6, "user"), &wishlistIDInput{ID: req.ID})
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("the requester can't join their own request", func(t *testing.T) {
+		_, err := h.join(fakeAuthedCtx(t, 5, "user"), &wishlistIDInput{ID: req.ID})
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("a closed request can't be joined", func(t *testing.T) {
+		closed := &models.WishlistRequest{RequesterID: 5, Title: "T", Author: "A", OLKey: "OL2", Status: "fulfilled"}
+		require.NoError(t, repo.Create(closed))
+		_, err := h.join(fakeAuthedCtx(t, 6, "user"), &wishlistIDInput{ID: closed.ID})
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("unknown ID is 404", func(t *testing.T) {
+		_, err := h.join(fakeAuthedCtx(t, 6, "user"), &wishlistIDInput{ID: 999})
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("leaving removes the co-requester", func(t *testing.T) {
+		_, err := h.leave(fakeAuthedCtx(t, 6, "user"), &wishlistIDInput{ID: req.ID})
+		require.NoError(t, err)
+
+		got, err := h.get(fakeAuthedCtx(t, 6, "user"), &wishlistIDInput{ID: req.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.Body.RequesterCount)
+		assert.False(t, got.Body.Joined)
+	})
+
+	t.Run("leaving without having joined is 404", func(t *testing.T) {
+		_, err := h.leave(fakeAuthedCtx(t, 6, "user"), &wishlistIDInput{ID: req.ID})
+		assertStatus(t, err, 404)
+	})
+}
+
 func TestWishlistAnonymity(t *testing.T) {
 	h, repo, _, _, _ := newWishlistHandler()
 	req := &models.WishlistRequest{
@@ -0,0 +1,239 @@
+package marc
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+)
+
+// bookLeader describes a new (05 "n") record for a book — language
+// material (06 "a"), monograph (07 "m") — in Unicode (09 "a"), at minimal
+// level (17 "7", since it's built from metadata-service data rather than
+// catalogued by hand) and without ISBD punctuation (18 "c").
+const bookLeader = "00000nam a22000007c 4500"
+
+// maxDescriptionBytes caps the 520 summary so its field stays within ISO
+// 2709's field length; a longer description is cut at a word.
+const maxDescriptionBytes = 4000
+
+// yearPattern finds the year in a PublishedDate, which metadata services
+// give as "1965", "1965-08" or "August 1965".
+var yearPattern = regexp.MustCompile(`\b(1[0-9]{3}|20[0-9]{2})\b`)
+
+// languageCodes maps the ISO 639-1 codes metadata services give to the MARC
+// language codes 008 takes, for the languages a church library is likely
+// to hold. Anything else is coded undetermined.
+var languageCodes = map[string]string{
+	"en": "eng", "zh": "chi", "ms": "may", "ta": "tam", "id": "ind",
+	"ko": "kor", "ja": "jpn", "tl": "tgl", "vi": "vie", "th": "tha",
+	"hi": "hin", "fr": "fre", "de": "ger", "es": "spa", "pt": "por",
+	"it": "ita", "nl": "dut", "la": "lat", "el": "gre", "he": "heb",
+	"ru": "rus", "ar": "ara",
+}
+
+// nameParticles are lowercase words that belong to the surname that
+// follows them: "Ursula K. Le Guin" files under "Le Guin".
+var nameParticles = map[string]bool{
+	"da": true, "de": true, "del": true, "della": true, "der": true, "di": true,
+	"du": true, "la": true, "le": true, "van": true, "von": true,
+}
+
+// nameSuffixes follow the forenames in an inverted heading: "King, Martin
+// Luther, Jr.".
+var nameSuffixes = map[string]bool{"jr.": true, "jr": true, "sr.": true, "sr": true, "ii": true, "iii": true, "iv": true}
+
+// BookRecord builds book's bibliographic record, with one 852 holding per
+// copy in book.Copies, each located at location — the name the receiving
+// library knows this collection by.
+//
+// Holdings identify a copy by its ID and describe its condition and status;
+// they don't name its owner, since the export leaves the community.
+func BookRecord(book models.Book, location string) Record {
+	r := Record{Leader: bookLeader}
+	r.Fields = append(r.Fields,
+		Field{Tag: "001", Value: strconv.FormatUint(uint64(book.ID), 10)},
+		Field{Tag: "008", Value: fixedLengthData(book)},
+	)
+	if isbn := bookmatch.NormalizeISBN(book.ISBN); isbn != "" {
+		r.Fields = append(r.Fields, dataField("020", ' ', ' ', 'a', isbn))
+	}
+	if book.OLKey != "" {
+		r.Fields = append(r.Fields, dataField("035", ' ', ' ', 'a', "(OpenLibrary)"+strings.TrimPrefix(book.OLKey, "/works/")))
+	}
+	if book.GoogleBooksID != "" {
+		r.Fields = append(r.Fields, dataField("035", ' ', ' ', 'a', "(GoogleBooks)"+book.GoogleBooksID))
+	}
+
+	// The first author is the main entry; everyone else credited is an
+	// added entry.
+	contributors := bookmatch.ParseContributors(book.Author)
+	main := slices.IndexFunc(contributors, func(c bookmatch.Contributor) bool { return c.Role == bookmatch.RoleAuthor })
+	if main >= 0 {
+		r.Fields = append(r.Fields, nameField("100", contributors[main]))
+	}
+	r.Fields = append(r.Fields, titleField(book, main >= 0))
+
+	if book.Publisher != "" || yearOf(book.PublishedDate) != "" {
+		f := Field{Tag: "264", Ind1: ' ', Ind2: '1'}
+		if book.Publisher != "" {
+			f.Subfields = append(f.Subfields, Subfield{'b', book.Publisher})
+		}
+		if year := yearOf(book.PublishedDate); year != "" {
+			f.Subfields = append(f.Subfields, Subfield{'c', year})
+		}
+		r.Fields = append(r.Fields, f)
+	}
+	if book.PageCount > 0 {
+		r.Fields = append(r.Fields, dataField("300", ' ', ' ', 'a', strconv.Itoa(book.PageCount)+" pages"))
+	}
+	if desc := truncate(strings.Join(strings.Fields(book.Description), " "), maxDescriptionBytes); desc != "" {
+		r.Fields = append(r.Fields, dataField("520", ' ', ' ', 'a', desc))
+	}
+	for i, c := range contributors {
+		if i != main {
+			r.Fields = append(r.Fields, nameField("700", c))
+		}
+	}
+	for _, c := range book.Copies {
+		r.Fields = append(r.Fields, holdingField(c, location))
+	}
+	return r
+}
+
+func dataField(tag string, ind1, ind2, code byte, value string) Field {
+	return Field{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: []Subfield{{code, value}}}
+}
+
+// fixedLengthData returns book's 008: date entered, publication year,
+// language, and the book-specific positions left uncoded.
+func fixedLengthData(book models.Book) string {
+	dateType, date1 := "n", "uuuu"
+	if year := yearOf(book.PublishedDate); year != "" {
+		dateType, date1 = "s", year
+	}
+	entered := "||||||"
+	if !book.CreatedAt.IsZero() {
+		entered = book.CreatedAt.Format("060102")
+	}
+	language := "und"
+	if code, ok := languageCodes[strings.ToLower(book.Language)]; ok {
+		language = code
+	} else if len(book.Language) == 3 {
+		language = strings.ToLower(book.Language)
+	}
+	// 00-05 entered, 06 date type, 07-14 dates, 15-17 place, 18-34 book
+	// specifics (illustrations, audience, form, contents, government
+	// publication, conference, festschrift, index, undefined, literary
+	// form, biography), 35-37 language, 38 modified, 39 cataloging source.
+	return entered + dateType + date1 + "    " + "xx " +
+		"    " + " " + " " + "    " + " " + "0" + "0" + "0" + " " + "|" + " " +
+		language + " " + "d"
+}
+
+// nameField builds a 100 or 700 personal-name heading for c, inverted to
+// "Surname, Forenames" where c's name has both.
+func nameField(tag string, c bookmatch.Contributor) Field {
+	heading, surname := invertName(c.Name)
+	ind1 := byte('0')
+	if surname {
+		ind1 = '1'
+	}
+	return Field{Tag: tag, Ind1: ind1, Ind2: ' ', Subfields: []Subfield{{'a', heading}, {'e', c.Role}}}
+}
+
+// invertName turns "Ursula K. Le Guin" into "Le Guin, Ursula K.",
+// reporting whether it found a surname to invert on; a single name such as
+// "Augustine" is left as it is.
+func invertName(name string) (string, bool) {
+	tokens := strings.Fields(name)
+	var suffix string
+	if n := len(tokens); n > 2 && nameSuffixes[strings.ToLower(tokens[n-1])] {
+		suffix, tokens = tokens[n-1], tokens[:n-1]
+	}
+	if len(tokens) < 2 {
+		return name, false
+	}
+	start := len(tokens) - 1
+	for start > 1 && nameParticles[strings.ToLower(tokens[start-1])] {
+		start--
+	}
+	heading := strings.Join(tokens[start:], " ") + ", " + strings.Join(tokens[:start], " ")
+	if suffix != "" {
+		heading += ", " + suffix
+	}
+	return heading, true
+}
+
+// titleField builds the 245 title statement: title and subtitle (split at
+// the first colon), and the statement of responsibility as credited.
+// Indicator 2 skips a leading article when filing.
+func titleField(book models.Book, hasMainEntry bool) Field {
+	ind1 := byte('0')
+	if hasMainEntry {
+		ind1 = '1'
+	}
+	title, subtitle, _ := strings.Cut(book.Title, ":")
+	title, subtitle = strings.TrimSpace(title), strings.TrimSpace(subtitle)
+	f := Field{Tag: "245", Ind1: ind1, Ind2: '0' + byte(nonfilingCharacters(title))}
+	f.Subfields = append(f.Subfields, Subfield{'a', title})
+	if subtitle != "" {
+		f.Subfields = append(f.Subfields, Subfield{'b', subtitle})
+	}
+	if book.Author != "" {
+		f.Subfields = append(f.Subfields, Subfield{'c', book.Author})
+	}
+	return f
+}
+
+// nonfilingCharacters returns how many leading characters of an English
+// title to skip when filing it: "The Hobbit" files under "Hobbit".
+func nonfilingCharacters(title string) int {
+	lower := strings.ToLower(title)
+	for _, article := range []string{"the ", "an ", "a "} {
+		if strings.HasPrefix(lower, article) {
+			return len(article)
+		}
+	}
+	return 0
+}
+
+// holdingField builds c's 852 location: where (a), which copy (p), and its
+// condition and status as public notes (z).
+func holdingField(c models.Copy, location string) Field {
+	f := Field{Tag: "852", Ind1: ' ', Ind2: ' ', Subfields: []Subfield{
+		{'a', location},
+		{'p', strconv.FormatUint(uint64(c.ID), 10)},
+	}}
+	if c.Condition != "" {
+		f.Subfields = append(f.Subfields, Subfield{'z', "Condition: " + c.Condition})
+	}
+	if c.Status != "" {
+		f.Subfields = append(f.Subfields, Subfield{'z', "Status: " + c.Status})
+	}
+	return f
+}
+
+func yearOf(publishedDate string) string {
+	return yearPattern.FindString(publishedDate)
+}
+
+// truncate cuts s to at most n bytes, at the last space before the limit,
+// marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := strings.LastIndexByte(s[:n-len("…")], ' ')
+	if cut <= 0 {
+		cut = n - len("…")
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+	}
+	return s[:cut] + "…"
+}
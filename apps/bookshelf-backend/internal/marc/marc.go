@@ -0,0 +1,215 @@
+// Package marc builds MARC 21 bibliographic records and writes them as
+// binary MARC (ISO 2709) or MARCXML — the two forms an integrated library
+// system imports — so a partner library can load our holdings into its own
+// catalog.
+//
+// Only what's needed for that is modelled: a record is a leader and an
+// ordered list of fields, and the writers compute lengths and the
+// directory. Character data is UTF-8 throughout (leader/09 "a").
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ISO 2709 structural characters.
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+// The largest field and record ISO 2709 can address: its directory and
+// leader hold them in four and five decimal digits.
+const (
+	maxFieldLength  = 9999
+	maxRecordLength = 99999
+)
+
+// ErrTooLong is returned for a record a field or the whole of which is too
+// long to write as ISO 2709.
+var ErrTooLong = errors.New("marc: record too long")
+
+// Subfield is one coded value within a data field.
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// Field is a control field (tags 001–009, which carry Value) or a data
+// field (every other tag, which carries indicators and Subfields).
+type Field struct {
+	Tag        string
+	Value      string
+	Ind1, Ind2 byte
+	Subfields  []Subfield
+}
+
+// IsControl reports whether f is a control field.
+func (f Field) IsControl() bool {
+	return strings.HasPrefix(f.Tag, "00")
+}
+
+// Record is one bibliographic record. Leader is its 24-character leader;
+// the record length (00–04) and base address of data (12–16) in it are
+// placeholders, filled in when the record is written.
+type Record struct {
+	Leader string
+	Fields []Field
+}
+
+// data returns f's content as ISO 2709 stores it, field terminator included.
+func (f Field) data() []byte {
+	var b strings.Builder
+	if f.IsControl() {
+		b.WriteString(clean(f.Value))
+	} else {
+		b.WriteByte(f.Ind1)
+		b.WriteByte(f.Ind2)
+		for _, sf := range f.Subfields {
+			b.WriteByte(subfieldDelimiter)
+			b.WriteByte(sf.Code)
+			b.WriteString(clean(sf.Value))
+		}
+	}
+	b.WriteByte(fieldTerminator)
+	return []byte(b.String())
+}
+
+// MarshalBinary encodes r as an ISO 2709 record: leader, directory, then
+// the fields' data.
+func (r Record) MarshalBinary() ([]byte, error) {
+	if len(r.Leader) != 24 {
+		return nil, fmt.Errorf("marc: leader must be 24 characters, got %d", len(r.Leader))
+	}
+	var directory, data []byte
+	for _, f := range r.Fields {
+		if len(f.Tag) != 3 {
+			return nil, fmt.Errorf("marc: invalid tag %q", f.Tag)
+		}
+		d := f.data()
+		if len(d) > maxFieldLength {
+			return nil, fmt.Errorf("%w: field %s is %d bytes", ErrTooLong, f.Tag, len(d))
+		}
+		directory = fmt.Appendf(directory, "%s%04d%05d", f.Tag, len(d), len(data))
+		data = append(data, d...)
+	}
+	directory = append(directory, fieldTerminator)
+
+	base := 24 + len(directory)
+	length := base + len(data) + 1
+	if length > maxRecordLength {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLong, length)
+	}
+	out := make([]byte, 0, length)
+	out = fmt.Appendf(out, "%05d%s%05d%s", length, r.Leader[5:12], base, r.Leader[17:])
+	out = append(out, directory...)
+	out = append(out, data...)
+	return append(out, recordTerminator), nil
+}
+
+// WriteBinary writes records to w as a stream of ISO 2709 records, the
+// usual contents of a .mrc file.
+func WriteBinary(w io.Writer, records []Record) error {
+	for _, r := range records {
+		b, err := r.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MARCXML's schema, per https://www.loc.gov/standards/marcxml/.
+const xmlNamespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteXML writes records to w as a MARCXML collection. Each leader carries
+// the same lengths the record's binary form would, so the two exports of a
+// catalog agree.
+func WriteXML(w io.Writer, records []Record) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	collection := xml.StartElement{
+		Name: xml.Name{Local: "collection"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: xmlNamespace}},
+	}
+	if err := enc.EncodeToken(collection); err != nil {
+		return err
+	}
+	for _, r := range records {
+		b, err := r.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		// Control fields come first in a MARC record, so splitting them out
+		// keeps the record's field order.
+		x := xmlRecord{Leader: string(b[:24])}
+		for _, f := range r.Fields {
+			if f.IsControl() {
+				x.ControlFields = append(x.ControlFields, xmlControlField{Tag: f.Tag, Value: clean(f.Value)})
+				continue
+			}
+			df := xmlDataField{Tag: f.Tag, Ind1: string(f.Ind1), Ind2: string(f.Ind2)}
+			for _, sf := range f.Subfields {
+				df.Subfields = append(df.Subfields, xmlSubfield{Code: string(sf.Code), Value: clean(sf.Value)})
+			}
+			x.DataFields = append(x.DataFields, df)
+		}
+		if err := enc.Encode(x); err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeToken(collection.End()); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// clean replaces control characters, which would corrupt an ISO 2709
+// record's structure and aren't allowed in XML, with spaces.
+func clean(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7F {
+			return ' '
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,190 @@
+package marc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+)
+
+func dune() models.Book {
+	return models.Book{
+		ID:            42,
+		Title:         "Dune: The Desert Planet",
+		Author:        "Frank Herbert",
+		ISBN:          "978-0-441-01359-3",
+		OLKey:         "/works/OL893415W",
+		Publisher:     "Ace",
+		PublishedDate: "2005-08-02",
+		PageCount:     658,
+		Language:      "en",
+		Description:   "Set on the desert planet Arrakis.",
+		CreatedAt:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Copies: []models.Copy{
+			{ID: 7, Condition: "good", Status: "available"},
+			{ID: 9, Status: "loaned"},
+		},
+	}
+}
+
+// field returns r's first field tagged tag.
+func field(t *testing.T, r Record, tag string) Field {
+	t.Helper()
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			return f
+		}
+	}
+	t.Fatalf("no %s field", tag)
+	return Field{}
+}
+
+func TestBookRecord(t *testing.T) {
+	r := BookRecord(dune(), "Grace Church Library")
+
+	assert.Equal(t, "42", field(t, r, "001").Value)
+	fixed := field(t, r, "008").Value
+	require.Len(t, fixed, 40)
+	assert.Equal(t, "240102s2005", fixed[:11])
+	assert.Equal(t, "eng", fixed[35:38])
+
+	assert.Equal(t, []Subfield{{'a', "9780441013593"}}, field(t, r, "020").Subfields)
+	assert.Equal(t, []Subfield{{'a', "(OpenLibrary)OL893415W"}}, field(t, r, "035").Subfields)
+	assert.Equal(t, []Subfield{{'a', "Herbert, Frank"}, {'e', "author"}}, field(t, r, "100").Subfields)
+
+	title := field(t, r, "245")
+	assert.Equal(t, byte('1'), title.Ind1, "there is a main entry")
+	assert.Equal(t, []Subfield{{'a', "Dune"}, {'b', "The Desert Planet"}, {'c', "Frank Herbert"}}, title.Subfields)
+	assert.Equal(t, []Subfield{{'b', "Ace"}, {'c', "2005"}}, field(t, r, "264").Subfields)
+	assert.Equal(t, []Subfield{{'a', "Set on the desert planet Arrakis."}}, field(t, r, "520").Subfields)
+
+	var holdings []Field
+	for _, f := range r.Fields {
+		if f.Tag == "852" {
+			holdings = append(holdings, f)
+		}
+	}
+	require.Len(t, holdings, 2, "one holding per copy")
+	assert.Equal(t, []Subfield{
+		{'a', "Grace Church Library"}, {'p', "7"}, {'z', "Condition: good"}, {'z', "Status: available"},
+	}, holdings[0].Subfields)
+}
+
+func TestBookRecord_Credits(t *testing.T) {
+	book := models.Book{ID: 1, Title: "The Brothers Karamazov", Author: "Fyodor Dostoevsky; translated by Richard Pevear and Larissa Volokhonsky"}
+	r := BookRecord(book, "Library")
+
+	assert.Equal(t, byte('4'), field(t, r, "245").Ind2, `"The " is skipped when filing`)
+	var added []string
+	for _, f := range r.Fields {
+		if f.Tag == "700" {
+			added = append(added, f.Subfields[0].Value+" ("+f.Subfields[1].Value+")")
+		}
+	}
+	assert.Equal(t, []string{"Pevear, Richard (translator)", "Volokhonsky, Larissa (translator)"}, added)
+
+	anonymous := BookRecord(models.Book{ID: 2, Title: "Beowulf"}, "Library")
+	for _, f := range anonymous.Fields {
+		assert.NotEqual(t, "100", f.Tag)
+	}
+	assert.Equal(t, byte('0'), field(t, anonymous, "245").Ind1)
+	assert.Equal(t, "nuuuu", field(t, anonymous, "008").Value[6:11], "no known publication year")
+}
+
+func TestInvertName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Frank Herbert":             "Herbert, Frank",
+		"Ursula K. Le Guin":         "Le Guin, Ursula K.",
+		"Martin Luther King Jr.":    "King, Martin Luther, Jr.",
+		"Ludwig van Beethoven":      "van Beethoven, Ludwig",
+		"J. R. R. Tolkien":          "Tolkien, J. R. R.",
+		"Augustine":                 "Augustine",
+		"Gabriel García Márquez":    "Márquez, Gabriel García",
+		"Charles Haddon Spurgeon":   "Spurgeon, Charles Haddon",
+		"Dietrich von Hildebrand":   "von Hildebrand, Dietrich",
+		"Anne-Marie de la Fontaine": "de la Fontaine, Anne-Marie",
+	} {
+		got, _ := invertName(name)
+		assert.Equal(t, want, got, name)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	r := Record{Leader: bookLeader, Fields: []Field{
+		{Tag: "001", Value: "42"},
+		{Tag: "245", Ind1: '0', Ind2: '0', Subfields: []Subfield{{'a', "Café"}}},
+	}}
+	b, err := r.MarshalBinary()
+	require.NoError(t, err)
+
+	// Leader, then a directory of two 12-byte entries and its terminator.
+	base := 24 + 2*12 + 1
+	assert.Equal(t, "00063nam a22000497c 4500", string(b[:24]))
+	assert.Len(t, b, 63, "Café is five bytes in UTF-8")
+	assert.Equal(t, "001000300000", string(b[24:36]))
+	assert.Equal(t, "245001000003", string(b[36:48]))
+	assert.Equal(t, "42\x1e", string(b[base:base+3]))
+	assert.Equal(t, "00\x1faCafé\x1e", string(b[base+3:len(b)-1]))
+	assert.Equal(t, byte(recordTerminator), b[len(b)-1])
+
+	t.Run("structural characters in data are replaced", func(t *testing.T) {
+		r := Record{Leader: bookLeader, Fields: []Field{{Tag: "500", Ind1: ' ', Ind2: ' ', Subfields: []Subfield{{'a', "a\x1eb\nc"}}}}}
+		b, err := r.MarshalBinary()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "\x1faa b c\x1e")
+	})
+
+	t.Run("an oversized field is refused", func(t *testing.T) {
+		r := Record{Leader: bookLeader, Fields: []Field{{Tag: "500", Subfields: []Subfield{{'a', strings.Repeat("x", 10000)}}}}}
+		_, err := r.MarshalBinary()
+		assert.ErrorIs(t, err, ErrTooLong)
+	})
+}
+
+func TestBookRecord_LongDescriptionStillEncodes(t *testing.T) {
+	book := dune()
+	book.Description = strings.Repeat("Arrakis é ", 2000)
+	b, err := BookRecord(book, "Library").MarshalBinary()
+	require.NoError(t, err)
+	assert.Less(t, len(b), maxRecordLength)
+}
+
+func TestWriteXML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteXML(&buf, []Record{BookRecord(dune(), "Library")}))
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.loc.gov/MARC21/slim collection"`
+		Records []struct {
+			Leader        string `xml:"leader"`
+			ControlFields []struct {
+				Tag   string `xml:"tag,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"controlfield"`
+			DataFields []struct {
+				Tag       string `xml:"tag,attr"`
+				Ind1      string `xml:"ind1,attr"`
+				Subfields []struct {
+					Code  string `xml:"code,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"subfield"`
+			} `xml:"datafield"`
+		} `xml:"record"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Records, 1)
+	rec := doc.Records[0]
+
+	binary, err := BookRecord(dune(), "Library").MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, string(binary[:24]), rec.Leader, "the leader matches the binary record's")
+	assert.Equal(t, "001", rec.ControlFields[0].Tag)
+	assert.Equal(t, "020", rec.DataFields[0].Tag)
+	assert.Equal(t, "9780441013593", rec.DataFields[0].Subfields[0].Value)
+}
@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// BookBrainz searches the BookBrainz edition index. It has no cover images
+// or descriptions.
+type BookBrainz struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewBookBrainz returns the BookBrainz provider.
+func NewBookBrainz() *BookBrainz {
+	return &BookBrainz{baseURL: "https://api.bookbrainz.org", client: httpClient}
+}
+
+func (p *BookBrainz) Name() string { return "bookbrainz" }
+
+// Timeout is shorter than the other providers': BookBrainz is the slowest
+// and contributes the least, so a search shouldn't wait long on it.
+func (p *BookBrainz) Timeout() time.Duration { return 6 * time.Second }
+
+func (p *BookBrainz) Capabilities() Capabilities {
+	return Capabilities{ISBN: true, FreeText: true}
+}
+
+// Search calls the BookBrainz search API.
+func (p *BookBrainz) Search(ctx context.Context, q Query) ([]BookMetadataResult, error) {
+	zerolog.Ctx(ctx).Debug().Str("query", q.Text).Msg("searching BookBrainz")
+	apiURL := fmt.Sprintf(
+		"%s/1/search?q=%s&type=edition&size=10",
+		p.baseURL, url.QueryEscape(q.Text),
+	)
+	var payload struct {
+		Results []struct {
+			BBID         string `json:"bbid"`
+			DefaultAlias struct {
+				Name string `json:"name"`
+			} `json:"default-alias"`
+			AuthorCredit struct {
+				Names []struct {
+					Name string `json:"name"`
+				} `json:"names"`
+			} `json:"author-credit"`
+		} `json:"search-results"`
+	}
+	if err := getJSON(ctx, p.client, "bookbrainz", apiURL, &payload); err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Debug().Str("query", q.Text).Int("results", len(payload.Results)).Msg("BookBrainz search complete")
+	results := make([]BookMetadataResult, 0, len(payload.Results))
+	for _, item := range payload.Results {
+		if item.DefaultAlias.Name == "" {
+			continue
+		}
+		r := BookMetadataResult{
+			Source:       p.Name(),
+			Title:        item.DefaultAlias.Name,
+			BookBrainzID: item.BBID,
+		}
+		names := make([]string, len(item.AuthorCredit.Names))
+		for i, n := range item.AuthorCredit.Names {
+			names[i] = n.Name
+		}
+		if len(names) > 0 {
+			r.Author = names[0]
+		}
+		r.Contributors = sourceContributors(names)
+		results = append(results, r)
+	}
+	return results, nil
+}
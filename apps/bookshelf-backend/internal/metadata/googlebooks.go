@@ -0,0 +1,135 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// GoogleBooks searches the Google Books API. It needs an API key — the
+// searching user's own, or the server-wide one — and is skipped without.
+type GoogleBooks struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewGoogleBooks returns the Google Books provider.
+func NewGoogleBooks() *GoogleBooks {
+	return &GoogleBooks{baseURL: "https://www.googleapis.com", client: httpClient}
+}
+
+func (p *GoogleBooks) Name() string { return "google_books" }
+
+func (p *GoogleBooks) Timeout() time.Duration { return 8 * time.Second }
+
+func (p *GoogleBooks) Capabilities() Capabilities {
+	return Capabilities{ISBN: true, FreeText: true, Descriptions: true, RequiresAPIKey: true}
+}
+
+type googleBooksIndustryIdentifier struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+}
+
+type googleBooksImageLinks struct {
+	Thumbnail string `json:"thumbnail"`
+}
+
+type googleBooksVolumeInfo struct {
+	Title               string                          `json:"title"`
+	Authors             []string                        `json:"authors"`
+	Publisher           string                          `json:"publisher"`
+	PublishedDate       string                          `json:"publishedDate"`
+	Description         string                          `json:"description"`
+	PageCount           int                             `json:"pageCount"`
+	Language            string                          `json:"language"`
+	IndustryIdentifiers []googleBooksIndustryIdentifier `json:"industryIdentifiers"`
+	ImageLinks          googleBooksImageLinks           `json:"imageLinks"`
+}
+
+type googleBooksItem struct {
+	ID         string                `json:"id"`
+	VolumeInfo googleBooksVolumeInfo `json:"volumeInfo"`
+}
+
+type googleBooksSearchResponse struct {
+	Items []googleBooksItem `json:"items"`
+}
+
+// Search calls the Google Books volumes API.
+func (p *GoogleBooks) Search(ctx context.Context, q Query) ([]BookMetadataResult, error) {
+	query := googleBooksQueryFor(q)
+	zerolog.Ctx(ctx).Debug().Str("query", query).Msg("searching Google Books")
+	apiURL := fmt.Sprintf(
+		"%s/books/v1/volumes?q=%s&key=%s&maxResults=10",
+		p.baseURL, url.QueryEscape(query), url.QueryEscape(q.APIKey),
+	)
+	var payload googleBooksSearchResponse
+	if err := getJSON(ctx, p.client, "google books", apiURL, &payload); err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Debug().Str("query", q.Text).Int("results", len(payload.Items)).Msg("Google Books search complete")
+	results := make([]BookMetadataResult, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		results = append(results, googleVolumeToResult(item))
+	}
+	return results, nil
+}
+
+// googleBooksQueryFor returns the query string to send to Google Books for
+// q. Google Books' free-text search does not reliably match a bare ISBN
+// string — a valid, indexed ISBN can return zero results without the
+// "isbn:" search operator — so ISBN queries are rewritten to use it.
+// Non-ISBN queries (title/author, including the handler's sibling-edition
+// re-fetch) pass through unchanged.
+func googleBooksQueryFor(q Query) string {
+	if q.ISBN != "" {
+		return "isbn:" + q.ISBN
+	}
+	return q.Text
+}
+
+// googleVolumeToResult converts a single Google Books API item into a normalised result.
+func googleVolumeToResult(item googleBooksItem) BookMetadataResult {
+	vi := item.VolumeInfo
+	r := BookMetadataResult{
+		Source:        "google_books",
+		GoogleBooksID: item.ID,
+		Title:         vi.Title,
+		Publisher:     vi.Publisher,
+		PublishedDate: vi.PublishedDate,
+		Description:   vi.Description,
+		PageCount:     vi.PageCount,
+		Language:      vi.Language,
+		ISBN:          preferredISBN(vi.IndustryIdentifiers),
+	}
+	if len(vi.Authors) > 0 {
+		r.Author = vi.Authors[0]
+	}
+	r.Contributors = sourceContributors(vi.Authors)
+	if thumb := vi.ImageLinks.Thumbnail; thumb != "" {
+		r.CoverURL = strings.Replace(thumb, "http://", "https://", 1)
+	}
+	return r
+}
+
+// preferredISBN returns the ISBN-13 identifier if present, else ISBN-10, else "".
+func preferredISBN(ids []googleBooksIndustryIdentifier) string {
+	for _, id := range ids {
+		if id.Type == "ISBN_13" {
+			return id.Identifier
+		}
+	}
+	for _, id := range ids {
+		if id.Type == "ISBN_10" {
+			return id.Identifier
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package metadata
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker tuning. A provider that fails breakerThreshold searches
+// in a row is skipped for breakerCooldown; after that one trial search is
+// let through, and its outcome closes the breaker or re-opens it for
+// another cooldown.
+const (
+	breakerThreshold = 3
+	breakerCooldown  = time.Minute
+	// healthWindow is how many recent searches ErrorRate and AvgLatencyMs
+	// are computed over.
+	healthWindow = 20
+)
+
+// BreakerState is a provider's circuit breaker state.
+type BreakerState string
+
+const (
+	// BreakerClosed is the normal state: the provider is searched.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen means the provider failed repeatedly and is being skipped
+	// until its cooldown ends.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen means the cooldown has ended and the next search is a
+	// trial.
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// ProviderHealth is a snapshot of a provider's recent searches and breaker
+// state, since the server started.
+type ProviderHealth struct {
+	State BreakerState `json:"state"`
+	// Requests is how many searches ErrorRate and AvgLatencyMs cover, at
+	// most the last 20.
+	Requests     int     `json:"requests"`
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs int64   `json:"avg_latency_ms"`
+	// ConsecutiveFailures counts failures since the last success.
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	// OpenUntil is when an open breaker lets a trial search through.
+	OpenUntil *time.Time `json:"open_until,omitempty"`
+	// Skipped counts searches the breaker kept from the provider.
+	Skipped int64 `json:"skipped"`
+}
+
+type searchOutcome struct {
+	latency time.Duration
+	failed  bool
+}
+
+// providerHealth tracks one provider's recent outcomes and breaker.
+type providerHealth struct {
+	mu            sync.Mutex
+	outcomes      [healthWindow]searchOutcome
+	count, next   int
+	consecutive   int
+	openUntil     time.Time
+	trialInFlight bool
+	lastFailureAt time.Time
+	lastError     string
+	skipped       int64
+}
+
+// allow reports whether a search may go to the provider at now, counting
+// it as skipped if not. Past an open breaker's cooldown only one trial
+// search is allowed until its outcome is recorded.
+func (h *providerHealth) allow(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(h.openUntil) || h.trialInFlight {
+		h.skipped++
+		return false
+	}
+	h.trialInFlight = true
+	return true
+}
+
+// record adds a finished search's outcome; err is nil on success.
+func (h *providerHealth) record(now time.Time, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.outcomes[h.next] = searchOutcome{latency: latency, failed: err != nil}
+	h.next = (h.next + 1) % healthWindow
+	if h.count < healthWindow {
+		h.count++
+	}
+
+	trial := h.trialInFlight
+	h.trialInFlight = false
+	if err == nil {
+		h.consecutive = 0
+		h.openUntil = time.Time{}
+		return
+	}
+	h.consecutive++
+	h.lastFailureAt = now
+	h.lastError = err.Error()
+	if trial || h.consecutive >= breakerThreshold {
+		h.openUntil = now.Add(breakerCooldown)
+	}
+}
+
+// release ends a search allow let through without recording an outcome,
+// so a half-open breaker's trial slot isn't held by an abandoned search.
+func (h *providerHealth) release() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.trialInFlight = false
+}
+
+func (h *providerHealth) snapshot(now time.Time) ProviderHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := ProviderHealth{
+		State:               BreakerClosed,
+		Requests:            h.count,
+		ConsecutiveFailures: h.consecutive,
+		LastError:           h.lastError,
+		Skipped:             h.skipped,
+	}
+	if h.count > 0 {
+		var failures int
+		var total time.Duration
+		for _, o := range h.outcomes[:h.count] {
+			total += o.latency
+			if o.failed {
+				failures++
+			}
+		}
+		out.ErrorRate = float64(failures) / float64(h.count)
+		out.AvgLatencyMs = (total / time.Duration(h.count)).Milliseconds()
+	}
+	if !h.lastFailureAt.IsZero() {
+		at := h.lastFailureAt
+		out.LastFailureAt = &at
+	}
+	if !h.openUntil.IsZero() {
+		until := h.openUntil
+		out.OpenUntil = &until
+		out.State = BreakerOpen
+		if !now.Before(until) {
+			out.State = BreakerHalfOpen
+		}
+	}
+	return out
+}
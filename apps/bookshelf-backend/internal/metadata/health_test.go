@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClockedRegistry returns a Registry of providers whose clock is the
+// returned pointer, so tests can step past the breaker cooldown.
+func newClockedRegistry(providers ...Provider) (*Registry, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRegistry(nil, providers...)
+	r.now = func() time.Time { return now }
+	return r, &now
+}
+
+func healthOf(r *Registry, name string) ProviderHealth {
+	for _, s := range r.Providers() {
+		if s.Provider.Name() == name {
+			return s.Health
+		}
+	}
+	return ProviderHealth{}
+}
+
+func TestRegistry_BreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	flaky := newFake("flaky")
+	flaky.err = errors.New("503")
+	r, _ := newClockedRegistry(flaky, newFake("ok"))
+
+	for range breakerThreshold {
+		r.Search(context.Background(), Query{Text: "dune"}, nil)
+	}
+	require.Len(t, flaky.queries, breakerThreshold)
+	h := healthOf(r, "flaky")
+	assert.Equal(t, BreakerOpen, h.State)
+	assert.Equal(t, "503", h.LastError)
+	assert.InDelta(t, 1.0, h.ErrorRate, 0.001)
+
+	results, report := r.Search(context.Background(), Query{Text: "dune"}, nil)
+	assert.Equal(t, []string{"ok"}, sources(results))
+	assert.Len(t, flaky.queries, breakerThreshold, "an open breaker skips the provider")
+	assert.Equal(t, []string{"flaky"}, report.Unanswered, "a skipped provider leaves the search incomplete")
+	assert.Equal(t, int64(1), healthOf(r, "flaky").Skipped)
+	assert.Equal(t, BreakerClosed, healthOf(r, "ok").State)
+}
+
+func TestRegistry_BreakerTrialAfterCooldown(t *testing.T) {
+	flaky := newFake("flaky")
+	flaky.err = errors.New("503")
+	r, now := newClockedRegistry(flaky)
+	for range breakerThreshold {
+		r.Search(context.Background(), Query{Text: "dune"}, nil)
+	}
+
+	*now = now.Add(breakerCooldown)
+	assert.Equal(t, BreakerHalfOpen, healthOf(r, "flaky").State)
+
+	t.Run("a failed trial re-opens the breaker", func(t *testing.T) {
+		r.Search(context.Background(), Query{Text: "dune"}, nil)
+		assert.Len(t, flaky.queries, breakerThreshold+1)
+		assert.Equal(t, BreakerOpen, healthOf(r, "flaky").State)
+	})
+
+	t.Run("a successful trial closes it", func(t *testing.T) {
+		*now = now.Add(breakerCooldown)
+		flaky.err = nil
+		results, _ := r.Search(context.Background(), Query{Text: "dune"}, nil)
+		assert.Equal(t, []string{"flaky"}, sources(results))
+		h := healthOf(r, "flaky")
+		assert.Equal(t, BreakerClosed, h.State)
+		assert.Zero(t, h.ConsecutiveFailures)
+		assert.Nil(t, h.OpenUntil)
+	})
+}
+
+func TestRegistry_SuccessResetsConsecutiveFailures(t *testing.T) {
+	flaky := newFake("flaky")
+	r, _ := newClockedRegistry(flaky)
+	for i := range 2 * breakerThreshold {
+		// Fail every other search: never breakerThreshold in a row.
+		flaky.err = nil
+		if i%2 == 0 {
+			flaky.err = errors.New("503")
+		}
+		r.Search(context.Background(), Query{Text: "dune"}, nil)
+	}
+
+	h := healthOf(r, "flaky")
+	assert.Equal(t, BreakerClosed, h.State)
+	assert.Equal(t, 2*breakerThreshold, h.Requests)
+	assert.InDelta(t, 0.5, h.ErrorRate, 0.001)
+}
+
+func TestRegistry_CallerCancellationIsNotAProviderFailure(t *testing.T) {
+	slow := newFake("slow")
+	slow.delay = time.Minute
+	r, _ := newClockedRegistry(slow)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range breakerThreshold {
+		r.Search(ctx, Query{Text: "dune"}, nil)
+	}
+
+	h := healthOf(r, "slow")
+	assert.Equal(t, BreakerClosed, h.State)
+	assert.Zero(t, h.Requests)
+}
+
+func TestProviderHealth_WindowKeepsRecentSearches(t *testing.T) {
+	var h providerHealth
+	now := time.Now()
+	h.record(now, time.Second, errors.New("slow"))
+	for range healthWindow {
+		h.record(now, 100*time.Millisecond, nil)
+	}
+
+	s := h.snapshot(now)
+	assert.Equal(t, healthWindow, s.Requests)
+	assert.Zero(t, s.ErrorRate, "the old failure has left the window")
+	assert.Equal(t, int64(100), s.AvgLatencyMs)
+	require.NotNil(t, s.LastFailureAt, "but is still the last failure")
+}
@@ -0,0 +1,134 @@
+// Package metadata looks books up in external metadata sources — Open
+// Library, Google Books, BookBrainz — behind a common Provider interface.
+// A Registry holds the providers, decides from admin settings which are
+// enabled and in what priority order, and fans a search out across them.
+//
+// Deduplicating and merging the results is left to the caller
+// (internal/handlers' consolidateResults): providers only fetch and
+// normalize.
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+)
+
+// BookMetadataResult is a normalised search result from any metadata source.
+type BookMetadataResult struct {
+	Source        string `json:"source"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	ISBN          string `json:"isbn"`
+	CoverURL      string `json:"cover_url"`
+	Description   string `json:"description"`
+	Publisher     string `json:"publisher"`
+	PublishedDate string `json:"published_date"`
+	PageCount     int    `json:"page_count"`
+	Language      string `json:"language"`
+	OLKey         string `json:"ol_key"`
+	GoogleBooksID string `json:"google_books_id"`
+	BookBrainzID  string `json:"bookbrainz_id,omitempty"`
+	// Contributors is every person the source credits, normalized, where
+	// Author is only the first. Passed back on book creation to link the
+	// book to Author records.
+	Contributors []bookmatch.Contributor `json:"contributors,omitempty"`
+	// EnrichedFields lists fields on this result that were backfilled from a
+	// sibling edition of the same work, rather than from this result's own source.
+	EnrichedFields []string `json:"enriched_fields,omitempty"`
+	// WorkKey is the normalizeTitleAuthor bucket key for this result, used by the
+	// frontend to cluster distinct editions of the same work for display. Empty
+	// when Title or Author is empty (never bucketed).
+	WorkKey string `json:"work_key,omitempty"`
+}
+
+// Query is one search, as handed to each provider.
+type Query struct {
+	// Text is the query as the user typed it: a title, author, or ISBN.
+	Text string
+	// ISBN is Text normalized to an ISBN-13 when Text is an ISBN, else "".
+	ISBN string
+	// APIKey is the caller's key for the provider being queried, for
+	// providers whose Capabilities say they require one. The Registry fills
+	// it in per provider.
+	APIKey string
+}
+
+// Capabilities describes what a provider can do, so the Registry only asks
+// it what it can answer.
+type Capabilities struct {
+	// ISBN is set if the provider can look a book up by ISBN.
+	ISBN bool `json:"isbn"`
+	// FreeText is set if the provider can search by title or author.
+	FreeText bool `json:"free_text"`
+	// Descriptions is set if the provider's results carry descriptions.
+	Descriptions bool `json:"descriptions"`
+	// RequiresAPIKey is set if the provider is skipped unless the search
+	// has a key for it.
+	RequiresAPIKey bool `json:"requires_api_key"`
+}
+
+// Provider is one external metadata source.
+type Provider interface {
+	// Name identifies the provider in settings and is the Source of every
+	// result it returns, e.g. "openlibrary".
+	Name() string
+	// Timeout bounds each Search call; the Registry cancels it after this.
+	Timeout() time.Duration
+	Capabilities() Capabilities
+	// Search returns the provider's results for q, normalised.
+	Search(ctx context.Context, q Query) ([]BookMetadataResult, error)
+}
+
+// httpClient is shared by the built-in providers. It has no timeout of its
+// own: each request carries its provider's deadline in its context.
+var httpClient = &http.Client{}
+
+// getJSON fetches apiURL and decodes its JSON body into dst. name is the
+// provider's, for error messages.
+func getJSON(ctx context.Context, client *http.Client, name, apiURL string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req) //nolint:gosec // url is built from a provider's fixed base URL
+	if err != nil {
+		// Drop the URL from the error: it can carry an API key, and these
+		// errors are logged and shown on the admin status page.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s request failed: %w", name, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", name, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, dst)
+}
+
+// sourceContributors turns a source's list of credited names into
+// contributors. Sources list one person per entry, occasionally with a role
+// marker such as "(Translator)"; unmarked names are authors.
+func sourceContributors(names []string) []bookmatch.Contributor {
+	var out []bookmatch.Contributor
+	for _, name := range names {
+		if c := bookmatch.ParseContributor(name); c.Name != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// OpenLibrary searches openlibrary.org. Its search API has no descriptions;
+// those come from the work record, fetched lazily by the frontend.
+type OpenLibrary struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewOpenLibrary returns the Open Library provider.
+func NewOpenLibrary() *OpenLibrary {
+	return &OpenLibrary{baseURL: "https://openlibrary.org", client: httpClient}
+}
+
+func (p *OpenLibrary) Name() string { return "openlibrary" }
+
+func (p *OpenLibrary) Timeout() time.Duration { return 10 * time.Second }
+
+func (p *OpenLibrary) Capabilities() Capabilities {
+	return Capabilities{ISBN: true, FreeText: true}
+}
+
+// Search calls the OL search API, which takes ISBNs and free text alike.
+func (p *OpenLibrary) Search(ctx context.Context, q Query) ([]BookMetadataResult, error) {
+	zerolog.Ctx(ctx).Debug().Str("query", q.Text).Msg("searching Open Library")
+	apiURL := fmt.Sprintf(
+		"%s/search.json?q=%s&fields=key,title,author_name,isbn,cover_i&limit=10",
+		p.baseURL, url.QueryEscape(q.Text),
+	)
+	var payload struct {
+		Docs []struct {
+			Key        string   `json:"key"`
+			Title      string   `json:"title"`
+			AuthorName []string `json:"author_name"`
+			ISBN       []string `json:"isbn"`
+			CoverI     int64    `json:"cover_i"`
+		} `json:"docs"`
+	}
+	if err := getJSON(ctx, p.client, "open library", apiURL, &payload); err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Debug().Str("query", q.Text).Int("results", len(payload.Docs)).Msg("Open Library search complete")
+	results := make([]BookMetadataResult, 0, len(payload.Docs))
+	for _, doc := range payload.Docs {
+		r := BookMetadataResult{
+			Source: p.Name(),
+			Title:  doc.Title,
+			OLKey:  doc.Key,
+		}
+		if len(doc.AuthorName) > 0 {
+			r.Author = doc.AuthorName[0]
+		}
+		r.Contributors = sourceContributors(doc.AuthorName)
+		if len(doc.ISBN) > 0 {
+			r.ISBN = doc.ISBN[0]
+		}
+		if doc.CoverI > 0 {
+			r.CoverURL = fmt.Sprintf("https://covers.openlibrary.org/b/id/%d-M.jpg", doc.CoverI)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleBooksQueryFor(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{
+			name: "ISBN query gets the isbn: operator",
+			q:    Query{Text: "978-1-433-53233-7", ISBN: "9781433532337"},
+			want: "isbn:9781433532337",
+		},
+		{
+			name: "title/author query passes through unchanged",
+			q:    Query{Text: "Church Discipline Jonathan Leeman"},
+			want: "Church Discipline Jonathan Leeman",
+		},
+		{
+			name: "empty query passes through unchanged",
+			q:    Query{},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, googleBooksQueryFor(tt.q))
+		})
+	}
+}
+
+// serveJSON starts a server answering every request with body, recording
+// the last request URL into *gotURL.
+func serveJSON(t *testing.T, gotURL *string, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotURL = r.URL.String()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenLibrary_Search(t *testing.T) {
+	var gotURL string
+	srv := serveJSON(t, &gotURL, `{"docs":[{"key":"/works/OL1W","title":"Dune","author_name":["Frank Herbert"],"isbn":["9780441013593"],"cover_i":42}]}`)
+	p := &OpenLibrary{baseURL: srv.URL, client: srv.Client()}
+
+	results, err := p.Search(context.Background(), Query{Text: "dune herbert"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, gotURL, "/search.json?q=dune+herbert")
+	assert.Equal(t, BookMetadataResult{
+		Source:       "openlibrary",
+		Title:        "Dune",
+		Author:       "Frank Herbert",
+		ISBN:         "9780441013593",
+		CoverURL:     "https://covers.openlibrary.org/b/id/42-M.jpg",
+		OLKey:        "/works/OL1W",
+		Contributors: sourceContributors([]string{"Frank Herbert"}),
+	}, results[0])
+}
+
+func TestGoogleBooks_Search(t *testing.T) {
+	var gotURL string
+	srv := serveJSON(t, &gotURL, `{"items":[{"id":"vol1","volumeInfo":{
+		"title":"Dune","authors":["Frank Herbert"],"description":"Desert planet.",
+		"industryIdentifiers":[{"type":"ISBN_10","identifier":"0441013597"},{"type":"ISBN_13","identifier":"9780441013593"}],
+		"imageLinks":{"thumbnail":"http://books.google.com/cover.jpg"}}}]}`)
+	p := &GoogleBooks{baseURL: srv.URL, client: srv.Client()}
+
+	results, err := p.Search(context.Background(), Query{Text: "0441013597", ISBN: "9780441013593", APIKey: "k"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, gotURL, "q=isbn%3A9780441013593")
+	assert.Contains(t, gotURL, "key=k")
+	assert.Equal(t, "google_books", results[0].Source)
+	assert.Equal(t, "vol1", results[0].GoogleBooksID)
+	assert.Equal(t, "9780441013593", results[0].ISBN, "ISBN-13 is preferred")
+	assert.Equal(t, "https://books.google.com/cover.jpg", results[0].CoverURL)
+	assert.Equal(t, "Desert planet.", results[0].Description)
+}
+
+func TestGoogleBooks_ErrorOmitsAPIKey(t *testing.T) {
+	p := &GoogleBooks{baseURL: "http://127.0.0.1:0", client: httpClient}
+
+	_, err := p.Search(context.Background(), Query{Text: "dune", APIKey: "secret-key"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-key")
+}
+
+func TestBookBrainz_Search(t *testing.T) {
+	var gotURL string
+	srv := serveJSON(t, &gotURL, `{"search-results":[
+		{"bbid":"bb-1","default-alias":{"name":"Dune"},"author-credit":{"names":[{"name":"Frank Herbert"}]}},
+		{"bbid":"bb-2","default-alias":{"name":""}}]}`)
+	p := &BookBrainz{baseURL: srv.URL, client: srv.Client()}
+
+	results, err := p.Search(context.Background(), Query{Text: "dune"})
+	require.NoError(t, err)
+	assert.Contains(t, gotURL, "/1/search?q=dune&type=edition")
+	require.Len(t, results, 1, "untitled editions are skipped")
+	assert.Equal(t, "bookbrainz", results[0].Source)
+	assert.Equal(t, "bb-1", results[0].BookBrainzID)
+	assert.Equal(t, "Frank Herbert", results[0].Author)
+}
+
+func TestGetJSON_NonOKStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	var dst any
+	err := getJSON(context.Background(), srv.Client(), "test", srv.URL, &dst)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test returned 429")
+}
@@ -0,0 +1,261 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Admin settings the Registry reads on every search, so a change applies
+// without a restart. Both are comma-separated lists of provider names;
+// unknown names are ignored.
+const (
+	// SettingPriority orders providers, highest priority first. Providers
+	// it leaves out follow the listed ones, in registration order.
+	SettingPriority = "metadata_provider_priority"
+	// SettingDisabled lists providers that are never queried.
+	SettingDisabled = "metadata_providers_disabled"
+)
+
+// Settings is where the Registry reads SettingPriority and SettingDisabled
+// from; repository.AdminRepository satisfies it.
+type Settings interface {
+	GetSetting(key string) (string, error)
+}
+
+// DefaultProviders returns the built-in providers in their default priority
+// order: Google Books, whose volumes carry the richest metadata, then Open
+// Library, then BookBrainz.
+func DefaultProviders() []Provider {
+	return []Provider{NewGoogleBooks(), NewOpenLibrary(), NewBookBrainz()}
+}
+
+// APIKeys maps a Google Books key to the provider that uses it, in the form
+// Registry.Search takes. The other built-in providers need no key.
+func APIKeys(googleBooksAPIKey string) map[string]string {
+	return map[string]string{"google_books": googleBooksAPIKey}
+}
+
+// Registry holds the metadata providers and decides, from admin settings,
+// which are enabled and in what order. It also tracks each provider's
+// health, and skips one whose circuit breaker is open. Adding a provider
+// means implementing Provider and passing it to NewRegistry.
+type Registry struct {
+	settings  Settings
+	providers []Provider
+	health    map[string]*providerHealth
+	now       func() time.Time
+}
+
+// NewRegistry creates a Registry of providers, listed in their default
+// priority order. settings may be nil, in which case every provider is
+// enabled in that order.
+func NewRegistry(settings Settings, providers ...Provider) *Registry {
+	health := make(map[string]*providerHealth, len(providers))
+	for _, p := range providers {
+		health[p.Name()] = &providerHealth{}
+	}
+	return &Registry{settings: settings, providers: providers, health: health, now: time.Now}
+}
+
+// ProviderState is a provider with its standing under the current
+// settings, and its health.
+type ProviderState struct {
+	Provider Provider
+	Enabled  bool
+	Health   ProviderHealth
+}
+
+// Providers returns every registered provider, enabled or not, in priority
+// order.
+func (r *Registry) Providers() []ProviderState {
+	disabled := map[string]bool{}
+	for _, name := range r.settingList(SettingDisabled) {
+		disabled[name] = true
+	}
+
+	byName := make(map[string]Provider, len(r.providers))
+	for _, p := range r.providers {
+		byName[p.Name()] = p
+	}
+	now := r.now()
+	placed := map[string]bool{}
+	out := make([]ProviderState, 0, len(r.providers))
+	add := func(p Provider) {
+		placed[p.Name()] = true
+		out = append(out, ProviderState{
+			Provider: p,
+			Enabled:  !disabled[p.Name()],
+			Health:   r.health[p.Name()].snapshot(now),
+		})
+	}
+	for _, name := range r.settingList(SettingPriority) {
+		if p, ok := byName[name]; ok && !placed[name] {
+			add(p)
+		}
+	}
+	for _, p := range r.providers {
+		if !placed[p.Name()] {
+			add(p)
+		}
+	}
+	return out
+}
+
+// Enabled returns the enabled providers in priority order.
+func (r *Registry) Enabled() []Provider {
+	var out []Provider
+	for _, s := range r.Providers() {
+		if s.Enabled {
+			out = append(out, s.Provider)
+		}
+	}
+	return out
+}
+
+// Fingerprint names the enabled providers in priority order, e.g.
+// "google_books,openlibrary". Results merged under one fingerprint rank
+// and include sources differently from another's, so a cache of them
+// should key on it.
+func (r *Registry) Fingerprint() string {
+	enabled := r.Enabled()
+	names := make([]string, len(enabled))
+	for i, p := range enabled {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Rank returns a function ranking a result's Source by the current
+// priority order, lower first. A source no provider is registered under
+// ranks after all of them.
+func (r *Registry) Rank() func(source string) int {
+	states := r.Providers()
+	ranks := make(map[string]int, len(states))
+	for i, s := range states {
+		ranks[s.Provider.Name()] = i
+	}
+	return func(source string) int {
+		if rank, ok := ranks[source]; ok {
+			return rank
+		}
+		return len(ranks)
+	}
+}
+
+// SearchReport says how a Search went: how many providers answered, and
+// which would have been asked but didn't answer.
+type SearchReport struct {
+	// Answered counts the providers that returned, results or not.
+	Answered int
+	// Unanswered names the providers skipped because their circuit breaker
+	// was open, or whose search failed, timed out or was abandoned.
+	// Providers never able to answer the query — disabled, lacking a
+	// capability or a required key — aren't listed.
+	Unanswered []string
+}
+
+// Complete reports whether every provider due to answer did, so the
+// results are as good as the providers can give.
+func (r SearchReport) Complete() bool {
+	return len(r.Unanswered) == 0
+}
+
+// Merge adds o's providers to r, for a search made of several Searches.
+func (r SearchReport) Merge(o SearchReport) SearchReport {
+	return SearchReport{
+		Answered:   r.Answered + o.Answered,
+		Unanswered: append(append([]string(nil), r.Unanswered...), o.Unanswered...),
+	}
+}
+
+// Search queries every enabled provider able to answer q concurrently,
+// each bounded by its own Timeout, and returns their results in priority
+// order. apiKeys holds the caller's key for each provider, by name; a
+// provider that requires a key is skipped without one, as is one whose
+// Capabilities don't cover q (an ISBN, or free text), or whose circuit
+// breaker is open. A provider's failure is logged, counts towards tripping
+// its breaker, and is otherwise ignored; the report says which providers
+// didn't answer, so a caller can tell degraded results from complete ones.
+func (r *Registry) Search(ctx context.Context, q Query, apiKeys map[string]string) ([]BookMetadataResult, SearchReport) {
+	providers := r.Enabled()
+	perProvider := make([][]BookMetadataResult, len(providers))
+	answered := make([]bool, len(providers))
+	asked := make([]bool, len(providers))
+	var report SearchReport
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		caps := p.Capabilities()
+		if (q.ISBN != "" && !caps.ISBN) || (q.ISBN == "" && !caps.FreeText) {
+			continue
+		}
+		pq := q
+		pq.APIKey = apiKeys[p.Name()]
+		if caps.RequiresAPIKey && pq.APIKey == "" {
+			continue
+		}
+		health := r.health[p.Name()]
+		if !health.allow(r.now()) {
+			zerolog.Ctx(ctx).Debug().Str("provider", p.Name()).Msg("metadata provider skipped: circuit open")
+			report.Unanswered = append(report.Unanswered, p.Name())
+			continue
+		}
+		asked[i] = true
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, p.Timeout())
+			defer cancel()
+			start := r.now()
+			items, err := p.Search(pctx, pq)
+			// A search abandoned by the caller says nothing about the
+			// provider, so it isn't recorded.
+			if ctx.Err() != nil {
+				health.release()
+			} else {
+				health.record(r.now(), r.now().Sub(start), err)
+			}
+			if err != nil {
+				zerolog.Ctx(ctx).Warn().Err(err).Str("provider", p.Name()).Msg("metadata search failed")
+				return
+			}
+			perProvider[i] = items
+			answered[i] = true
+		}(i, p)
+	}
+	wg.Wait()
+
+	var results []BookMetadataResult
+	for i, items := range perProvider {
+		results = append(results, items...)
+		switch {
+		case answered[i]:
+			report.Answered++
+		case asked[i]:
+			report.Unanswered = append(report.Unanswered, providers[i].Name())
+		}
+	}
+	return results, report
+}
+
+// settingList reads a comma-separated setting; unset or unreadable is
+// empty.
+func (r *Registry) settingList(key string) []string {
+	if r.settings == nil {
+		return nil
+	}
+	value, err := r.settings.GetSetting(key)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
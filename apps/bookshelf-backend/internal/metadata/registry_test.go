@@ -0,0 +1,168 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is a Provider returning a fixed result, recording the
+// queries it was asked.
+type fakeProvider struct {
+	name    string
+	caps    Capabilities
+	timeout time.Duration
+	delay   time.Duration
+	err     error
+	queries []Query
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Timeout() time.Duration {
+	if p.timeout == 0 {
+		return time.Second
+	}
+	return p.timeout
+}
+
+func (p *fakeProvider) Capabilities() Capabilities { return p.caps }
+
+func (p *fakeProvider) Search(ctx context.Context, q Query) ([]BookMetadataResult, error) {
+	p.queries = append(p.queries, q)
+	if p.delay > 0 {
+		select {
+		case <-time.After(p.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []BookMetadataResult{{Source: p.name, Title: q.Text}}, nil
+}
+
+func newFake(name string) *fakeProvider {
+	return &fakeProvider{name: name, caps: Capabilities{ISBN: true, FreeText: true}}
+}
+
+// mapSettings is a Settings backed by a map; missing keys are an error, as
+// with the admin repository.
+type mapSettings map[string]string
+
+func (s mapSettings) GetSetting(key string) (string, error) {
+	if v, ok := s[key]; ok {
+		return v, nil
+	}
+	return "", errors.New("not found")
+}
+
+func names(states []ProviderState) []string {
+	out := make([]string, len(states))
+	for i, s := range states {
+		out[i] = s.Provider.Name()
+	}
+	return out
+}
+
+func sources(results []BookMetadataResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Source
+	}
+	return out
+}
+
+func TestRegistry_Providers_DefaultOrderWithoutSettings(t *testing.T) {
+	r := NewRegistry(nil, newFake("a"), newFake("b"), newFake("c"))
+
+	states := r.Providers()
+	assert.Equal(t, []string{"a", "b", "c"}, names(states))
+	for _, s := range states {
+		assert.True(t, s.Enabled)
+	}
+}
+
+func TestRegistry_Providers_FollowsPrioritySetting(t *testing.T) {
+	r := NewRegistry(mapSettings{
+		// Unknown and repeated names are ignored; unlisted providers follow.
+		SettingPriority: " c, unknown ,c,a",
+		SettingDisabled: "a",
+	}, newFake("a"), newFake("b"), newFake("c"))
+
+	states := r.Providers()
+	assert.Equal(t, []string{"c", "a", "b"}, names(states))
+	assert.False(t, states[1].Enabled)
+	require.Len(t, r.Enabled(), 2)
+
+	assert.Equal(t, "c,b", r.Fingerprint())
+
+	rank := r.Rank()
+	assert.Equal(t, 0, rank("c"))
+	assert.Equal(t, 2, rank("b"))
+	assert.Equal(t, 3, rank("somewhere-else"), "unknown sources rank last")
+}
+
+func TestRegistry_Search_ReturnsResultsInPriorityOrder(t *testing.T) {
+	slow := newFake("slow")
+	slow.delay = 20 * time.Millisecond
+	r := NewRegistry(mapSettings{SettingPriority: "slow,fast"}, newFake("fast"), slow)
+
+	results, _ := r.Search(context.Background(), Query{Text: "dune"}, nil)
+	assert.Equal(t, []string{"slow", "fast"}, sources(results))
+}
+
+func TestRegistry_Search_SkipsDisabledAndIncapableProviders(t *testing.T) {
+	disabled := newFake("disabled")
+	textOnly := newFake("text_only")
+	textOnly.caps = Capabilities{FreeText: true}
+	isbnOnly := newFake("isbn_only")
+	isbnOnly.caps = Capabilities{ISBN: true}
+	r := NewRegistry(mapSettings{SettingDisabled: "disabled"}, disabled, textOnly, isbnOnly)
+
+	results, _ := r.Search(context.Background(), Query{Text: "9780441013593", ISBN: "9780441013593"}, nil)
+	assert.Equal(t, []string{"isbn_only"}, sources(results))
+
+	results, report := r.Search(context.Background(), Query{Text: "dune"}, nil)
+	assert.Equal(t, []string{"text_only"}, sources(results))
+	assert.Empty(t, disabled.queries)
+	assert.True(t, report.Complete(), "providers that could never answer don't make a search incomplete")
+}
+
+func TestRegistry_Search_PassesEachProviderItsOwnKey(t *testing.T) {
+	keyed := newFake("keyed")
+	keyed.caps.RequiresAPIKey = true
+	open := newFake("open")
+	r := NewRegistry(nil, keyed, open)
+
+	results, _ := r.Search(context.Background(), Query{Text: "dune"}, nil)
+	assert.Equal(t, []string{"open"}, sources(results), "a provider needing a key is skipped without one")
+	assert.Empty(t, keyed.queries)
+
+	results, _ = r.Search(context.Background(), Query{Text: "dune"}, map[string]string{"keyed": "k"})
+	assert.Equal(t, []string{"keyed", "open"}, sources(results))
+	require.Len(t, keyed.queries, 1)
+	assert.Equal(t, "k", keyed.queries[0].APIKey)
+	assert.Empty(t, open.queries[len(open.queries)-1].APIKey)
+}
+
+func TestRegistry_Search_DropsFailedAndTimedOutProviders(t *testing.T) {
+	failing := newFake("failing")
+	failing.err = errors.New("boom")
+	hung := newFake("hung")
+	hung.delay = time.Minute
+	hung.timeout = 10 * time.Millisecond
+	r := NewRegistry(nil, failing, hung, newFake("ok"))
+
+	start := time.Now()
+	results, report := r.Search(context.Background(), Query{Text: "dune"}, nil)
+	assert.Equal(t, []string{"ok"}, sources(results))
+	assert.Less(t, time.Since(start), 5*time.Second, "the hung provider is cut off at its timeout")
+	assert.Equal(t, SearchReport{Answered: 1, Unanswered: []string{"failing", "hung"}}, report)
+	assert.False(t, report.Complete())
+}
@@ -36,6 +36,15 @@ type User struct {
 	EmailNotificationsEnabled bool   `gorm:"column:email_notifications_enabled;not null" json:"email_notifications_enabled"`
 	TelegramUsername          string `gorm:"column:telegram_username" json:"telegram_username,omitempty"`
 	WhatsAppUsername          string `gorm:"column:whatsapp_username" json:"whatsapp_username,omitempty"`
+
+	// DeletionScheduledAt is when a self-service account deletion takes
+	// effect; nil when none is pending. DeletionTransferToID is who gets
+	// the member's copies then (nil: they're deleted). AnonymizedAt is set
+	// once the deletion has run and this row is only a placeholder for the
+	// member on loan history, named "Deleted member".
+	DeletionScheduledAt  *time.Time `gorm:"column:deletion_scheduled_at" json:"deletion_scheduled_at,omitempty"`
+	DeletionTransferToID *uint      `gorm:"column:deletion_transfer_to_id" json:"-"`
+	AnonymizedAt         *time.Time `gorm:"column:anonymized_at" json:"-"`
 }
 
 // RegistrationVerification holds a short-lived OTP code proving control of an
@@ -104,8 +113,43 @@ type Book struct {
 	// than sourced for this book itself — see
 	// internal/services/description_reconciliation.go. Never cleared
 	// automatically if Description is later edited directly.
-	DescriptionEnriched bool   `gorm:"not null;default:false" json:"description_enriched"`
-	Copies              []Copy `json:"copies,omitempty"`
+	DescriptionEnriched bool `gorm:"not null;default:false" json:"description_enriched"`
+	// MetadataCheckedAt is when the metadata-backfill job last looked this
+	// book up to fill its empty fields — see
+	// internal/services/metadata_backfill.go. Nil if it never has.
+	MetadataCheckedAt *time.Time `gorm:"index" json:"-"`
+	// CoverBlurhash is a BlurHash placeholder for a locally cached cover,
+	// shown while the image loads; "" for external covers and ones that
+	// couldn't be decoded — see internal/covers.
+	CoverBlurhash string `gorm:"not null;default:''" json:"cover_blurhash,omitempty"`
+	Copies        []Copy `json:"copies,omitempty"`
+	// Contributors is the structured form of Author — every person credited
+	// on the book, with their role. Author stays the free-text display
+	// credit; Contributors is derived from it (or from richer metadata) by
+	// services.ContributorService.
+	Contributors []BookContributor `json:"contributors,omitempty"`
+}
+
+// Author is a person credited on one or more books. NameKey
+// (bookmatch.AuthorKey) is what makes "Lewis, C.S." and "C. S. Lewis" the
+// same Author; Name is the normalized display form of whichever spelling
+// was seen first.
+type Author struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	NameKey   string    `gorm:"not null;uniqueIndex" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BookContributor credits an Author on a Book in a Role (bookmatch.Role*:
+// author, editor or translator). Position orders a book's contributors as
+// credited.
+type BookContributor struct {
+	BookID   uint   `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	AuthorID uint   `gorm:"primaryKey;autoIncrement:false" json:"author_id"`
+	Role     string `gorm:"primaryKey" json:"role"`
+	Position int    `gorm:"not null" json:"-"`
+	Author   Author `json:"author"`
 }
 
 // Copy is a physical instance of a Book owned by a church member.
@@ -174,6 +218,67 @@ type WishlistRequest struct {
 	CreatedAt       time.Time  `json:"created_at"`
 	Requester       User       `json:"requester,omitempty"`
 	FulfilledBook   *Book      `json:"fulfilled_book,omitempty"`
+	// CoRequesters are the members who joined this request ("me too")
+	// rather than posting a duplicate of it.
+	CoRequesters []WishlistCoRequester `json:"co_requesters,omitempty"`
+}
+
+// WishlistCoRequester is a member who joined another member's
+// WishlistRequest. A member joins a request at most once, and never their
+// own.
+type WishlistCoRequester struct {
+	ID                uint      `gorm:"primarykey" json:"id"`
+	WishlistRequestID uint      `gorm:"not null;uniqueIndex:idx_wishlist_co_requesters_request_user" json:"wishlist_request_id"`
+	UserID            uint      `gorm:"not null;uniqueIndex:idx_wishlist_co_requesters_request_user;index" json:"user_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	User              User      `json:"user,omitempty"`
+}
+
+// BookAffinity is one directed edge of the "borrowed together" graph: Score
+// counts the distinct members who have borrowed both BookID and
+// RelatedBookID. Rows are written in both directions and rebuilt wholesale
+// by the recommendations job (internal/services/recommendations.go) — never
+// edited in place.
+type BookAffinity struct {
+	BookID        uint `gorm:"primaryKey;autoIncrement:false" json:"book_id"`
+	RelatedBookID uint `gorm:"primaryKey;autoIncrement:false" json:"related_book_id"`
+	Score         int  `gorm:"not null" json:"score"`
+}
+
+// UserRecommendation is one precomputed "you might like" entry for UserID,
+// derived from BookAffinity and already excluding books the user owns or has
+// borrowed at the time the recommendations job last ran.
+type UserRecommendation struct {
+	UserID uint `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	BookID uint `gorm:"primaryKey;autoIncrement:false" json:"book_id"`
+	Score  int  `gorm:"not null" json:"score"`
+}
+
+// ReadingList is a member's own named list of catalog books — "to read",
+// "favourites" and so on. Unlike a WishlistRequest it only ever references
+// Books already in the catalog and asks nothing of other members. Private
+// lists (the default) are visible to their owner and admins only.
+type ReadingList struct {
+	ID          uint               `gorm:"primarykey" json:"id"`
+	OwnerID     uint               `gorm:"not null" json:"owner_id"`
+	Name        string             `gorm:"not null" json:"name"`
+	Description string             `json:"description"`
+	IsPublic    bool               `gorm:"column:is_public;not null;default:false" json:"is_public"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Entries     []ReadingListEntry `json:"entries,omitempty"`
+}
+
+// ReadingListEntry places one Book on a ReadingList. A book appears at most
+// once per list; Position orders entries ascending.
+type ReadingListEntry struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	ReadingListID uint      `gorm:"not null;uniqueIndex:idx_reading_list_entries_list_book" json:"reading_list_id"`
+	BookID        uint      `gorm:"not null;uniqueIndex:idx_reading_list_entries_list_book" json:"book_id"`
+	Position      int       `gorm:"not null" json:"position"`
+	Notes         string    `json:"notes"`
+	CreatedAt     time.Time `json:"created_at"`
+	Book          Book      `json:"book,omitempty"`
 }
 
 // Notification is an in-app alert delivered to a user.
@@ -181,7 +286,10 @@ type WishlistRequest struct {
 //
 //	marked_loaned | marked_returned | return_undone | waitlist_available |
 //	copy_transferred_in | copy_transferred_out | wishlist_fulfilled |
-//	user_pending_approval
+//	wishlist_possible_match | user_pending_approval
+//
+// BookID is set only on wishlist_possible_match: the newly added Book that
+// may be what WishlistRequestID's requester wanted.
 type Notification struct {
 	ID                uint      `gorm:"primarykey" json:"id"`
 	RecipientID       uint      `gorm:"not null" json:"recipient_id"`
@@ -189,6 +297,7 @@ type Notification struct {
 	LoanRequestID     *uint     `json:"loan_request_id"`
 	WishlistRequestID *uint     `json:"wishlist_request_id"`
 	PendingUserID     *uint     `json:"pending_user_id"`
+	BookID            *uint     `json:"book_id"`
 	Read              bool      `gorm:"default:false" json:"read"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -212,3 +321,50 @@ type Announcement struct {
 	Active    bool      `gorm:"not null" json:"active"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// MetadataCacheEntry is one cached metadata search response, keyed by the
+// search's cache key. Results is the consolidated []BookMetadataResult as
+// JSON — the cache only ever reads it back whole.
+type MetadataCacheEntry struct {
+	Key       string    `gorm:"primaryKey"`
+	Results   string    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// ImportJob is a books import (POST /copies/mine/import) running in the
+// background. Content and Decisions are the upload as submitted, kept until
+// the job finishes so a job interrupted by a restart can resume where it
+// stopped; ProcessedRows counts the rows already recorded as ImportJobRows.
+// Status values: queued | running | completed | failed | cancelled
+type ImportJob struct {
+	ID            uint   `gorm:"primarykey" json:"id"`
+	OwnerID       uint   `gorm:"not null;index" json:"owner_id"`
+	Status        string `gorm:"not null;default:'queued';index" json:"status"`
+	Format        string `gorm:"not null" json:"format"`
+	Source        string `gorm:"not null" json:"source"`
+	Content       string `gorm:"not null" json:"-"`
+	Decisions     string `gorm:"not null" json:"-"` // JSON object, row number → decision
+	TotalRows     int    `gorm:"not null" json:"total_rows"`
+	ProcessedRows int    `gorm:"not null" json:"processed_rows"`
+	// Error is set when Status is failed.
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// ImportJobRow is the outcome of one row of an ImportJob. Row is the 1-based
+// row number in the file; Action is one of the import actions
+// (create_book | match_existing_book | skipped).
+type ImportJobRow struct {
+	ID                uint   `gorm:"primarykey"`
+	ImportJobID       uint   `gorm:"not null;uniqueIndex:idx_import_job_rows_job_row"`
+	Row               int    `gorm:"not null;uniqueIndex:idx_import_job_rows_job_row"`
+	Title             string `gorm:"not null"`
+	Action            string `gorm:"not null"`
+	Reason            string
+	MatchedBookID     *uint
+	MatchedBookTitle  string
+	MatchedBookAuthor string
+}
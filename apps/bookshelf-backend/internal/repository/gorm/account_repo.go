@@ -0,0 +1,298 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// AccountRepository is the GORM implementation of repository.AccountRepository.
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository creates a new AccountRepository.
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+func (r *AccountRepository) Export(userID uint) (*repository.AccountData, error) {
+	var d repository.AccountData
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&d.User, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrNotFound
+			}
+			return err
+		}
+		if err := tx.Preload("Book").Where("owner_id = ?", userID).Order("id").Find(&d.Copies).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Copy.Book").Preload("Copy.Owner").
+			Where("borrower_id = ?", userID).Order("id").Find(&d.LoansAsBorrower).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Copy.Book").Preload("Borrower").
+			Where("copy_id IN (?)", tx.Model(&models.Copy{}).Select("id").Where("owner_id = ?", userID)).
+			Order("id").Find(&d.LoansAsOwner).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Order("id").Find(&d.Waitlist).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Book").
+			Where("id IN (?)", tx.Model(&models.WaitlistEntry{}).Select("copy_id").Where("user_id = ?", userID)).
+			Order("id").Find(&d.WaitlistCopies).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("FulfilledBook").Where("requester_id = ?", userID).Order("id").Find(&d.Wishlist).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("CoRequesters", "user_id = ?", userID).
+			Where("id IN (?)", tx.Model(&models.WishlistCoRequester{}).Select("wishlist_request_id").Where("user_id = ?", userID)).
+			Order("id").Find(&d.WishlistJoined).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("recipient_id = ?", userID).Order("id").Find(&d.Notifications).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Entries", func(tx *gorm.DB) *gorm.DB { return tx.Order("position") }).Preload("Entries.Book").
+			Where("owner_id = ?", userID).Order("id").Find(&d.ReadingLists).Error; err != nil {
+			return err
+		}
+		return tx.Where("owner_id = ?", userID).Order("id").Find(&d.ImportJobs).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// deletedMemberName is what an anonymized account is called on the loan
+// history it stays attached to.
+const deletedMemberName = "Deleted member"
+
+// ownedCopyIDs is a subquery for the IDs of userID's copies.
+func ownedCopyIDs(tx *gorm.DB, userID uint) *gorm.DB {
+	return tx.Model(&models.Copy{}).Select("id").Where("owner_id = ?", userID)
+}
+
+func countActiveLoans(tx *gorm.DB, userID uint) (int64, error) {
+	var n int64
+	err := tx.Model(&models.LoanRequest{}).
+		Where("status = ?", "accepted").
+		Where("borrower_id = ? OR copy_id IN (?)", userID, ownedCopyIDs(tx, userID)).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *AccountRepository) CountActiveLoans(userID uint) (int64, error) {
+	return countActiveLoans(r.db, userID)
+}
+
+func (r *AccountRepository) ListDueDeletions(now time.Time) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *AccountRepository) Delete(userID uint, now time.Time) ([]string, error) {
+	var covers []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrNotFound
+			}
+			return err
+		}
+		active, err := countActiveLoans(tx, userID)
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return repository.ErrActiveLoans
+		}
+		if err := settlePendingLoans(tx, userID, now); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? OR copy_id IN (?)", userID, ownedCopyIDs(tx, userID)).
+			Delete(&models.WaitlistEntry{}).Error; err != nil {
+			return err
+		}
+		if covers, err = handOverCopies(tx, &user); err != nil {
+			return err
+		}
+		if err := handOverWishlist(tx, userID); err != nil {
+			return err
+		}
+		if err := deleteOwnedRows(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&user).Select("*").Omit("id", "created_at").Updates(models.User{
+			Name:         deletedMemberName,
+			Email:        fmt.Sprintf("deleted-%d@deleted.invalid", userID),
+			Role:         "user",
+			Suspended:    true,
+			AnonymizedAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return covers, nil
+}
+
+// settlePendingLoans cancels userID's pending loan requests and declines
+// pending requests for their copies, telling each borrower as OnRejected
+// does, then frees any copy no request is pending on any more.
+func settlePendingLoans(tx *gorm.DB, userID uint, now time.Time) error {
+	var declined []models.LoanRequest
+	if err := tx.Where("status = ? AND copy_id IN (?)", "pending", ownedCopyIDs(tx, userID)).
+		Order("id").Find(&declined).Error; err != nil {
+		return err
+	}
+	var cancelled []models.LoanRequest
+	if err := tx.Where("status = ? AND borrower_id = ?", "pending", userID).Find(&cancelled).Error; err != nil {
+		return err
+	}
+	copyIDs := make([]uint, 0, len(declined)+len(cancelled))
+	for _, lr := range declined {
+		if err := tx.Model(&lr).Updates(map[string]any{"status": "rejected", "responded_at": now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.Notification{RecipientID: lr.BorrowerID, Type: "request_rejected", LoanRequestID: &lr.ID}).Error; err != nil {
+			return err
+		}
+		copyIDs = append(copyIDs, lr.CopyID)
+	}
+	for _, lr := range cancelled {
+		if err := tx.Model(&lr).Update("status", "cancelled").Error; err != nil {
+			return err
+		}
+		copyIDs = append(copyIDs, lr.CopyID)
+	}
+	if len(copyIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.Copy{}).
+		Where("id IN ? AND status = ?", copyIDs, "requested").
+		Where("NOT EXISTS (SELECT 1 FROM loan_requests WHERE loan_requests.copy_id = copies.id AND loan_requests.status = 'pending')").
+		Update("status", "available").Error
+}
+
+// handOverCopies moves user's copies to user.DeletionTransferToID, telling
+// them once (however many copies there are) as a copy transfer does, or
+// deletes the copies when there's no one (still) to take them. It returns
+// the cover URLs of the books deleted along with them.
+func handOverCopies(tx *gorm.DB, user *models.User) ([]string, error) {
+	var copyIDs []uint
+	if err := ownedCopyIDs(tx, user.ID).Order("id").Pluck("id", &copyIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(copyIDs) == 0 {
+		return nil, nil
+	}
+	if to := user.DeletionTransferToID; to != nil && *to != user.ID {
+		var recipient models.User
+		err := tx.Where("anonymized_at IS NULL AND suspended = ?", false).First(&recipient, *to).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&models.Copy{}).Where("id IN ?", copyIDs).Update("owner_id", recipient.ID).Error; err != nil {
+				return nil, err
+			}
+			return nil, tx.Create(&models.Notification{RecipientID: recipient.ID, Type: "copy_transferred_in"}).Error
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	return deleteCopies(tx, copyIDs)
+}
+
+// deleteCopies deletes copyIDs, then cleans up a book left with no copies
+// as CopyHandler.deleteCopy does: deleted if it's keyless, since nothing
+// could ever match it again. It returns those books' cover URLs, for the
+// caller to remove the cached files (see covers.RemoveCached).
+//
+// The loans on the copies — every one settled by now — are the borrowers'
+// history as much as the owner's, so they stay, and so do the borrowers'
+// notifications about them. Like a loan on a copy its owner deleted, each
+// keeps the copy's ID, which AUTOINCREMENT never hands out again.
+func deleteCopies(tx *gorm.DB, copyIDs []uint) ([]string, error) {
+	var bookIDs []uint
+	if err := tx.Model(&models.Copy{}).Where("id IN ?", copyIDs).Distinct().Pluck("book_id", &bookIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("id IN ?", copyIDs).Delete(&models.Copy{}).Error; err != nil {
+		return nil, err
+	}
+
+	var orphans []models.Book
+	if err := tx.Where("id IN ? AND ol_key = '' AND google_books_id = '' AND isbn = ''", bookIDs).
+		Where("NOT EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)").
+		Find(&orphans).Error; err != nil {
+		return nil, err
+	}
+	var covers []string
+	for _, b := range orphans {
+		if err := tx.Model(&models.WishlistRequest{}).Where("fulfilled_book_id = ?", b.ID).
+			Update("fulfilled_book_id", nil).Error; err != nil {
+			return nil, err
+		}
+		if err := deleteBook(tx, b.ID); err != nil {
+			return nil, err
+		}
+		if b.CoverURL != "" {
+			covers = append(covers, b.CoverURL)
+		}
+	}
+	return covers, nil
+}
+
+// handOverWishlist passes each of userID's open wishlist requests to its
+// earliest co-requester, so the members who joined it keep waiting for the
+// book, and cancels those nobody joined. A handed-over request becomes
+// anonymous: its new requester never chose to be named on the board.
+func handOverWishlist(tx *gorm.DB, userID uint) error {
+	var open []models.WishlistRequest
+	if err := tx.Where("requester_id = ? AND status = ?", userID, "open").Find(&open).Error; err != nil {
+		return err
+	}
+	for _, w := range open {
+		if _, err := handOverWishlistRequest(tx, w.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteOwnedRows deletes what only ever mattered to userID: their
+// notifications, reading lists, imports and recommendations.
+func deleteOwnedRows(tx *gorm.DB, userID uint) error {
+	if err := tx.Where("recipient_id = ?", userID).Delete(&models.Notification{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.WishlistCoRequester{}).Error; err != nil {
+		return err
+	}
+	lists := tx.Model(&models.ReadingList{}).Select("id").Where("owner_id = ?", userID)
+	if err := tx.Where("reading_list_id IN (?)", lists).Delete(&models.ReadingListEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("owner_id = ?", userID).Delete(&models.ReadingList{}).Error; err != nil {
+		return err
+	}
+	jobs := tx.Model(&models.ImportJob{}).Select("id").Where("owner_id = ?", userID)
+	if err := tx.Where("import_job_id IN (?)", jobs).Delete(&models.ImportJobRow{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("owner_id = ?", userID).Delete(&models.ImportJob{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.UserRecommendation{}).Error
+}
@@ -0,0 +1,244 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+func TestAccountRepository_Export(t *testing.T) {
+	db := openTestDB(t)
+	me := models.User{Name: "Me", Email: "me@example.com", Password: "x", Role: "user"}
+	other := models.User{Name: "Other", Email: "other@example.com", Password: "x", Role: "user"}
+	require.NoError(t, db.Create(&me).Error)
+	require.NoError(t, db.Create(&other).Error)
+	dune := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	emma := models.Book{Title: "Emma", Author: "Jane Austen"}
+	require.NoError(t, db.Create(&dune).Error)
+	require.NoError(t, db.Create(&emma).Error)
+	mine := models.Copy{BookID: dune.ID, OwnerID: me.ID, Status: "loaned"}
+	theirs := models.Copy{BookID: emma.ID, OwnerID: other.ID, Status: "loaned"}
+	require.NoError(t, db.Create(&mine).Error)
+	require.NoError(t, db.Create(&theirs).Error)
+	require.NoError(t, db.Create(&models.LoanRequest{CopyID: mine.ID, BorrowerID: other.ID, Status: "active"}).Error)
+	require.NoError(t, db.Create(&models.LoanRequest{CopyID: theirs.ID, BorrowerID: me.ID, Status: "active"}).Error)
+	require.NoError(t, db.Create(&models.WaitlistEntry{CopyID: theirs.ID, UserID: me.ID}).Error)
+	require.NoError(t, db.Create(&models.WaitlistEntry{CopyID: mine.ID, UserID: other.ID}).Error)
+	require.NoError(t, db.Create(&models.WishlistRequest{RequesterID: me.ID, Title: "Persuasion", Author: "Jane Austen"}).Error)
+	middlemarch := models.WishlistRequest{RequesterID: other.ID, Title: "Middlemarch", Author: "George Eliot"}
+	require.NoError(t, db.Create(&middlemarch).Error)
+	require.NoError(t, db.Create(&models.WishlistCoRequester{WishlistRequestID: middlemarch.ID, UserID: me.ID}).Error)
+	require.NoError(t, db.Create(&models.WishlistCoRequester{WishlistRequestID: middlemarch.ID, UserID: 999}).Error)
+	require.NoError(t, db.Create(&models.Notification{RecipientID: me.ID, Type: "loan_request"}).Error)
+	require.NoError(t, db.Create(&models.Notification{RecipientID: other.ID, Type: "loan_request"}).Error)
+	list := models.ReadingList{OwnerID: me.ID, Name: "Classics"}
+	require.NoError(t, db.Create(&list).Error)
+	require.NoError(t, db.Create(&models.ReadingListEntry{ReadingListID: list.ID, BookID: emma.ID, Position: 1}).Error)
+	require.NoError(t, db.Create(&models.ImportJob{OwnerID: me.ID, Format: "csv", Source: "goodreads", Content: "x", Decisions: "{}"}).Error)
+
+	d, err := NewAccountRepository(db).Export(me.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "me@example.com", d.User.Email)
+	require.Len(t, d.Copies, 1)
+	assert.Equal(t, "Dune", d.Copies[0].Book.Title)
+
+	require.Len(t, d.LoansAsBorrower, 1)
+	assert.Equal(t, "Emma", d.LoansAsBorrower[0].Copy.Book.Title)
+	assert.Equal(t, "Other", d.LoansAsBorrower[0].Copy.Owner.Name)
+	require.Len(t, d.LoansAsOwner, 1)
+	assert.Equal(t, "Dune", d.LoansAsOwner[0].Copy.Book.Title)
+	assert.Equal(t, "Other", d.LoansAsOwner[0].Borrower.Name)
+
+	require.Len(t, d.Waitlist, 1)
+	assert.Equal(t, theirs.ID, d.Waitlist[0].CopyID)
+	require.Len(t, d.WaitlistCopies, 1)
+	assert.Equal(t, "Emma", d.WaitlistCopies[0].Book.Title)
+
+	require.Len(t, d.Wishlist, 1)
+	assert.Equal(t, "Persuasion", d.Wishlist[0].Title)
+	require.Len(t, d.WishlistJoined, 1)
+	assert.Equal(t, "Middlemarch", d.WishlistJoined[0].Title)
+	require.Len(t, d.WishlistJoined[0].CoRequesters, 1, "only the exporting member's own row")
+	assert.Equal(t, me.ID, d.WishlistJoined[0].CoRequesters[0].UserID)
+	assert.Len(t, d.Notifications, 1)
+	require.Len(t, d.ReadingLists, 1)
+	require.Len(t, d.ReadingLists[0].Entries, 1)
+	assert.Equal(t, "Emma", d.ReadingLists[0].Entries[0].Book.Title)
+	assert.Len(t, d.ImportJobs, 1)
+
+	_, err = NewAccountRepository(db).Export(999)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestAccountRepository_Delete(t *testing.T) {
+	db := openTestDB(t)
+	accounts := NewAccountRepository(db)
+	now := time.Now()
+	leaving := models.User{Name: "Leaving", Email: "leaving@example.com", Password: "hash", Role: "user", Phone: "555"}
+	heir := models.User{Name: "Heir", Email: "heir@example.com", Password: "x", Role: "user"}
+	friend := models.User{Name: "Friend", Email: "friend@example.com", Password: "x", Role: "user"}
+	for _, u := range []*models.User{&leaving, &heir, &friend} {
+		require.NoError(t, db.Create(u).Error)
+	}
+	book := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, db.Create(&book).Error)
+	mine := models.Copy{BookID: book.ID, OwnerID: leaving.ID, Status: "requested"}
+	theirs := models.Copy{BookID: book.ID, OwnerID: friend.ID, Status: "requested"}
+	require.NoError(t, db.Create(&mine).Error)
+	require.NoError(t, db.Create(&theirs).Error)
+	require.NoError(t, db.Create(&models.Copy{BookID: book.ID, OwnerID: leaving.ID, Status: "available"}).Error)
+	lent := models.LoanRequest{CopyID: mine.ID, BorrowerID: friend.ID, Status: "accepted"}
+	require.NoError(t, db.Create(&lent).Error)
+	history := models.LoanRequest{CopyID: theirs.ID, BorrowerID: leaving.ID, Status: "returned"}
+	require.NoError(t, db.Create(&history).Error)
+	require.NoError(t, db.Create(&models.LoanRequest{CopyID: mine.ID, BorrowerID: friend.ID, Status: "pending"}).Error)
+	require.NoError(t, db.Create(&models.LoanRequest{CopyID: theirs.ID, BorrowerID: leaving.ID, Status: "pending"}).Error)
+	require.NoError(t, db.Create(&models.WaitlistEntry{CopyID: mine.ID, UserID: friend.ID}).Error)
+	require.NoError(t, db.Create(&models.WishlistRequest{RequesterID: leaving.ID, Title: "Emma", Author: "Jane Austen", Status: "open"}).Error)
+	joined := models.WishlistRequest{RequesterID: leaving.ID, Title: "Persuasion", Author: "Jane Austen", Status: "open"}
+	require.NoError(t, db.Create(&joined).Error)
+	require.NoError(t, db.Create(&models.WishlistCoRequester{WishlistRequestID: joined.ID, UserID: friend.ID}).Error)
+	theirWish := models.WishlistRequest{RequesterID: friend.ID, Title: "Middlemarch", Author: "George Eliot", Status: "open"}
+	require.NoError(t, db.Create(&theirWish).Error)
+	require.NoError(t, db.Create(&models.WishlistCoRequester{WishlistRequestID: theirWish.ID, UserID: leaving.ID}).Error)
+	require.NoError(t, db.Create(&models.Notification{RecipientID: leaving.ID, Type: "request_received"}).Error)
+	require.NoError(t, db.Create(&models.ReadingList{OwnerID: leaving.ID, Name: "Mine"}).Error)
+	require.NoError(t, db.Model(&leaving).Update("deletion_transfer_to_id", heir.ID).Error)
+
+	n, err := accounts.CountActiveLoans(leaving.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "the copy they lent out")
+	_, err = accounts.Delete(leaving.ID, now)
+	assert.ErrorIs(t, err, repository.ErrActiveLoans)
+
+	require.NoError(t, db.Model(&lent).Update("status", "returned").Error)
+	covers, err := accounts.Delete(leaving.ID, now)
+	require.NoError(t, err)
+	assert.Empty(t, covers, "the copies were handed over, so no book went")
+
+	var gone models.User
+	require.NoError(t, db.First(&gone, leaving.ID).Error)
+	assert.Equal(t, "Deleted member", gone.Name)
+	assert.NotEqual(t, "leaving@example.com", gone.Email)
+	assert.Empty(t, gone.Password)
+	assert.Empty(t, gone.Phone)
+	assert.True(t, gone.Suspended)
+	assert.NotNil(t, gone.AnonymizedAt)
+
+	var kept models.LoanRequest
+	require.NoError(t, db.First(&kept, history.ID).Error)
+	assert.Equal(t, leaving.ID, kept.BorrowerID, "loan history stays, attached to the anonymized row")
+
+	var moved models.Copy
+	require.NoError(t, db.First(&moved, mine.ID).Error)
+	assert.Equal(t, heir.ID, moved.OwnerID)
+	assert.Equal(t, "available", moved.Status)
+	var other models.Copy
+	require.NoError(t, db.First(&other, theirs.ID).Error)
+	assert.Equal(t, "available", other.Status, "their cancelled request no longer holds the copy")
+
+	var statuses []string
+	require.NoError(t, db.Model(&models.LoanRequest{}).Order("id").Pluck("status", &statuses).Error)
+	assert.Equal(t, []string{"returned", "returned", "rejected", "cancelled"}, statuses)
+
+	var count int64
+	require.NoError(t, db.Model(&models.WaitlistEntry{}).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, db.Model(&models.ReadingList{}).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, db.Model(&models.Notification{}).Where("recipient_id = ?", leaving.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, db.Model(&models.Notification{}).Where("recipient_id = ? AND type = ?", friend.ID, "request_rejected").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	require.NoError(t, db.Model(&models.Notification{}).Where("recipient_id = ? AND type = ?", heir.ID, "copy_transferred_in").Count(&count).Error)
+	assert.Equal(t, int64(1), count, "one notification for both copies")
+	require.NoError(t, db.Model(&models.Copy{}).Where("owner_id = ?", heir.ID).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+	var wish models.WishlistRequest
+	require.NoError(t, db.First(&wish).Error)
+	assert.Equal(t, "cancelled", wish.Status, "nobody joined it")
+	var handedOver models.WishlistRequest
+	require.NoError(t, db.First(&handedOver, joined.ID).Error)
+	assert.Equal(t, "open", handedOver.Status)
+	assert.Equal(t, friend.ID, handedOver.RequesterID, "passed to the member who joined it")
+	assert.True(t, handedOver.IsAnonymous)
+	require.NoError(t, db.Model(&models.WishlistCoRequester{}).Count(&count).Error)
+	assert.Zero(t, count, "neither the heir's row nor the leaver's own joins remain")
+
+	members, err := NewAdminRepository(db).ListUsers()
+	require.NoError(t, err)
+	assert.Len(t, members, 2, "the placeholder isn't listed as a member")
+}
+
+func TestAccountRepository_DeleteWithoutHeirDeletesCopies(t *testing.T) {
+	db := openTestDB(t)
+	leaving := models.User{Name: "Leaving", Email: "leaving@example.com", Password: "x", Role: "user"}
+	require.NoError(t, db.Create(&leaving).Error)
+	borrower := models.User{Name: "Borrower", Email: "borrower@example.com", Password: "x", Role: "user"}
+	require.NoError(t, db.Create(&borrower).Error)
+	keyed := models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719", CoverURL: "/api/covers/dune.jpg"}
+	keyless := models.Book{Title: "Zine", Author: "Nobody", CoverURL: "/api/covers/zine.jpg"}
+	shared := models.Book{Title: "Notes", Author: "Someone"}
+	for _, b := range []*models.Book{&keyed, &keyless, &shared} {
+		require.NoError(t, db.Create(b).Error)
+	}
+	require.NoError(t, db.Create(&models.BookContributor{BookID: keyless.ID, AuthorID: 1, Role: "author"}).Error)
+	var lentOut models.Copy
+	for _, b := range []models.Book{keyed, keyless, shared} {
+		c := models.Copy{BookID: b.ID, OwnerID: leaving.ID, Status: "available"}
+		require.NoError(t, db.Create(&c).Error)
+		if b.ID == keyless.ID {
+			lentOut = c
+		}
+	}
+	require.NoError(t, db.Create(&models.Copy{BookID: shared.ID, OwnerID: borrower.ID, Status: "available"}).Error)
+	history := models.LoanRequest{CopyID: lentOut.ID, BorrowerID: borrower.ID, Status: "returned"}
+	require.NoError(t, db.Create(&history).Error)
+	require.NoError(t, db.Create(&models.Notification{RecipientID: borrower.ID, Type: "marked_returned", LoanRequestID: &history.ID}).Error)
+	wish := models.WishlistRequest{RequesterID: borrower.ID, Title: "Zine", Status: "fulfilled", FulfilledBookID: &keyless.ID}
+	require.NoError(t, db.Create(&wish).Error)
+
+	covers, err := NewAccountRepository(db).Delete(leaving.ID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/covers/zine.jpg"}, covers, "only the book that went")
+
+	var count int64
+	require.NoError(t, db.Model(&models.Copy{}).Where("owner_id = ?", leaving.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	var left []uint
+	require.NoError(t, db.Model(&models.Book{}).Order("id").Pluck("id", &left).Error)
+	assert.Equal(t, []uint{keyed.ID, shared.ID}, left, "the keyless book nobody else has goes; a keyed or still-shared one stays")
+	require.NoError(t, db.Model(&models.BookContributor{}).Count(&count).Error)
+	assert.Zero(t, count)
+	var kept models.LoanRequest
+	require.NoError(t, db.First(&kept, history.ID).Error, "the borrower's history stays")
+	assert.Equal(t, borrower.ID, kept.BorrowerID)
+	require.NoError(t, db.Model(&models.Notification{}).Where("recipient_id = ?", borrower.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count, "and so do their notifications about it")
+	var got models.WishlistRequest
+	require.NoError(t, db.First(&got, wish.ID).Error)
+	assert.Nil(t, got.FulfilledBookID)
+}
+
+func TestAccountRepository_ListDueDeletions(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	due := models.User{Name: "Due", Email: "due@example.com", Password: "x", Role: "user", DeletionScheduledAt: &past}
+	later := models.User{Name: "Later", Email: "later@example.com", Password: "x", Role: "user", DeletionScheduledAt: &future}
+	done := models.User{Name: "Done", Email: "done@example.com", Password: "x", Role: "user", DeletionScheduledAt: &past, AnonymizedAt: &past}
+	stay := models.User{Name: "Stay", Email: "stay@example.com", Password: "x", Role: "user"}
+	for _, u := range []*models.User{&due, &later, &done, &stay} {
+		require.NoError(t, db.Create(u).Error)
+	}
+
+	users, err := NewAccountRepository(db).ListDueDeletions(now)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, due.ID, users[0].ID)
+}
@@ -24,9 +24,13 @@ func NewAdminRepository(db *gorm.DB) *AdminRepository {
 	return &AdminRepository{db: db}
 }
 
+// isMember restricts a users query to real members, leaving out the
+// placeholder rows AccountRepository.Delete leaves behind for loan history.
+const isMember = "anonymized_at IS NULL"
+
 func (r *AdminRepository) ListUsers() ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Order("created_at asc").Find(&users).Error; err != nil {
+	if err := r.db.Where(isMember).Order("created_at asc").Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
@@ -34,12 +38,12 @@ func (r *AdminRepository) ListUsers() ([]models.User, error) {
 
 func (r *AdminRepository) ListUsersPaginated(page, pageSize int) (*repository.PaginatedResult[models.User], error) {
 	var total int64
-	if err := r.db.Model(&models.User{}).Count(&total).Error; err != nil {
+	if err := r.db.Model(&models.User{}).Where(isMember).Count(&total).Error; err != nil {
 		return nil, err
 	}
 	var users []models.User
 	offset := (page - 1) * pageSize
-	if err := r.db.Order("created_at asc").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	if err := r.db.Where(isMember).Order("created_at asc").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
 		return nil, err
 	}
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
@@ -121,7 +125,7 @@ func (r *AdminRepository) GetDashboardStats() (*repository.DashboardStats, error
 	if err := r.db.Model(&models.Copy{}).Where("status = ?", "loaned").Count(&stats.LoanedCopies).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Model(&models.User{}).Count(&stats.TotalUsers).Error; err != nil {
+	if err := r.db.Model(&models.User{}).Where(isMember).Count(&stats.TotalUsers).Error; err != nil {
 		return nil, err
 	}
 	if err := r.db.Model(&models.User{}).
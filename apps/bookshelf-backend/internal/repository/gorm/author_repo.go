@@ -0,0 +1,100 @@
+package gorm
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// AuthorRepository is the GORM implementation of repository.AuthorRepository.
+type AuthorRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthorRepository creates a new AuthorRepository.
+func NewAuthorRepository(db *gorm.DB) *AuthorRepository {
+	return &AuthorRepository{db: db}
+}
+
+// FindOrCreate inserts-or-ignores on name_key and then reads the row back,
+// so two books linking the same new author concurrently still end up
+// sharing one Author.
+func (r *AuthorRepository) FindOrCreate(a *models.Author) error {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(a).Error; err != nil {
+		return err
+	}
+	return r.db.Where("name_key = ?", a.NameKey).First(a).Error
+}
+
+func (r *AuthorRepository) GetByID(id uint) (*models.Author, error) {
+	var a models.Author
+	if err := r.db.First(&a, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *AuthorRepository) ReplaceBookContributors(bookID uint, contributors []models.BookContributor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", bookID).Delete(&models.BookContributor{}).Error; err != nil {
+			return err
+		}
+		if len(contributors) == 0 {
+			return nil
+		}
+		for i := range contributors {
+			contributors[i].BookID = bookID
+		}
+		return tx.Omit("Author").Create(&contributors).Error
+	})
+}
+
+func (r *AuthorRepository) ListBooksWithoutContributors(limit int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.Where("author != ''").
+		Where("NOT EXISTS (SELECT 1 FROM book_contributors WHERE book_contributors.book_id = books.id)").
+		Order("id ASC").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
+
+func (r *AuthorRepository) ListCredits(authorID uint) ([]repository.AuthorCredit, error) {
+	var rows []models.BookContributor
+	if err := r.db.Where("author_id = ?", authorID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []repository.AuthorCredit{}, nil
+	}
+	bookIDs := make([]uint, len(rows))
+	for i, row := range rows {
+		bookIDs[i] = row.BookID
+	}
+	// Same rule as the catalog: a book with no copies left isn't listed.
+	var books []models.Book
+	if err := r.db.Where("id IN ?", bookIDs).
+		Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)").
+		Order("title ASC, id ASC").
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	roles := map[uint][]string{}
+	for _, row := range rows {
+		roles[row.BookID] = append(roles[row.BookID], row.Role)
+	}
+	credits := make([]repository.AuthorCredit, 0, len(rows))
+	for _, b := range books {
+		for _, role := range roles[b.ID] {
+			credits = append(credits, repository.AuthorCredit{Book: b, Role: role})
+		}
+	}
+	return credits, nil
+}
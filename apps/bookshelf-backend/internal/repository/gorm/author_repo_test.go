@@ -0,0 +1,90 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+)
+
+func TestAuthorRepository_FindOrCreate_MatchesOnNameKey(t *testing.T) {
+	db := openTestDB(t)
+	authors := NewAuthorRepository(db)
+
+	first := models.Author{Name: "C. S. Lewis", NameKey: "c s lewis"}
+	require.NoError(t, authors.FindOrCreate(&first))
+	require.NotZero(t, first.ID)
+
+	again := models.Author{Name: "C.S. Lewis", NameKey: "c s lewis"}
+	require.NoError(t, authors.FindOrCreate(&again))
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, "C. S. Lewis", again.Name, "the stored display name wins")
+}
+
+func TestAuthorRepository_ContributorsAndCredits(t *testing.T) {
+	db := openTestDB(t)
+	authors := NewAuthorRepository(db)
+	books := NewBookRepository(db)
+	copies := NewCopyRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+
+	gaiman := models.Author{Name: "Neil Gaiman", NameKey: "neil gaiman"}
+	pratchett := models.Author{Name: "Terry Pratchett", NameKey: "terry pratchett"}
+	require.NoError(t, authors.FindOrCreate(&gaiman))
+	require.NoError(t, authors.FindOrCreate(&pratchett))
+
+	omens := models.Book{Title: "Good Omens", Author: "Neil Gaiman & Terry Pratchett"}
+	coraline := models.Book{Title: "Coraline", Author: "Neil Gaiman"}
+	gone := models.Book{Title: "Anansi Boys", Author: "Neil Gaiman"}
+	for _, b := range []*models.Book{&omens, &coraline, &gone} {
+		require.NoError(t, books.Create(b))
+	}
+	for _, b := range []models.Book{omens, coraline} {
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, OwnerID: owner.ID, Condition: "good", Status: "available"}))
+	}
+
+	pending, err := authors.ListBooksWithoutContributors(10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 3)
+
+	require.NoError(t, authors.ReplaceBookContributors(omens.ID, []models.BookContributor{
+		{AuthorID: gaiman.ID, Role: "author", Position: 0},
+		{AuthorID: pratchett.ID, Role: "author", Position: 1},
+	}))
+	require.NoError(t, authors.ReplaceBookContributors(coraline.ID, []models.BookContributor{{AuthorID: gaiman.ID, Role: "author"}}))
+	require.NoError(t, authors.ReplaceBookContributors(gone.ID, []models.BookContributor{{AuthorID: gaiman.ID, Role: "author"}}))
+
+	pending, err = authors.ListBooksWithoutContributors(10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	t.Run("credits skip books without copies, by title", func(t *testing.T) {
+		credits, err := authors.ListCredits(gaiman.ID)
+		require.NoError(t, err)
+		var titles []string
+		for _, c := range credits {
+			titles = append(titles, c.Book.Title)
+		}
+		assert.Equal(t, []string{"Coraline", "Good Omens"}, titles)
+	})
+
+	t.Run("replace swaps the whole set", func(t *testing.T) {
+		require.NoError(t, authors.ReplaceBookContributors(omens.ID, []models.BookContributor{{AuthorID: pratchett.ID, Role: "editor"}}))
+		book, err := books.GetByIDWithCopies(omens.ID)
+		require.NoError(t, err)
+		require.Len(t, book.Contributors, 1)
+		assert.Equal(t, "Terry Pratchett", book.Contributors[0].Author.Name)
+		assert.Equal(t, "editor", book.Contributors[0].Role)
+	})
+
+	t.Run("deleting a book drops its contributors", func(t *testing.T) {
+		require.NoError(t, books.Delete(&gone))
+		var count int64
+		require.NoError(t, db.Model(&models.BookContributor{}).Where("book_id = ?", gone.ID).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
@@ -2,10 +2,12 @@ package gorm
 
 import (
 	"errors"
+	"strconv"
+	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 )
@@ -55,46 +57,135 @@ func (r *BookRepository) FindByISBN(isbn string) (*models.Book, error) {
 	return &book, nil
 }
 
-func (r *BookRepository) buildListQuery(search, sort string, availableOnly bool) *gorm.DB {
-	tx := r.db.Model(&models.Book{})
+// maxFuzzyMatches caps how many typo-tolerant matches a search adds on top
+// of its substring matches, keeping the bound ID list small however loose
+// the query.
+const maxFuzzyMatches = 100
+
+// buildFilterQuery applies the catalog's search and availability filters.
+// A search matches a substring of the title or author, or — to tolerate
+// typos — any book fuzzyMatchIDs finds.
+func (r *BookRepository) buildFilterQuery(search string, availableOnly bool) *gorm.DB {
+	tx := r.catalogQuery(availableOnly)
 	if search != "" {
 		like := "%" + search + "%"
-		tx = tx.Where("title LIKE ? OR author LIKE ?", like, like)
+		fuzzy, err := r.fuzzyMatchIDs(search, availableOnly)
+		if err != nil {
+			_ = tx.AddError(err)
+		}
+		if len(fuzzy) == 0 {
+			tx = tx.Where("title LIKE ? OR author LIKE ?", like, like)
+		} else {
+			tx = tx.Where("title LIKE ? OR author LIKE ? OR books.id IN ?", like, like, fuzzy)
+		}
 	}
-	// A book with no copies left (e.g. its last copy was just removed by its
-	// owner) shouldn't linger in the catalog — same rule ListRecent already
-	// applies to the "recently added" shelf.
+	return tx
+}
+
+// catalogQuery selects the books the catalog lists. A book with no copies
+// left (e.g. its last copy was just removed by its owner) shouldn't linger
+// in the catalog — same rule ListRecent already applies to the "recently
+// added" shelf.
+func (r *BookRepository) catalogQuery(availableOnly bool) *gorm.DB {
+	tx := r.db.Model(&models.Book{})
 	if availableOnly {
-		tx = tx.Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id AND copies.status = 'available')")
-	} else {
-		tx = tx.Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)")
+		return tx.Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id AND copies.status = 'available')")
 	}
+	return tx.Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)")
+}
+
+// fuzzyMatchIDs returns the IDs of the catalog books bookmatch.FuzzyMatch
+// matches search against, leaving out those the substring match already
+// finds, at most maxFuzzyMatches of them in title order. The comparison
+// runs in Go over every remaining title and author — SQLite has no
+// edit-distance or trigram index to push it down to — which is cheap at
+// the size of a community library's catalog.
+func (r *BookRepository) fuzzyMatchIDs(search string, availableOnly bool) ([]uint, error) {
+	var rows []struct {
+		ID     uint
+		Title  string
+		Author string
+	}
+	like := "%" + search + "%"
+	if err := r.catalogQuery(availableOnly).
+		Where("NOT (title LIKE ? OR author LIKE ?)", like, like).
+		Order("title, books.id").
+		Select("books.id, title, author").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for _, row := range rows {
+		if bookmatch.FuzzyMatch(search, row.Title, row.Author) {
+			ids = append(ids, row.ID)
+			if len(ids) == maxFuzzyMatches {
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (r *BookRepository) buildListQuery(search, sort string, availableOnly bool) *gorm.DB {
+	return bookKeyset(search, sort).order(r.buildFilterQuery(search, availableOnly))
+}
+
+// relevanceRank ranks a prefix match on title above one on author, above
+// a mid-string substring match, above a typo-tolerant fuzzy match. Bound
+// to relevanceVars.
+const relevanceRank = "CASE WHEN title LIKE ? THEN 0 WHEN author LIKE ? THEN 1 WHEN title LIKE ? OR author LIKE ? THEN 2 ELSE 3 END"
+
+// relevanceVars returns relevanceRank's bind variables for search.
+func relevanceVars(search string) []any {
+	prefix, like := search+"%", "%"+search+"%"
+	return []any{prefix, prefix, like, like}
+}
+
+// bookKeyset returns the catalog's order for sort. Each ends with the book
+// ID, so books with equal titles still page deterministically.
+func bookKeyset(search, sort string) keyset {
 	switch sort {
 	case "author":
-		tx = tx.Order("author ASC, title ASC")
+		return keyset{columns: []string{"author", "title", "books.id"}, parseKeys: parseStringKeys}
 	case "newest":
-		tx = tx.Order("books.created_at DESC")
+		return createdAtKeyset("books.created_at", "books.id")
 	case "relevance":
 		// Only meaningful alongside a search term — a prefix match on
 		// title or author ranks above a mid-string substring match, so a
 		// query like "harry" surfaces "Harry Potter" before "The Harried
 		// Reader". Falls back to title order for an empty query, same as
 		// the default case.
-		if search == "" {
-			tx = tx.Order("title ASC")
-		} else {
-			prefix := search + "%"
-			tx = tx.Clauses(clause.OrderBy{
-				Expression: clause.Expr{
-					SQL:  "CASE WHEN title LIKE ? THEN 0 WHEN author LIKE ? THEN 1 ELSE 2 END, title ASC",
-					Vars: []any{prefix, prefix},
-				},
-			})
+		if search != "" {
+			return keyset{
+				columns:   []string{relevanceRank, "title", "books.id"},
+				vars:      relevanceVars(search),
+				parseKeys: parseRankedKeys,
+			}
 		}
-	default:
-		tx = tx.Order("title ASC")
 	}
-	return tx
+	return keyset{columns: []string{"title", "books.id"}, parseKeys: parseStringKeys}
+}
+
+// bookCursor returns the cursor positioned at b in the given sort order.
+func (r *BookRepository) bookCursor(search, sort string) func(models.Book) (*repository.Cursor, error) {
+	return func(b models.Book) (*repository.Cursor, error) {
+		switch {
+		case sort == "author":
+			return &repository.Cursor{Keys: []string{b.Author, b.Title}, ID: b.ID}, nil
+		case sort == "newest":
+			return &repository.Cursor{Keys: []string{timeKey(b.CreatedAt)}, ID: b.ID}, nil
+		case sort == "relevance" && search != "":
+			// The rank is recomputed by SQLite rather than in Go so it
+			// matches LIKE's own case-folding and wildcard rules exactly.
+			var rank int
+			if err := r.db.Model(&models.Book{}).Select(relevanceRank, relevanceVars(search)...).
+				Where("id = ?", b.ID).Scan(&rank).Error; err != nil {
+				return nil, err
+			}
+			return &repository.Cursor{Keys: []string{strconv.Itoa(rank), b.Title}, ID: b.ID}, nil
+		}
+		return &repository.Cursor{Keys: []string{b.Title}, ID: b.ID}, nil
+	}
 }
 
 func (r *BookRepository) List(search, sort string, availableOnly bool) ([]models.Book, error) {
@@ -105,22 +196,68 @@ func (r *BookRepository) List(search, sort string, availableOnly bool) ([]models
 	return books, nil
 }
 
+func (r *BookRepository) ListMissingMetadata(checkedBefore time.Time, limit int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.Where("isbn = '' OR publisher = '' OR published_date = '' OR page_count = 0 OR language = '' OR description = ''").
+		Where("metadata_checked_at IS NULL OR metadata_checked_at < ?", checkedBefore).
+		// SQLite sorts NULLs first ascending: never-checked books lead.
+		Order("metadata_checked_at ASC, id ASC").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
+
+func (r *BookRepository) ListCoverURLs(prefix string) ([]string, error) {
+	var urls []string
+	err := r.db.Model(&models.Book{}).
+		Where("cover_url LIKE ?", prefix+"%").
+		Distinct().
+		Pluck("cover_url", &urls).Error
+	return urls, err
+}
+
+func (r *BookRepository) ListWithoutCover() ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.Select("id", "title", "author").Where("cover_url = ''").Find(&books).Error
+	return books, err
+}
+
+func (r *BookRepository) ListWithCopies() ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.Preload("Copies", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)").
+		Order("id").
+		Find(&books).Error
+	return books, err
+}
+
 func (r *BookRepository) ListPaginated(search, sort string, availableOnly bool, page, pageSize int) (*repository.PaginatedResult[models.Book], error) {
+	// Built once and shared by both queries, so a search's fuzzy matching
+	// isn't computed twice.
+	query := r.buildListQuery(search, sort, availableOnly).Session(&gorm.Session{})
 	var total int64
-	if err := r.buildListQuery(search, sort, availableOnly).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, err
 	}
 	var books []models.Book
 	offset := (page - 1) * pageSize
-	if err := r.buildListQuery(search, sort, availableOnly).Offset(offset).Limit(pageSize).Find(&books).Error; err != nil {
+	if err := query.Offset(offset).Limit(pageSize).Find(&books).Error; err != nil {
 		return nil, err
 	}
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	next, err := nextPageCursor(books, page, totalPages, r.bookCursor(search, sort))
+	if err != nil {
+		return nil, err
+	}
 	return &repository.PaginatedResult[models.Book]{
-		Items: books, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages,
+		Items: books, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages, Next: next,
 	}, nil
 }
 
+func (r *BookRepository) ListAfter(search, sort string, availableOnly bool, after *repository.Cursor, limit int) (*repository.CursorResult[models.Book], error) {
+	return keysetPage(r.buildFilterQuery(search, availableOnly), bookKeyset(search, sort), after, limit, r.bookCursor(search, sort))
+}
+
 func (r *BookRepository) ListRecent(limit int) ([]models.Book, error) {
 	var books []models.Book
 	err := r.db.Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)").
@@ -130,9 +267,24 @@ func (r *BookRepository) ListRecent(limit int) ([]models.Book, error) {
 	return books, err
 }
 
+func (r *BookRepository) GetByID(id uint) (*models.Book, error) {
+	var book models.Book
+	if err := r.db.First(&book, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &book, nil
+}
+
 func (r *BookRepository) GetByIDWithCopies(id uint) (*models.Book, error) {
 	var book models.Book
-	if err := r.db.Preload("Copies.Owner").First(&book, id).Error; err != nil {
+	err := r.db.Preload("Copies.Owner").
+		Preload("Contributors", func(tx *gorm.DB) *gorm.DB { return tx.Order("position ASC") }).
+		Preload("Contributors.Author").
+		First(&book, id).Error
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound
 		}
@@ -149,9 +301,27 @@ func (r *BookRepository) Save(book *models.Book) error {
 	return r.db.Save(book).Error
 }
 
+func (r *BookRepository) UpdateFields(book *models.Book, columns ...string) error {
+	return r.db.Model(book).Select(columns).Updates(book).Error
+}
+
 // Delete hard-deletes book — Book has no DeletedAt field, so this is a real DELETE.
 func (r *BookRepository) Delete(book *models.Book) error {
-	return r.db.Delete(book).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteBook(tx, book.ID)
+	})
+}
+
+// deleteBook hard-deletes bookID along with the rows that only mean
+// something while it exists.
+func deleteBook(tx *gorm.DB, bookID uint) error {
+	if err := tx.Where("book_id = ?", bookID).Delete(&models.BookContributor{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("book_id = ?", bookID).Delete(&models.ReadingListEntry{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Book{}, bookID).Error
 }
 
 // CountCopies returns the total number of Copy rows for bookID, with no status filter.
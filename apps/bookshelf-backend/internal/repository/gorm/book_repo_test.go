@@ -1,7 +1,9 @@
 package gorm
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,6 +38,91 @@ func TestBookRepository_FindByISBN(t *testing.T) {
 	})
 }
 
+func TestBookRepository_ListMissingMetadata(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	recent := now.Add(-time.Hour)
+	stale := now.Add(-48 * time.Hour)
+	cutoff := now.Add(-24 * time.Hour)
+
+	require.NoError(t, books.Create(&models.Book{Title: "Complete", Author: "A", ISBN: "9780000000001", Publisher: "P",
+		PublishedDate: "1965", PageCount: 100, Language: "en", Description: "D"}))
+	require.NoError(t, books.Create(&models.Book{Title: "Checked recently", Author: "A", MetadataCheckedAt: &recent}))
+	require.NoError(t, books.Create(&models.Book{Title: "Checked long ago", Author: "A", MetadataCheckedAt: &stale}))
+	require.NoError(t, books.Create(&models.Book{Title: "Never checked", Author: "A", ISBN: "9780000000002", Publisher: "P", PageCount: 100}))
+	require.NoError(t, books.Create(&models.Book{Title: "Never checked either", Author: "A"}))
+	require.NoError(t, books.Create(&models.Book{Title: "Missing only a description", Author: "A", ISBN: "9780000000003", Publisher: "P",
+		PublishedDate: "1965", PageCount: 100, Language: "en"}))
+
+	got, err := books.ListMissingMetadata(cutoff, 10)
+	require.NoError(t, err)
+	var titles []string
+	for _, b := range got {
+		titles = append(titles, b.Title)
+	}
+	assert.Equal(t, []string{"Never checked", "Never checked either", "Missing only a description", "Checked long ago"}, titles)
+
+	got, err = books.ListMissingMetadata(cutoff, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "Never checked", got[0].Title)
+}
+
+func TestBookRepository_ListCoverURLs(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+
+	require.NoError(t, books.Create(&models.Book{Title: "Cached", Author: "A", CoverURL: "/api/covers/a.jpg"}))
+	require.NoError(t, books.Create(&models.Book{Title: "Same cover", Author: "A", CoverURL: "/api/covers/a.jpg"}))
+	require.NoError(t, books.Create(&models.Book{Title: "Other", Author: "A", CoverURL: "/api/covers/b.png"}))
+	require.NoError(t, books.Create(&models.Book{Title: "External", Author: "A", CoverURL: "https://covers.openlibrary.org/b/id/1-L.jpg"}))
+	require.NoError(t, books.Create(&models.Book{Title: "No cover", Author: "A"}))
+
+	got, err := books.ListCoverURLs("/api/covers/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/api/covers/a.jpg", "/api/covers/b.png"}, got)
+}
+
+func TestBookRepository_ListWithoutCover(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+
+	coverless := models.Book{Title: "No cover", Author: "A", Publisher: "P"}
+	require.NoError(t, books.Create(&coverless))
+	require.NoError(t, books.Create(&models.Book{Title: "Cached", Author: "A", CoverURL: "/api/covers/a.jpg"}))
+
+	got, err := books.ListWithoutCover()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, coverless.ID, got[0].ID)
+	assert.Equal(t, "No cover", got[0].Title)
+	assert.Equal(t, "A", got[0].Author)
+}
+
+func TestBookRepository_ListWithCopies(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+	copies := NewCopyRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+	dune := models.Book{Title: "Dune", Author: "A"}
+	require.NoError(t, books.Create(&dune))
+	for _, condition := range []string{"good", "worn"} {
+		require.NoError(t, copies.Create(&models.Copy{BookID: dune.ID, OwnerID: owner.ID, Condition: condition, Status: "available"}))
+	}
+	require.NoError(t, books.Create(&models.Book{Title: "No Copies Left", Author: "A"}))
+
+	got, err := books.ListWithCopies()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, dune.ID, got[0].ID)
+	require.Len(t, got[0].Copies, 2)
+	assert.Equal(t, "good", got[0].Copies[0].Condition)
+	assert.Equal(t, "worn", got[0].Copies[1].Condition)
+}
+
 func TestBookRepository_ListPaginated_ExcludesBooksWithNoCopies(t *testing.T) {
 	db := openTestDB(t)
 	books := NewBookRepository(db)
@@ -91,17 +178,107 @@ func TestBookRepository_ListPaginated_RelevanceSort(t *testing.T) {
 	require.Equal(t, []string{"Harry Potter", "The Harried Reader"}, titles)
 }
 
+func TestBookRepository_ListPaginated_ToleratesTypos(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+	copies := NewCopyRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+
+	for _, b := range []models.Book{
+		{Title: "The Hobbit", Author: "J. R. R. Tolkien"},
+		{Title: "Tolkein: A Misspelled Biography", Author: "B"},
+		{Title: "The Chronicles of Narnia", Author: "C. S. Lewis"},
+	} {
+		require.NoError(t, books.Create(&b))
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, OwnerID: owner.ID, Condition: "good", Status: "available"}))
+	}
+
+	titles := func(search, sort string) []string {
+		t.Helper()
+		result, err := books.ListPaginated(search, sort, false, 1, 20)
+		require.NoError(t, err)
+		var out []string
+		for _, b := range result.Items {
+			out = append(out, b.Title)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"The Chronicles of Narnia"}, titles("Narnai", "title"))
+	// The exact match on the misspelling ranks above the typo-tolerant
+	// match on the real name.
+	assert.Equal(t, []string{"Tolkein: A Misspelled Biography", "The Hobbit"}, titles("Tolkein", "relevance"))
+	assert.Empty(t, titles("Tolkein Narnia", "title"))
+}
+
+func TestBookRepository_FuzzyMatchIDs(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+	copies := NewCopyRepository(db)
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+
+	shelve := func(title string) uint {
+		t.Helper()
+		b := models.Book{Title: title, Author: "C. S. Lewis"}
+		require.NoError(t, books.Create(&b))
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, OwnerID: owner.ID, Status: "available"}))
+		return b.ID
+	}
+	shelve("Narnai")
+	require.NoError(t, books.Create(&models.Book{Title: "The Chronicles of Narnia", Author: "C. S. Lewis"}))
+	for i := 0; i < maxFuzzyMatches+5; i++ {
+		shelve(fmt.Sprintf("Narnia %03d", i))
+	}
+
+	ids, err := books.fuzzyMatchIDs("Narnai", false)
+	require.NoError(t, err)
+	assert.Len(t, ids, maxFuzzyMatches, "capped, leaving out the substring match and the book with no copies")
+
+	result, err := books.ListPaginated("Narnai", "title", false, 1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxFuzzyMatches+1), result.Total)
+}
+
+func TestBookRepository_UpdateFields(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+	book := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, books.Create(&book))
+	require.NoError(t, db.Model(&models.Book{}).Where("id = ?", book.ID).Update("cover_url", "/api/covers/dune.jpg").Error)
+
+	book.Publisher, book.Title = "Ace", "Stale title"
+	require.NoError(t, books.UpdateFields(&book, "publisher"))
+
+	var got models.Book
+	require.NoError(t, db.First(&got, book.ID).Error)
+	assert.Equal(t, "Ace", got.Publisher)
+	assert.Equal(t, "Dune", got.Title, "unnamed columns are left alone")
+	assert.Equal(t, "/api/covers/dune.jpg", got.CoverURL)
+}
+
 func TestBookRepository_Delete(t *testing.T) {
 	db := openTestDB(t)
 	books := NewBookRepository(db)
 
 	book := models.Book{Title: "T1", Author: "A"}
+	other := models.Book{Title: "T2", Author: "A"}
 	require.NoError(t, books.Create(&book))
+	require.NoError(t, books.Create(&other))
+	list := models.ReadingList{OwnerID: 1, Name: "Queue"}
+	require.NoError(t, db.Create(&list).Error)
+	require.NoError(t, db.Create(&models.ReadingListEntry{ReadingListID: list.ID, BookID: book.ID, Position: 0}).Error)
+	require.NoError(t, db.Create(&models.ReadingListEntry{ReadingListID: list.ID, BookID: other.ID, Position: 1}).Error)
 
 	require.NoError(t, books.Delete(&book))
 
 	_, err := books.GetByIDWithCopies(book.ID)
 	assert.ErrorIs(t, err, repository.ErrNotFound)
+	var left []uint
+	require.NoError(t, db.Model(&models.ReadingListEntry{}).Pluck("book_id", &left).Error)
+	assert.Equal(t, []uint{other.ID}, left, "its reading-list entries go with it")
 }
 
 func TestBookRepository_CountCopies(t *testing.T) {
@@ -136,3 +313,102 @@ func TestBookRepository_CountCopies(t *testing.T) {
 	require.NoError(t, err)
 	assert.EqualValues(t, 2, count)
 }
+
+// walkBooksAfter follows ListAfter's cursors to the end, returning every
+// title in order.
+func walkBooksAfter(t *testing.T, books *BookRepository, search, sort string, limit int) []string {
+	t.Helper()
+	var titles []string
+	var after *repository.Cursor
+	for {
+		page, err := books.ListAfter(search, sort, false, after, limit)
+		require.NoError(t, err)
+		for _, b := range page.Items {
+			titles = append(titles, b.Title)
+		}
+		if page.Next == nil {
+			return titles
+		}
+		after = page.Next
+	}
+}
+
+func TestBookRepository_ListAfter_MatchesPageOrder(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+	copies := NewCopyRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+
+	// Duplicate titles and authors make the ID tiebreaker matter.
+	for _, b := range []models.Book{
+		{Title: "Dune", Author: "Herbert"},
+		{Title: "Emma", Author: "Austen"},
+		{Title: "Dune", Author: "Herbert"},
+		{Title: "Persuasion", Author: "Austen"},
+		{Title: "Duneland", Author: "Smith"},
+		{Title: "A Dune Companion", Author: "Dune Society"},
+		{Title: "Beloved", Author: "Morrison"},
+	} {
+		require.NoError(t, books.Create(&b))
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, OwnerID: owner.ID, Condition: "good", Status: "available"}))
+	}
+
+	for _, tc := range []struct{ search, sort string }{
+		{"", "title"}, {"", "author"}, {"", "newest"}, {"dune", "relevance"}, {"dnue", "relevance"},
+	} {
+		t.Run(tc.sort, func(t *testing.T) {
+			all, err := books.ListPaginated(tc.search, tc.sort, false, 1, 100)
+			require.NoError(t, err)
+			var want []string
+			for _, b := range all.Items {
+				want = append(want, b.Title)
+			}
+			assert.Equal(t, want, walkBooksAfter(t, books, tc.search, tc.sort, 2))
+		})
+	}
+}
+
+func TestBookRepository_ListAfter_UnaffectedByInsertsMidScroll(t *testing.T) {
+	db := openTestDB(t)
+	books := NewBookRepository(db)
+	copies := NewCopyRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+	add := func(title string) {
+		b := models.Book{Title: title, Author: "A"}
+		require.NoError(t, books.Create(&b))
+		require.NoError(t, copies.Create(&models.Copy{BookID: b.ID, OwnerID: owner.ID, Condition: "good", Status: "available"}))
+	}
+	for _, title := range []string{"B", "D", "F", "H"} {
+		add(title)
+	}
+
+	first, err := books.ListPaginated("", "title", false, 1, 2)
+	require.NoError(t, err)
+	require.NotNil(t, first.Next, "page-number results carry a cursor for the next page")
+
+	// A book sorting before the cursor would shift an OFFSET page by one.
+	add("A")
+
+	second, err := books.ListAfter("", "title", false, first.Next, 2)
+	require.NoError(t, err)
+	var titles []string
+	for _, b := range second.Items {
+		titles = append(titles, b.Title)
+	}
+	assert.Equal(t, []string{"F", "H"}, titles)
+	assert.Nil(t, second.Next)
+}
+
+func TestBookRepository_ListAfter_RejectsMismatchedCursor(t *testing.T) {
+	books := NewBookRepository(openTestDB(t))
+
+	_, err := books.ListAfter("", "newest", false, &repository.Cursor{Keys: []string{"Dune"}, ID: 1}, 10)
+	assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+
+	_, err = books.ListAfter("", "author", false, &repository.Cursor{Keys: []string{"Herbert"}, ID: 1}, 10)
+	assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+}
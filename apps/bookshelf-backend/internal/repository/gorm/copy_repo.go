@@ -75,6 +75,19 @@ func (r *CopyRepository) ListOwnedBookIDs(ownerID uint) ([]uint, error) {
 	return bookIDs, nil
 }
 
+func (r *CopyRepository) ListAvailableByBookIDs(bookIDs []uint) ([]models.Copy, error) {
+	copies := []models.Copy{}
+	if len(bookIDs) == 0 {
+		return copies, nil
+	}
+	if err := r.db.Where("book_id IN ? AND status = ?", bookIDs, "available").
+		Order("id ASC").
+		Find(&copies).Error; err != nil {
+		return nil, err
+	}
+	return copies, nil
+}
+
 func (r *CopyRepository) CountByOwnerID(ownerID uint) (int64, error) {
 	var count int64
 	if err := r.db.Model(&models.Copy{}).Where("owner_id = ?", ownerID).Count(&count).Error; err != nil {
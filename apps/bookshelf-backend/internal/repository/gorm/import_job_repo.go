@@ -0,0 +1,103 @@
+package gorm
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// ImportJobRepository is the GORM implementation of repository.ImportJobRepository.
+type ImportJobRepository struct {
+	db *gorm.DB
+}
+
+// NewImportJobRepository creates a new ImportJobRepository.
+func NewImportJobRepository(db *gorm.DB) *ImportJobRepository {
+	return &ImportJobRepository{db: db}
+}
+
+func (r *ImportJobRepository) Create(j *models.ImportJob) error {
+	return r.db.Create(j).Error
+}
+
+func (r *ImportJobRepository) GetByID(id uint) (*models.ImportJob, error) {
+	var j models.ImportJob
+	if err := r.db.First(&j, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *ImportJobRepository) GetStatus(id uint) (string, error) {
+	var status string
+	res := r.db.Model(&models.ImportJob{}).Where("id = ?", id).Limit(1).Pluck("status", &status)
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", repository.ErrNotFound
+	}
+	return status, nil
+}
+
+func (r *ImportJobRepository) HasUnfinished(ownerID uint) (bool, error) {
+	var n int64
+	err := r.db.Model(&models.ImportJob{}).
+		Where("owner_id = ? AND status IN ?", ownerID, []string{"queued", "running"}).
+		Count(&n).Error
+	return n > 0, err
+}
+
+func (r *ImportJobRepository) ListByOwnerID(ownerID uint, limit int) ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	err := r.db.Omit("content", "decisions").
+		Where("owner_id = ?", ownerID).
+		Order("id desc").Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *ImportJobRepository) ListUnfinished() ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	err := r.db.Where("status IN ?", []string{"queued", "running"}).Order("id asc").Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *ImportJobRepository) SetStatus(id uint, from []string, status, errMsg string) (bool, error) {
+	updates := map[string]any{"status": status, "error": errMsg, "updated_at": time.Now()}
+	if status != "queued" && status != "running" {
+		updates["finished_at"] = time.Now()
+		updates["content"] = ""
+		updates["decisions"] = "{}"
+	}
+	res := r.db.Model(&models.ImportJob{}).Where("id = ? AND status IN ?", id, from).Updates(updates)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *ImportJobRepository) RecordRow(row *models.ImportJobRow, bookCopy *models.Copy) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(row).Error; err != nil {
+			return err
+		}
+		if bookCopy != nil {
+			if err := tx.Create(bookCopy).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.ImportJob{}).Where("id = ?", row.ImportJobID).
+			Updates(map[string]any{"processed_rows": gorm.Expr("processed_rows + 1"), "updated_at": time.Now()}).Error
+	})
+}
+
+func (r *ImportJobRepository) ListRows(jobID uint) ([]models.ImportJobRow, error) {
+	var rows []models.ImportJobRow
+	err := r.db.Where("import_job_id = ?", jobID).Order("row asc").Find(&rows).Error
+	return rows, err
+}
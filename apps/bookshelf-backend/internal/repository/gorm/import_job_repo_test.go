@@ -0,0 +1,98 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+func TestImportJobRepository_RecordRowAdvancesProgress(t *testing.T) {
+	jobs := NewImportJobRepository(openTestDB(t))
+	job := models.ImportJob{OwnerID: 1, Status: "running", Format: "csv", Source: "goodreads", Content: "...", Decisions: "{}", TotalRows: 2}
+	require.NoError(t, jobs.Create(&job))
+
+	require.NoError(t, jobs.RecordRow(&models.ImportJobRow{ImportJobID: job.ID, Row: 2, Title: "B", Action: "skipped", Reason: "missing title"}, nil))
+	require.NoError(t, jobs.RecordRow(&models.ImportJobRow{ImportJobID: job.ID, Row: 1, Title: "A", Action: "create_book"},
+		&models.Copy{BookID: 1, OwnerID: 1, Status: "available"}))
+	require.Error(t, jobs.RecordRow(&models.ImportJobRow{ImportJobID: job.ID, Row: 1, Title: "A", Action: "create_book"},
+		&models.Copy{BookID: 1, OwnerID: 1, Status: "available"}),
+		"a row is recorded at most once")
+
+	got, err := jobs.GetByID(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.ProcessedRows, "the failed duplicate must not count")
+	var copies int64
+	require.NoError(t, jobs.db.Model(&models.Copy{}).Count(&copies).Error)
+	assert.Equal(t, int64(1), copies, "the duplicate's copy is rolled back with it")
+	rows, err := jobs.ListRows(job.ID)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []int{1, 2}, []int{rows[0].Row, rows[1].Row})
+}
+
+func TestImportJobRepository_SetStatus(t *testing.T) {
+	jobs := NewImportJobRepository(openTestDB(t))
+	job := models.ImportJob{OwnerID: 1, Status: "queued", Format: "json", Source: "bookshelf", Content: "[]", Decisions: `{"1":"accept_match"}`}
+	require.NoError(t, jobs.Create(&job))
+
+	ok, err := jobs.SetStatus(job.ID, []string{"queued"}, "running", "")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, err := jobs.GetByID(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", got.Content, "a running job keeps what it needs to resume")
+	assert.Nil(t, got.FinishedAt)
+
+	ok, err = jobs.SetStatus(job.ID, []string{"queued"}, "running", "")
+	require.NoError(t, err)
+	assert.False(t, ok, "only moves from the given statuses")
+
+	ok, err = jobs.SetStatus(job.ID, []string{"queued", "running"}, "cancelled", "")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, err = jobs.GetByID(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", got.Status)
+	assert.Empty(t, got.Content)
+	assert.Equal(t, "{}", got.Decisions)
+	assert.NotNil(t, got.FinishedAt)
+}
+
+func TestImportJobRepository_Lists(t *testing.T) {
+	jobs := NewImportJobRepository(openTestDB(t))
+	for _, j := range []models.ImportJob{
+		{OwnerID: 1, Status: "completed"},
+		{OwnerID: 1, Status: "running"},
+		{OwnerID: 2, Status: "queued"},
+		{OwnerID: 1, Status: "queued"},
+	} {
+		j.Format, j.Source, j.Content, j.Decisions = "json", "bookshelf", "[]", "{}"
+		require.NoError(t, jobs.Create(&j))
+	}
+
+	for owner, want := range map[uint]bool{1: true, 2: true, 3: false} {
+		busy, err := jobs.HasUnfinished(owner)
+		require.NoError(t, err)
+		assert.Equal(t, want, busy, "owner %d", owner)
+	}
+	status, err := jobs.GetStatus(2)
+	require.NoError(t, err)
+	assert.Equal(t, "running", status)
+	_, err = jobs.GetStatus(99)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	unfinished, err := jobs.ListUnfinished()
+	require.NoError(t, err)
+	require.Len(t, unfinished, 3)
+	assert.Equal(t, []uint{2, 3, 4}, []uint{unfinished[0].ID, unfinished[1].ID, unfinished[2].ID})
+
+	mine, err := jobs.ListByOwnerID(1, 2)
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+	assert.Equal(t, []uint{4, 2}, []uint{mine[0].ID, mine[1].ID}, "newest first, capped at limit")
+	assert.Empty(t, mine[0].Content, "the upload itself isn't listed")
+}
@@ -0,0 +1,137 @@
+package gorm
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// keyset describes a list's sort order for keyset pagination: the SQL
+// expressions it sorts by, all in one direction, ending with the row ID as
+// a tiebreaker so every row has a unique position. The same description
+// drives both ORDER BY and the "after the cursor" condition, so the two
+// can't drift apart.
+type keyset struct {
+	// columns are the sort expressions, ID last (e.g. "title", "books.id").
+	columns []string
+	// vars are bound into placeholders inside columns, if any.
+	vars []any
+	desc bool
+	// parseKeys converts a cursor's string keys (every column but the ID)
+	// back into SQL values, returning repository.ErrInvalidCursor if they
+	// don't fit.
+	parseKeys func(keys []string) ([]any, error)
+}
+
+// order applies the keyset's ORDER BY to tx.
+func (k keyset) order(tx *gorm.DB) *gorm.DB {
+	dir := " ASC"
+	if k.desc {
+		dir = " DESC"
+	}
+	return tx.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:  strings.Join(k.columns, dir+", ") + dir,
+		Vars: k.vars,
+	}})
+}
+
+// after narrows tx to rows strictly past c, comparing the whole sort key as
+// an SQLite row value.
+func (k keyset) after(tx *gorm.DB, c *repository.Cursor) (*gorm.DB, error) {
+	if len(c.Keys) != len(k.columns)-1 {
+		return nil, repository.ErrInvalidCursor
+	}
+	keys, err := k.parseKeys(c.Keys)
+	if err != nil {
+		return nil, err
+	}
+	op := " > "
+	if k.desc {
+		op = " < "
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(k.columns)), ", ")
+	args := append(append(append([]any{}, k.vars...), keys...), c.ID)
+	return tx.Where("("+strings.Join(k.columns, ", ")+")"+op+"("+placeholders+")", args...), nil
+}
+
+// keysetPage fetches up to limit rows of tx (already filtered, not yet
+// ordered) after the cursor. One extra row is read to tell whether another
+// page follows; cursorFor builds the cursor for a page's last row.
+func keysetPage[T any](tx *gorm.DB, k keyset, after *repository.Cursor, limit int, cursorFor func(T) (*repository.Cursor, error)) (*repository.CursorResult[T], error) {
+	if after != nil {
+		var err error
+		if tx, err = k.after(tx, after); err != nil {
+			return nil, err
+		}
+	}
+	var items []T
+	if err := k.order(tx).Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	result := &repository.CursorResult[T]{Items: items}
+	if len(items) > limit {
+		result.Items = items[:limit]
+		next, err := cursorFor(result.Items[limit-1])
+		if err != nil {
+			return nil, err
+		}
+		result.Next = next
+	}
+	return result, nil
+}
+
+// nextPageCursor returns the cursor continuing after a page-number page, or
+// nil when it's the last page.
+func nextPageCursor[T any](items []T, page, totalPages int, cursorFor func(T) (*repository.Cursor, error)) (*repository.Cursor, error) {
+	if page >= totalPages || len(items) == 0 {
+		return nil, nil
+	}
+	return cursorFor(items[len(items)-1])
+}
+
+// timeKey encodes a timestamp sort key. RFC 3339 with nanoseconds keeps the
+// original UTC offset, so the parsed value binds to the same text the
+// SQLite driver stored and compares correctly.
+func timeKey(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseTimeKeys is a keyset.parseKeys for a single timestamp column.
+func parseTimeKeys(keys []string) ([]any, error) {
+	t, err := time.Parse(time.RFC3339Nano, keys[0])
+	if err != nil {
+		return nil, repository.ErrInvalidCursor
+	}
+	return []any{t}, nil
+}
+
+// parseStringKeys is a keyset.parseKeys for text columns.
+func parseStringKeys(keys []string) ([]any, error) {
+	out := make([]any, len(keys))
+	for i, k := range keys {
+		out[i] = k
+	}
+	return out, nil
+}
+
+// parseRankedKeys is a keyset.parseKeys for an integer rank followed by
+// text columns.
+func parseRankedKeys(keys []string) ([]any, error) {
+	rank, err := strconv.Atoi(keys[0])
+	if err != nil {
+		return nil, repository.ErrInvalidCursor
+	}
+	rest, _ := parseStringKeys(keys[1:])
+	return append([]any{rank}, rest...), nil
+}
+
+// createdAtKeyset is the newest-first order shared by most lists: a
+// timestamp column, then ID, both descending.
+func createdAtKeyset(column, idColumn string) keyset {
+	return keyset{columns: []string{column, idColumn}, desc: true, parseKeys: parseTimeKeys}
+}
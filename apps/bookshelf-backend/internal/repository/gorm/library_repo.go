@@ -0,0 +1,460 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// LibraryRepository is the GORM implementation of repository.LibraryRepository.
+type LibraryRepository struct {
+	db *gorm.DB
+}
+
+// NewLibraryRepository creates a new LibraryRepository.
+func NewLibraryRepository(db *gorm.DB) *LibraryRepository {
+	return &LibraryRepository{db: db}
+}
+
+func (r *LibraryRepository) Snapshot() (*repository.LibrarySnapshot, error) {
+	var s repository.LibrarySnapshot
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Order("id").Find(&s.Users).Error; err != nil {
+			return err
+		}
+		if err := tx.Order("id").Find(&s.Books).Error; err != nil {
+			return err
+		}
+		if err := tx.Order("id").Find(&s.Copies).Error; err != nil {
+			return err
+		}
+		if err := tx.Order("id").Find(&s.LoanRequests).Error; err != nil {
+			return err
+		}
+		return tx.Preload("CoRequesters", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+			Order("id").Find(&s.WishlistRequests).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *LibraryRepository) Restore(s *repository.LibrarySnapshot) (repository.LibraryRestoreStats, error) {
+	var stats repository.LibraryRestoreStats
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := libraryRestore{tx: tx, stats: &stats}
+		userIDs, err := res.users(s.Users)
+		if err != nil {
+			return err
+		}
+		bookIDs, err := res.books(s.Books)
+		if err != nil {
+			return err
+		}
+		copyIDs, err := res.copies(s.Copies, bookIDs, userIDs)
+		if err != nil {
+			return err
+		}
+		if err := res.loanRequests(s.LoanRequests, copyIDs, userIDs); err != nil {
+			return err
+		}
+		return res.wishlistRequests(s.WishlistRequests, bookIDs, userIDs)
+	})
+	if err != nil {
+		return repository.LibraryRestoreStats{}, err
+	}
+	return stats, nil
+}
+
+// libraryRestore holds one Restore's transaction. Each step returns a map
+// from the snapshot's IDs to the stored ones, for the steps after it.
+type libraryRestore struct {
+	tx    *gorm.DB
+	stats *repository.LibraryRestoreStats
+}
+
+// create inserts v without its associations — a snapshot's records carry
+// none, and a zero-valued Book or Owner must not be written as one.
+func (r libraryRestore) create(v any) error {
+	return r.tx.Omit(clause.Associations).Create(v).Error
+}
+
+// update writes incoming's columns over matched record existing where they
+// differ from current, its own values for the same columns, and counts it
+// as updated or, if nothing differed, matched.
+func (r libraryRestore) update(existing any, current, incoming map[string]any, count *repository.RestoreCount) error {
+	changes := make(map[string]any)
+	for col, v := range incoming {
+		if !sameValue(current[col], v) {
+			changes[col] = v
+		}
+	}
+	if len(changes) == 0 {
+		count.Matched++
+		return nil
+	}
+	if err := r.tx.Model(existing).Updates(changes).Error; err != nil {
+		return err
+	}
+	count.Updated++
+	return nil
+}
+
+// sameValue compares two column values, times by instant — a time read
+// back from SQLite doesn't carry the location it was written with.
+func sameValue(a, b any) bool {
+	switch a := a.(type) {
+	case *time.Time:
+		b := b.(*time.Time)
+		if a == nil || b == nil {
+			return a == b
+		}
+		return a.Equal(*b)
+	case *uint:
+		b := b.(*uint)
+		if a == nil || b == nil {
+			return a == b
+		}
+		return *a == *b
+	}
+	return a == b
+}
+
+// first loads the first record matching query into dst, reporting whether
+// there was one.
+func first[T any](q *gorm.DB, dst *T) (bool, error) {
+	err := q.Order("id").First(dst).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r libraryRestore) users(users []models.User) (map[uint]uint, error) {
+	ids := make(map[uint]uint, len(users))
+	for _, u := range users {
+		var existing models.User
+		found, err := first(r.tx.Where("email = ? COLLATE NOCASE", u.Email), &existing)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ids[u.ID] = existing.ID
+			if err := r.update(&existing, userColumns(existing), userColumns(u), &r.stats.Users); err != nil {
+				return nil, fmt.Errorf("user %s: %w", u.Email, err)
+			}
+			continue
+		}
+		ref := u.ID
+		u.ID = 0
+		if err := r.create(&u); err != nil {
+			return nil, fmt.Errorf("user %s: %w", u.Email, err)
+		}
+		ids[ref] = u.ID
+		r.stats.Users.Created++
+	}
+	return ids, nil
+}
+
+// userColumns are the columns a restore updates on a matched member: all
+// but their email, which matched, what only this instance knows (OTP
+// codes, a pending deletion), and their password and role — taken from an
+// older or another instance's export, those could lock out the admin
+// restoring it or make anyone an admin.
+func userColumns(u models.User) map[string]any {
+	return map[string]any{
+		"name":                        u.Name,
+		"phone":                       u.Phone,
+		"verified":                    u.Verified,
+		"phone_verified":              u.PhoneVerified,
+		"suspended":                   u.Suspended,
+		"pending_approval":            u.PendingApproval,
+		"email_notifications_enabled": u.EmailNotificationsEnabled,
+		"telegram_username":           u.TelegramUsername,
+		"whatsapp_username":           u.WhatsAppUsername,
+	}
+}
+
+func (r libraryRestore) books(books []models.Book) (map[uint]uint, error) {
+	ids := make(map[uint]uint, len(books))
+	for _, b := range books {
+		existing, err := r.findBook(b)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			ids[b.ID] = existing.ID
+			if err := r.update(existing, bookColumns(*existing, *existing), bookColumns(b, *existing), &r.stats.Books); err != nil {
+				return nil, fmt.Errorf("book %q: %w", b.Title, err)
+			}
+			continue
+		}
+		ref := b.ID
+		b.ID = 0
+		b.Copies, b.Contributors = nil, nil
+		if err := r.create(&b); err != nil {
+			return nil, fmt.Errorf("book %q: %w", b.Title, err)
+		}
+		ids[ref] = b.ID
+		r.stats.Books.Created++
+	}
+	return ids, nil
+}
+
+// bookColumns are the columns a restore updates on matched book existing,
+// with b's values. Its keys and cover are only filled in where existing has
+// none: a book matched on one key keeps the others it has, and a cover
+// cached here beats the export's, whose own cached covers don't come along.
+func bookColumns(b, existing models.Book) map[string]any {
+	cols := map[string]any{
+		"title":                b.Title,
+		"author":               b.Author,
+		"description":          b.Description,
+		"description_enriched": b.DescriptionEnriched,
+		"publisher":            b.Publisher,
+		"published_date":       b.PublishedDate,
+		"page_count":           b.PageCount,
+		"language":             b.Language,
+	}
+	for col, v := range map[string][2]string{
+		"isbn":            {b.ISBN, existing.ISBN},
+		"ol_key":          {b.OLKey, existing.OLKey},
+		"google_books_id": {b.GoogleBooksID, existing.GoogleBooksID},
+		"cover_url":       {b.CoverURL, existing.CoverURL},
+	} {
+		if v[1] == "" {
+			cols[col] = v[0]
+		}
+	}
+	return cols
+}
+
+// findBook matches b the way findExistingBook does for a manually added
+// book: OLKey, then GoogleBooksID, then ISBN only if b has neither. A book
+// with no keys at all matches an equally keyless one with the same title
+// and author.
+func (r libraryRestore) findBook(b models.Book) (*models.Book, error) {
+	var existing models.Book
+	var queries []*gorm.DB
+	if b.OLKey != "" {
+		queries = append(queries, r.tx.Where("ol_key = ?", b.OLKey))
+	}
+	if b.GoogleBooksID != "" {
+		queries = append(queries, r.tx.Where("google_books_id = ?", b.GoogleBooksID))
+	}
+	if b.OLKey == "" && b.GoogleBooksID == "" {
+		if b.ISBN != "" {
+			queries = append(queries, r.tx.Where("isbn = ?", b.ISBN))
+		} else {
+			queries = append(queries, r.tx.Where(
+				"title = ? AND author = ? AND ol_key = '' AND google_books_id = '' AND isbn = ''", b.Title, b.Author))
+		}
+	}
+	for _, q := range queries {
+		found, err := first(q, &existing)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return &existing, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r libraryRestore) copies(copies []models.Copy, bookIDs, userIDs map[uint]uint) (map[uint]uint, error) {
+	ids := make(map[uint]uint, len(copies))
+	// How many of each owner's copies of each book have been placed so far,
+	// so the nth in the snapshot matches the nth already stored.
+	type ownerBook struct{ owner, book uint }
+	seen := map[ownerBook]int{}
+	for _, c := range copies {
+		bookID, ok := bookIDs[c.BookID]
+		if !ok {
+			return nil, fmt.Errorf("copy %d: unknown book %d", c.ID, c.BookID)
+		}
+		ownerID, ok := userIDs[c.OwnerID]
+		if !ok {
+			return nil, fmt.Errorf("copy %d: unknown owner %d", c.ID, c.OwnerID)
+		}
+
+		key := ownerBook{ownerID, bookID}
+		var existing models.Copy
+		found, err := first(r.tx.Where("book_id = ? AND owner_id = ?", bookID, ownerID).Offset(seen[key]), &existing)
+		if err != nil {
+			return nil, err
+		}
+		seen[key]++
+		if found {
+			ids[c.ID] = existing.ID
+			if err := r.update(&existing, copyColumns(existing), copyColumns(c), &r.stats.Copies); err != nil {
+				return nil, fmt.Errorf("copy %d: %w", c.ID, err)
+			}
+			continue
+		}
+		ref := c.ID
+		c.ID, c.BookID, c.OwnerID = 0, bookID, ownerID
+		if err := r.create(&c); err != nil {
+			return nil, fmt.Errorf("copy %d: %w", ref, err)
+		}
+		ids[ref] = c.ID
+		r.stats.Copies.Created++
+	}
+	return ids, nil
+}
+
+// copyColumns are the columns a restore updates on a matched copy.
+func copyColumns(c models.Copy) map[string]any {
+	return map[string]any{
+		"condition":            c.Condition,
+		"notes":                c.Notes,
+		"status":               c.Status,
+		"auto_approve":         c.AutoApprove,
+		"return_date_required": c.ReturnDateRequired,
+		"hide_owner":           c.HideOwner,
+	}
+}
+
+func (r libraryRestore) loanRequests(loans []models.LoanRequest, copyIDs, userIDs map[uint]uint) error {
+	for _, l := range loans {
+		copyID, ok := copyIDs[l.CopyID]
+		if !ok {
+			return fmt.Errorf("loan request %d: unknown copy %d", l.ID, l.CopyID)
+		}
+		borrowerID, ok := userIDs[l.BorrowerID]
+		if !ok {
+			return fmt.Errorf("loan request %d: unknown borrower %d", l.ID, l.BorrowerID)
+		}
+		var returnedBy *uint
+		if l.ReturnedBy != nil {
+			id, ok := userIDs[*l.ReturnedBy]
+			if !ok {
+				return fmt.Errorf("loan request %d: unknown returner %d", l.ID, *l.ReturnedBy)
+			}
+			returnedBy = &id
+		}
+
+		var existing models.LoanRequest
+		found, err := first(r.tx.Where("copy_id = ? AND borrower_id = ? AND requested_at = ?", copyID, borrowerID, l.RequestedAt), &existing)
+		if err != nil {
+			return err
+		}
+		ref := l.ID
+		l.ID, l.CopyID, l.BorrowerID, l.ReturnedBy = 0, copyID, borrowerID, returnedBy
+		if found {
+			if err := r.update(&existing, loanColumns(existing), loanColumns(l), &r.stats.LoanRequests); err != nil {
+				return fmt.Errorf("loan request %d: %w", ref, err)
+			}
+			continue
+		}
+		if err := r.create(&l); err != nil {
+			return fmt.Errorf("loan request %d: %w", ref, err)
+		}
+		r.stats.LoanRequests.Created++
+	}
+	return nil
+}
+
+// loanColumns are the columns a restore updates on a matched loan request:
+// how far it got.
+func loanColumns(l models.LoanRequest) map[string]any {
+	return map[string]any{
+		"message":              l.Message,
+		"status":               l.Status,
+		"responded_at":         l.RespondedAt,
+		"loaned_at":            l.LoanedAt,
+		"returned_at":          l.ReturnedAt,
+		"returned_by":          l.ReturnedBy,
+		"expected_return_date": l.ExpectedReturnDate,
+	}
+}
+
+func (r libraryRestore) wishlistRequests(requests []models.WishlistRequest, bookIDs, userIDs map[uint]uint) error {
+	for _, w := range requests {
+		requesterID, ok := userIDs[w.RequesterID]
+		if !ok {
+			return fmt.Errorf("wishlist request %d: unknown requester %d", w.ID, w.RequesterID)
+		}
+		var fulfilledBookID *uint
+		if w.FulfilledBookID != nil {
+			id, ok := bookIDs[*w.FulfilledBookID]
+			if !ok {
+				return fmt.Errorf("wishlist request %d: unknown fulfilled book %d", w.ID, *w.FulfilledBookID)
+			}
+			fulfilledBookID = &id
+		}
+
+		var existing models.WishlistRequest
+		found, err := first(r.tx.Where("requester_id = ? AND title = ? AND author = ? AND created_at = ?",
+			requesterID, w.Title, w.Author, w.CreatedAt), &existing)
+		if err != nil {
+			return err
+		}
+		ref, storedID := w.ID, existing.ID
+		w.ID, w.RequesterID, w.FulfilledBookID, w.FulfilledBook = 0, requesterID, fulfilledBookID, nil
+		if found {
+			if err := r.update(&existing, wishlistColumns(existing), wishlistColumns(w), &r.stats.WishlistRequests); err != nil {
+				return fmt.Errorf("wishlist request %d: %w", ref, err)
+			}
+		} else {
+			if err := r.create(&w); err != nil {
+				return fmt.Errorf("wishlist request %d: %w", ref, err)
+			}
+			r.stats.WishlistRequests.Created++
+			storedID = w.ID
+		}
+		if err := r.coRequesters(ref, storedID, w.CoRequesters, userIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wishlistColumns are the columns a restore updates on a matched wishlist
+// request.
+func wishlistColumns(w models.WishlistRequest) map[string]any {
+	return map[string]any{
+		"isbn":              w.ISBN,
+		"ol_key":            w.OLKey,
+		"google_books_id":   w.GoogleBooksID,
+		"cover_url":         w.CoverURL,
+		"notes":             w.Notes,
+		"status":            w.Status,
+		"is_anonymous":      w.IsAnonymous,
+		"fulfilled_book_id": w.FulfilledBookID,
+		"fulfilled_at":      w.FulfilledAt,
+	}
+}
+
+// coRequesters restores the members joined to snapshot wishlist request ref,
+// stored as requestID.
+func (r libraryRestore) coRequesters(ref, requestID uint, coRequesters []models.WishlistCoRequester, userIDs map[uint]uint) error {
+	for _, co := range coRequesters {
+		userID, ok := userIDs[co.UserID]
+		if !ok {
+			return fmt.Errorf("wishlist request %d: unknown co-requester %d", ref, co.UserID)
+		}
+		var existing models.WishlistCoRequester
+		found, err := first(r.tx.Where("wishlist_request_id = ? AND user_id = ?", requestID, userID), &existing)
+		if err != nil {
+			return err
+		}
+		if found {
+			r.stats.WishlistCoRequesters.Matched++
+			continue
+		}
+		co.ID, co.WishlistRequestID, co.UserID = 0, requestID, userID
+		if err := r.create(&co); err != nil {
+			return fmt.Errorf("wishlist request %d co-requester: %w", ref, err)
+		}
+		r.stats.WishlistCoRequesters.Created++
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// librarySnapshotFixture is a small library as another instance would have
+// it, with IDs that don't line up with a fresh database's.
+func librarySnapshotFixture() *repository.LibrarySnapshot {
+	requested := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	returned := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	owner, borrower, book := uint(40), uint(41), uint(70)
+	return &repository.LibrarySnapshot{
+		Users: []models.User{
+			{ID: owner, Name: "Owner", Email: "Owner@Example.com", Password: "hash-1", Role: "admin", Verified: true, EmailNotificationsEnabled: true},
+			{ID: borrower, Name: "Borrower", Email: "borrower@example.com", Password: "hash-2", Role: "user"},
+		},
+		Books: []models.Book{
+			{ID: book, Title: "Dune", Author: "Frank Herbert", OLKey: "/works/OL893415W"},
+			{ID: 71, Title: "Zine", Author: "Anon"},
+		},
+		Copies: []models.Copy{
+			{ID: 90, BookID: book, OwnerID: owner, Status: "available", Condition: "good"},
+			{ID: 91, BookID: book, OwnerID: owner, Status: "available", Condition: "worn"},
+			{ID: 92, BookID: 71, OwnerID: borrower, Status: "available"},
+		},
+		LoanRequests: []models.LoanRequest{
+			{ID: 5, CopyID: 91, BorrowerID: borrower, Status: "returned", RequestedAt: requested, ReturnedAt: &returned, ReturnedBy: &owner},
+		},
+		WishlistRequests: []models.WishlistRequest{
+			{
+				ID: 8, RequesterID: borrower, Title: "Dune", Author: "Frank Herbert", Status: "fulfilled", FulfilledBookID: &book, CreatedAt: requested,
+				CoRequesters: []models.WishlistCoRequester{{ID: 3, WishlistRequestID: 8, UserID: owner, CreatedAt: requested}},
+			},
+		},
+	}
+}
+
+func TestLibraryRepository_RestoreIntoEmptyDatabase(t *testing.T) {
+	db := openTestDB(t)
+	library := NewLibraryRepository(db)
+
+	stats, err := library.Restore(librarySnapshotFixture())
+	require.NoError(t, err)
+	assert.Equal(t, repository.LibraryRestoreStats{
+		Users:                repository.RestoreCount{Created: 2},
+		Books:                repository.RestoreCount{Created: 2},
+		Copies:               repository.RestoreCount{Created: 3},
+		LoanRequests:         repository.RestoreCount{Created: 1},
+		WishlistRequests:     repository.RestoreCount{Created: 1},
+		WishlistCoRequesters: repository.RestoreCount{Created: 1},
+	}, stats)
+
+	s, err := library.Snapshot()
+	require.NoError(t, err)
+	require.Len(t, s.Users, 2)
+	assert.Equal(t, "hash-1", s.Users[0].Password, "accounts carry over with their password")
+	require.Len(t, s.Copies, 3)
+	dune := s.Books[0]
+	assert.Equal(t, dune.ID, s.Copies[1].BookID)
+	assert.Equal(t, "worn", s.Copies[1].Condition)
+
+	require.Len(t, s.LoanRequests, 1)
+	loan := s.LoanRequests[0]
+	assert.Equal(t, s.Copies[1].ID, loan.CopyID, "references are remapped to the new IDs")
+	assert.Equal(t, s.Users[1].ID, loan.BorrowerID)
+	require.NotNil(t, loan.ReturnedBy)
+	assert.Equal(t, s.Users[0].ID, *loan.ReturnedBy)
+	assert.True(t, loan.RequestedAt.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)))
+
+	require.Len(t, s.WishlistRequests, 1)
+	require.NotNil(t, s.WishlistRequests[0].FulfilledBookID)
+	assert.Equal(t, dune.ID, *s.WishlistRequests[0].FulfilledBookID)
+	require.Len(t, s.WishlistRequests[0].CoRequesters, 1)
+	assert.Equal(t, s.Users[0].ID, s.WishlistRequests[0].CoRequesters[0].UserID)
+}
+
+func TestLibraryRepository_RestoreMatchesExistingRecords(t *testing.T) {
+	db := openTestDB(t)
+	library := NewLibraryRepository(db)
+	// Already here: the owner (under different casing), Dune, and one of the
+	// owner's two copies of it.
+	existingOwner := models.User{Name: "Local Owner", Email: "owner@example.com", Password: "local", Role: "user"}
+	require.NoError(t, db.Create(&existingOwner).Error)
+	existingDune := models.Book{Title: "Dune (local)", Author: "F. Herbert", OLKey: "/works/OL893415W"}
+	require.NoError(t, db.Create(&existingDune).Error)
+	require.NoError(t, db.Omit("Book", "Owner").Create(&models.Copy{BookID: existingDune.ID, OwnerID: existingOwner.ID, Status: "available"}).Error)
+
+	stats, err := library.Restore(librarySnapshotFixture())
+	require.NoError(t, err)
+	assert.Equal(t, repository.RestoreCount{Created: 1, Updated: 1}, stats.Users)
+	assert.Equal(t, repository.RestoreCount{Created: 1, Updated: 1}, stats.Books)
+	assert.Equal(t, repository.RestoreCount{Created: 2, Updated: 1}, stats.Copies)
+
+	var owner models.User
+	require.NoError(t, db.First(&owner, existingOwner.ID).Error)
+	assert.Equal(t, "Owner", owner.Name, "a matched record is updated from the snapshot")
+	assert.Equal(t, "local", owner.Password, "except a member's password")
+	assert.Equal(t, "user", owner.Role, "and role")
+	assert.Equal(t, "owner@example.com", owner.Email, "but keeps the email it matched on")
+	var dune models.Book
+	require.NoError(t, db.First(&dune, existingDune.ID).Error)
+	assert.Equal(t, "Dune", dune.Title)
+
+	t.Run("restoring the same snapshot again changes nothing", func(t *testing.T) {
+		again, err := library.Restore(librarySnapshotFixture())
+		require.NoError(t, err)
+		assert.Equal(t, repository.LibraryRestoreStats{
+			Users:                repository.RestoreCount{Matched: 2},
+			Books:                repository.RestoreCount{Matched: 2},
+			Copies:               repository.RestoreCount{Matched: 3},
+			LoanRequests:         repository.RestoreCount{Matched: 1},
+			WishlistRequests:     repository.RestoreCount{Matched: 1},
+			WishlistCoRequesters: repository.RestoreCount{Matched: 1},
+		}, again)
+	})
+
+	t.Run("restoring a newer snapshot brings matched records up to date", func(t *testing.T) {
+		returned := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+		newer := librarySnapshotFixture()
+		newer.Copies[2].Status = "loaned"
+		newer.LoanRequests[0].Status = "cancelled"
+		newer.LoanRequests[0].ReturnedAt = &returned
+		newer.WishlistRequests[0].Status = "cancelled"
+		newer.WishlistRequests[0].FulfilledBookID = nil
+
+		stats, err := library.Restore(newer)
+		require.NoError(t, err)
+		assert.Equal(t, repository.RestoreCount{Updated: 1, Matched: 2}, stats.Copies)
+		assert.Equal(t, repository.RestoreCount{Updated: 1}, stats.LoanRequests)
+		assert.Equal(t, repository.RestoreCount{Updated: 1}, stats.WishlistRequests)
+
+		s, err := library.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, "loaned", s.Copies[2].Status)
+		assert.Equal(t, "cancelled", s.LoanRequests[0].Status)
+		require.NotNil(t, s.LoanRequests[0].ReturnedAt)
+		assert.True(t, s.LoanRequests[0].ReturnedAt.Equal(returned))
+		assert.Equal(t, "cancelled", s.WishlistRequests[0].Status)
+		assert.Nil(t, s.WishlistRequests[0].FulfilledBookID)
+	})
+}
+
+func TestLibraryRepository_RestoreRollsBackOnABadReference(t *testing.T) {
+	db := openTestDB(t)
+	library := NewLibraryRepository(db)
+	s := librarySnapshotFixture()
+	s.Copies[2].OwnerID = 999
+
+	_, err := library.Restore(s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown owner 999")
+
+	var users int64
+	require.NoError(t, db.Model(&models.User{}).Count(&users).Error)
+	assert.Zero(t, users, "nothing is kept from a failed restore")
+}
@@ -104,30 +104,44 @@ func (r *LoanRequestRepository) ListByBorrowerID(borrowerID uint) ([]models.Loan
 	return requests, err
 }
 
-func (r *LoanRequestRepository) ListByBorrowerIDPaginated(borrowerID uint, statuses []string, page, pageSize int) (*repository.PaginatedResult[models.LoanRequest], error) {
-	countQuery := r.db.Model(&models.LoanRequest{}).Where("borrower_id = ?", borrowerID)
-	selectQuery := r.db.Preload("Copy.Book").Preload("Copy.Owner").Preload("Borrower").
-		Where("borrower_id = ?", borrowerID)
+func (r *LoanRequestRepository) borrowerQuery(borrowerID uint, statuses []string) *gorm.DB {
+	tx := r.db.Model(&models.LoanRequest{}).Where("borrower_id = ?", borrowerID)
 	if len(statuses) > 0 {
-		countQuery = countQuery.Where("status IN ?", statuses)
-		selectQuery = selectQuery.Where("status IN ?", statuses)
+		tx = tx.Where("status IN ?", statuses)
 	}
+	return tx
+}
+
+// borrowerLoanKeyset orders a borrower's loan requests newest first.
+var borrowerLoanKeyset = createdAtKeyset("requested_at", "id")
+
+func loanRequestCursor(lr models.LoanRequest) (*repository.Cursor, error) {
+	return &repository.Cursor{Keys: []string{timeKey(lr.RequestedAt)}, ID: lr.ID}, nil
+}
 
+func (r *LoanRequestRepository) ListByBorrowerIDPaginated(borrowerID uint, statuses []string, page, pageSize int) (*repository.PaginatedResult[models.LoanRequest], error) {
 	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
+	if err := r.borrowerQuery(borrowerID, statuses).Count(&total).Error; err != nil {
 		return nil, err
 	}
 	var requests []models.LoanRequest
 	offset := (page - 1) * pageSize
-	if err := selectQuery.Order("requested_at DESC").Offset(offset).Limit(pageSize).Find(&requests).Error; err != nil {
+	selectQuery := r.borrowerQuery(borrowerID, statuses).Preload("Copy.Book").Preload("Copy.Owner").Preload("Borrower")
+	if err := borrowerLoanKeyset.order(selectQuery).Offset(offset).Limit(pageSize).Find(&requests).Error; err != nil {
 		return nil, err
 	}
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	next, _ := nextPageCursor(requests, page, totalPages, loanRequestCursor)
 	return &repository.PaginatedResult[models.LoanRequest]{
-		Items: requests, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages,
+		Items: requests, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages, Next: next,
 	}, nil
 }
 
+func (r *LoanRequestRepository) ListByBorrowerIDAfter(borrowerID uint, statuses []string, after *repository.Cursor, limit int) (*repository.CursorResult[models.LoanRequest], error) {
+	tx := r.borrowerQuery(borrowerID, statuses).Preload("Copy.Book").Preload("Copy.Owner").Preload("Borrower")
+	return keysetPage(tx, borrowerLoanKeyset, after, limit, loanRequestCursor)
+}
+
 // ListActiveByBorrowerID returns borrowerID's accepted (currently-held) loan
 // requests, due-soonest first, with NULL expected_return_date sorted last.
 func (r *LoanRequestRepository) ListActiveByBorrowerID(borrowerID uint) ([]models.LoanRequest, error) {
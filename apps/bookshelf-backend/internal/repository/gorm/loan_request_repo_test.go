@@ -27,7 +27,12 @@ func openTestDB(t *testing.T) *gorm.DB {
 	require.NoError(t, db.AutoMigrate(
 		&models.User{}, &models.Book{}, &models.Copy{},
 		&models.LoanRequest{}, &models.Notification{}, &models.WaitlistEntry{},
-		&models.Announcement{}, &models.WishlistRequest{},
+		&models.Announcement{}, &models.WishlistRequest{}, &models.WishlistCoRequester{},
+		&models.BookAffinity{}, &models.UserRecommendation{},
+		&models.ReadingList{}, &models.ReadingListEntry{},
+		&models.Author{}, &models.BookContributor{},
+		&models.MetadataCacheEntry{},
+		&models.ImportJob{}, &models.ImportJobRow{},
 	))
 	return db
 }
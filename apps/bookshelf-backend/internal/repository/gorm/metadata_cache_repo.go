@@ -0,0 +1,67 @@
+package gorm
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// MetadataCacheRepository is the GORM implementation of repository.MetadataCacheRepository.
+type MetadataCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewMetadataCacheRepository creates a new MetadataCacheRepository.
+func NewMetadataCacheRepository(db *gorm.DB) *MetadataCacheRepository {
+	return &MetadataCacheRepository{db: db}
+}
+
+func (r *MetadataCacheRepository) Get(key string) (*models.MetadataCacheEntry, error) {
+	var e models.MetadataCacheEntry
+	if err := r.db.Where("key = ?", key).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *MetadataCacheRepository) Put(e *models.MetadataCacheEntry) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(e).Error
+}
+
+func (r *MetadataCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", now).Delete(&models.MetadataCacheEntry{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *MetadataCacheRepository) Trim(maxEntries int) (int64, error) {
+	count, err := r.Count()
+	if err != nil {
+		return 0, err
+	}
+	excess := int(count) - maxEntries
+	if excess <= 0 {
+		return 0, nil
+	}
+	oldest := r.db.Model(&models.MetadataCacheEntry{}).Select("key").Order("expires_at asc, key asc").Limit(excess)
+	res := r.db.Where("key IN (?)", oldest).Delete(&models.MetadataCacheEntry{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *MetadataCacheRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.MetadataCacheEntry{}).Count(&count).Error
+	return count, err
+}
+
+func (r *MetadataCacheRepository) DeleteAll() (int64, error) {
+	res := r.db.Where("1 = 1").Delete(&models.MetadataCacheEntry{})
+	return res.RowsAffected, res.Error
+}
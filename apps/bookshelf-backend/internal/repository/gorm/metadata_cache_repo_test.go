@@ -0,0 +1,65 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+func TestMetadataCacheRepository_PutReplacesExistingKey(t *testing.T) {
+	cache := NewMetadataCacheRepository(openTestDB(t))
+	expires := time.Now().Add(time.Hour)
+
+	require.NoError(t, cache.Put(&models.MetadataCacheEntry{Key: "dune", Results: "[]", ExpiresAt: expires}))
+	require.NoError(t, cache.Put(&models.MetadataCacheEntry{Key: "dune", Results: `[{"title":"Dune"}]`, ExpiresAt: expires}))
+
+	e, err := cache.Get("dune")
+	require.NoError(t, err)
+	assert.Equal(t, `[{"title":"Dune"}]`, e.Results)
+	count, err := cache.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = cache.Get("missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestMetadataCacheRepository_DeleteExpired(t *testing.T) {
+	cache := NewMetadataCacheRepository(openTestDB(t))
+	now := time.Now()
+	require.NoError(t, cache.Put(&models.MetadataCacheEntry{Key: "stale", Results: "[]", ExpiresAt: now.Add(-time.Minute)}))
+	require.NoError(t, cache.Put(&models.MetadataCacheEntry{Key: "fresh", Results: "[]", ExpiresAt: now.Add(time.Minute)}))
+
+	deleted, err := cache.DeleteExpired(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = cache.Get("fresh")
+	assert.NoError(t, err)
+}
+
+func TestMetadataCacheRepository_TrimEvictsSoonestToExpire(t *testing.T) {
+	cache := NewMetadataCacheRepository(openTestDB(t))
+	now := time.Now()
+	for i, key := range []string{"c", "a", "b"} {
+		require.NoError(t, cache.Put(&models.MetadataCacheEntry{Key: key, Results: "[]", ExpiresAt: now.Add(time.Duration(i) * time.Minute)}))
+	}
+
+	deleted, err := cache.Trim(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	_, err = cache.Get("b")
+	assert.NoError(t, err, "the latest-expiring entry survives")
+
+	deleted, err = cache.Trim(5)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	deleted, err = cache.DeleteAll()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
@@ -35,30 +35,43 @@ func (r *NotificationRepository) FindByRecipient(recipientID uint, unreadOnly bo
 	return notifications, nil
 }
 
-func (r *NotificationRepository) FindByRecipientPaginated(recipientID uint, unreadOnly bool, page, pageSize int) (*repository.PaginatedResult[models.Notification], error) {
-	var total int64
-	cq := r.db.Model(&models.Notification{}).Where("recipient_id = ?", recipientID)
+func (r *NotificationRepository) recipientQuery(recipientID uint, unreadOnly bool) *gorm.DB {
+	tx := r.db.Model(&models.Notification{}).Where("recipient_id = ?", recipientID)
 	if unreadOnly {
-		cq = cq.Where("read = ?", false)
+		tx = tx.Where("read = ?", false)
 	}
-	if err := cq.Count(&total).Error; err != nil {
+	return tx
+}
+
+// notificationKeyset orders a recipient's notifications newest first.
+var notificationKeyset = createdAtKeyset("created_at", "id")
+
+func notificationCursor(n models.Notification) (*repository.Cursor, error) {
+	return &repository.Cursor{Keys: []string{timeKey(n.CreatedAt)}, ID: n.ID}, nil
+}
+
+func (r *NotificationRepository) FindByRecipientPaginated(recipientID uint, unreadOnly bool, page, pageSize int) (*repository.PaginatedResult[models.Notification], error) {
+	var total int64
+	if err := r.recipientQuery(recipientID, unreadOnly).Count(&total).Error; err != nil {
 		return nil, err
 	}
 	var notifications []models.Notification
 	offset := (page - 1) * pageSize
-	fq := r.db.Where("recipient_id = ?", recipientID).Order("created_at DESC").Offset(offset).Limit(pageSize)
-	if unreadOnly {
-		fq = fq.Where("read = ?", false)
-	}
+	fq := notificationKeyset.order(r.recipientQuery(recipientID, unreadOnly)).Offset(offset).Limit(pageSize)
 	if err := fq.Find(&notifications).Error; err != nil {
 		return nil, err
 	}
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	next, _ := nextPageCursor(notifications, page, totalPages, notificationCursor)
 	return &repository.PaginatedResult[models.Notification]{
-		Items: notifications, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages,
+		Items: notifications, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages, Next: next,
 	}, nil
 }
 
+func (r *NotificationRepository) FindByRecipientAfter(recipientID uint, unreadOnly bool, after *repository.Cursor, limit int) (*repository.CursorResult[models.Notification], error) {
+	return keysetPage(r.recipientQuery(recipientID, unreadOnly), notificationKeyset, after, limit, notificationCursor)
+}
+
 func (r *NotificationRepository) GetByID(id uint) (*models.Notification, error) {
 	var n models.Notification
 	if err := r.db.First(&n, id).Error; err != nil {
@@ -0,0 +1,48 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+func TestNotificationRepository_FindByRecipientAfter(t *testing.T) {
+	db := openTestDB(t)
+	notifs := NewNotificationRepository(db)
+
+	recipient := models.User{Name: "Recipient", Email: "r@example.com"}
+	require.NoError(t, db.Create(&recipient).Error)
+
+	// Two notifications share a timestamp, so only the ID tiebreaker keeps
+	// them from being skipped or repeated across a page boundary.
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{base, base.Add(time.Minute), base.Add(time.Minute), base.Add(2 * time.Minute)} {
+		require.NoError(t, notifs.Create(&models.Notification{RecipientID: recipient.ID, Type: "t", CreatedAt: at}))
+	}
+
+	var ids []uint
+	var after *repository.Cursor
+	for {
+		page, err := notifs.FindByRecipientAfter(recipient.ID, false, after, 1)
+		require.NoError(t, err)
+		for _, n := range page.Items {
+			ids = append(ids, n.ID)
+		}
+		if page.Next == nil {
+			break
+		}
+		after = page.Next
+
+		// Arriving mid-scroll, a newer notification belongs before the
+		// cursor and must not show up on a later page.
+		if len(ids) == 1 {
+			require.NoError(t, notifs.Create(&models.Notification{RecipientID: recipient.ID, Type: "t", CreatedAt: base.Add(time.Hour)}))
+		}
+	}
+	assert.Equal(t, []uint{4, 3, 2, 1}, ids)
+}
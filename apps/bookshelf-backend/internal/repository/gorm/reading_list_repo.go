@@ -0,0 +1,150 @@
+package gorm
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// ReadingListRepository is the GORM implementation of repository.ReadingListRepository.
+type ReadingListRepository struct {
+	db *gorm.DB
+}
+
+// NewReadingListRepository creates a new ReadingListRepository.
+func NewReadingListRepository(db *gorm.DB) *ReadingListRepository {
+	return &ReadingListRepository{db: db}
+}
+
+func (r *ReadingListRepository) Create(l *models.ReadingList) error {
+	return r.db.Omit("Entries").Create(l).Error
+}
+
+func (r *ReadingListRepository) GetByID(id uint) (*models.ReadingList, error) {
+	var l models.ReadingList
+	err := r.db.
+		Preload("Entries", func(tx *gorm.DB) *gorm.DB { return tx.Order("position ASC, id ASC") }).
+		Preload("Entries.Book").
+		First(&l, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Save updates the list's own columns only — entries are managed through
+// the entry methods below, never by saving a list with Entries populated.
+func (r *ReadingListRepository) Save(l *models.ReadingList) error {
+	return r.db.Omit("Entries").Save(l).Error
+}
+
+func (r *ReadingListRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("reading_list_id = ?", id).Delete(&models.ReadingListEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ReadingList{}, id).Error
+	})
+}
+
+func (r *ReadingListRepository) ListByOwnerID(ownerID uint, publicOnly bool) ([]models.ReadingList, error) {
+	tx := r.db.Where("owner_id = ?", ownerID)
+	if publicOnly {
+		tx = tx.Where("is_public = ?", true)
+	}
+	var out []models.ReadingList
+	if err := tx.Order("created_at ASC, id ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *ReadingListRepository) CountEntriesBatch(listIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(listIDs))
+	if len(listIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ReadingListID uint
+		Count         int64
+	}
+	if err := r.db.Model(&models.ReadingListEntry{}).
+		Select("reading_list_id, COUNT(*) AS count").
+		Where("reading_list_id IN ?", listIDs).
+		Group("reading_list_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ReadingListID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *ReadingListRepository) AddEntry(e *models.ReadingListEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxPos *int
+		if err := tx.Model(&models.ReadingListEntry{}).
+			Where("reading_list_id = ?", e.ReadingListID).
+			Select("MAX(position)").
+			Scan(&maxPos).Error; err != nil {
+			return err
+		}
+		e.Position = 0
+		if maxPos != nil {
+			e.Position = *maxPos + 1
+		}
+		if err := tx.Omit("Book").Create(e).Error; err != nil {
+			if isUniqueViolation(err) {
+				return repository.ErrConflict
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *ReadingListRepository) FindEntry(listID, bookID uint) (*models.ReadingListEntry, error) {
+	var e models.ReadingListEntry
+	if err := r.db.Where("reading_list_id = ? AND book_id = ?", listID, bookID).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *ReadingListRepository) SaveEntry(e *models.ReadingListEntry) error {
+	return r.db.Omit("Book").Save(e).Error
+}
+
+func (r *ReadingListRepository) RemoveEntry(listID, bookID uint) error {
+	result := r.db.Where("reading_list_id = ? AND book_id = ?", listID, bookID).Delete(&models.ReadingListEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ReadingListRepository) Reorder(listID uint, bookIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, bookID := range bookIDs {
+			if err := tx.Model(&models.ReadingListEntry{}).
+				Where("reading_list_id = ? AND book_id = ?", listID, bookID).
+				Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+func TestReadingListRepository_Entries(t *testing.T) {
+	db := openTestDB(t)
+	lists := NewReadingListRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+	first := models.Book{Title: "First", Author: "A"}
+	second := models.Book{Title: "Second", Author: "A"}
+	require.NoError(t, db.Create(&first).Error)
+	require.NoError(t, db.Create(&second).Error)
+
+	list := models.ReadingList{OwnerID: owner.ID, Name: "To read"}
+	require.NoError(t, lists.Create(&list))
+
+	require.NoError(t, lists.AddEntry(&models.ReadingListEntry{ReadingListID: list.ID, BookID: first.ID}))
+	require.NoError(t, lists.AddEntry(&models.ReadingListEntry{ReadingListID: list.ID, BookID: second.ID, Notes: "lent by Sam"}))
+
+	t.Run("adding the same book twice is a conflict", func(t *testing.T) {
+		err := lists.AddEntry(&models.ReadingListEntry{ReadingListID: list.ID, BookID: first.ID})
+		require.ErrorIs(t, err, repository.ErrConflict)
+	})
+
+	t.Run("GetByID preloads entries in position order with their books", func(t *testing.T) {
+		got, err := lists.GetByID(list.ID)
+		require.NoError(t, err)
+		require.Len(t, got.Entries, 2)
+		assert.Equal(t, "First", got.Entries[0].Book.Title)
+		assert.Equal(t, "Second", got.Entries[1].Book.Title)
+		assert.Equal(t, "lent by Sam", got.Entries[1].Notes)
+	})
+
+	t.Run("Reorder rewrites positions", func(t *testing.T) {
+		require.NoError(t, lists.Reorder(list.ID, []uint{second.ID, first.ID}))
+		got, err := lists.GetByID(list.ID)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, got.Entries[0].BookID)
+	})
+
+	t.Run("CountEntriesBatch counts per list", func(t *testing.T) {
+		counts, err := lists.CountEntriesBatch([]uint{list.ID, list.ID + 100})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), counts[list.ID])
+		assert.Zero(t, counts[list.ID+100])
+	})
+
+	t.Run("RemoveEntry of a missing book is not found", func(t *testing.T) {
+		require.ErrorIs(t, lists.RemoveEntry(list.ID, 999), repository.ErrNotFound)
+	})
+
+	t.Run("Delete removes the list and its entries", func(t *testing.T) {
+		require.NoError(t, lists.Delete(list.ID))
+		_, err := lists.GetByID(list.ID)
+		require.ErrorIs(t, err, repository.ErrNotFound)
+		var remaining int64
+		require.NoError(t, db.Model(&models.ReadingListEntry{}).Count(&remaining).Error)
+		assert.Zero(t, remaining)
+	})
+}
+
+func TestReadingListRepository_ListByOwnerIDPublicOnly(t *testing.T) {
+	db := openTestDB(t)
+	lists := NewReadingListRepository(db)
+
+	require.NoError(t, lists.Create(&models.ReadingList{OwnerID: 1, Name: "Public", IsPublic: true}))
+	require.NoError(t, lists.Create(&models.ReadingList{OwnerID: 1, Name: "Private"}))
+	require.NoError(t, lists.Create(&models.ReadingList{OwnerID: 2, Name: "Someone else's", IsPublic: true}))
+
+	all, err := lists.ListByOwnerID(1, false)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	public, err := lists.ListByOwnerID(1, true)
+	require.NoError(t, err)
+	require.Len(t, public, 1)
+	assert.Equal(t, "Public", public[0].Name)
+}
@@ -0,0 +1,100 @@
+package gorm
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// recommendationInsertBatchSize bounds how many rows a single INSERT in
+// ReplaceAll carries, keeping each statement well under SQLite's bound
+// parameter limit.
+const recommendationInsertBatchSize = 500
+
+// RecommendationRepository is the GORM implementation of
+// repository.RecommendationRepository.
+type RecommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository creates a new RecommendationRepository.
+func NewRecommendationRepository(db *gorm.DB) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+func (r *RecommendationRepository) ListBorrowHistory() ([]repository.UserBook, error) {
+	var pairs []repository.UserBook
+	if err := r.db.Table("loan_requests").
+		Select("DISTINCT loan_requests.borrower_id AS user_id, copies.book_id AS book_id").
+		Joins("JOIN copies ON copies.id = loan_requests.copy_id").
+		Where("loan_requests.status IN ?", []string{"accepted", "returned"}).
+		Scan(&pairs).Error; err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+func (r *RecommendationRepository) ListOwnership() ([]repository.UserBook, error) {
+	var pairs []repository.UserBook
+	if err := r.db.Table("copies").
+		Select("DISTINCT owner_id AS user_id, book_id").
+		Scan(&pairs).Error; err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+func (r *RecommendationRepository) ReplaceAll(affinities []models.BookAffinity, recs []models.UserRecommendation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.BookAffinity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&models.UserRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(affinities) > 0 {
+			if err := tx.CreateInBatches(affinities, recommendationInsertBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		if len(recs) > 0 {
+			if err := tx.CreateInBatches(recs, recommendationInsertBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *RecommendationRepository) ListRelated(bookID uint, limit int) ([]models.Book, error) {
+	var books []models.Book
+	if err := r.db.Model(&models.Book{}).
+		Joins("JOIN book_affinities ON book_affinities.related_book_id = books.id").
+		Where("book_affinities.book_id = ?", bookID).
+		Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)").
+		Order("book_affinities.score DESC, books.title ASC").
+		Limit(limit).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (r *RecommendationRepository) ListForUser(userID uint, limit int) ([]models.Book, error) {
+	var books []models.Book
+	if err := r.db.Model(&models.Book{}).
+		Joins("JOIN user_recommendations ON user_recommendations.book_id = books.id").
+		Where("user_recommendations.user_id = ?", userID).
+		Where("EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)").
+		Where("NOT EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id AND copies.owner_id = ?)", userID).
+		Where(`NOT EXISTS (SELECT 1 FROM loan_requests JOIN copies ON copies.id = loan_requests.copy_id
+			WHERE copies.book_id = books.id AND loan_requests.borrower_id = ? AND loan_requests.status IN ?)`,
+			userID, []string{"accepted", "returned"}).
+		Order("user_recommendations.score DESC, books.title ASC").
+		Limit(limit).
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
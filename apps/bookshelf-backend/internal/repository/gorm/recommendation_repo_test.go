@@ -0,0 +1,104 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// seedLoan creates a loan request by borrower for bookCopy in the given status.
+func seedLoan(t *testing.T, db *gorm.DB, bookCopy models.Copy, borrower models.User, status string) {
+	t.Helper()
+	require.NoError(t, db.Create(&models.LoanRequest{CopyID: bookCopy.ID, BorrowerID: borrower.ID, Status: status}).Error)
+}
+
+func TestRecommendationRepository_ListBorrowHistory(t *testing.T) {
+	db := openTestDB(t)
+	recs := NewRecommendationRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	borrower := models.User{Name: "Borrower", Email: "borrower@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+	require.NoError(t, db.Create(&borrower).Error)
+	book := models.Book{Title: "Borrowed", Author: "A"}
+	require.NoError(t, db.Create(&book).Error)
+	bookCopy := models.Copy{BookID: book.ID, OwnerID: owner.ID}
+	require.NoError(t, db.Create(&bookCopy).Error)
+
+	seedLoan(t, db, bookCopy, borrower, "returned")
+	seedLoan(t, db, bookCopy, borrower, "accepted")
+	seedLoan(t, db, bookCopy, owner, "rejected")
+
+	pairs, err := recs.ListBorrowHistory()
+
+	require.NoError(t, err)
+	assert.Equal(t, []repository.UserBook{{UserID: borrower.ID, BookID: book.ID}}, pairs,
+		"repeat loans collapse to one pair and unsuccessful requests don't count")
+}
+
+func TestRecommendationRepository_ListForUser(t *testing.T) {
+	db := openTestDB(t)
+	recs := NewRecommendationRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	member := models.User{Name: "Member", Email: "member@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+	require.NoError(t, db.Create(&member).Error)
+
+	books := map[string]*models.Book{}
+	for _, title := range []string{"Best", "Good", "Copyless", "Owned", "Borrowed"} {
+		b := &models.Book{Title: title, Author: "A"}
+		require.NoError(t, db.Create(b).Error)
+		books[title] = b
+	}
+	for _, title := range []string{"Best", "Good", "Borrowed"} {
+		require.NoError(t, db.Create(&models.Copy{BookID: books[title].ID, OwnerID: owner.ID}).Error)
+	}
+	require.NoError(t, db.Create(&models.Copy{BookID: books["Owned"].ID, OwnerID: member.ID}).Error)
+	var borrowedCopy models.Copy
+	require.NoError(t, db.Where("book_id = ?", books["Borrowed"].ID).First(&borrowedCopy).Error)
+	seedLoan(t, db, borrowedCopy, member, "returned")
+
+	require.NoError(t, recs.ReplaceAll(nil, []models.UserRecommendation{
+		{UserID: member.ID, BookID: books["Good"].ID, Score: 1},
+		{UserID: member.ID, BookID: books["Best"].ID, Score: 5},
+		{UserID: member.ID, BookID: books["Copyless"].ID, Score: 9},
+		{UserID: member.ID, BookID: books["Owned"].ID, Score: 9},
+		{UserID: member.ID, BookID: books["Borrowed"].ID, Score: 9},
+	}))
+
+	got, err := recs.ListForUser(member.ID, 10)
+
+	require.NoError(t, err)
+	require.Len(t, got, 2, "copyless, owned, and already-borrowed books are filtered at read time")
+	assert.Equal(t, "Best", got[0].Title)
+	assert.Equal(t, "Good", got[1].Title)
+}
+
+func TestRecommendationRepository_ReplaceAllSwapsPreviousRows(t *testing.T) {
+	db := openTestDB(t)
+	recs := NewRecommendationRepository(db)
+
+	owner := models.User{Name: "Owner", Email: "owner@example.com"}
+	require.NoError(t, db.Create(&owner).Error)
+	a := models.Book{Title: "A", Author: "X"}
+	b := models.Book{Title: "B", Author: "X"}
+	c := models.Book{Title: "C", Author: "X"}
+	for _, book := range []*models.Book{&a, &b, &c} {
+		require.NoError(t, db.Create(book).Error)
+		require.NoError(t, db.Create(&models.Copy{BookID: book.ID, OwnerID: owner.ID}).Error)
+	}
+
+	require.NoError(t, recs.ReplaceAll([]models.BookAffinity{{BookID: a.ID, RelatedBookID: b.ID, Score: 1}}, nil))
+	require.NoError(t, recs.ReplaceAll([]models.BookAffinity{{BookID: a.ID, RelatedBookID: c.ID, Score: 2}}, nil))
+
+	got, err := recs.ListRelated(a.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, c.ID, got[0].ID)
+}
@@ -27,7 +27,7 @@ func (r *WishlistRequestRepository) Create(req *models.WishlistRequest) error {
 
 func (r *WishlistRequestRepository) GetByID(id uint) (*models.WishlistRequest, error) {
 	var req models.WishlistRequest
-	if err := r.db.Preload("Requester").Preload("FulfilledBook").First(&req, id).Error; err != nil {
+	if err := r.db.Preload("Requester").Preload("FulfilledBook").Preload("CoRequesters").First(&req, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound
 		}
@@ -36,8 +36,10 @@ func (r *WishlistRequestRepository) GetByID(id uint) (*models.WishlistRequest, e
 	return &req, nil
 }
 
+// Save omits CoRequesters: they're written only by Join and Leave, and
+// re-saving a stale preloaded set would resurrect a row someone just left.
 func (r *WishlistRequestRepository) Save(req *models.WishlistRequest) error {
-	return r.db.Save(req).Error
+	return r.db.Omit("CoRequesters").Save(req).Error
 }
 
 func (r *WishlistRequestRepository) buildOpenQuery(search string) *gorm.DB {
@@ -49,6 +51,13 @@ func (r *WishlistRequestRepository) buildOpenQuery(search string) *gorm.DB {
 	return tx
 }
 
+// openWishlistKeyset orders the browse board newest first.
+var openWishlistKeyset = createdAtKeyset("created_at", "id")
+
+func wishlistCursor(req models.WishlistRequest) (*repository.Cursor, error) {
+	return &repository.Cursor{Keys: []string{timeKey(req.CreatedAt)}, ID: req.ID}, nil
+}
+
 func (r *WishlistRequestRepository) ListOpenPaginated(search string, page, pageSize int) (*repository.PaginatedResult[models.WishlistRequest], error) {
 	var total int64
 	if err := r.buildOpenQuery(search).Count(&total).Error; err != nil {
@@ -56,19 +65,24 @@ func (r *WishlistRequestRepository) ListOpenPaginated(search string, page, pageS
 	}
 	var items []models.WishlistRequest
 	offset := (page - 1) * pageSize
-	if err := r.buildOpenQuery(search).Preload("Requester").
-		Order("created_at desc").Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
+	if err := openWishlistKeyset.order(r.buildOpenQuery(search).Preload("Requester").Preload("CoRequesters")).
+		Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
 		return nil, err
 	}
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	next, _ := nextPageCursor(items, page, totalPages, wishlistCursor)
 	return &repository.PaginatedResult[models.WishlistRequest]{
-		Items: items, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages,
+		Items: items, Total: total, Page: page, PageSize: pageSize, TotalPages: totalPages, Next: next,
 	}, nil
 }
 
+func (r *WishlistRequestRepository) ListOpenAfter(search string, after *repository.Cursor, limit int) (*repository.CursorResult[models.WishlistRequest], error) {
+	return keysetPage(r.buildOpenQuery(search).Preload("Requester").Preload("CoRequesters"), openWishlistKeyset, after, limit, wishlistCursor)
+}
+
 func (r *WishlistRequestRepository) ListByRequesterID(requesterID uint) ([]models.WishlistRequest, error) {
 	var items []models.WishlistRequest
-	err := r.db.Preload("FulfilledBook").
+	err := r.db.Preload("FulfilledBook").Preload("CoRequesters").
 		Where("requester_id = ?", requesterID).
 		Order("created_at desc").
 		Find(&items).Error
@@ -87,6 +101,12 @@ func (r *WishlistRequestRepository) FindOpenByGoogleBooksID(googleBooksID string
 	return items, err
 }
 
+func (r *WishlistRequestRepository) ListOpen() ([]models.WishlistRequest, error) {
+	var items []models.WishlistRequest
+	err := r.db.Where("status = ?", "open").Order("created_at asc, id asc").Find(&items).Error
+	return items, err
+}
+
 func (r *WishlistRequestRepository) FindOpenMatch(isbn, olKey, googleBooksID string) (*models.WishlistRequest, error) {
 	var conds []string
 	var args []any
@@ -109,7 +129,7 @@ func (r *WishlistRequestRepository) FindOpenMatch(isbn, olKey, googleBooksID str
 	var req models.WishlistRequest
 	err := r.db.Where("status = ?", "open").
 		Where(strings.Join(conds, " OR "), args...).
-		Preload("Requester").
+		Preload("Requester").Preload("CoRequesters").
 		Order("created_at asc").
 		First(&req).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -128,3 +148,63 @@ func (r *WishlistRequestRepository) ClearFulfilledBookID(bookID uint) error {
 		Where("fulfilled_book_id = ?", bookID).
 		Update("fulfilled_book_id", nil).Error
 }
+
+func (r *WishlistRequestRepository) Join(requestID, userID uint) error {
+	co := models.WishlistCoRequester{WishlistRequestID: requestID, UserID: userID}
+	if err := r.db.Omit("User").Create(&co).Error; err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *WishlistRequestRepository) Leave(requestID, userID uint) error {
+	result := r.db.Where("wishlist_request_id = ? AND user_id = ?", requestID, userID).Delete(&models.WishlistCoRequester{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WishlistRequestRepository) HandOver(requestID uint) (uint, error) {
+	var heirID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		heirID, err = handOverWishlistRequest(tx, requestID)
+		return err
+	})
+	return heirID, err
+}
+
+// handOverWishlistRequest is HandOver within tx, shared with account
+// deletion.
+func handOverWishlistRequest(tx *gorm.DB, requestID uint) (uint, error) {
+	var heir models.WishlistCoRequester
+	found, err := first(tx.Where("wishlist_request_id = ?", requestID).Order("created_at asc, id asc"), &heir)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, tx.Model(&models.WishlistRequest{}).Where("id = ?", requestID).Update("status", "cancelled").Error
+	}
+	if err := tx.Delete(&heir).Error; err != nil {
+		return 0, err
+	}
+	err = tx.Model(&models.WishlistRequest{}).Where("id = ?", requestID).
+		Updates(map[string]any{"requester_id": heir.UserID, "is_anonymous": true}).Error
+	return heir.UserID, err
+}
+
+func (r *WishlistRequestRepository) ListCoRequesters(requestID uint) ([]models.WishlistCoRequester, error) {
+	var co []models.WishlistCoRequester
+	err := r.db.Preload("User").
+		Where("wishlist_request_id = ?", requestID).
+		Order("created_at asc, id asc").
+		Find(&co).Error
+	return co, err
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 )
 
 func TestWishlistRequestRepository_ListOpenPaginated(t *testing.T) {
@@ -64,6 +65,92 @@ func TestWishlistRequestRepository_FindOpenByExternalKey(t *testing.T) {
 	})
 }
 
+func TestWishlistRequestRepository_ListOpen(t *testing.T) {
+	db := openTestDB(t)
+	requester := models.User{Name: "Requester", Email: "req-open@example.com"}
+	require.NoError(t, db.Create(&requester).Error)
+	requests := NewWishlistRequestRepository(db)
+
+	require.NoError(t, requests.Create(&models.WishlistRequest{RequesterID: requester.ID, Title: "First", Author: "A", Status: "open"}))
+	require.NoError(t, requests.Create(&models.WishlistRequest{RequesterID: requester.ID, Title: "Done", Author: "A", Status: "fulfilled"}))
+	require.NoError(t, requests.Create(&models.WishlistRequest{RequesterID: requester.ID, Title: "Second", Author: "A", Status: "open"}))
+
+	open, err := requests.ListOpen()
+	require.NoError(t, err)
+	require.Len(t, open, 2)
+	assert.Equal(t, "First", open[0].Title)
+	assert.Equal(t, "Second", open[1].Title)
+}
+
+func TestWishlistRequestRepository_CoRequesters(t *testing.T) {
+	db := openTestDB(t)
+	requester := models.User{Name: "Requester", Email: "req-co@example.com"}
+	alice := models.User{Name: "Alice", Email: "alice-co@example.com"}
+	bob := models.User{Name: "Bob", Email: "bob-co@example.com"}
+	require.NoError(t, db.Create(&requester).Error)
+	require.NoError(t, db.Create(&alice).Error)
+	require.NoError(t, db.Create(&bob).Error)
+	requests := NewWishlistRequestRepository(db)
+	req := &models.WishlistRequest{RequesterID: requester.ID, Title: "Wanted", Author: "A", OLKey: "OL1", Status: "open"}
+	require.NoError(t, requests.Create(req))
+
+	require.NoError(t, requests.Join(req.ID, alice.ID))
+	require.NoError(t, requests.Join(req.ID, bob.ID))
+	assert.ErrorIs(t, requests.Join(req.ID, alice.ID), repository.ErrConflict)
+
+	t.Run("ListCoRequesters returns join order with users", func(t *testing.T) {
+		co, err := requests.ListCoRequesters(req.ID)
+		require.NoError(t, err)
+		require.Len(t, co, 2)
+		assert.Equal(t, "Alice", co[0].User.Name)
+		assert.Equal(t, "Bob", co[1].User.Name)
+	})
+
+	t.Run("board reads preload co-requesters", func(t *testing.T) {
+		page, err := requests.ListOpenPaginated("", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Len(t, page.Items[0].CoRequesters, 2)
+	})
+
+	t.Run("Save does not resurrect a co-requester who left", func(t *testing.T) {
+		stale, err := requests.GetByID(req.ID)
+		require.NoError(t, err)
+		require.Len(t, stale.CoRequesters, 2)
+		require.NoError(t, requests.Leave(req.ID, bob.ID))
+		stale.Notes = "edited"
+		require.NoError(t, requests.Save(stale))
+
+		reloaded, err := requests.GetByID(req.ID)
+		require.NoError(t, err)
+		require.Len(t, reloaded.CoRequesters, 1)
+		assert.Equal(t, alice.ID, reloaded.CoRequesters[0].UserID)
+	})
+
+	t.Run("Leave without joining is not found", func(t *testing.T) {
+		assert.ErrorIs(t, requests.Leave(req.ID, bob.ID), repository.ErrNotFound)
+	})
+
+	t.Run("HandOver passes the request on, then cancels it once nobody's left", func(t *testing.T) {
+		heir, err := requests.HandOver(req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, alice.ID, heir)
+		reloaded, err := requests.GetByID(req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "open", reloaded.Status)
+		assert.Equal(t, alice.ID, reloaded.RequesterID)
+		assert.True(t, reloaded.IsAnonymous)
+		assert.Empty(t, reloaded.CoRequesters)
+
+		heir, err = requests.HandOver(req.ID)
+		require.NoError(t, err)
+		assert.Zero(t, heir)
+		reloaded, err = requests.GetByID(req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", reloaded.Status)
+	})
+}
+
 func TestWishlistRequestRepository_FindOpenMatch(t *testing.T) {
 	db := openTestDB(t)
 	requester := models.User{Name: "Requester", Email: "req3@example.com"}
@@ -16,6 +16,14 @@ var ErrNotFound = errors.New("record not found")
 // ErrConflict is returned when a unique constraint would be violated (e.g. duplicate waitlist entry).
 var ErrConflict = errors.New("conflict")
 
+// ErrInvalidCursor is returned when a pagination cursor's keys don't fit the
+// list it was passed to (e.g. a cursor from a differently sorted list).
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrActiveLoans is returned when an account can't be deleted because a
+// copy it borrowed or lent is still out on loan.
+var ErrActiveLoans = errors.New("account has active loans")
+
 // PaginatedResult holds a page of items plus total count metadata.
 type PaginatedResult[T any] struct {
 	Items      []T   `json:"items"`
@@ -23,6 +31,26 @@ type PaginatedResult[T any] struct {
 	Page       int   `json:"page"`
 	PageSize   int   `json:"page_size"`
 	TotalPages int   `json:"total_pages"`
+	// Next, where the list supports keyset pagination, continues after this
+	// page's last item; nil on the last page. It lets a client that started
+	// with page numbers switch to cursors.
+	Next *Cursor `json:"-"`
+}
+
+// Cursor is a keyset pagination position: the sort-key values and ID of the
+// last item already returned. The next page continues strictly after it, so
+// rows inserted meanwhile can't shift items between pages the way OFFSET
+// does, and no COUNT is needed. Handlers hand it to clients as an opaque
+// token (see handlers/cursor.go).
+type Cursor struct {
+	Keys []string
+	ID   uint
+}
+
+// CursorResult holds one keyset-paginated page. Next is nil on the last page.
+type CursorResult[T any] struct {
+	Items []T
+	Next  *Cursor
 }
 
 // UserRepository handles persistence for User records.
@@ -70,13 +98,42 @@ type BookRepository interface {
 	FindByISBN(isbn string) (*models.Book, error)
 	List(search, sort string, availableOnly bool) ([]models.Book, error)
 	ListPaginated(search, sort string, availableOnly bool, page, pageSize int) (*PaginatedResult[models.Book], error)
+	// ListAfter is ListPaginated's keyset form: up to limit books in the
+	// same order, continuing after the cursor (nil for the first page).
+	ListAfter(search, sort string, availableOnly bool, after *Cursor, limit int) (*CursorResult[models.Book], error)
 	ListRecent(limit int) ([]models.Book, error)
+	// ListMissingMetadata returns up to limit books missing any field the
+	// metadata-backfill job fills (ISBN, publisher, published date, page
+	// count, language or description) that it hasn't checked since
+	// checkedBefore — never-checked books first, then
+	// the longest since checked.
+	ListMissingMetadata(checkedBefore time.Time, limit int) ([]models.Book, error)
+	// ListCoverURLs returns every distinct cover_url starting with prefix —
+	// the cover-gc job's set of covers still in use.
+	ListCoverURLs(prefix string) ([]string, error)
+	// ListWithoutCover returns the ID, title and author of every book with
+	// an empty cover_url — the ones served a generated placeholder.
+	ListWithoutCover() ([]models.Book, error)
+	// ListWithCopies returns every book with at least one copy, in ID
+	// order, with its Copies (in ID order) preloaded — the whole catalog
+	// with its holdings, for exports.
+	ListWithCopies() ([]models.Book, error)
+	// GetByID returns the book alone, with no associations loaded.
+	GetByID(id uint) (*models.Book, error)
+	// GetByIDWithCopies returns the book with its Copies (and their owners)
+	// and its Contributors (and their authors, in credit order) preloaded.
 	GetByIDWithCopies(id uint) (*models.Book, error)
 	Create(book *models.Book) error
 	Save(book *models.Book) error
+	// UpdateFields writes only the named columns of book, so a concurrent
+	// write to its others (the cover-refresh job's cover_url, say) isn't
+	// overwritten with what book was loaded with.
+	UpdateFields(book *models.Book, columns ...string) error
 	// Delete hard-deletes book — there is no soft-delete on Book (no
 	// DeletedAt field). Used to clean up an orphaned keyless book once its
-	// last Copy is removed — see CopyHandler.maybeDeleteOrphanedBook.
+	// last Copy is removed — see CopyHandler.maybeDeleteOrphanedBook. Its
+	// contributor credits and reading-list entries go with it; the Authors
+	// themselves stay.
 	Delete(book *models.Book) error
 	CountAvailableCopies(bookID uint) (int64, error)
 	// CountAvailableCopiesBatch returns a map of bookID → available copy count
@@ -99,6 +156,9 @@ type CopyRepository interface {
 	// one copy of, without loading full Copy/Book rows — for callers that
 	// only need membership (e.g. a "yours" badge), not full records.
 	ListOwnedBookIDs(ownerID uint) ([]uint, error)
+	// ListAvailableByBookIDs returns the copies of any of bookIDs whose
+	// status is "available", ordered by ID.
+	ListAvailableByBookIDs(bookIDs []uint) ([]models.Copy, error)
 	CountByOwnerID(ownerID uint) (int64, error)
 	Save(bookCopy *models.Copy) error
 	Delete(bookCopy *models.Copy) error
@@ -122,6 +182,8 @@ type LoanRequestRepository interface {
 	// optionally filtered to the given statuses (empty/nil = no filter, i.e.
 	// every status).
 	ListByBorrowerIDPaginated(borrowerID uint, statuses []string, page, pageSize int) (*PaginatedResult[models.LoanRequest], error)
+	// ListByBorrowerIDAfter is ListByBorrowerIDPaginated's keyset form.
+	ListByBorrowerIDAfter(borrowerID uint, statuses []string, after *Cursor, limit int) (*CursorResult[models.LoanRequest], error)
 	// ListActiveByBorrowerID returns borrowerID's currently-held loans
 	// (status "accepted"), due-soonest first with no-due-date requests last.
 	ListActiveByBorrowerID(borrowerID uint) ([]models.LoanRequest, error)
@@ -139,6 +201,8 @@ type NotificationRepository interface {
 	Create(n *models.Notification) error
 	FindByRecipient(recipientID uint, unreadOnly bool) ([]models.Notification, error)
 	FindByRecipientPaginated(recipientID uint, unreadOnly bool, page, pageSize int) (*PaginatedResult[models.Notification], error)
+	// FindByRecipientAfter is FindByRecipientPaginated's keyset form.
+	FindByRecipientAfter(recipientID uint, unreadOnly bool, after *Cursor, limit int) (*CursorResult[models.Notification], error)
 	GetByID(id uint) (*models.Notification, error)
 	Save(n *models.Notification) error
 	MarkAllReadForRecipient(recipientID uint) error
@@ -213,6 +277,9 @@ type WaitlistRepository interface {
 }
 
 // WishlistRequestRepository handles persistence for WishlistRequest records.
+// GetByID, ListOpenPaginated, ListOpenAfter, ListByRequesterID and
+// FindOpenMatch preload CoRequesters (without their Users), for requester
+// counts; Save never writes them — only Join and Leave do.
 type WishlistRequestRepository interface {
 	Create(r *models.WishlistRequest) error
 	GetByID(id uint) (*models.WishlistRequest, error)
@@ -220,20 +287,283 @@ type WishlistRequestRepository interface {
 	// ListOpenPaginated returns open (status="open") requests, optionally
 	// filtered by a title/author search, newest first — powers the browse board.
 	ListOpenPaginated(search string, page, pageSize int) (*PaginatedResult[models.WishlistRequest], error)
+	// ListOpenAfter is ListOpenPaginated's keyset form.
+	ListOpenAfter(search string, after *Cursor, limit int) (*CursorResult[models.WishlistRequest], error)
 	ListByRequesterID(requesterID uint) ([]models.WishlistRequest, error)
 	// FindOpenByOLKey and FindOpenByGoogleBooksID power the auto-match hook in
 	// createBook — they return every open request sharing the key, since
 	// multiple members can separately be looking for the same book.
 	FindOpenByOLKey(olKey string) ([]models.WishlistRequest, error)
 	FindOpenByGoogleBooksID(googleBooksID string) ([]models.WishlistRequest, error)
+	// ListOpen returns every open request, oldest first — the candidate set
+	// for the auto-match hook's second tier (ISBN and title/author
+	// similarity), which is scored in Go rather than SQL.
+	ListOpen() ([]models.WishlistRequest, error)
 	// FindOpenMatch returns the earliest open request matching any of the
 	// given external keys (ISBN, OL key, Google Books ID), with its
 	// Requester preloaded — powers the create-time dedup check so a member
 	// can join an existing request instead of posting a duplicate. Returns
 	// (nil, nil) if no key is given or none match.
 	FindOpenMatch(isbn, olKey, googleBooksID string) (*models.WishlistRequest, error)
+	// Join adds userID as a co-requester of requestID ("me too"), returning
+	// ErrConflict if they already joined it.
+	Join(requestID, userID uint) error
+	// Leave removes userID as a co-requester of requestID, returning
+	// ErrNotFound if they hadn't joined it.
+	Leave(requestID, userID uint) error
+	// ListCoRequesters returns requestID's co-requesters, earliest first,
+	// with User preloaded — everyone notified alongside the requester when
+	// the request is fulfilled.
+	ListCoRequesters(requestID uint) ([]models.WishlistCoRequester, error)
+	// HandOver passes requestID to its earliest co-requester, who stops
+	// being one and becomes its (anonymous) requester, and returns their
+	// user ID — so the members who joined a request keep waiting for the
+	// book when its requester withdraws. With nobody to pass it to, the
+	// request is cancelled instead and 0 is returned.
+	HandOver(requestID uint) (uint, error)
 	// ClearFulfilledBookID nulls FulfilledBookID on every request pointing at
 	// bookID — called before hard-deleting a Book so no row is left pointing
 	// at a deleted one.
 	ClearFulfilledBookID(bookID uint) error
 }
+
+// UserBook pairs a member with a book they have some relationship to —
+// borrowed it, or own a copy of it, depending on which method returned it.
+type UserBook struct {
+	UserID uint
+	BookID uint
+}
+
+// RecommendationRepository handles persistence for the precomputed
+// "borrowed together" affinities and per-user recommendations.
+type RecommendationRepository interface {
+	// ListBorrowHistory returns the distinct (borrower, book) pairs for every
+	// loan request that reached "accepted" or "returned" — the same
+	// definition of "borrowed" the dashboard's most-borrowed ranking uses.
+	ListBorrowHistory() ([]UserBook, error)
+	// ListOwnership returns the distinct (owner, book) pairs across every Copy.
+	ListOwnership() ([]UserBook, error)
+	// ReplaceAll atomically swaps every stored affinity and recommendation
+	// for the given sets — the job always recomputes from scratch.
+	ReplaceAll(affinities []models.BookAffinity, recs []models.UserRecommendation) error
+	// ListRelated returns up to limit books most often borrowed alongside
+	// bookID, strongest affinity first. Books with no copies are skipped,
+	// matching the catalog listing.
+	ListRelated(bookID uint, limit int) ([]models.Book, error)
+	// ListForUser returns up to limit of userID's recommended books, best
+	// first. Books the user has since come to own or borrow are filtered out
+	// at read time too, so a stale recommendation never outlives the job's
+	// next run by being shown for something they already have.
+	ListForUser(userID uint, limit int) ([]models.Book, error)
+}
+
+// ReadingListRepository handles persistence for ReadingList records and
+// their entries.
+type ReadingListRepository interface {
+	Create(l *models.ReadingList) error
+	// GetByID returns the list with its Entries (and each entry's Book)
+	// preloaded, ordered by Position.
+	GetByID(id uint) (*models.ReadingList, error)
+	Save(l *models.ReadingList) error
+	// Delete removes the list and every entry on it.
+	Delete(id uint) error
+	// ListByOwnerID returns ownerID's lists, oldest first, without entries —
+	// only public ones when publicOnly is set.
+	ListByOwnerID(ownerID uint, publicOnly bool) ([]models.ReadingList, error)
+	// CountEntriesBatch returns a map of listID → entry count for all
+	// requested list IDs in a single query.
+	CountEntriesBatch(listIDs []uint) (map[uint]int64, error)
+	// AddEntry appends e to the end of its list (overwriting e.Position).
+	// Returns ErrConflict if the book is already on the list.
+	AddEntry(e *models.ReadingListEntry) error
+	FindEntry(listID, bookID uint) (*models.ReadingListEntry, error)
+	SaveEntry(e *models.ReadingListEntry) error
+	// RemoveEntry returns ErrNotFound if the book isn't on the list.
+	RemoveEntry(listID, bookID uint) error
+	// Reorder sets each entry's Position to its index in bookIDs, which the
+	// caller must have checked names exactly the list's current books.
+	Reorder(listID uint, bookIDs []uint) error
+}
+
+// AuthorCredit is one book an author is credited on, in one role. A person
+// who both wrote and translated a book has two credits for it.
+type AuthorCredit struct {
+	Book models.Book
+	Role string
+}
+
+// AuthorRepository handles persistence for Author records and the
+// book_contributors join that credits them on books.
+type AuthorRepository interface {
+	// FindOrCreate fills a with the stored author sharing its NameKey,
+	// creating it from a.Name if there's none yet.
+	FindOrCreate(a *models.Author) error
+	GetByID(id uint) (*models.Author, error)
+	// ReplaceBookContributors swaps bookID's contributors for contributors,
+	// in one transaction.
+	ReplaceBookContributors(bookID uint, contributors []models.BookContributor) error
+	// ListBooksWithoutContributors returns up to limit books that have a
+	// free-text Author but no contributors yet, lowest ID first.
+	ListBooksWithoutContributors(limit int) ([]models.Book, error)
+	// ListCredits returns authorID's credits on books that still have at
+	// least one copy, by title.
+	ListCredits(authorID uint) ([]AuthorCredit, error)
+}
+
+// MetadataCacheRepository handles persistence for MetadataCacheEntry
+// records, the persistent metadata search cache.
+type MetadataCacheRepository interface {
+	// Get returns the entry stored under key, expired or not.
+	Get(key string) (*models.MetadataCacheEntry, error)
+	// Put stores e, replacing any entry under the same key.
+	Put(e *models.MetadataCacheEntry) error
+	// DeleteExpired deletes every entry that expired before now, returning
+	// how many it deleted.
+	DeleteExpired(now time.Time) (int64, error)
+	// Trim deletes the entries soonest to expire until at most maxEntries
+	// remain, returning how many it deleted.
+	Trim(maxEntries int) (int64, error)
+	Count() (int64, error)
+	// DeleteAll empties the cache, returning how many entries it deleted.
+	DeleteAll() (int64, error)
+}
+
+// ImportJobRepository handles persistence for ImportJob records and the
+// ImportJobRows they record as they run.
+type ImportJobRepository interface {
+	Create(j *models.ImportJob) error
+	GetByID(id uint) (*models.ImportJob, error)
+	// GetStatus returns job id's status alone, without reading its stored
+	// upload. Returns ErrNotFound if there is no such job.
+	GetStatus(id uint) (string, error)
+	// HasUnfinished reports whether ownerID has a queued or running job.
+	HasUnfinished(ownerID uint) (bool, error)
+	// ListByOwnerID returns ownerID's most recent jobs, newest first, at
+	// most limit of them.
+	ListByOwnerID(ownerID uint, limit int) ([]models.ImportJob, error)
+	// ListUnfinished returns every queued or running job, oldest first.
+	ListUnfinished() ([]models.ImportJob, error)
+	// SetStatus moves job id to status if its current status is one of
+	// from, reporting whether it did. Moving it to a finished status
+	// (anything but queued or running) also sets FinishedAt and drops the
+	// stored Content and Decisions, which are only needed to resume it.
+	SetStatus(id uint, from []string, status, errMsg string) (bool, error)
+	// RecordRow stores one row's outcome, creates bookCopy if it isn't nil,
+	// and advances its job's ProcessedRows, in one transaction — so a row's
+	// copy exists exactly when its outcome is recorded, and a job resumed
+	// after a crash never creates it twice.
+	RecordRow(row *models.ImportJobRow, bookCopy *models.Copy) error
+	// ListRows returns jobID's recorded rows in row order.
+	ListRows(jobID uint) ([]models.ImportJobRow, error)
+}
+
+// LibrarySnapshot is the whole library at one moment: every member, book,
+// copy, loan request and wishlist request, each wishlist request with its
+// CoRequesters. Records refer to each other by ID, as stored.
+type LibrarySnapshot struct {
+	Users            []models.User
+	Books            []models.Book
+	Copies           []models.Copy
+	LoanRequests     []models.LoanRequest
+	WishlistRequests []models.WishlistRequest
+}
+
+// RestoreCount is how many records of one kind a restore created, how many
+// it matched to ones already there and updated, how many it matched and
+// found already up to date, and how many it skipped because they referred
+// to records missing from the export.
+type RestoreCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Matched int `json:"matched"`
+	Skipped int `json:"skipped"`
+}
+
+// LibraryRestoreStats reports what LibraryRepository.Restore did.
+type LibraryRestoreStats struct {
+	Users            RestoreCount `json:"users"`
+	Books            RestoreCount `json:"books"`
+	Copies           RestoreCount `json:"copies"`
+	LoanRequests     RestoreCount `json:"loan_requests"`
+	WishlistRequests RestoreCount `json:"wishlist_requests"`
+	// WishlistCoRequesters counts the members joined to wishlist requests.
+	WishlistCoRequesters RestoreCount `json:"wishlist_co_requesters"`
+}
+
+// LibraryRepository reads and writes the whole library at once, for moving
+// a community between instances.
+type LibraryRepository interface {
+	// Snapshot reads every record in one read transaction, each kind in ID
+	// order.
+	Snapshot() (*LibrarySnapshot, error)
+	// Restore upserts s in one transaction: either all of it is written or
+	// none. IDs in s are only references between its own records; each
+	// record is matched to an existing one or created with a new ID, and
+	// the references remapped. Users match by email; books by OLKey,
+	// GoogleBooksID or ISBN (the findExistingBook precedence), or a keyless
+	// book by exact title and author; a copy matches the same owner's
+	// existing copies of its book in ID order; loan requests match by copy,
+	// borrower and RequestedAt; wishlist requests by requester, title,
+	// author and CreatedAt, and their co-requesters by member. A matched
+	// record is updated from s — a member's profile (never their password
+	// or role: a restore mustn't lock anyone out or change who's an
+	// admin; those are only set on a member it creates), a book's details, a copy's status and settings, how far a loan or wishlist
+	// request got — so restoring a newer snapshot brings it up to date,
+	// and restoring the same snapshot twice changes nothing the second
+	// time.
+	Restore(s *LibrarySnapshot) (LibraryRestoreStats, error)
+}
+
+// AccountData is everything stored about one member, for handing back to
+// them. Related records a member sees in the app are preloaded: each copy's
+// Book; each loan's Copy.Book, plus Copy.Owner on loans they borrowed and
+// Borrower on loans of their copies; each reading list's Entries.Book; and
+// each of their own wishlist requests' FulfilledBook.
+type AccountData struct {
+	User            models.User
+	Copies          []models.Copy
+	LoansAsBorrower []models.LoanRequest
+	LoansAsOwner    []models.LoanRequest
+	Waitlist        []models.WaitlistEntry
+	// WaitlistCopies are the copies Waitlist entries wait for, with Book.
+	WaitlistCopies []models.Copy
+	Wishlist       []models.WishlistRequest
+	// WishlistJoined are the requests the user joined as a co-requester,
+	// each with only the user's own CoRequesters row.
+	WishlistJoined []models.WishlistRequest
+	Notifications  []models.Notification
+	ReadingLists   []models.ReadingList
+	ImportJobs     []models.ImportJob
+}
+
+// AccountRepository reads and removes a member's own data across every
+// table.
+type AccountRepository interface {
+	// Export gathers userID's data in one read transaction, each kind in ID
+	// order. Returns ErrNotFound if there is no such user.
+	Export(userID uint) (*AccountData, error)
+	// CountActiveLoans counts accepted loans userID is the borrower or the
+	// copy owner on.
+	CountActiveLoans(userID uint) (int64, error)
+	// ListDueDeletions returns the not yet anonymized users whose
+	// DeletionScheduledAt is at or before now, in ID order.
+	ListDueDeletions(now time.Time) ([]models.User, error)
+	// Delete carries out userID's account deletion in one transaction.
+	// Loan rows are kept for the other party's history; instead the user
+	// row itself is anonymized — name "Deleted member", a placeholder
+	// email, no password or contact details — so those rows no longer say
+	// who it was. The user's pending loan requests are cancelled and
+	// pending requests for their copies declined. Their copies go to
+	// DeletionTransferToID when that is still an active member, who gets one
+	// notification for them all, and are deleted otherwise, along with any
+	// keyless book left without a copy, whose cover URLs are returned so
+	// the caller can remove the cached files; the loans on deleted copies
+	// stay, as their borrowers' history.
+	// Each open wishlist request passes, as anonymous, to its earliest
+	// co-requester, or is cancelled if nobody joined it. Waitlist entries,
+	// wishlist joins, notifications, reading lists, imports and
+	// recommendations go. Returns ErrActiveLoans, changing nothing, while
+	// CountActiveLoans is non-zero, and ErrNotFound if there is no such
+	// user.
+	Delete(userID uint, now time.Time) ([]string, error)
+}
@@ -7,11 +7,15 @@
 package repotest
 
 import (
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"gorm.io/gorm/schema"
+
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 )
@@ -215,13 +219,15 @@ func (r *AdminRepository) SetDashboardStats(s *repository.DashboardStats) {
 	r.stats = s
 }
 
-// ListUsers returns all stored users ordered by ID.
+// ListUsers returns all stored users but anonymized ones, ordered by ID.
 func (r *AdminRepository) ListUsers() ([]models.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	out := make([]models.User, 0, len(r.users))
 	for _, u := range r.users {
-		out = append(out, *u)
+		if u.AnonymizedAt == nil {
+			out = append(out, *u)
+		}
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
 	return out, nil
@@ -419,6 +425,25 @@ func (r *CopyRepository) ListOwnedBookIDs(ownerID uint) ([]uint, error) {
 	return out, nil
 }
 
+// ListAvailableByBookIDs returns the available copies of any of bookIDs,
+// ordered by ID.
+func (r *CopyRepository) ListAvailableByBookIDs(bookIDs []uint) ([]models.Copy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wanted := map[uint]bool{}
+	for _, id := range bookIDs {
+		wanted[id] = true
+	}
+	out := []models.Copy{}
+	for _, c := range r.byID {
+		if wanted[c.BookID] && c.Status == "available" {
+			out = append(out, *c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
 // CountByOwnerID counts copies owned by ownerID.
 func (r *CopyRepository) CountByOwnerID(ownerID uint) (int64, error) {
 	items, _ := r.ListByOwnerID(ownerID)
@@ -505,10 +530,19 @@ func (r *NotificationRepository) FindByRecipientPaginated(recipientID uint, unre
 	items := append([]models.Notification{}, all[start:end]...)
 	return &repository.PaginatedResult[models.Notification]{
 		Items: items, Total: int64(len(all)), Page: page, PageSize: pageSize,
-		TotalPages: totalPages(len(all), pageSize),
+		TotalPages: totalPages(len(all), pageSize), Next: pageCursor(all, end, notificationID),
 	}, nil
 }
 
+// FindByRecipientAfter returns up to limit of FindByRecipient's results
+// following the cursor.
+func (r *NotificationRepository) FindByRecipientAfter(recipientID uint, unreadOnly bool, after *repository.Cursor, limit int) (*repository.CursorResult[models.Notification], error) {
+	all, _ := r.FindByRecipient(recipientID, unreadOnly)
+	return cursorPage(all, after, limit, notificationID), nil
+}
+
+func notificationID(n models.Notification) uint { return n.ID }
+
 // GetByID returns the notification with the given ID, or repository.ErrNotFound.
 func (r *NotificationRepository) GetByID(id uint) (*models.Notification, error) {
 	r.mu.Lock()
@@ -860,31 +894,46 @@ func (r *LoanRequestRepository) ListByBorrowerID(borrowerID uint) ([]models.Loan
 	return out, nil
 }
 
-// ListByBorrowerIDPaginated returns a page of loan requests made by
-// borrowerID, optionally filtered to the given statuses.
-func (r *LoanRequestRepository) ListByBorrowerIDPaginated(borrowerID uint, statuses []string, page, pageSize int) (*repository.PaginatedResult[models.LoanRequest], error) {
+// listByBorrowerIDWithStatuses returns ListByBorrowerID's results,
+// optionally filtered to the given statuses.
+func (r *LoanRequestRepository) listByBorrowerIDWithStatuses(borrowerID uint, statuses []string) []models.LoanRequest {
 	all, _ := r.ListByBorrowerID(borrowerID)
-	if len(statuses) > 0 {
-		set := map[string]bool{}
-		for _, s := range statuses {
-			set[s] = true
-		}
-		filtered := make([]models.LoanRequest, 0, len(all))
-		for _, lr := range all {
-			if set[lr.Status] {
-				filtered = append(filtered, lr)
-			}
+	if len(statuses) == 0 {
+		return all
+	}
+	set := map[string]bool{}
+	for _, s := range statuses {
+		set[s] = true
+	}
+	filtered := make([]models.LoanRequest, 0, len(all))
+	for _, lr := range all {
+		if set[lr.Status] {
+			filtered = append(filtered, lr)
 		}
-		all = filtered
 	}
+	return filtered
+}
+
+// ListByBorrowerIDPaginated returns a page of loan requests made by
+// borrowerID, optionally filtered to the given statuses.
+func (r *LoanRequestRepository) ListByBorrowerIDPaginated(borrowerID uint, statuses []string, page, pageSize int) (*repository.PaginatedResult[models.LoanRequest], error) {
+	all := r.listByBorrowerIDWithStatuses(borrowerID, statuses)
 	start, end := paginationBounds(len(all), page, pageSize)
 	items := append([]models.LoanRequest{}, all[start:end]...)
 	return &repository.PaginatedResult[models.LoanRequest]{
 		Items: items, Total: int64(len(all)), Page: page, PageSize: pageSize,
-		TotalPages: totalPages(len(all), pageSize),
+		TotalPages: totalPages(len(all), pageSize), Next: pageCursor(all, end, loanRequestID),
 	}, nil
 }
 
+// ListByBorrowerIDAfter returns up to limit of borrowerID's loan requests
+// following the cursor, optionally filtered to the given statuses.
+func (r *LoanRequestRepository) ListByBorrowerIDAfter(borrowerID uint, statuses []string, after *repository.Cursor, limit int) (*repository.CursorResult[models.LoanRequest], error) {
+	return cursorPage(r.listByBorrowerIDWithStatuses(borrowerID, statuses), after, limit, loanRequestID), nil
+}
+
+func loanRequestID(lr models.LoanRequest) uint { return lr.ID }
+
 // ListActiveByBorrowerID returns borrowerID's accepted loan requests,
 // mimicking the GORM implementation's due-date-ascending / NULLs-last order.
 func (r *LoanRequestRepository) ListActiveByBorrowerID(borrowerID uint) ([]models.LoanRequest, error) {
@@ -982,9 +1031,9 @@ func (r *LoanRequestRepository) CountActiveLoansByBorrower(borrowerID uint) (int
 
 // BookRepository is an in-memory fake of repository.BookRepository. It only
 // implements the querying needed by wishlist's fulfill handler (GetByID
-// lookup, Create for test fixtures) — List/ListPaginated/ListRecent and the
-// available-copies counters return empty/zero, since nothing that consumes
-// this fake exercises them yet.
+// lookup, Create for test fixtures) plus simple in-memory listing. The
+// listing methods and available-copies counters answer from copies once
+// SetCopies is called, and see no books before that.
 type BookRepository struct {
 	mu     sync.Mutex
 	nextID uint
@@ -1029,6 +1078,26 @@ func (r *BookRepository) Save(book *models.Book) error {
 	return nil
 }
 
+// UpdateFields copies the named columns from book onto the stored book,
+// matching them to fields by GORM's default column naming, or returns
+// repository.ErrNotFound.
+func (r *BookRepository) UpdateFields(book *models.Book, columns ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.byID[book.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	src, dst := reflect.ValueOf(book).Elem(), reflect.ValueOf(stored).Elem()
+	var naming schema.NamingStrategy
+	for i := range src.NumField() {
+		if slices.Contains(columns, naming.ColumnName("", src.Type().Field(i).Name)) {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return nil
+}
+
 // FindByOLKey returns the book with the given OLKey, or repository.ErrNotFound.
 func (r *BookRepository) FindByOLKey(olKey string) (*models.Book, error) {
 	r.mu.Lock()
@@ -1069,6 +1138,11 @@ func (r *BookRepository) FindByISBN(isbn string) (*models.Book, error) {
 	return nil, repository.ErrNotFound
 }
 
+// GetByID returns the book with the given ID, or repository.ErrNotFound.
+func (r *BookRepository) GetByID(id uint) (*models.Book, error) {
+	return r.GetByIDWithCopies(id)
+}
+
 // GetByIDWithCopies returns the book with the given ID, or repository.ErrNotFound.
 // The fake stores no Copies association — callers needing one must populate
 // it on the models.Book passed to Create.
@@ -1083,12 +1157,11 @@ func (r *BookRepository) GetByIDWithCopies(id uint) (*models.Book, error) {
 	return &cp, nil
 }
 
-// List returns nil — not exercised by any test using this fake yet.
 // List returns books with at least one copy — mirrors the real
 // implementation's EXISTS-copies filter (availableOnly narrows that to an
 // available copy) — optionally substring-filtered by search against
-// title/author, case-insensitive. sort is ignored: no caller relies on
-// ordering from this fake yet.
+// title/author, case-insensitive. sort is ignored: results are always in ID
+// order.
 func (r *BookRepository) List(search, _ string, availableOnly bool) ([]models.Book, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -1104,6 +1177,7 @@ func (r *BookRepository) List(search, _ string, availableOnly bool) ([]models.Bo
 		}
 		out = append(out, *b)
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
 	return out, nil
 }
 
@@ -1136,20 +1210,168 @@ func (r *BookRepository) Count() int {
 	return len(r.byID)
 }
 
-// ListPaginated returns an empty page — not exercised by any test using this fake yet.
-func (r *BookRepository) ListPaginated(_, _ string, _ bool, page, pageSize int) (*repository.PaginatedResult[models.Book], error) {
-	return &repository.PaginatedResult[models.Book]{Page: page, PageSize: pageSize}, nil
+// ListPaginated pages through List's results in ID order. sort is ignored,
+// as in List.
+func (r *BookRepository) ListPaginated(search, sortBy string, availableOnly bool, page, pageSize int) (*repository.PaginatedResult[models.Book], error) {
+	all, err := r.List(search, sortBy, availableOnly)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	start, end := paginationBounds(len(all), page, pageSize)
+	return &repository.PaginatedResult[models.Book]{
+		Items:      all[start:end],
+		Total:      int64(len(all)),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages(len(all), pageSize),
+		Next:       pageCursor(all, end, bookID),
+	}, nil
+}
+
+// ListAfter pages through List's results in ID order following the cursor.
+// sort is ignored, as in List.
+func (r *BookRepository) ListAfter(search, sortBy string, availableOnly bool, after *repository.Cursor, limit int) (*repository.CursorResult[models.Book], error) {
+	all, err := r.List(search, sortBy, availableOnly)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return cursorPage(all, after, limit, bookID), nil
+}
+
+func bookID(b models.Book) uint { return b.ID }
+
+// ListRecent returns up to limit books with at least one copy, newest
+// (highest ID) first.
+func (r *BookRepository) ListRecent(limit int) ([]models.Book, error) {
+	all, err := r.List("", "", false)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID > all[j].ID })
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+// ListMissingMetadata returns up to limit books missing an ISBN,
+// publisher, published date, page count, language or description and not
+// checked since checkedBefore —
+// never-checked first, then longest since checked, then by ID.
+func (r *BookRepository) ListMissingMetadata(checkedBefore time.Time, limit int) ([]models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []models.Book
+	for _, b := range r.byID {
+		incomplete := b.ISBN == "" || b.Publisher == "" || b.PublishedDate == "" || b.PageCount == 0 ||
+			b.Language == "" || b.Description == ""
+		due := b.MetadataCheckedAt == nil || b.MetadataCheckedAt.Before(checkedBefore)
+		if incomplete && due {
+			out = append(out, *b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ci, cj := out[i].MetadataCheckedAt, out[j].MetadataCheckedAt
+		switch {
+		case ci == nil && cj != nil:
+			return true
+		case ci != nil && cj == nil:
+			return false
+		case ci != nil && !ci.Equal(*cj):
+			return ci.Before(*cj)
+		}
+		return out[i].ID < out[j].ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *BookRepository) ListCoverURLs(prefix string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := map[string]bool{}
+	var out []string
+	for _, b := range r.byID {
+		if strings.HasPrefix(b.CoverURL, prefix) && !seen[b.CoverURL] {
+			seen[b.CoverURL] = true
+			out = append(out, b.CoverURL)
+		}
+	}
+	return out, nil
+}
+
+func (r *BookRepository) ListWithoutCover() ([]models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []models.Book
+	for _, b := range r.byID {
+		if b.CoverURL == "" {
+			out = append(out, models.Book{ID: b.ID, Title: b.Title, Author: b.Author})
+		}
+	}
+	return out, nil
 }
 
-// ListRecent returns nil — not exercised by any test using this fake yet.
-func (r *BookRepository) ListRecent(_ int) ([]models.Book, error) { return nil, nil }
+// ListWithCopies returns every book with at least one copy in copies, in ID
+// order, with those copies attached (none before SetCopies is called).
+func (r *BookRepository) ListWithCopies() ([]models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.copies == nil {
+		return nil, nil
+	}
+	r.copies.mu.Lock()
+	defer r.copies.mu.Unlock()
+	byBook := map[uint][]models.Copy{}
+	for _, c := range r.copies.byID {
+		byBook[c.BookID] = append(byBook[c.BookID], *c)
+	}
+	var out []models.Book
+	for _, b := range r.byID {
+		copies := byBook[b.ID]
+		if len(copies) == 0 {
+			continue
+		}
+		sort.Slice(copies, func(i, j int) bool { return copies[i].ID < copies[j].ID })
+		book := *b
+		book.Copies = copies
+		out = append(out, book)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
 
-// CountAvailableCopies returns 0 — not exercised by any test using this fake yet.
-func (r *BookRepository) CountAvailableCopies(_ uint) (int64, error) { return 0, nil }
+// CountAvailableCopies returns the number of copies of bookID with status
+// "available", delegating to copies (0 if SetCopies was never called).
+func (r *BookRepository) CountAvailableCopies(bookID uint) (int64, error) {
+	counts, err := r.CountAvailableCopiesBatch([]uint{bookID})
+	return counts[bookID], err
+}
 
-// CountAvailableCopiesBatch returns an empty map — not exercised by any test using this fake yet.
-func (r *BookRepository) CountAvailableCopiesBatch(_ []uint) (map[uint]int64, error) {
-	return map[uint]int64{}, nil
+// CountAvailableCopiesBatch returns bookID → available copy count for each
+// requested book that has at least one, delegating to copies (an empty map
+// if SetCopies was never called).
+func (r *BookRepository) CountAvailableCopiesBatch(bookIDs []uint) (map[uint]int64, error) {
+	counts := map[uint]int64{}
+	if r.copies == nil {
+		return counts, nil
+	}
+	wanted := make(map[uint]bool, len(bookIDs))
+	for _, id := range bookIDs {
+		wanted[id] = true
+	}
+	r.copies.mu.Lock()
+	defer r.copies.mu.Unlock()
+	for _, c := range r.copies.byID {
+		if wanted[c.BookID] && c.Status == "available" {
+			counts[c.BookID]++
+		}
+	}
+	return counts, nil
 }
 
 // Delete removes book from the store.
@@ -1178,16 +1400,30 @@ func (r *BookRepository) CountCopies(bookID uint) (int64, error) {
 }
 
 // WishlistRequestRepository is an in-memory fake of
-// repository.WishlistRequestRepository.
+// repository.WishlistRequestRepository. Co-requesters are kept apart from
+// the stored requests and attached to every copy handed out, as the real
+// implementation's preload does, but without their Users.
 type WishlistRequestRepository struct {
-	mu     sync.Mutex
-	nextID uint
-	byID   map[uint]*models.WishlistRequest
+	mu           sync.Mutex
+	nextID       uint
+	nextCoID     uint
+	byID         map[uint]*models.WishlistRequest
+	coRequesters map[uint][]models.WishlistCoRequester
 }
 
 // NewWishlistRequestRepository creates an empty fake WishlistRequestRepository.
 func NewWishlistRequestRepository() *WishlistRequestRepository {
-	return &WishlistRequestRepository{byID: map[uint]*models.WishlistRequest{}}
+	return &WishlistRequestRepository{
+		byID:         map[uint]*models.WishlistRequest{},
+		coRequesters: map[uint][]models.WishlistCoRequester{},
+	}
+}
+
+// withCoRequesters returns req with its co-requesters attached. Callers
+// must hold r.mu.
+func (r *WishlistRequestRepository) withCoRequesters(req models.WishlistRequest) models.WishlistRequest {
+	req.CoRequesters = slices.Clone(r.coRequesters[req.ID])
+	return req
 }
 
 // Create inserts req, assigning it a new ID.
@@ -1209,7 +1445,7 @@ func (r *WishlistRequestRepository) GetByID(id uint) (*models.WishlistRequest, e
 	if !ok {
 		return nil, repository.ErrNotFound
 	}
-	cp := *req
+	cp := r.withCoRequesters(*req)
 	return &cp, nil
 }
 
@@ -1227,9 +1463,9 @@ func (r *WishlistRequestRepository) Save(req *models.WishlistRequest) error {
 	return nil
 }
 
-// ListOpenPaginated returns a page of open requests, optionally filtered by
-// a case-sensitive title/author substring search, newest first.
-func (r *WishlistRequestRepository) ListOpenPaginated(search string, page, pageSize int) (*repository.PaginatedResult[models.WishlistRequest], error) {
+// listOpen returns open requests, optionally filtered by a case-sensitive
+// title/author substring search, newest first.
+func (r *WishlistRequestRepository) listOpen(search string) []models.WishlistRequest {
 	r.mu.Lock()
 	all := make([]models.WishlistRequest, 0, len(r.byID))
 	for _, req := range r.byID {
@@ -1239,18 +1475,31 @@ func (r *WishlistRequestRepository) ListOpenPaginated(search string, page, pageS
 		if search != "" && !strings.Contains(req.Title, search) && !strings.Contains(req.Author, search) {
 			continue
 		}
-		all = append(all, *req)
+		all = append(all, r.withCoRequesters(*req))
 	}
 	r.mu.Unlock()
 	sort.Slice(all, func(i, j int) bool { return all[i].CreatedAt.After(all[j].CreatedAt) })
+	return all
+}
+
+// ListOpenPaginated returns a page of listOpen's results.
+func (r *WishlistRequestRepository) ListOpenPaginated(search string, page, pageSize int) (*repository.PaginatedResult[models.WishlistRequest], error) {
+	all := r.listOpen(search)
 	start, end := paginationBounds(len(all), page, pageSize)
 	items := append([]models.WishlistRequest{}, all[start:end]...)
 	return &repository.PaginatedResult[models.WishlistRequest]{
 		Items: items, Total: int64(len(all)), Page: page, PageSize: pageSize,
-		TotalPages: totalPages(len(all), pageSize),
+		TotalPages: totalPages(len(all), pageSize), Next: pageCursor(all, end, wishlistRequestID),
 	}, nil
 }
 
+// ListOpenAfter returns up to limit of listOpen's results following the cursor.
+func (r *WishlistRequestRepository) ListOpenAfter(search string, after *repository.Cursor, limit int) (*repository.CursorResult[models.WishlistRequest], error) {
+	return cursorPage(r.listOpen(search), after, limit, wishlistRequestID), nil
+}
+
+func wishlistRequestID(req models.WishlistRequest) uint { return req.ID }
+
 // ListByRequesterID returns every request made by requesterID, newest first.
 func (r *WishlistRequestRepository) ListByRequesterID(requesterID uint) ([]models.WishlistRequest, error) {
 	r.mu.Lock()
@@ -1258,7 +1507,7 @@ func (r *WishlistRequestRepository) ListByRequesterID(requesterID uint) ([]model
 	out := []models.WishlistRequest{}
 	for _, req := range r.byID {
 		if req.RequesterID == requesterID {
-			out = append(out, *req)
+			out = append(out, r.withCoRequesters(*req))
 		}
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
@@ -1297,6 +1546,13 @@ func (r *WishlistRequestRepository) FindOpenByGoogleBooksID(googleBooksID string
 	return out, nil
 }
 
+// ListOpen returns every open request, oldest first.
+func (r *WishlistRequestRepository) ListOpen() ([]models.WishlistRequest, error) {
+	out := r.listOpen("")
+	slices.Reverse(out)
+	return out, nil
+}
+
 // wishlistMatchesAnyKey reports whether req shares any of the given
 // non-empty external keys.
 func wishlistMatchesAnyKey(req *models.WishlistRequest, isbn, olKey, googleBooksID string) bool {
@@ -1325,10 +1581,70 @@ func (r *WishlistRequestRepository) FindOpenMatch(isbn, olKey, googleBooksID str
 	if match == nil {
 		return nil, nil
 	}
-	cp := *match
+	cp := r.withCoRequesters(*match)
 	return &cp, nil
 }
 
+// Join adds userID as a co-requester of requestID, or returns
+// repository.ErrConflict if they already joined it.
+func (r *WishlistRequestRepository) Join(requestID, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, co := range r.coRequesters[requestID] {
+		if co.UserID == userID {
+			return repository.ErrConflict
+		}
+	}
+	r.nextCoID++
+	r.coRequesters[requestID] = append(r.coRequesters[requestID], models.WishlistCoRequester{
+		ID: r.nextCoID, WishlistRequestID: requestID, UserID: userID, CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+// Leave removes userID as a co-requester of requestID, or returns
+// repository.ErrNotFound if they hadn't joined it.
+func (r *WishlistRequestRepository) Leave(requestID, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	co := r.coRequesters[requestID]
+	i := slices.IndexFunc(co, func(c models.WishlistCoRequester) bool { return c.UserID == userID })
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+	r.coRequesters[requestID] = slices.Delete(co, i, i+1)
+	return nil
+}
+
+// ListCoRequesters returns requestID's co-requesters in join order. Unlike
+// the real implementation, their Users are not populated.
+func (r *WishlistRequestRepository) ListCoRequesters(requestID uint) ([]models.WishlistCoRequester, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.coRequesters[requestID]), nil
+}
+
+// HandOver makes requestID's earliest co-requester its anonymous requester,
+// or cancels it if nobody joined it.
+func (r *WishlistRequestRepository) HandOver(requestID uint) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.byID[requestID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	co := r.coRequesters[requestID]
+	if len(co) == 0 {
+		req.Status = "cancelled"
+		return 0, nil
+	}
+	heir := co[0]
+	r.coRequesters[requestID] = slices.Delete(co, 0, 1)
+	req.RequesterID = heir.UserID
+	req.IsAnonymous = true
+	return heir.UserID, nil
+}
+
 // ClearFulfilledBookID nulls FulfilledBookID on every stored request pointing at bookID.
 func (r *WishlistRequestRepository) ClearFulfilledBookID(bookID uint) error {
 	r.mu.Lock()
@@ -1341,26 +1657,865 @@ func (r *WishlistRequestRepository) ClearFulfilledBookID(bookID uint) error {
 	return nil
 }
 
-// paginationBounds returns the [start, end) slice bounds for page/pageSize
-// over a collection of the given length.
-func paginationBounds(length, page, pageSize int) (start, end int) {
-	start = (page - 1) * pageSize
-	if start > length {
-		start = length
+// RecommendationRepository is an in-memory fake of
+// repository.RecommendationRepository. Borrow history and ownership are
+// seeded directly via SetBorrowHistory/SetOwnership; ListRelated/ListForUser
+// resolve stored rows against books and skip any ID it doesn't hold, but
+// don't apply the real implementation's has-copies or read-time
+// owned/borrowed filters.
+type RecommendationRepository struct {
+	mu         sync.Mutex
+	books      *BookRepository
+	history    []repository.UserBook
+	ownership  []repository.UserBook
+	affinities []models.BookAffinity
+	recs       []models.UserRecommendation
+}
+
+// NewRecommendationRepository creates an empty fake RecommendationRepository
+// resolving book IDs against books.
+func NewRecommendationRepository(books *BookRepository) *RecommendationRepository {
+	return &RecommendationRepository{books: books}
+}
+
+// SetBorrowHistory seeds what ListBorrowHistory returns — a test helper, not
+// part of the repository.RecommendationRepository interface.
+func (r *RecommendationRepository) SetBorrowHistory(pairs []repository.UserBook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = pairs
+}
+
+// SetOwnership seeds what ListOwnership returns — a test helper, not part of
+// the repository.RecommendationRepository interface.
+func (r *RecommendationRepository) SetOwnership(pairs []repository.UserBook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ownership = pairs
+}
+
+// ListBorrowHistory returns the pairs seeded via SetBorrowHistory.
+func (r *RecommendationRepository) ListBorrowHistory() ([]repository.UserBook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]repository.UserBook(nil), r.history...), nil
+}
+
+// ListOwnership returns the pairs seeded via SetOwnership.
+func (r *RecommendationRepository) ListOwnership() ([]repository.UserBook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]repository.UserBook(nil), r.ownership...), nil
+}
+
+// ReplaceAll overwrites every stored affinity and recommendation.
+func (r *RecommendationRepository) ReplaceAll(affinities []models.BookAffinity, recs []models.UserRecommendation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.affinities = append([]models.BookAffinity(nil), affinities...)
+	r.recs = append([]models.UserRecommendation(nil), recs...)
+	return nil
+}
+
+// Affinities returns every stored affinity row — a test helper, not part of
+// the repository.RecommendationRepository interface.
+func (r *RecommendationRepository) Affinities() []models.BookAffinity {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.BookAffinity(nil), r.affinities...)
+}
+
+// Recommendations returns every stored recommendation row — a test helper,
+// not part of the repository.RecommendationRepository interface.
+func (r *RecommendationRepository) Recommendations() []models.UserRecommendation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.UserRecommendation(nil), r.recs...)
+}
+
+// ListRelated returns up to limit books related to bookID, highest score first.
+func (r *RecommendationRepository) ListRelated(bookID uint, limit int) ([]models.Book, error) {
+	r.mu.Lock()
+	var scored []models.UserRecommendation
+	for _, a := range r.affinities {
+		if a.BookID == bookID {
+			scored = append(scored, models.UserRecommendation{BookID: a.RelatedBookID, Score: a.Score})
+		}
 	}
-	if start < 0 {
-		start = 0
+	r.mu.Unlock()
+	return r.resolveScored(scored, limit), nil
+}
+
+// ListForUser returns up to limit books recommended for userID, highest score first.
+func (r *RecommendationRepository) ListForUser(userID uint, limit int) ([]models.Book, error) {
+	r.mu.Lock()
+	var scored []models.UserRecommendation
+	for _, rec := range r.recs {
+		if rec.UserID == userID {
+			scored = append(scored, rec)
+		}
 	}
-	end = start + pageSize
-	if end > length {
-		end = length
+	r.mu.Unlock()
+	return r.resolveScored(scored, limit), nil
+}
+
+// resolveScored sorts scored by Score descending (BookID ascending on ties)
+// and resolves each BookID against r.books, keeping at most limit.
+func (r *RecommendationRepository) resolveScored(scored []models.UserRecommendation, limit int) []models.Book {
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].BookID < scored[j].BookID
+	})
+	out := []models.Book{}
+	for _, s := range scored {
+		if len(out) >= limit {
+			break
+		}
+		if b, err := r.books.GetByIDWithCopies(s.BookID); err == nil {
+			out = append(out, *b)
+		}
 	}
-	return start, end
+	return out
 }
 
-// totalPages returns the number of pages of pageSize needed to cover length items.
-func totalPages(length, pageSize int) int {
-	return (length + pageSize - 1) / pageSize
+// ReadingListRepository is an in-memory fake of
+// repository.ReadingListRepository. Entry Books are resolved against books
+// on read, mirroring the real implementation's preload.
+type ReadingListRepository struct {
+	mu          sync.Mutex
+	nextID      uint
+	nextEntryID uint
+	byID        map[uint]*models.ReadingList
+	entries     map[uint]*models.ReadingListEntry
+	books       *BookRepository
+}
+
+// NewReadingListRepository creates an empty fake ReadingListRepository
+// resolving entry books against books.
+func NewReadingListRepository(books *BookRepository) *ReadingListRepository {
+	return &ReadingListRepository{
+		byID:    map[uint]*models.ReadingList{},
+		entries: map[uint]*models.ReadingListEntry{},
+		books:   books,
+	}
+}
+
+// Create inserts l, assigning it a new ID and timestamps.
+func (r *ReadingListRepository) Create(l *models.ReadingList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	l.ID = r.nextID
+	now := time.Now()
+	l.CreatedAt, l.UpdatedAt = now, now
+	cp := *l
+	cp.Entries = nil
+	r.byID[l.ID] = &cp
+	return nil
+}
+
+// GetByID returns the list with its entries ordered by Position, or
+// repository.ErrNotFound.
+func (r *ReadingListRepository) GetByID(id uint) (*models.ReadingList, error) {
+	r.mu.Lock()
+	l, ok := r.byID[id]
+	if !ok {
+		r.mu.Unlock()
+		return nil, repository.ErrNotFound
+	}
+	cp := *l
+	var entries []models.ReadingListEntry
+	for _, e := range r.entries {
+		if e.ReadingListID == id {
+			entries = append(entries, *e)
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Position != entries[j].Position {
+			return entries[i].Position < entries[j].Position
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	for i := range entries {
+		if b, err := r.books.GetByIDWithCopies(entries[i].BookID); err == nil {
+			entries[i].Book = *b
+		}
+	}
+	cp.Entries = entries
+	return &cp, nil
+}
+
+// Save overwrites the stored list's own fields, bumping UpdatedAt.
+func (r *ReadingListRepository) Save(l *models.ReadingList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l.UpdatedAt = time.Now()
+	cp := *l
+	cp.Entries = nil
+	r.byID[l.ID] = &cp
+	return nil
+}
+
+// Delete removes the list and its entries.
+func (r *ReadingListRepository) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byID, id)
+	for entryID, e := range r.entries {
+		if e.ReadingListID == id {
+			delete(r.entries, entryID)
+		}
+	}
+	return nil
+}
+
+// ListByOwnerID returns ownerID's lists in ID order, optionally public only.
+func (r *ReadingListRepository) ListByOwnerID(ownerID uint, publicOnly bool) ([]models.ReadingList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []models.ReadingList
+	for _, l := range r.byID {
+		if l.OwnerID != ownerID || (publicOnly && !l.IsPublic) {
+			continue
+		}
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// CountEntriesBatch returns listID → entry count for each requested list
+// that has at least one entry.
+func (r *ReadingListRepository) CountEntriesBatch(listIDs []uint) (map[uint]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wanted := make(map[uint]bool, len(listIDs))
+	for _, id := range listIDs {
+		wanted[id] = true
+	}
+	counts := map[uint]int64{}
+	for _, e := range r.entries {
+		if wanted[e.ReadingListID] {
+			counts[e.ReadingListID]++
+		}
+	}
+	return counts, nil
+}
+
+// AddEntry appends e after the list's current last entry, or returns
+// repository.ErrConflict if the book is already on the list.
+func (r *ReadingListRepository) AddEntry(e *models.ReadingListEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.Position = 0
+	for _, existing := range r.entries {
+		if existing.ReadingListID != e.ReadingListID {
+			continue
+		}
+		if existing.BookID == e.BookID {
+			return repository.ErrConflict
+		}
+		if existing.Position >= e.Position {
+			e.Position = existing.Position + 1
+		}
+	}
+	r.nextEntryID++
+	e.ID = r.nextEntryID
+	e.CreatedAt = time.Now()
+	cp := *e
+	cp.Book = models.Book{}
+	r.entries[e.ID] = &cp
+	return nil
+}
+
+// FindEntry returns the entry for bookID on listID, or repository.ErrNotFound.
+func (r *ReadingListRepository) FindEntry(listID, bookID uint) (*models.ReadingListEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.ReadingListID == listID && e.BookID == bookID {
+			cp := *e
+			return &cp, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// SaveEntry overwrites the stored entry.
+func (r *ReadingListRepository) SaveEntry(e *models.ReadingListEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *e
+	cp.Book = models.Book{}
+	r.entries[e.ID] = &cp
+	return nil
+}
+
+// RemoveEntry deletes the entry for bookID on listID, or returns
+// repository.ErrNotFound.
+func (r *ReadingListRepository) RemoveEntry(listID, bookID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, e := range r.entries {
+		if e.ReadingListID == listID && e.BookID == bookID {
+			delete(r.entries, id)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// Reorder sets each entry's Position to its index in bookIDs.
+func (r *ReadingListRepository) Reorder(listID uint, bookIDs []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, bookID := range bookIDs {
+		for _, e := range r.entries {
+			if e.ReadingListID == listID && e.BookID == bookID {
+				e.Position = i
+			}
+		}
+	}
+	return nil
+}
+
+// paginationBounds returns the [start, end) slice bounds for page/pageSize
+// over a collection of the given length.
+func paginationBounds(length, page, pageSize int) (start, end int) {
+	start = (page - 1) * pageSize
+	if start > length {
+		start = length
+	}
+	if start < 0 {
+		start = 0
+	}
+	end = start + pageSize
+	if end > length {
+		end = length
+	}
+	return start, end
+}
+
+// totalPages returns the number of pages of pageSize needed to cover length items.
+func totalPages(length, pageSize int) int {
+	return (length + pageSize - 1) / pageSize
+}
+
+// The fakes' cursors carry only an ID: each fake list has a fixed order,
+// so the item with that ID marks the position. Unlike the GORM keyset, a
+// cursor whose item has since been deleted yields an empty page.
+
+// pageCursor returns the cursor continuing after all[:end], or nil when
+// nothing follows.
+func pageCursor[T any](all []T, end int, id func(T) uint) *repository.Cursor {
+	if end <= 0 || end >= len(all) {
+		return nil
+	}
+	return &repository.Cursor{ID: id(all[end-1])}
+}
+
+// cursorPage returns up to limit items of all following the cursor (nil for
+// the first page).
+func cursorPage[T any](all []T, after *repository.Cursor, limit int, id func(T) uint) *repository.CursorResult[T] {
+	start := 0
+	if after != nil {
+		start = len(all)
+		for i, item := range all {
+			if id(item) == after.ID {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := start + limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return &repository.CursorResult[T]{
+		Items: append([]T{}, all[start:end]...),
+		Next:  pageCursor(all, end, id),
+	}
+}
+
+// AuthorRepository is an in-memory fake of repository.AuthorRepository.
+// Credits are resolved against books, mirroring the real implementation's
+// join.
+type AuthorRepository struct {
+	mu           sync.Mutex
+	nextID       uint
+	byID         map[uint]*models.Author
+	contributors map[uint][]models.BookContributor
+	books        *BookRepository
+}
+
+// NewAuthorRepository creates an empty fake AuthorRepository resolving
+// credited books against books.
+func NewAuthorRepository(books *BookRepository) *AuthorRepository {
+	return &AuthorRepository{
+		byID:         map[uint]*models.Author{},
+		contributors: map[uint][]models.BookContributor{},
+		books:        books,
+	}
+}
+
+// FindOrCreate fills a with the stored author sharing its NameKey, creating
+// it if there's none.
+func (r *AuthorRepository) FindOrCreate(a *models.Author) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.byID {
+		if existing.NameKey == a.NameKey {
+			*a = *existing
+			return nil
+		}
+	}
+	r.nextID++
+	a.ID = r.nextID
+	a.CreatedAt = time.Now()
+	cp := *a
+	r.byID[a.ID] = &cp
+	return nil
+}
+
+func (r *AuthorRepository) GetByID(id uint) (*models.Author, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.byID[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (r *AuthorRepository) ReplaceBookContributors(bookID uint, contributors []models.BookContributor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := make([]models.BookContributor, len(contributors))
+	for i, c := range contributors {
+		c.BookID = bookID
+		c.Author = models.Author{}
+		stored[i] = c
+	}
+	r.contributors[bookID] = stored
+	return nil
+}
+
+// Contributors returns the contributors stored for bookID, with Author
+// filled in — a test helper, not part of the repository.AuthorRepository
+// interface.
+func (r *AuthorRepository) Contributors(bookID uint) []models.BookContributor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := append([]models.BookContributor{}, r.contributors[bookID]...)
+	for i := range out {
+		if a, ok := r.byID[out[i].AuthorID]; ok {
+			out[i].Author = *a
+		}
+	}
+	return out
+}
+
+// ListBooksWithoutContributors returns up to limit books with a non-empty
+// Author and no stored contributors, lowest ID first.
+func (r *AuthorRepository) ListBooksWithoutContributors(limit int) ([]models.Book, error) {
+	r.mu.Lock()
+	linked := map[uint]bool{}
+	for id, cs := range r.contributors {
+		linked[id] = len(cs) > 0
+	}
+	r.mu.Unlock()
+
+	r.books.mu.Lock()
+	defer r.books.mu.Unlock()
+	var out []models.Book
+	for _, b := range r.books.byID {
+		if b.Author != "" && !linked[b.ID] {
+			out = append(out, *b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// ListCredits returns authorID's credits on books that have at least one
+// copy, by title.
+func (r *AuthorRepository) ListCredits(authorID uint) ([]repository.AuthorCredit, error) {
+	r.mu.Lock()
+	var rows []models.BookContributor
+	for _, cs := range r.contributors {
+		for _, c := range cs {
+			if c.AuthorID == authorID {
+				rows = append(rows, c)
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	r.books.mu.Lock()
+	defer r.books.mu.Unlock()
+	credits := []repository.AuthorCredit{}
+	for _, c := range rows {
+		b, ok := r.books.byID[c.BookID]
+		if !ok || !r.books.hasCopyLocked(b.ID, false) {
+			continue
+		}
+		credits = append(credits, repository.AuthorCredit{Book: *b, Role: c.Role})
+	}
+	sort.SliceStable(credits, func(i, j int) bool {
+		if credits[i].Book.Title != credits[j].Book.Title {
+			return credits[i].Book.Title < credits[j].Book.Title
+		}
+		return credits[i].Book.ID < credits[j].Book.ID
+	})
+	return credits, nil
+}
+
+// MetadataCacheRepository is an in-memory fake of
+// repository.MetadataCacheRepository.
+type MetadataCacheRepository struct {
+	mu    sync.Mutex
+	byKey map[string]*models.MetadataCacheEntry
+}
+
+// NewMetadataCacheRepository creates an empty fake MetadataCacheRepository.
+func NewMetadataCacheRepository() *MetadataCacheRepository {
+	return &MetadataCacheRepository{byKey: map[string]*models.MetadataCacheEntry{}}
+}
+
+// Get returns the entry stored under key, expired or not, or repository.ErrNotFound.
+func (r *MetadataCacheRepository) Get(key string) (*models.MetadataCacheEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.byKey[key]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	cp := *e
+	return &cp, nil
+}
+
+// Put stores e, replacing any entry under the same key.
+func (r *MetadataCacheRepository) Put(e *models.MetadataCacheEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *e
+	if cp.CreatedAt.IsZero() {
+		cp.CreatedAt = time.Now()
+	}
+	r.byKey[e.Key] = &cp
+	return nil
+}
+
+// DeleteExpired removes every entry that expired before now, returning how
+// many were removed.
+func (r *MetadataCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for key, e := range r.byKey {
+		if e.ExpiresAt.Before(now) {
+			delete(r.byKey, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Trim removes the entries soonest to expire until at most maxEntries
+// remain, returning how many were removed.
+func (r *MetadataCacheRepository) Trim(maxEntries int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	excess := len(r.byKey) - maxEntries
+	if excess <= 0 {
+		return 0, nil
+	}
+	entries := make([]*models.MetadataCacheEntry, 0, len(r.byKey))
+	for _, e := range r.byKey {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].ExpiresAt.Equal(entries[j].ExpiresAt) {
+			return entries[i].ExpiresAt.Before(entries[j].ExpiresAt)
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	for _, e := range entries[:excess] {
+		delete(r.byKey, e.Key)
+	}
+	return int64(excess), nil
+}
+
+// Count returns how many entries are stored, expired or not.
+func (r *MetadataCacheRepository) Count() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.byKey)), nil
+}
+
+// DeleteAll removes every entry, returning how many were removed.
+func (r *MetadataCacheRepository) DeleteAll() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := int64(len(r.byKey))
+	r.byKey = map[string]*models.MetadataCacheEntry{}
+	return n, nil
+}
+
+// ImportJobRepository is an in-memory fake of repository.ImportJobRepository.
+type ImportJobRepository struct {
+	mu     sync.Mutex
+	nextID uint
+	byID   map[uint]*models.ImportJob
+	rows   map[uint][]models.ImportJobRow
+	copies *CopyRepository
+}
+
+// NewImportJobRepository creates an empty fake ImportJobRepository.
+func NewImportJobRepository() *ImportJobRepository {
+	return &ImportJobRepository{byID: map[uint]*models.ImportJob{}, rows: map[uint][]models.ImportJobRow{}}
+}
+
+// Create inserts j, assigning it a new ID and timestamps.
+func (r *ImportJobRepository) Create(j *models.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	j.ID = r.nextID
+	now := time.Now()
+	j.CreatedAt, j.UpdatedAt = now, now
+	cp := *j
+	r.byID[j.ID] = &cp
+	return nil
+}
+
+// GetByID returns the job, or repository.ErrNotFound.
+func (r *ImportJobRepository) GetByID(id uint) (*models.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.byID[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	cp := *j
+	return &cp, nil
+}
+
+// SetCopies gives RecordRow somewhere to create the copies it's passed.
+func (r *ImportJobRepository) SetCopies(copies *CopyRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.copies = copies
+}
+
+// GetStatus returns the job's status, or repository.ErrNotFound.
+func (r *ImportJobRepository) GetStatus(id uint) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.byID[id]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	return j.Status, nil
+}
+
+// HasUnfinished reports whether ownerID has a queued or running job.
+func (r *ImportJobRepository) HasUnfinished(ownerID uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.byID {
+		if j.OwnerID == ownerID && (j.Status == "queued" || j.Status == "running") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListByOwnerID returns ownerID's newest jobs first, at most limit, without
+// their Content or Decisions.
+func (r *ImportJobRepository) ListByOwnerID(ownerID uint, limit int) ([]models.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []models.ImportJob
+	for _, j := range r.byID {
+		if j.OwnerID == ownerID {
+			cp := *j
+			cp.Content, cp.Decisions = "", ""
+			out = append(out, cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// ListUnfinished returns every queued or running job, oldest first.
+func (r *ImportJobRepository) ListUnfinished() ([]models.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []models.ImportJob
+	for _, j := range r.byID {
+		if j.Status == "queued" || j.Status == "running" {
+			out = append(out, *j)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// SetStatus moves job id to status if its status is one of from, clearing
+// Content and Decisions and stamping FinishedAt when status is a finished
+// one.
+func (r *ImportJobRepository) SetStatus(id uint, from []string, status, errMsg string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.byID[id]
+	if !ok || !slices.Contains(from, j.Status) {
+		return false, nil
+	}
+	j.Status, j.Error, j.UpdatedAt = status, errMsg, time.Now()
+	if status != "queued" && status != "running" {
+		now := time.Now()
+		j.FinishedAt = &now
+		j.Content, j.Decisions = "", "{}"
+	}
+	return true, nil
+}
+
+// RecordRow stores row, creates bookCopy (if not nil) in the CopyRepository
+// given to SetCopies, and advances its job's ProcessedRows, returning
+// repository.ErrConflict if that row is already recorded.
+func (r *ImportJobRepository) RecordRow(row *models.ImportJobRow, bookCopy *models.Copy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.rows[row.ImportJobID] {
+		if existing.Row == row.Row {
+			return repository.ErrConflict
+		}
+	}
+	if bookCopy != nil {
+		if err := r.copies.Create(bookCopy); err != nil {
+			return err
+		}
+	}
+	r.rows[row.ImportJobID] = append(r.rows[row.ImportJobID], *row)
+	if j, ok := r.byID[row.ImportJobID]; ok {
+		j.ProcessedRows++
+	}
+	return nil
+}
+
+// ListRows returns jobID's recorded rows in row order.
+func (r *ImportJobRepository) ListRows(jobID uint) ([]models.ImportJobRow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := slices.Clone(r.rows[jobID])
+	sort.Slice(out, func(i, j int) bool { return out[i].Row < out[j].Row })
+	return out, nil
+}
+
+// LibraryRepository is a fake of repository.LibraryRepository. Whole-library
+// matching only means anything against real tables (see the gorm tests), so
+// this one serves Library as the snapshot and keeps every snapshot it's
+// asked to restore, answering with Stats.
+type LibraryRepository struct {
+	mu       sync.Mutex
+	Library  repository.LibrarySnapshot
+	Stats    repository.LibraryRestoreStats
+	Restored []*repository.LibrarySnapshot
+}
+
+// NewLibraryRepository creates a fake LibraryRepository with an empty library.
+func NewLibraryRepository() *LibraryRepository {
+	return &LibraryRepository{}
+}
+
+// Snapshot returns a copy of Library.
+func (r *LibraryRepository) Snapshot() (*repository.LibrarySnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.Library
+	return &s, nil
+}
+
+// Restore records s and returns Stats.
+func (r *LibraryRepository) Restore(s *repository.LibrarySnapshot) (repository.LibraryRestoreStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Restored = append(r.Restored, s)
+	return r.Stats, nil
+}
+
+// AccountRepository is a fake of repository.AccountRepository. Gathering or
+// removing a member's rows only means anything against real tables (see
+// the gorm tests), so this one serves whatever Accounts holds for the user,
+// reports ActiveLoans, and records the users Delete was called for.
+type AccountRepository struct {
+	mu          sync.Mutex
+	Accounts    map[uint]*repository.AccountData
+	ActiveLoans map[uint]int64
+	Due         []models.User
+	Deleted     []uint
+	Covers      map[uint][]string
+}
+
+// NewAccountRepository creates a fake AccountRepository with no accounts.
+func NewAccountRepository() *AccountRepository {
+	return &AccountRepository{Accounts: map[uint]*repository.AccountData{}, ActiveLoans: map[uint]int64{}}
+}
+
+// Export returns a copy of Accounts[userID], or repository.ErrNotFound.
+func (r *AccountRepository) Export(userID uint) (*repository.AccountData, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.Accounts[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	out := *d
+	return &out, nil
+}
+
+// CountActiveLoans returns ActiveLoans[userID].
+func (r *AccountRepository) CountActiveLoans(userID uint) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ActiveLoans[userID], nil
+}
+
+// ListDueDeletions returns the users in Due scheduled at or before now.
+func (r *AccountRepository) ListDueDeletions(now time.Time) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []models.User
+	for _, u := range r.Due {
+		if u.DeletionScheduledAt != nil && !u.DeletionScheduledAt.After(now) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+// Delete records userID in Deleted and returns Covers[userID], or returns
+// repository.ErrActiveLoans while ActiveLoans[userID] is non-zero.
+func (r *AccountRepository) Delete(userID uint, _ time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ActiveLoans[userID] > 0 {
+		return nil, repository.ErrActiveLoans
+	}
+	r.Deleted = append(r.Deleted, userID)
+	return r.Covers[userID], nil
 }
 
 var (
@@ -1374,4 +2529,11 @@ var (
 	_ repository.RegistrationVerificationRepository = (*RegistrationVerificationRepository)(nil)
 	_ repository.WishlistRequestRepository          = (*WishlistRequestRepository)(nil)
 	_ repository.BookRepository                     = (*BookRepository)(nil)
+	_ repository.RecommendationRepository           = (*RecommendationRepository)(nil)
+	_ repository.ReadingListRepository              = (*ReadingListRepository)(nil)
+	_ repository.AuthorRepository                   = (*AuthorRepository)(nil)
+	_ repository.MetadataCacheRepository            = (*MetadataCacheRepository)(nil)
+	_ repository.ImportJobRepository                = (*ImportJobRepository)(nil)
+	_ repository.LibraryRepository                  = (*LibraryRepository)(nil)
+	_ repository.AccountRepository                  = (*AccountRepository)(nil)
 )
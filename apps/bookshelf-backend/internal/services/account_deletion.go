@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/covers"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
+)
+
+// AccountDeletionService carries out self-service account deletions whose
+// grace period has run out (see AccountHandler.scheduleDeletion). A member
+// who has borrowed or lent a copy since scheduling keeps their account
+// until the loan ends; each run retries them.
+type AccountDeletionService struct {
+	accounts   repository.AccountRepository
+	coverStore storage.Store
+	now        func() time.Time
+}
+
+// NewAccountDeletionService creates an AccountDeletionService. coverStore
+// is where the cached covers of books deleted with a member's copies are
+// removed from.
+func NewAccountDeletionService(accounts repository.AccountRepository, coverStore storage.Store) *AccountDeletionService {
+	return &AccountDeletionService{accounts: accounts, coverStore: coverStore, now: time.Now}
+}
+
+// Run deletes every account due for deletion and returns a summary,
+// matching the signature RegisterJob expects.
+func (s *AccountDeletionService) Run(ctx context.Context) string {
+	now := s.now()
+	due, err := s.accounts.ListDueDeletions(now)
+	if err != nil {
+		return "failed: " + err.Error()
+	}
+	var deleted, waiting, failed int
+	for _, u := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		coverURLs, err := s.accounts.Delete(u.ID, now)
+		switch {
+		case err == nil:
+			deleted++
+			log.Info().Uint("user_id", u.ID).Msg("account deletion: account anonymized")
+			for _, coverURL := range coverURLs {
+				if err := covers.RemoveCached(ctx, s.coverStore, coverURL); err != nil {
+					log.Warn().Err(err).Str("cover_url", coverURL).Msg("account deletion: could not delete cached cover")
+				}
+			}
+		case errors.Is(err, repository.ErrActiveLoans):
+			waiting++
+		default:
+			failed++
+			log.Error().Err(err).Uint("user_id", u.ID).Msg("account deletion: failed")
+		}
+	}
+	result := fmt.Sprintf("deleted %d account(s), %d waiting on active loans", deleted, waiting)
+	if failed > 0 {
+		result += fmt.Sprintf(", %d failed", failed)
+	}
+	return result
+}
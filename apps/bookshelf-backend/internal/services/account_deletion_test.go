@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
+)
+
+func TestAccountDeletionService_Run(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	accounts := repotest.NewAccountRepository()
+	accounts.Due = []models.User{
+		{ID: 1, DeletionScheduledAt: &past},
+		{ID: 2, DeletionScheduledAt: &past},
+		{ID: 3, DeletionScheduledAt: &future},
+	}
+	accounts.ActiveLoans[2] = 1
+	accounts.Covers = map[uint][]string{1: {"/api/covers/zine.jpg", "https://covers.openlibrary.org/b/id/1-L.jpg"}}
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "zine.jpg"), []byte("fake image"), 0o600))
+	s := NewAccountDeletionService(accounts, store)
+	s.now = func() time.Time { return now }
+
+	assert.Equal(t, "deleted 1 account(s), 1 waiting on active loans", s.Run(context.Background()))
+	assert.Equal(t, []uint{1}, accounts.Deleted, "not the one with a loan out, nor the one still in its grace period")
+	_, err = os.Stat(filepath.Join(dir, "zine.jpg"))
+	assert.True(t, os.IsNotExist(err), "the cover of a book deleted with their copies is removed")
+}
@@ -5,10 +5,11 @@ import (
 	"compress/gzip"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -18,11 +19,12 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
 // backupFilenamePattern matches server-generated snapshot filenames — the
-// sole validation gate before any filename-derived path touches the
-// filesystem (list/download/delete all route through resolvePath).
+// sole validation gate before any filename-derived key reaches the backups
+// store (list/download/delete all check it).
 var backupFilenamePattern = regexp.MustCompile(`^bookshelf-backup-\d{8}T\d{6}Z\.tar\.gz$`)
 
 // defaultBackupRetention is used when backup_retention_count is absent/invalid.
@@ -37,22 +39,24 @@ type BackupInfo struct {
 
 // BackupService creates, lists, and prunes backup snapshots — each a
 // tar.gz bundle of a VACUUM INTO'd copy of the SQLite database plus the
-// cover-image cache directory.
+// cached cover images — kept in their own storage.Store.
 type BackupService struct {
-	dbPath     string
-	coversDir  string
-	backupsDir string
+	// scratchDir is where a snapshot's database copy and archive are
+	// assembled before being uploaded to backups; it must have room for
+	// both, so it's normally alongside the database.
+	scratchDir string
+	covers     storage.Store
+	backups    storage.Store
 	sqlDB      *sql.DB
 	admin      repository.AdminRepository
 }
 
-// NewBackupService creates a BackupService. backupsDir must already exist
-// (created at boot alongside coversDir, same as main.go does today).
-func NewBackupService(sqlDB *sql.DB, admin repository.AdminRepository, dbPath, coversDir, backupsDir string) *BackupService {
+// NewBackupService creates a BackupService. scratchDir must already exist.
+func NewBackupService(sqlDB *sql.DB, admin repository.AdminRepository, scratchDir string, covers, backups storage.Store) *BackupService {
 	return &BackupService{
-		dbPath:     dbPath,
-		coversDir:  coversDir,
-		backupsDir: backupsDir,
+		scratchDir: scratchDir,
+		covers:     covers,
+		backups:    backups,
 		sqlDB:      sqlDB,
 		admin:      admin,
 	}
@@ -73,34 +77,32 @@ func (b *BackupService) CreateSnapshot(ctx context.Context) string {
 
 func (b *BackupService) createSnapshot(ctx context.Context) (BackupInfo, error) {
 	filename := "bookshelf-backup-" + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"
-	finalPath := filepath.Join(b.backupsDir, filename)
 
-	tmpDB := filepath.Join(b.backupsDir, ".tmp-db-"+filename)
+	tmpDB := filepath.Join(b.scratchDir, ".tmp-db-"+filename)
 	if err := b.vacuumToFile(ctx, tmpDB); err != nil {
 		return BackupInfo{}, fmt.Errorf("snapshot database: %w", err)
 	}
 	defer os.Remove(tmpDB) //nolint:errcheck
 
-	tmpArchive := filepath.Join(b.backupsDir, ".tmp-"+filename)
-	if err := b.writeArchive(tmpArchive, tmpDB); err != nil {
-		_ = os.Remove(tmpArchive)
+	tmpArchive := filepath.Join(b.scratchDir, ".tmp-"+filename)
+	defer os.Remove(tmpArchive) //nolint:errcheck
+	if err := b.writeArchive(ctx, tmpArchive, tmpDB); err != nil {
 		return BackupInfo{}, fmt.Errorf("write archive: %w", err)
 	}
 
-	if err := os.Rename(tmpArchive, finalPath); err != nil {
-		_ = os.Remove(tmpArchive)
-		return BackupInfo{}, fmt.Errorf("finalize archive: %w", err)
+	if err := b.upload(ctx, filename, tmpArchive); err != nil {
+		return BackupInfo{}, fmt.Errorf("store archive: %w", err)
 	}
 
-	if err := b.pruneOldSnapshots(); err != nil {
+	if err := b.pruneOldSnapshots(ctx); err != nil {
 		log.Warn().Err(err).Msg("backup: pruning old snapshots failed")
 	}
 
-	stat, err := os.Stat(finalPath)
+	stat, err := b.backups.Stat(ctx, filename)
 	if err != nil {
 		return BackupInfo{}, err
 	}
-	return BackupInfo{Filename: filename, SizeBytes: stat.Size(), CreatedAt: stat.ModTime()}, nil
+	return BackupInfo{Filename: filename, SizeBytes: stat.Size, CreatedAt: stat.ModTime}, nil
 }
 
 // vacuumToFile snapshots the live database to dest via SQLite's VACUUM INTO —
@@ -111,10 +113,10 @@ func (b *BackupService) vacuumToFile(ctx context.Context, dest string) error {
 	return err
 }
 
-// writeArchive bundles the vacuumed database file and coversDir's contents
+// writeArchive bundles the vacuumed database file and the cached covers
 // into a gzip-compressed tar at destPath.
-func (b *BackupService) writeArchive(destPath, dbFile string) error {
-	f, err := os.Create(destPath) //nolint:gosec // destPath is server-generated (backupsDir + our own filename)
+func (b *BackupService) writeArchive(ctx context.Context, destPath, dbFile string) error {
+	f, err := os.Create(destPath) //nolint:gosec // destPath is server-generated (scratchDir + our own filename)
 	if err != nil {
 		return err
 	}
@@ -129,12 +131,26 @@ func (b *BackupService) writeArchive(destPath, dbFile string) error {
 	if err := addFileToTar(tw, dbFile, "bookshelf.db"); err != nil {
 		return err
 	}
-	if err := addDirToTar(tw, b.coversDir, "covers"); err != nil {
+	if err := addStoreToTar(ctx, tw, b.covers, "covers"); err != nil {
 		return err
 	}
 	return nil
 }
 
+// upload copies the finished archive at path into the backups store.
+func (b *BackupService) upload(ctx context.Context, filename, path string) error {
+	f, err := os.Open(path) //nolint:gosec // our own scratch file
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return b.backups.Put(ctx, filename, f, stat.Size(), "application/gzip")
+}
+
 // addFileToTar writes a single file into tw under arcName.
 func addFileToTar(tw *tar.Writer, srcPath, arcName string) error {
 	stat, err := os.Stat(srcPath)
@@ -158,77 +174,98 @@ func addFileToTar(tw *tar.Writer, srcPath, arcName string) error {
 	return err
 }
 
-// addDirToTar recursively writes dirPath's contents into tw under arcPrefix.
-// A missing dirPath is treated as empty (nothing to back up yet), not an error.
-func addDirToTar(tw *tar.Writer, dirPath, arcPrefix string) error {
-	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+// addStoreToTar writes every object in store into tw under arcPrefix. A
+// nil store is treated as empty.
+func addStoreToTar(ctx context.Context, tw *tar.Writer, store storage.Store, arcPrefix string) error {
+	if store == nil {
 		return nil
 	}
-	return filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
+	objects, err := store.List(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := addObjectToTar(ctx, tw, store, obj.Key, path.Join(arcPrefix, obj.Key)); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				// Deleted since List; nothing to back up.
+				continue
+			}
 			return err
 		}
-		if d.IsDir() {
-			return nil
-		}
-		rel, relErr := filepath.Rel(dirPath, path)
-		if relErr != nil {
-			return relErr
-		}
-		return addFileToTar(tw, path, filepath.Join(arcPrefix, rel))
-	})
+	}
+	return nil
+}
+
+func addObjectToTar(ctx context.Context, tw *tar.Writer, store storage.Store, key, arcName string) error {
+	r, info, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close() //nolint:errcheck
+	hdr := &tar.Header{Name: arcName, Mode: 0o644, Size: info.Size, ModTime: info.ModTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, r) //nolint:gosec // tar writer, not an HTTP response
+	return err
 }
 
 // ListSnapshots returns stored backup archives, newest first.
-func (b *BackupService) ListSnapshots() ([]BackupInfo, error) {
-	entries, err := os.ReadDir(b.backupsDir)
+func (b *BackupService) ListSnapshots(ctx context.Context) ([]BackupInfo, error) {
+	objects, err := b.backups.List(ctx, "bookshelf-backup-")
 	if err != nil {
 		return nil, err
 	}
-	out := make([]BackupInfo, 0, len(entries))
-	for _, e := range entries {
-		if e.IsDir() || !backupFilenamePattern.MatchString(e.Name()) {
+	out := make([]BackupInfo, 0, len(objects))
+	for _, obj := range objects {
+		if !backupFilenamePattern.MatchString(obj.Key) {
 			continue
 		}
-		stat, statErr := e.Info()
-		if statErr != nil {
-			continue
-		}
-		out = append(out, BackupInfo{Filename: e.Name(), SizeBytes: stat.Size(), CreatedAt: stat.ModTime()})
+		out = append(out, BackupInfo{Filename: obj.Key, SizeBytes: obj.Size, CreatedAt: obj.ModTime})
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
 	return out, nil
 }
 
-// ResolvePath validates filename against the server-generated backup
-// filename pattern and returns its full path, or repository.ErrNotFound if
-// the name is invalid or the file doesn't exist. This is the single choke
-// point every filename-taking operation (download, delete) routes through.
-func (b *BackupService) ResolvePath(filename string) (string, error) {
+// OpenSnapshot validates filename against the server-generated backup
+// filename pattern and opens the archive, or returns repository.ErrNotFound
+// if the name is invalid or there's no such archive. Together with
+// DeleteSnapshot, the single choke point every filename-taking operation
+// routes through. The caller closes the reader.
+func (b *BackupService) OpenSnapshot(ctx context.Context, filename string) (io.ReadCloser, BackupInfo, error) {
 	if !backupFilenamePattern.MatchString(filename) {
-		return "", repository.ErrNotFound
+		return nil, BackupInfo{}, repository.ErrNotFound
 	}
-	path := filepath.Join(b.backupsDir, filename)
-	if _, err := os.Stat(path); err != nil {
-		return "", repository.ErrNotFound
+	r, info, err := b.backups.Get(ctx, filename)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, BackupInfo{}, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, BackupInfo{}, err
 	}
-	return path, nil
+	return r, BackupInfo{Filename: filename, SizeBytes: info.Size, CreatedAt: info.ModTime}, nil
 }
 
-// DeleteSnapshot removes the named backup archive.
-func (b *BackupService) DeleteSnapshot(filename string) error {
-	path, err := b.ResolvePath(filename)
-	if err != nil {
+// DeleteSnapshot removes the named backup archive, or returns
+// repository.ErrNotFound as OpenSnapshot does.
+func (b *BackupService) DeleteSnapshot(ctx context.Context, filename string) error {
+	if !backupFilenamePattern.MatchString(filename) {
+		return repository.ErrNotFound
+	}
+	if _, err := b.backups.Stat(ctx, filename); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return repository.ErrNotFound
+		}
 		return err
 	}
-	return os.Remove(path)
+	return b.backups.Delete(ctx, filename)
 }
 
 // pruneOldSnapshots deletes all but the newest backup_retention_count
 // snapshots (falling back to defaultBackupRetention when the setting is
 // absent/invalid).
-func (b *BackupService) pruneOldSnapshots() error {
-	snapshots, err := b.ListSnapshots()
+func (b *BackupService) pruneOldSnapshots(ctx context.Context) error {
+	snapshots, err := b.ListSnapshots(ctx)
 	if err != nil {
 		return err
 	}
@@ -237,7 +274,7 @@ func (b *BackupService) pruneOldSnapshots() error {
 		return nil
 	}
 	for _, s := range snapshots[keep:] {
-		if delErr := b.DeleteSnapshot(s.Filename); delErr != nil {
+		if delErr := b.DeleteSnapshot(ctx, s.Filename); delErr != nil {
 			log.Warn().Err(delErr).Str("filename", s.Filename).Msg("backup: failed to prune snapshot")
 		}
 	}
@@ -15,6 +15,9 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
 func openTestDB(t *testing.T) *sql.DB {
@@ -38,10 +41,17 @@ func newTestBackupService(t *testing.T) (*BackupService, string) {
 	require.NoError(t, os.WriteFile(filepath.Join(coversDir, "cover1.jpg"), []byte("fake-jpeg"), 0o600))
 
 	backupsDir := t.TempDir()
-	svc := NewBackupService(openTestDB(t), stubAdminRepo{}, "", coversDir, backupsDir)
+	svc := NewBackupService(openTestDB(t), stubAdminRepo{}, t.TempDir(), newLocalStore(t, coversDir), newLocalStore(t, backupsDir))
 	return svc, backupsDir
 }
 
+func newLocalStore(t *testing.T, dir string) storage.Store {
+	t.Helper()
+	store, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+	return store
+}
+
 func archiveNames(t *testing.T, path string) []string {
 	t.Helper()
 	f, err := os.Open(path) //nolint:gosec // test fixture path
@@ -71,7 +81,7 @@ func TestCreateSnapshot_ContainsDBAndCovers(t *testing.T) {
 	result := svc.CreateSnapshot(context.Background())
 	assert.Contains(t, result, "created bookshelf-backup-")
 
-	snapshots, err := svc.ListSnapshots()
+	snapshots, err := svc.ListSnapshots(context.Background())
 	require.NoError(t, err)
 	require.Len(t, snapshots, 1)
 	assert.Positive(t, snapshots[0].SizeBytes)
@@ -88,33 +98,36 @@ func TestListSnapshots_IgnoresUnrelatedFiles(t *testing.T) {
 
 	svc.CreateSnapshot(context.Background())
 
-	snapshots, err := svc.ListSnapshots()
+	snapshots, err := svc.ListSnapshots(context.Background())
 	require.NoError(t, err)
 	require.Len(t, snapshots, 1)
 }
 
-func TestResolvePath_RejectsPathTraversal(t *testing.T) {
+func TestOpenSnapshot_RejectsPathTraversal(t *testing.T) {
 	svc, _ := newTestBackupService(t)
 	svc.CreateSnapshot(context.Background())
 
-	_, err := svc.ResolvePath("../../etc/passwd")
-	require.Error(t, err)
+	_, _, err := svc.OpenSnapshot(context.Background(), "../../etc/passwd")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, _, err = svc.OpenSnapshot(context.Background(), "not-a-real-backup.tar.gz")
+	require.ErrorIs(t, err, repository.ErrNotFound)
 
-	_, err = svc.ResolvePath("not-a-real-backup.tar.gz")
-	require.Error(t, err)
+	_, _, err = svc.OpenSnapshot(context.Background(), "bookshelf-backup-20200101T000000Z.tar.gz")
+	require.ErrorIs(t, err, repository.ErrNotFound)
 }
 
 func TestDeleteSnapshot(t *testing.T) {
 	svc, _ := newTestBackupService(t)
 	svc.CreateSnapshot(context.Background())
 
-	snapshots, err := svc.ListSnapshots()
+	snapshots, err := svc.ListSnapshots(context.Background())
 	require.NoError(t, err)
 	require.Len(t, snapshots, 1)
 
-	require.NoError(t, svc.DeleteSnapshot(snapshots[0].Filename))
+	require.NoError(t, svc.DeleteSnapshot(context.Background(), snapshots[0].Filename))
 
-	snapshots, err = svc.ListSnapshots()
+	snapshots, err = svc.ListSnapshots(context.Background())
 	require.NoError(t, err)
 	assert.Empty(t, snapshots)
 }
@@ -135,9 +148,9 @@ func TestPruneOldSnapshots_KeepsRetentionCount(t *testing.T) {
 		require.NoError(t, os.Chtimes(path, mtime, mtime))
 	}
 
-	require.NoError(t, svc.pruneOldSnapshots())
+	require.NoError(t, svc.pruneOldSnapshots(context.Background()))
 
-	snapshots, err := svc.ListSnapshots()
+	snapshots, err := svc.ListSnapshots(context.Background())
 	require.NoError(t, err)
 	require.Len(t, snapshots, 2)
 	// Newest-first: the two most recently modified fakes should survive.
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// contributorBackfillBatch bounds how many books one Run links, so a large
+// catalog that predates contributors is caught up over a few runs instead
+// of one long pass.
+const contributorBackfillBatch = 500
+
+// ContributorService links books to Author records. Names are normalized
+// with bookmatch.NormalizeAuthorName and matched on bookmatch.AuthorKey, so
+// "Lewis, C.S." and "C. S. Lewis" credit the same Author. Book.Author stays
+// the free-text credit line shown in the catalog.
+type ContributorService struct {
+	authors repository.AuthorRepository
+}
+
+// NewContributorService creates a ContributorService.
+func NewContributorService(authors repository.AuthorRepository) *ContributorService {
+	return &ContributorService{authors: authors}
+}
+
+// LinkBook replaces book's contributors with contributors — as reported by
+// a metadata source — or, when there are none, with those parsed from
+// book.Author. A failure is logged rather than returned: contributors are
+// derived data, and the nightly job picks up any book left without them.
+func (s *ContributorService) LinkBook(_ context.Context, book *models.Book, contributors []bookmatch.Contributor) {
+	if len(contributors) == 0 {
+		contributors = bookmatch.ParseContributors(book.Author)
+	}
+	if err := s.link(book.ID, contributors); err != nil {
+		log.Warn().Err(err).Uint("book_id", book.ID).Msg("contributors: failed to link book")
+	}
+}
+
+// Run links every book that has a free-text Author but no contributors yet,
+// up to contributorBackfillBatch, and returns a human-readable summary for
+// JobStatus.LastResult, matching the signature RegisterJob expects.
+func (s *ContributorService) Run(_ context.Context) string {
+	books, err := s.authors.ListBooksWithoutContributors(contributorBackfillBatch)
+	if err != nil {
+		log.Error().Err(err).Msg("contributors: failed to list unlinked books")
+		return "failed: " + err.Error()
+	}
+
+	linked := 0
+	for _, book := range books {
+		if err := s.link(book.ID, bookmatch.ParseContributors(book.Author)); err != nil {
+			log.Warn().Err(err).Uint("book_id", book.ID).Msg("contributors: failed to link book")
+			continue
+		}
+		linked++
+	}
+
+	log.Info().Int("linked", linked).Int("candidates", len(books)).Msg("contributors: complete")
+	return fmt.Sprintf("linked %d of %d books", linked, len(books))
+}
+
+// link resolves each contributor to an Author and stores them in order. A
+// person credited twice in the same role (e.g. once as "C.S. Lewis" and once
+// as "Lewis, C. S.") is kept once.
+func (s *ContributorService) link(bookID uint, contributors []bookmatch.Contributor) error {
+	rows := make([]models.BookContributor, 0, len(contributors))
+	seen := map[string]bool{}
+	for _, c := range contributors {
+		name := bookmatch.NormalizeAuthorName(c.Name)
+		key := bookmatch.AuthorKey(name)
+		if key == "" {
+			continue
+		}
+		role := c.Role
+		if role == "" {
+			role = bookmatch.RoleAuthor
+		}
+		if seen[key+"|"+role] {
+			continue
+		}
+		seen[key+"|"+role] = true
+
+		author := models.Author{Name: name, NameKey: key}
+		if err := s.authors.FindOrCreate(&author); err != nil {
+			return err
+		}
+		rows = append(rows, models.BookContributor{AuthorID: author.ID, Role: role, Position: len(rows)})
+	}
+	return s.authors.ReplaceBookContributors(bookID, rows)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func TestContributorService_LinkBook_SharesAuthorsAcrossSpellings(t *testing.T) {
+	books := repotest.NewBookRepository()
+	authors := repotest.NewAuthorRepository(books)
+	svc := NewContributorService(authors)
+
+	narnia := models.Book{Title: "The Lion, the Witch and the Wardrobe", Author: "C.S. Lewis"}
+	letters := models.Book{Title: "The Screwtape Letters", Author: "Lewis, C. S."}
+	require.NoError(t, books.Create(&narnia))
+	require.NoError(t, books.Create(&letters))
+
+	svc.LinkBook(context.Background(), &narnia, nil)
+	svc.LinkBook(context.Background(), &letters, nil)
+
+	a, b := authors.Contributors(narnia.ID), authors.Contributors(letters.ID)
+	require.Len(t, a, 1)
+	require.Len(t, b, 1)
+	assert.Equal(t, a[0].AuthorID, b[0].AuthorID)
+	assert.Equal(t, "C. S. Lewis", a[0].Author.Name)
+}
+
+func TestContributorService_LinkBook_PrefersSourceContributors(t *testing.T) {
+	books := repotest.NewBookRepository()
+	authors := repotest.NewAuthorRepository(books)
+	svc := NewContributorService(authors)
+
+	war := models.Book{Title: "War and Peace", Author: "Leo Tolstoy"}
+	require.NoError(t, books.Create(&war))
+
+	svc.LinkBook(context.Background(), &war, []bookmatch.Contributor{
+		{Name: "Leo Tolstoy", Role: bookmatch.RoleAuthor},
+		{Name: "Richard Pevear", Role: bookmatch.RoleTranslator},
+		{Name: "Larissa Volokhonsky", Role: bookmatch.RoleTranslator},
+		{Name: "Tolstoy, Leo", Role: bookmatch.RoleAuthor},
+	})
+
+	got := authors.Contributors(war.ID)
+	require.Len(t, got, 3, "the inverted duplicate is dropped")
+	assert.Equal(t, "Leo Tolstoy", got[0].Author.Name)
+	assert.Equal(t, bookmatch.RoleTranslator, got[1].Role)
+	assert.Equal(t, 2, got[2].Position)
+}
+
+func TestContributorService_Run_BackfillsUnlinkedBooks(t *testing.T) {
+	books := repotest.NewBookRepository()
+	authors := repotest.NewAuthorRepository(books)
+	svc := NewContributorService(authors)
+
+	omens := models.Book{Title: "Good Omens", Author: "Neil Gaiman & Terry Pratchett"}
+	anon := models.Book{Title: "Beowulf"}
+	linked := models.Book{Title: "Coraline", Author: "Neil Gaiman"}
+	for _, b := range []*models.Book{&omens, &anon, &linked} {
+		require.NoError(t, books.Create(b))
+	}
+	svc.LinkBook(context.Background(), &linked, nil)
+
+	assert.Equal(t, "linked 1 of 1 books", svc.Run(context.Background()))
+
+	got := authors.Contributors(omens.ID)
+	require.Len(t, got, 2)
+	assert.Equal(t, authors.Contributors(linked.ID)[0].AuthorID, got[0].AuthorID, "Gaiman is one Author across both books")
+	assert.Equal(t, "Terry Pratchett", got[1].Author.Name)
+	assert.Empty(t, authors.Contributors(anon.ID))
+}
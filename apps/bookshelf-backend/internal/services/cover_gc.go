@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/covers"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
+)
+
+// defaultCoverGCGracePeriod is used when cover_gc_grace_period is
+// absent/invalid.
+const defaultCoverGCGracePeriod = 7 * 24 * time.Hour
+
+// coverURLPrefix is how a book's CoverURL points at a file in the covers
+// store (see handlers.downloadCover).
+const coverURLPrefix = "/api/covers/"
+
+// CoverGCService deletes cached cover files no book references any more.
+// Cover files are named after the URL they were downloaded from, so a book
+// whose cover is refreshed or re-pointed leaves its old file behind; only
+// deleting a book's last copy removes its cover as it goes.
+//
+// Generated placeholders (covers.Placeholder) are kept while their book is
+// still coverless and its title and author unchanged.
+//
+// A file is only deleted once it's older than the grace period (the
+// admin setting cover_gc_grace_period, a Go duration), so a cover
+// downloaded for a book that hasn't been saved yet isn't swept out from
+// under it.
+type CoverGCService struct {
+	books  repository.BookRepository
+	admin  repository.AdminRepository
+	covers storage.Store
+	now    func() time.Time
+}
+
+// NewCoverGCService creates a CoverGCService sweeping coverStore.
+func NewCoverGCService(books repository.BookRepository, admin repository.AdminRepository, coverStore storage.Store) *CoverGCService {
+	return &CoverGCService{
+		books:  books,
+		admin:  admin,
+		covers: coverStore,
+		now:    time.Now,
+	}
+}
+
+// Run deletes orphaned cover files past the grace period and returns a
+// summary with the space reclaimed, matching the signature RegisterJob
+// expects.
+func (s *CoverGCService) Run(ctx context.Context) string {
+	return s.sweep(ctx, false)
+}
+
+// DryRun reports what Run would delete without deleting anything, for
+// Scheduler.RegisterDryRun.
+func (s *CoverGCService) DryRun(ctx context.Context) string {
+	return s.sweep(ctx, true)
+}
+
+func (s *CoverGCService) sweep(ctx context.Context, dryRun bool) string {
+	orphans, err := s.orphans(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("cover-gc: failed to find orphaned covers")
+		return "failed: " + err.Error()
+	}
+
+	var deleted int
+	var reclaimed int64
+	for _, obj := range orphans {
+		if ctx.Err() != nil {
+			break
+		}
+		if !dryRun {
+			if err := s.covers.Delete(ctx, obj.Key); err != nil {
+				log.Warn().Err(err).Str("key", obj.Key).Msg("cover-gc: delete failed")
+				continue
+			}
+		}
+		deleted++
+		reclaimed += obj.Size
+	}
+
+	if dryRun {
+		return fmt.Sprintf("dry run: would delete %d orphaned cover file(s), reclaiming %s", deleted, humanSize(reclaimed))
+	}
+	log.Info().Int("files", deleted).Int64("bytes", reclaimed).Msg("cover-gc: orphaned covers deleted")
+	result := fmt.Sprintf("deleted %d orphaned cover file(s), reclaimed %s", deleted, humanSize(reclaimed))
+	if deleted < len(orphans) {
+		result += fmt.Sprintf(" (%d left for the next run)", len(orphans)-deleted)
+	}
+	return result
+}
+
+// orphans lists the files in the covers store that no book's CoverURL
+// points at, directly or as one of its size variants, that aren't the
+// placeholder of a coverless book, and that are older than the grace
+// period.
+func (s *CoverGCService) orphans(ctx context.Context) ([]storage.ObjectInfo, error) {
+	// Listed before the references are read: a cover stored in between is
+	// then either referenced already or too new to be deleted.
+	objects, err := s.covers.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list covers: %w", err)
+	}
+	urls, err := s.books.ListCoverURLs(coverURLPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list cover urls: %w", err)
+	}
+	coverless, err := s.books.ListWithoutCover()
+	if err != nil {
+		return nil, fmt.Errorf("list coverless books: %w", err)
+	}
+
+	referenced := make(map[string]bool, len(urls)*3+len(coverless))
+	for _, u := range urls {
+		filename := strings.TrimPrefix(u, coverURLPrefix)
+		for _, size := range []covers.Size{covers.Full, covers.Medium, covers.Thumb} {
+			referenced[covers.VariantName(filename, size)] = true
+		}
+	}
+	for _, b := range coverless {
+		referenced[covers.PlaceholderName(b.ID, b.Title, b.Author)] = true
+	}
+
+	cutoff := s.now().Add(-s.gracePeriod())
+	var out []storage.ObjectInfo
+	for _, obj := range objects {
+		if !referenced[obj.Key] && obj.ModTime.Before(cutoff) {
+			out = append(out, obj)
+		}
+	}
+	return out, nil
+}
+
+func (s *CoverGCService) gracePeriod() time.Duration {
+	if s.admin != nil {
+		if val, err := s.admin.GetSetting("cover_gc_grace_period"); err == nil && val != "" {
+			if d, err := time.ParseDuration(val); err == nil && d >= 0 {
+				return d
+			}
+		}
+	}
+	return defaultCoverGCGracePeriod
+}
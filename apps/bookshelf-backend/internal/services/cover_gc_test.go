@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/covers"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+// newCoverGCDeps returns a CoverGCService over a covers dir holding a
+// referenced cover with its variants, an orphaned cover with a thumbnail
+// (both a month old), and an orphan written just now.
+func newCoverGCDeps(t *testing.T) (*CoverGCService, *repotest.AdminRepository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	monthAgo := time.Now().Add(-30 * 24 * time.Hour)
+	for name, data := range map[string]string{
+		"kept.jpg":        "full",
+		"kept-medium.jpg": "medium",
+		"kept-thumb.jpg":  "thumb",
+		"stale.jpg":       "0123456789",
+		"stale-thumb.jpg": "01234",
+		"fresh.png":       "fresh",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		if name != "fresh.png" {
+			require.NoError(t, os.Chtimes(path, monthAgo, monthAgo))
+		}
+	}
+
+	books := repotest.NewBookRepository()
+	require.NoError(t, books.Create(&models.Book{Title: "Cached", Author: "A", CoverURL: "/api/covers/kept.jpg"}))
+	require.NoError(t, books.Create(&models.Book{Title: "External", Author: "A", CoverURL: "https://covers.openlibrary.org/b/id/1-L.jpg"}))
+	admin := repotest.NewAdminRepository()
+	return NewCoverGCService(books, admin, newLocalStore(t, dir)), admin, dir
+}
+
+func coverFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestCoverGC_DeletesOrphansPastTheGracePeriod(t *testing.T) {
+	svc, _, dir := newCoverGCDeps(t)
+
+	result := svc.Run(context.Background())
+
+	assert.Equal(t, "deleted 2 orphaned cover file(s), reclaimed 15 B", result)
+	assert.ElementsMatch(t, []string{"kept.jpg", "kept-medium.jpg", "kept-thumb.jpg", "fresh.png"}, coverFiles(t, dir),
+		"referenced covers and their variants, and orphans inside the grace period, must be kept")
+}
+
+func TestCoverGC_DryRunDeletesNothing(t *testing.T) {
+	svc, _, dir := newCoverGCDeps(t)
+	before := coverFiles(t, dir)
+
+	result := svc.DryRun(context.Background())
+
+	assert.Equal(t, "dry run: would delete 2 orphaned cover file(s), reclaiming 15 B", result)
+	assert.Equal(t, before, coverFiles(t, dir))
+}
+
+func TestCoverGC_GracePeriodSetting(t *testing.T) {
+	svc, admin, dir := newCoverGCDeps(t)
+	require.NoError(t, admin.UpsertSetting("cover_gc_grace_period", "0s"))
+
+	result := svc.Run(context.Background())
+
+	assert.Equal(t, "deleted 3 orphaned cover file(s), reclaimed 20 B", result)
+	assert.ElementsMatch(t, []string{"kept.jpg", "kept-medium.jpg", "kept-thumb.jpg"}, coverFiles(t, dir))
+}
+
+func TestCoverGC_KeepsPlaceholdersOfCoverlessBooks(t *testing.T) {
+	dir := t.TempDir()
+	books := repotest.NewBookRepository()
+	book := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, books.Create(&book))
+
+	current := covers.PlaceholderName(book.ID, "Dune", "Frank Herbert")
+	// Written before the book's title was corrected.
+	stale := covers.PlaceholderName(book.ID, "Dnue", "Frank Herbert")
+	monthAgo := time.Now().Add(-30 * 24 * time.Hour)
+	for _, name := range []string{current, stale} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("<svg/>"), 0o600))
+		require.NoError(t, os.Chtimes(path, monthAgo, monthAgo))
+	}
+
+	svc := NewCoverGCService(books, repotest.NewAdminRepository(), newLocalStore(t, dir))
+	assert.Equal(t, "deleted 1 orphaned cover file(s), reclaimed 6 B", svc.Run(context.Background()))
+	assert.Equal(t, []string{current}, coverFiles(t, dir))
+}
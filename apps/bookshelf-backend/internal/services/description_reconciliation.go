@@ -51,15 +51,15 @@ func (s *DescriptionReconciliationService) Run(_ context.Context) string {
 	return result
 }
 
-// bucketByWorkKey groups books' indices by bookmatch.NormalizeTitleAuthor.
-// Books with an empty Title or Author are excluded from bucketing.
+// bucketByWorkKey groups books' indices by bookmatch.WorkKey. Books with an
+// empty Title or Author have no work key and are excluded from bucketing.
 func bucketByWorkKey(books []models.Book) map[string][]int {
 	buckets := map[string][]int{}
 	for i, book := range books {
-		if book.Title == "" || book.Author == "" {
+		key := bookmatch.WorkKey(book.Title, book.Author)
+		if key == "" {
 			continue
 		}
-		key := bookmatch.NormalizeTitleAuthor(book.Title, book.Author)
 		buckets[key] = append(buckets[key], i)
 	}
 	return buckets
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+const (
+	// metadataBackfillBatch bounds how many books one Run looks up.
+	metadataBackfillBatch = 50
+	// metadataBackfillDelay spaces lookups out, so a run is a trickle of
+	// requests rather than a burst against providers' rate limits (Google
+	// Books' quota is per day and shared with users' searches). A batch
+	// takes a few minutes.
+	metadataBackfillDelay = 3 * time.Second
+	// metadataBackfillRecheck is how long a book the providers couldn't
+	// complete waits before it's looked up again.
+	metadataBackfillRecheck = 30 * 24 * time.Hour
+)
+
+// backfillFields are the fields MetadataBackfillService fills, by column
+// name, in the order Run reports them.
+var backfillFields = []string{"isbn", "publisher", "published_date", "page_count", "language", "description"}
+
+// MetadataBackfillService fills empty metadata fields on catalog books —
+// typically ones created by a CSV import, which only carries what the
+// exporting library had — by looking each book up in the metadata providers
+// again. It only ever fills an empty field; nothing a user or an earlier
+// lookup set is overwritten.
+type MetadataBackfillService struct {
+	books     repository.BookRepository
+	providers *metadata.Registry
+	apiKeys   map[string]string
+	delay     time.Duration
+	now       func() time.Time
+}
+
+// NewMetadataBackfillService creates a MetadataBackfillService searching
+// providers with the server-wide Google Books key, if any.
+func NewMetadataBackfillService(books repository.BookRepository, providers *metadata.Registry, googleBooksAPIKey string) *MetadataBackfillService {
+	return &MetadataBackfillService{
+		books:     books,
+		providers: providers,
+		apiKeys:   metadata.APIKeys(googleBooksAPIKey),
+		delay:     metadataBackfillDelay,
+		now:       time.Now,
+	}
+}
+
+// Run looks up a batch of incomplete books and fills what it can, returning
+// a summary with per-field fill counts for JobStatus.LastResult, matching
+// the signature RegisterJob expects. Every book some provider answered for
+// is marked checked, filled or not, so the next run moves on to others; one
+// no provider answered for (all failing, or skipped by their circuit
+// breakers) is left to be looked up again.
+func (s *MetadataBackfillService) Run(ctx context.Context) string {
+	books, err := s.books.ListMissingMetadata(s.now().Add(-metadataBackfillRecheck), metadataBackfillBatch)
+	if err != nil {
+		log.Error().Err(err).Msg("metadata-backfill: failed to list incomplete books")
+		return "failed: " + err.Error()
+	}
+
+	filled := map[string]int{}
+	updated, checked, unanswered := 0, 0, 0
+	for i := range books {
+		if ctx.Err() != nil || (i > 0 && !sleepCtx(ctx, s.delay)) {
+			break
+		}
+		book := &books[i]
+		results, answered := s.lookup(ctx, book)
+		if ctx.Err() != nil {
+			// The lookup was cut short; leave the book for the next run.
+			break
+		}
+		if !answered {
+			unanswered++
+			continue
+		}
+		fields := fillEmptyFields(book, results)
+		checkedAt := s.now()
+		book.MetadataCheckedAt = &checkedAt
+		if err := s.books.UpdateFields(book, append(fields, "metadata_checked_at")...); err != nil {
+			log.Warn().Err(err).Uint("book_id", book.ID).Msg("metadata-backfill: failed to save book")
+			continue
+		}
+		checked++
+		if len(fields) > 0 {
+			updated++
+		}
+		for _, f := range fields {
+			filled[f]++
+		}
+	}
+
+	result := fmt.Sprintf("checked %d books, updated %d", checked, updated)
+	if counts := fieldCounts(filled); counts != "" {
+		result += ": " + counts
+	}
+	if unanswered > 0 {
+		result += fmt.Sprintf("; %d left for later, no provider answered", unanswered)
+	}
+	if checked+unanswered < len(books) && ctx.Err() != nil {
+		result += " (stopped early)"
+	}
+	log.Info().Int("checked", checked).Int("updated", updated).Int("unanswered", unanswered).
+		Interface("filled", filled).Msg("metadata-backfill: complete")
+	return result
+}
+
+// lookup returns the provider results describing book's edition, and
+// whether any provider answered at all — with none, an empty result says
+// nothing about the book. With an ISBN on file only results carrying that
+// ISBN count; without one, only results for the same work
+// (bookmatch.WorkKey), narrowed to the single most complete one so fields
+// don't get mixed across editions. A book with neither has nothing to look
+// up, which counts as answered.
+func (s *MetadataBackfillService) lookup(ctx context.Context, book *models.Book) ([]metadata.BookMetadataResult, bool) {
+	if isbn := bookmatch.NormalizeISBN(book.ISBN); isbn != "" {
+		var out []metadata.BookMetadataResult
+		results, report := s.providers.Search(ctx, metadata.Query{Text: isbn, ISBN: isbn}, s.apiKeys)
+		for _, r := range results {
+			if bookmatch.NormalizeISBN(r.ISBN) == isbn {
+				out = append(out, r)
+			}
+		}
+		return out, report.Answered > 0
+	}
+
+	workKey := bookmatch.WorkKey(book.Title, book.Author)
+	if workKey == "" {
+		return nil, true
+	}
+	var best []metadata.BookMetadataResult
+	results, report := s.providers.Search(ctx, metadata.Query{Text: book.Title + " " + book.Author}, s.apiKeys)
+	for _, r := range results {
+		if bookmatch.WorkKey(r.Title, r.Author) != workKey {
+			continue
+		}
+		// Strictly more complete only: on a tie the earlier, higher
+		// priority provider's result stays.
+		if best == nil || completeness(r) > completeness(best[0]) {
+			best = []metadata.BookMetadataResult{r}
+		}
+	}
+	return best, report.Answered > 0
+}
+
+// completeness counts how many of the backfillFields r could fill.
+func completeness(r metadata.BookMetadataResult) int {
+	return len(fillEmptyFields(&models.Book{}, []metadata.BookMetadataResult{r}))
+}
+
+// fillEmptyFields fills each of book's empty backfillFields from the first
+// result (in provider priority order) that has it, returning the names of
+// the fields it filled.
+func fillEmptyFields(book *models.Book, results []metadata.BookMetadataResult) []string {
+	var filled []string
+	fillString := func(name string, dst *string, get func(metadata.BookMetadataResult) string) {
+		if *dst != "" {
+			return
+		}
+		for _, r := range results {
+			if v := strings.TrimSpace(get(r)); v != "" {
+				*dst = v
+				filled = append(filled, name)
+				return
+			}
+		}
+	}
+
+	fillString("isbn", &book.ISBN, func(r metadata.BookMetadataResult) string { return r.ISBN })
+	fillString("publisher", &book.Publisher, func(r metadata.BookMetadataResult) string { return r.Publisher })
+	fillString("published_date", &book.PublishedDate, func(r metadata.BookMetadataResult) string { return r.PublishedDate })
+	if book.PageCount == 0 {
+		for _, r := range results {
+			if r.PageCount > 0 {
+				book.PageCount = r.PageCount
+				filled = append(filled, "page_count")
+				break
+			}
+		}
+	}
+	fillString("language", &book.Language, func(r metadata.BookMetadataResult) string { return r.Language })
+	fillString("description", &book.Description, func(r metadata.BookMetadataResult) string { return r.Description })
+	return filled
+}
+
+// fieldCounts renders per-field fill counts, e.g. "isbn 3, publisher 5",
+// in backfillFields order, omitting fields never filled.
+func fieldCounts(filled map[string]int) string {
+	var parts []string
+	for _, f := range backfillFields {
+		if n := filled[f]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", f, n))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sleepCtx waits d, returning false if ctx is cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
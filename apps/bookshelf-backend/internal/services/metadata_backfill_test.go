@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/metadata"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+// backfillProvider is a metadata.Provider returning fixed results, or err,
+// recording the queries it was sent. onSearch, if set, runs during each
+// search.
+type backfillProvider struct {
+	results  []metadata.BookMetadataResult
+	err      error
+	onSearch func()
+	queries  []metadata.Query
+}
+
+func (p *backfillProvider) Name() string           { return "fake" }
+func (p *backfillProvider) Timeout() time.Duration { return time.Second }
+func (p *backfillProvider) Capabilities() metadata.Capabilities {
+	return metadata.Capabilities{ISBN: true, FreeText: true, Descriptions: true}
+}
+func (p *backfillProvider) Search(_ context.Context, q metadata.Query) ([]metadata.BookMetadataResult, error) {
+	p.queries = append(p.queries, q)
+	if p.onSearch != nil {
+		p.onSearch()
+	}
+	return p.results, p.err
+}
+
+var backfillNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newBackfillDeps(results ...metadata.BookMetadataResult) (*MetadataBackfillService, *repotest.BookRepository, *backfillProvider) {
+	books := repotest.NewBookRepository()
+	provider := &backfillProvider{results: results}
+	svc := NewMetadataBackfillService(books, metadata.NewRegistry(nil, provider), "")
+	svc.delay = 0
+	svc.now = func() time.Time { return backfillNow }
+	return svc, books, provider
+}
+
+func TestMetadataBackfill_FillsOnlyEmptyFieldsFromSameISBN(t *testing.T) {
+	svc, books, _ := newBackfillDeps(
+		metadata.BookMetadataResult{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593", Publisher: "Other Edition", Language: "fr"},
+		metadata.BookMetadataResult{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719", Publisher: "Ace", PageCount: 412, Language: "en", Description: "Desert planet"},
+	)
+	book := models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "978-0-441-17271-9", Description: "Set by a user"}
+	require.NoError(t, books.Create(&book))
+
+	result := svc.Run(context.Background())
+
+	assert.Equal(t, "checked 1 books, updated 1: publisher 1, page_count 1, language 1", result)
+	got, err := books.GetByIDWithCopies(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ace", got.Publisher, "only the result for the book's own ISBN is used")
+	assert.Equal(t, 412, got.PageCount)
+	assert.Equal(t, "en", got.Language)
+	assert.Equal(t, "978-0-441-17271-9", got.ISBN, "filled fields are never rewritten")
+	assert.Equal(t, "Set by a user", got.Description)
+	require.NotNil(t, got.MetadataCheckedAt)
+	assert.True(t, got.MetadataCheckedAt.Equal(backfillNow))
+}
+
+func TestMetadataBackfill_WithoutISBNUsesMostCompleteSameWorkResult(t *testing.T) {
+	svc, books, provider := newBackfillDeps(
+		metadata.BookMetadataResult{Title: "Dune Messiah", Author: "Frank Herbert", ISBN: "9780593098233", Publisher: "Ace", PageCount: 336, Language: "en"},
+		metadata.BookMetadataResult{Title: "Dune", Author: "Frank Herbert", Publisher: "Chilton"},
+		metadata.BookMetadataResult{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719", Publisher: "Ace", PageCount: 412},
+	)
+	book := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, books.Create(&book))
+
+	result := svc.Run(context.Background())
+
+	assert.Equal(t, "checked 1 books, updated 1: isbn 1, publisher 1, page_count 1", result)
+	got, err := books.GetByIDWithCopies(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "9780441172719", got.ISBN)
+	assert.Equal(t, "Ace", got.Publisher, "fields come from one result, not mixed across editions")
+	assert.Equal(t, 412, got.PageCount)
+	assert.Empty(t, got.Language, "other works' results are ignored")
+	require.Len(t, provider.queries, 1)
+	assert.Equal(t, "Dune Frank Herbert", provider.queries[0].Text)
+}
+
+func TestMetadataBackfill_SkipsRecentlyCheckedAndCompleteBooks(t *testing.T) {
+	svc, books, provider := newBackfillDeps()
+	recent := backfillNow.Add(-24 * time.Hour)
+	stale := backfillNow.Add(-metadataBackfillRecheck - time.Hour)
+	require.NoError(t, books.Create(&models.Book{Title: "Checked yesterday", Author: "A", MetadataCheckedAt: &recent}))
+	require.NoError(t, books.Create(&models.Book{Title: "Complete", Author: "B", ISBN: "9780441172719", Publisher: "Ace",
+		PublishedDate: "1990", PageCount: 412, Language: "en", Description: "Desert planet."}))
+	due := models.Book{Title: "Checked long ago", Author: "C", MetadataCheckedAt: &stale}
+	require.NoError(t, books.Create(&due))
+
+	result := svc.Run(context.Background())
+
+	assert.Equal(t, "checked 1 books, updated 0", result)
+	assert.Len(t, provider.queries, 1)
+
+	// The book is now marked checked, so an immediate rerun has nothing to do.
+	assert.Equal(t, "checked 0 books, updated 0", svc.Run(context.Background()))
+	assert.Len(t, provider.queries, 1)
+}
+
+func TestMetadataBackfill_LeavesBooksUncheckedWhenNoProviderAnswers(t *testing.T) {
+	svc, books, provider := newBackfillDeps()
+	provider.err = errors.New("503")
+	book := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, books.Create(&book))
+
+	result := svc.Run(context.Background())
+
+	assert.Equal(t, "checked 0 books, updated 0; 1 left for later, no provider answered", result)
+	got, err := books.GetByIDWithCopies(book.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.MetadataCheckedAt, "an outage doesn't put the book off for the recheck period")
+}
+
+func TestMetadataBackfill_WritesOnlyTheFieldsItFills(t *testing.T) {
+	svc, books, provider := newBackfillDeps(
+		metadata.BookMetadataResult{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719", Publisher: "Ace"},
+	)
+	book := models.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719"}
+	require.NoError(t, books.Create(&book))
+	// The cover-refresh job caches the cover while the lookup is in flight.
+	provider.onSearch = func() {
+		stored, err := books.GetByIDWithCopies(book.ID)
+		require.NoError(t, err)
+		stored.CoverURL = "/api/covers/dune.jpg"
+		require.NoError(t, books.Save(stored))
+	}
+
+	svc.Run(context.Background())
+
+	got, err := books.GetByIDWithCopies(book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ace", got.Publisher)
+	assert.Equal(t, "/api/covers/dune.jpg", got.CoverURL, "a concurrent write to another field survives")
+	assert.NotNil(t, got.MetadataCheckedAt)
+}
+
+func TestMetadataBackfill_StopsWhenCancelled(t *testing.T) {
+	svc, books, provider := newBackfillDeps()
+	book := models.Book{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, books.Create(&book))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := svc.Run(ctx)
+
+	assert.Equal(t, "checked 0 books, updated 0 (stopped early)", result)
+	assert.Empty(t, provider.queries)
+	got, err := books.GetByIDWithCopies(book.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.MetadataCheckedAt, "an interrupted lookup leaves the book for the next run")
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+)
+
+// Caps on how much of the affinity graph is persisted. Nothing reads past
+// the first handful of either list, and without a cap a single prolific
+// borrower would contribute O(n²) affinity rows on their own.
+const (
+	maxRelatedPerBook           = 20
+	maxRecommendationsPerMember = 20
+)
+
+// RecommendationService computes "borrowed together" book affinities from
+// loan history — members who borrowed X also borrowed Y — and, from those,
+// a per-member "you might like" list. Everything is recomputed from scratch
+// on each run and swapped in atomically; nothing is computed at request time.
+type RecommendationService struct {
+	recs repository.RecommendationRepository
+}
+
+// NewRecommendationService creates a RecommendationService.
+func NewRecommendationService(recs repository.RecommendationRepository) *RecommendationService {
+	return &RecommendationService{recs: recs}
+}
+
+// Run recomputes every affinity and recommendation and returns a
+// human-readable summary for JobStatus.LastResult, matching the signature
+// RegisterJob expects.
+func (s *RecommendationService) Run(_ context.Context) string {
+	history, err := s.recs.ListBorrowHistory()
+	if err != nil {
+		log.Error().Err(err).Msg("recommendations: failed to list borrow history")
+		return "failed: " + err.Error()
+	}
+	ownership, err := s.recs.ListOwnership()
+	if err != nil {
+		log.Error().Err(err).Msg("recommendations: failed to list ownership")
+		return "failed: " + err.Error()
+	}
+
+	borrowed := groupByUser(history)
+	owned := groupByUser(ownership)
+
+	affinity := coBorrowAffinity(borrowed)
+	affinities := topAffinities(affinity)
+	recs := memberRecommendations(borrowed, owned, affinity)
+
+	if err := s.recs.ReplaceAll(affinities, recs); err != nil {
+		log.Error().Err(err).Msg("recommendations: failed to store results")
+		return "failed: " + err.Error()
+	}
+
+	members := map[uint]struct{}{}
+	for _, r := range recs {
+		members[r.UserID] = struct{}{}
+	}
+	log.Info().Int("affinities", len(affinities)).Int("members", len(members)).Msg("recommendations: complete")
+	return fmt.Sprintf("stored %d affinities, recommendations for %d members", len(affinities), len(members))
+}
+
+// groupByUser turns (user, book) pairs into a per-user set of book IDs.
+func groupByUser(pairs []repository.UserBook) map[uint]map[uint]struct{} {
+	out := map[uint]map[uint]struct{}{}
+	for _, p := range pairs {
+		if out[p.UserID] == nil {
+			out[p.UserID] = map[uint]struct{}{}
+		}
+		out[p.UserID][p.BookID] = struct{}{}
+	}
+	return out
+}
+
+// coBorrowAffinity counts, for every ordered pair of distinct books, how
+// many members have borrowed both. The result is symmetric.
+func coBorrowAffinity(borrowed map[uint]map[uint]struct{}) map[uint]map[uint]int {
+	affinity := map[uint]map[uint]int{}
+	for _, books := range borrowed {
+		for a := range books {
+			for b := range books {
+				if a == b {
+					continue
+				}
+				if affinity[a] == nil {
+					affinity[a] = map[uint]int{}
+				}
+				affinity[a][b]++
+			}
+		}
+	}
+	return affinity
+}
+
+// scoredBook is a candidate book and its accumulated score, used to rank
+// both the related-books and per-member lists.
+type scoredBook struct {
+	bookID uint
+	score  int
+}
+
+// topScored ranks scores highest first (ties broken by lower book ID, so
+// runs are deterministic) and keeps at most limit entries.
+func topScored(scores map[uint]int, limit int) []scoredBook {
+	ranked := make([]scoredBook, 0, len(scores))
+	for id, score := range scores {
+		ranked = append(ranked, scoredBook{bookID: id, score: score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].bookID < ranked[j].bookID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// topAffinities flattens affinity into rows, keeping only each book's
+// maxRelatedPerBook strongest neighbours.
+func topAffinities(affinity map[uint]map[uint]int) []models.BookAffinity {
+	var out []models.BookAffinity
+	for bookID, related := range affinity {
+		for _, r := range topScored(related, maxRelatedPerBook) {
+			out = append(out, models.BookAffinity{BookID: bookID, RelatedBookID: r.bookID, Score: r.score})
+		}
+	}
+	return out
+}
+
+// memberRecommendations scores, for each member with borrow history, every
+// book co-borrowed with something they've read, summing affinities across
+// everything they've borrowed. Books the member owns or has already
+// borrowed are never recommended.
+func memberRecommendations(borrowed, owned map[uint]map[uint]struct{}, affinity map[uint]map[uint]int) []models.UserRecommendation {
+	var out []models.UserRecommendation
+	for userID, books := range borrowed {
+		scores := map[uint]int{}
+		for read := range books {
+			for candidate, score := range affinity[read] {
+				if _, seen := books[candidate]; seen {
+					continue
+				}
+				if _, own := owned[userID][candidate]; own {
+					continue
+				}
+				scores[candidate] += score
+			}
+		}
+		for _, r := range topScored(scores, maxRecommendationsPerMember) {
+			out = append(out, models.UserRecommendation{UserID: userID, BookID: r.bookID, Score: r.score})
+		}
+	}
+	return out
+}
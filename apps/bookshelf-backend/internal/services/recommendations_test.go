@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repotest"
+)
+
+func newRecommendationDeps() (*RecommendationService, *repotest.RecommendationRepository) {
+	recs := repotest.NewRecommendationRepository(repotest.NewBookRepository())
+	return NewRecommendationService(recs), recs
+}
+
+func affinityScore(affinities []models.BookAffinity, bookID, relatedID uint) int {
+	for _, a := range affinities {
+		if a.BookID == bookID && a.RelatedBookID == relatedID {
+			return a.Score
+		}
+	}
+	return 0
+}
+
+func recommendedBookIDs(recs []models.UserRecommendation, userID uint) []uint {
+	var ids []uint
+	for _, r := range recs {
+		if r.UserID == userID {
+			ids = append(ids, r.BookID)
+		}
+	}
+	return ids
+}
+
+func TestRecommendations_CountsDistinctCoBorrowersSymmetrically(t *testing.T) {
+	svc, recs := newRecommendationDeps()
+	recs.SetBorrowHistory([]repository.UserBook{
+		{UserID: 1, BookID: 10}, {UserID: 1, BookID: 20},
+		{UserID: 2, BookID: 10}, {UserID: 2, BookID: 20}, {UserID: 2, BookID: 30},
+	})
+
+	result := svc.Run(context.Background())
+
+	affinities := recs.Affinities()
+	assert.Equal(t, 2, affinityScore(affinities, 10, 20))
+	assert.Equal(t, 2, affinityScore(affinities, 20, 10))
+	assert.Equal(t, 1, affinityScore(affinities, 10, 30))
+	assert.Equal(t, 1, affinityScore(affinities, 30, 20))
+	assert.Equal(t, 0, affinityScore(affinities, 10, 10), "a book is never related to itself")
+	assert.Contains(t, result, "stored 6 affinities")
+}
+
+func TestRecommendations_ExcludesBooksAlreadyBorrowedOrOwned(t *testing.T) {
+	svc, recs := newRecommendationDeps()
+	recs.SetBorrowHistory([]repository.UserBook{
+		{UserID: 1, BookID: 10}, {UserID: 1, BookID: 20}, {UserID: 1, BookID: 30},
+		{UserID: 2, BookID: 10},
+	})
+	recs.SetOwnership([]repository.UserBook{{UserID: 2, BookID: 30}})
+
+	svc.Run(context.Background())
+
+	assert.Equal(t, []uint{20}, recommendedBookIDs(recs.Recommendations(), 2))
+	assert.Empty(t, recommendedBookIDs(recs.Recommendations(), 1), "member 1 has borrowed everything co-borrowed with their books")
+}
+
+func TestRecommendations_RanksBySummedAffinity(t *testing.T) {
+	svc, recs := newRecommendationDeps()
+	recs.SetBorrowHistory([]repository.UserBook{
+		// 40 is co-borrowed with both of member 9's books; 50 with only one.
+		{UserID: 1, BookID: 10}, {UserID: 1, BookID: 40},
+		{UserID: 2, BookID: 20}, {UserID: 2, BookID: 40},
+		{UserID: 3, BookID: 10}, {UserID: 3, BookID: 50},
+		{UserID: 9, BookID: 10}, {UserID: 9, BookID: 20},
+	})
+
+	svc.Run(context.Background())
+
+	assert.Equal(t, []uint{40, 50}, recommendedBookIDs(recs.Recommendations(), 9))
+}
+
+func TestRecommendations_EmptyHistoryClearsPreviousResults(t *testing.T) {
+	svc, recs := newRecommendationDeps()
+	require.NoError(t, recs.ReplaceAll(
+		[]models.BookAffinity{{BookID: 1, RelatedBookID: 2, Score: 1}},
+		[]models.UserRecommendation{{UserID: 1, BookID: 2, Score: 1}},
+	))
+
+	result := svc.Run(context.Background())
+
+	assert.Empty(t, recs.Affinities())
+	assert.Empty(t, recs.Recommendations())
+	assert.Equal(t, "stored 0 affinities, recommendations for 0 members", result)
+}
@@ -2,12 +2,9 @@ package services
 
 import (
 	"context"
-	"crypto/sha256"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,8 +12,10 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/covers"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/storage"
 )
 
 // defaultInterval is used when no valid interval is configured.
@@ -27,14 +26,21 @@ const defaultInterval = 24 * time.Hour
 // avoiding an unbounded burst of outbound requests.
 const coverRefreshConcurrency = 6
 
+// Errors returned by Scheduler.DryRun.
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrNoDryRun   = errors.New("job has no dry-run mode")
+)
+
 // JobStatus describes the current state of a background job.
 type JobStatus struct {
-	Name       string     `json:"name"`
-	Running    bool       `json:"running"`
-	Interval   string     `json:"interval"`
-	LastRunAt  *time.Time `json:"last_run_at"`
-	NextRunAt  *time.Time `json:"next_run_at"`
-	LastResult string     `json:"last_result"`
+	Name           string     `json:"name"`
+	Running        bool       `json:"running"`
+	Interval       string     `json:"interval"`
+	LastRunAt      *time.Time `json:"last_run_at"`
+	NextRunAt      *time.Time `json:"next_run_at"`
+	LastResult     string     `json:"last_result"`
+	SupportsDryRun bool       `json:"supports_dry_run"` // see Scheduler.DryRun
 }
 
 // nextRunAt computes when a job is next due, given its last run time and
@@ -53,12 +59,12 @@ func nextRunAt(lastRunAt *time.Time, interval time.Duration) *time.Time {
 // named jobs (e.g. "backup") are attached via RegisterJob, sharing the same
 // ticker/trigger/status machinery via the job type below.
 type Scheduler struct {
-	books     repository.BookRepository
-	admin     repository.AdminRepository
-	coversDir string
-	fallback  time.Duration // interval used when setting is absent/invalid
-	client    *http.Client
-	trigger   chan struct{}
+	books      repository.BookRepository
+	admin      repository.AdminRepository
+	coverStore storage.Store // nil disables cover refresh
+	fallback   time.Duration // interval used when setting is absent/invalid
+	client     *http.Client
+	trigger    chan struct{}
 
 	mu         sync.RWMutex
 	running    bool
@@ -74,6 +80,7 @@ type job struct {
 	settingKey string
 	fallback   time.Duration
 	run        func(ctx context.Context) string
+	dryRun     func(ctx context.Context) string // nil if the job has no dry-run mode
 	trigger    chan struct{}
 
 	mu         sync.RWMutex
@@ -85,18 +92,18 @@ type job struct {
 // NewScheduler creates a Scheduler.
 // intervalStr is the fallback duration string (e.g. "24h") used when the
 // admin setting "cover_refresh_interval" is absent. Defaults to 24h on error.
-func NewScheduler(books repository.BookRepository, admin repository.AdminRepository, coversDir, intervalStr string) *Scheduler {
+func NewScheduler(books repository.BookRepository, admin repository.AdminRepository, coverStore storage.Store, intervalStr string) *Scheduler {
 	fallback, err := time.ParseDuration(intervalStr)
 	if err != nil || fallback <= 0 {
 		fallback = defaultInterval
 	}
 	return &Scheduler{
-		books:     books,
-		admin:     admin,
-		coversDir: coversDir,
-		fallback:  fallback,
-		client:    &http.Client{Timeout: 15 * time.Second},
-		trigger:   make(chan struct{}, 1),
+		books:      books,
+		admin:      admin,
+		coverStore: coverStore,
+		fallback:   fallback,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		trigger:    make(chan struct{}, 1),
 	}
 }
 
@@ -128,6 +135,19 @@ func (s *Scheduler) RegisterJob(name, settingKey string, fallback time.Duration,
 	})
 }
 
+// RegisterDryRun gives the RegisterJob-added job name a dry-run mode:
+// dryRun reports what a run would do without doing it, for DryRun. Must be
+// called before Start. Returns false if no job with that name is registered.
+func (s *Scheduler) RegisterDryRun(name string, dryRun func(ctx context.Context) string) bool {
+	for _, j := range s.extra {
+		if j.name == name {
+			j.dryRun = dryRun
+			return true
+		}
+	}
+	return false
+}
+
 // Status returns the current status of every job — the built-in
 // cover-refresh job first, then any RegisterJob-added jobs in registration order.
 func (s *Scheduler) Status() []JobStatus {
@@ -156,12 +176,13 @@ func (j *job) status(interval time.Duration) JobStatus {
 	j.mu.RLock()
 	defer j.mu.RUnlock()
 	return JobStatus{
-		Name:       j.name,
-		Running:    j.running,
-		Interval:   interval.String(),
-		LastRunAt:  j.lastRunAt,
-		NextRunAt:  nextRunAt(j.lastRunAt, interval),
-		LastResult: j.lastResult,
+		Name:           j.name,
+		Running:        j.running,
+		Interval:       interval.String(),
+		LastRunAt:      j.lastRunAt,
+		NextRunAt:      nextRunAt(j.lastRunAt, interval),
+		LastResult:     j.lastResult,
+		SupportsDryRun: j.dryRun != nil,
 	}
 }
 
@@ -202,6 +223,26 @@ func (s *Scheduler) TriggerNow(name string) bool {
 	return false
 }
 
+// DryRun runs the named job's dry-run mode synchronously and returns its
+// result. Unlike TriggerNow it leaves the job's status untouched, and it
+// may run alongside a real run of the same job. Returns ErrUnknownJob if no
+// job with that name is registered, ErrNoDryRun if it has no dry-run mode.
+func (s *Scheduler) DryRun(ctx context.Context, name string) (string, error) {
+	for _, j := range s.extra {
+		if j.name != name {
+			continue
+		}
+		if j.dryRun == nil {
+			return "", ErrNoDryRun
+		}
+		return j.dryRun(ctx), nil
+	}
+	if name == "cover-refresh" {
+		return "", ErrNoDryRun
+	}
+	return "", ErrUnknownJob
+}
+
 // Start launches the scheduler goroutine. It runs until ctx is cancelled.
 // It uses a 1-minute base tick and checks the configured interval on each tick,
 // so interval changes take effect within a minute.
@@ -309,9 +350,10 @@ func (s *Scheduler) runJob(ctx context.Context, j *job) {
 }
 
 // refreshCovers downloads and caches cover images for books that still have
-// external URLs (i.e. not yet cached locally).
+// external URLs (i.e. not yet cached locally), and generates the size
+// variants of cached covers that predate them.
 func (s *Scheduler) refreshCovers(ctx context.Context) {
-	if s.coversDir == "" {
+	if s.coverStore == nil {
 		return
 	}
 	if !s.beginRun() {
@@ -345,7 +387,7 @@ booksLoop:
 		go func(book models.Book) {
 			defer wg.Done()
 			defer func() { <-sem }()
-			if s.refreshBookCover(&book) {
+			if s.refreshBookCover(ctx, &book) {
 				refreshed.Add(1)
 			}
 		}(book)
@@ -387,78 +429,44 @@ func (s *Scheduler) setLastResult(result string) {
 }
 
 // refreshBookCover downloads and caches book's cover if it has one hosted
-// externally (not already a locally-cached path). Returns true if the book
+// externally, or generates the size variants and blurhash of a locally
+// cached one stored before covers.Store made them. Returns true if the book
 // was updated.
-func (s *Scheduler) refreshBookCover(book *models.Book) bool {
-	if book.CoverURL == "" || strings.HasPrefix(book.CoverURL, "/") {
-		return false
-	}
-
-	localPath, dlErr := s.downloadCover(book.CoverURL)
-	if dlErr != nil {
-		log.Warn().Err(dlErr).Uint("book_id", book.ID).Msg("scheduler: cover download failed")
+func (s *Scheduler) refreshBookCover(ctx context.Context, book *models.Book) bool {
+	const localPrefix = "/api/covers/"
+	var stored covers.Stored
+	switch {
+	case book.CoverURL == "":
 		return false
-	}
-	if localPath == "" || localPath == book.CoverURL {
+	case strings.HasPrefix(book.CoverURL, localPrefix):
+		if book.CoverBlurhash != "" {
+			return false
+		}
+		var err error
+		stored, err = covers.Reprocess(ctx, s.coverStore, strings.TrimPrefix(book.CoverURL, localPrefix))
+		if err != nil {
+			// Typically a WebP original, which can't be decoded, or one
+			// over covers.MaxPixels; it's served as-is at every size.
+			log.Debug().Err(err).Uint("book_id", book.ID).Msg("scheduler: cover not reprocessed")
+			return false
+		}
+	case strings.HasPrefix(book.CoverURL, "/"):
 		return false
+	default:
+		var err error
+		stored, err = covers.Download(ctx, s.client, book.CoverURL, s.coverStore)
+		if err != nil {
+			log.Warn().Err(err).Uint("book_id", book.ID).Msg("scheduler: cover download failed")
+			return false
+		}
+		log.Debug().Str("filename", stored.Filename).Msg("scheduler: cover cached")
 	}
 
-	book.CoverURL = localPath
+	book.CoverURL = localPrefix + stored.Filename
+	book.CoverBlurhash = stored.Blurhash
 	if saveErr := s.books.Save(book); saveErr != nil {
 		log.Warn().Err(saveErr).Uint("book_id", book.ID).Msg("scheduler: failed to save cover path")
 		return false
 	}
 	return true
 }
-
-// downloadCover fetches an external image URL and saves it to the coversDir.
-// Returns the proxy-accessible path (/api/covers/<filename>) on success.
-func (s *Scheduler) downloadCover(externalURL string) (string, error) {
-	sum := sha256.Sum256([]byte(externalURL))
-	baseName := fmt.Sprintf("%x", sum[:8])
-
-	resp, err := s.client.Get(externalURL) //nolint:noctx
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close() //nolint:errcheck
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("cover fetch returned %d", resp.StatusCode)
-	}
-
-	ct := resp.Header.Get("Content-Type")
-	if !strings.HasPrefix(ct, "image/") {
-		return "", fmt.Errorf("unexpected content-type %q", ct)
-	}
-
-	ext := ".jpg"
-	if strings.Contains(ct, "png") {
-		ext = ".png"
-	} else if strings.Contains(ct, "webp") {
-		ext = ".webp"
-	}
-
-	filename := baseName + ext
-	destPath := filepath.Join(s.coversDir, filename)
-
-	// Already cached — skip re-download.
-	if _, statErr := os.Stat(destPath); statErr == nil {
-		return "/api/covers/" + filename, nil
-	}
-
-	f, err := os.Create(destPath) //nolint:gosec
-	if err != nil {
-		return "", err
-	}
-	defer f.Close() //nolint:errcheck
-
-	const maxBytes = 10 << 20 // 10 MiB
-	if _, err := io.Copy(f, io.LimitReader(resp.Body, maxBytes)); err != nil {
-		_ = os.Remove(destPath)
-		return "", err
-	}
-
-	log.Debug().Str("filename", filename).Msg("scheduler: cover cached")
-	return "/api/covers/" + filename, nil
-}
@@ -1,9 +1,15 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"image"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -14,19 +20,21 @@ import (
 
 type stubBookRepo struct {
 	repository.BookRepository
-	books []models.Book
-	mu    sync.Mutex
-	saved int
+	books     []models.Book
+	mu        sync.Mutex
+	saved     int
+	lastSaved models.Book
 }
 
 func (r *stubBookRepo) List(_, _ string, _ bool) ([]models.Book, error) {
 	return r.books, nil
 }
 
-func (r *stubBookRepo) Save(_ *models.Book) error {
+func (r *stubBookRepo) Save(book *models.Book) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.saved++
+	r.lastSaved = *book
 	return nil
 }
 
@@ -53,7 +61,7 @@ func TestRefreshCovers_ConcurrentDownloadsSaveAllBooks(t *testing.T) {
 	}
 	repo := &stubBookRepo{books: books}
 
-	sched := NewScheduler(repo, stubAdminRepo{}, t.TempDir(), "24h")
+	sched := NewScheduler(repo, stubAdminRepo{}, newLocalStore(t, t.TempDir()), "24h")
 	sched.refreshCovers(context.Background())
 
 	repo.mu.Lock()
@@ -63,8 +71,37 @@ func TestRefreshCovers_ConcurrentDownloadsSaveAllBooks(t *testing.T) {
 	}
 }
 
+func TestRefreshCovers_GeneratesVariantsForLegacyLocalCover(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 600, 900))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "legacy.png"), buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	repo := &stubBookRepo{books: []models.Book{
+		{ID: 1, CoverURL: "/api/covers/legacy.png"},
+		{ID: 2, CoverURL: "/api/covers/done.jpg", CoverBlurhash: "already-set"},
+	}}
+
+	sched := NewScheduler(repo, stubAdminRepo{}, newLocalStore(t, dir), "24h")
+	sched.refreshCovers(context.Background())
+
+	if repo.saved != 1 {
+		t.Fatalf("expected 1 book saved, got %d", repo.saved)
+	}
+	if repo.lastSaved.CoverURL != "/api/covers/legacy.png" || repo.lastSaved.CoverBlurhash == "" {
+		t.Fatalf("expected original path kept with a blurhash, got %+v", repo.lastSaved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "legacy-thumb.jpg")); err != nil {
+		t.Fatalf("expected thumbnail variant: %v", err)
+	}
+}
+
 func TestScheduler_RegisterJob_StatusIncludesEveryJob(t *testing.T) {
-	sched := NewScheduler(&stubBookRepo{}, stubAdminRepo{}, t.TempDir(), "24h")
+	sched := NewScheduler(&stubBookRepo{}, stubAdminRepo{}, nil, "24h")
 	sched.RegisterJob("backup", "backup_interval", time.Hour, func(context.Context) string {
 		return "ok"
 	})
@@ -85,7 +122,7 @@ func TestScheduler_RegisterJob_StatusIncludesEveryJob(t *testing.T) {
 }
 
 func TestScheduler_TriggerNow_RunsRegisteredJobAndReportsUnknown(t *testing.T) {
-	sched := NewScheduler(&stubBookRepo{}, stubAdminRepo{}, t.TempDir(), "24h")
+	sched := NewScheduler(&stubBookRepo{}, stubAdminRepo{}, nil, "24h")
 	ran := make(chan struct{}, 1)
 	sched.RegisterJob("backup", "backup_interval", time.Hour, func(context.Context) string {
 		ran <- struct{}{}
@@ -109,3 +146,29 @@ func TestScheduler_TriggerNow_RunsRegisteredJobAndReportsUnknown(t *testing.T) {
 		t.Fatal("expected TriggerNow for an unknown job name to return false")
 	}
 }
+
+func TestScheduler_DryRun(t *testing.T) {
+	sched := NewScheduler(&stubBookRepo{}, stubAdminRepo{}, nil, "24h")
+	sched.RegisterJob("backup", "backup_interval", time.Hour, func(context.Context) string {
+		t.Error("a dry run must not call the job's run function")
+		return ""
+	})
+	sched.RegisterJob("cover-gc", "cover_gc_interval", time.Hour, func(context.Context) string { return "" })
+	if !sched.RegisterDryRun("cover-gc", func(context.Context) string { return "would delete 0" }) {
+		t.Fatal("expected RegisterDryRun to find the registered job")
+	}
+
+	result, err := sched.DryRun(context.Background(), "cover-gc")
+	if err != nil || result != "would delete 0" {
+		t.Fatalf("DryRun(cover-gc) = %q, %v", result, err)
+	}
+	if got := sched.Status()[2]; !got.SupportsDryRun || got.LastRunAt != nil {
+		t.Fatalf("expected cover-gc to support dry runs and not be marked as run, got %+v", got)
+	}
+	if _, err := sched.DryRun(context.Background(), "backup"); !errors.Is(err, ErrNoDryRun) {
+		t.Fatalf("expected ErrNoDryRun for backup, got %v", err)
+	}
+	if _, err := sched.DryRun(context.Background(), "no-such-job"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("expected ErrUnknownJob, got %v", err)
+	}
+}
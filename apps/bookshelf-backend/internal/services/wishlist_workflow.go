@@ -8,14 +8,16 @@ import (
 
 	"github.com/rs/zerolog"
 
+	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/bookmatch"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/models"
 	"github.com/tanjd/core-repository/apps/bookshelf-backend/internal/repository"
 )
 
 // WishlistWorkflow orchestrates the side-effects (notification, email) of
 // fulfilling a WishlistRequest, whether triggered automatically (a newly
-// added Book matches an open request's external key) or manually (a member
-// links an existing Book to a near-match request).
+// added Book matches an open request's external key or ISBN) or manually (a
+// member links an existing Book to a near-match request), and of suggesting
+// a newly added Book to a requester as a possible match.
 type WishlistWorkflow struct {
 	requests repository.WishlistRequestRepository
 	notifs   repository.NotificationRepository
@@ -34,9 +36,10 @@ func NewWishlistWorkflow(
 }
 
 // OnBookCreated auto-matches a newly created Book against open
-// WishlistRequests by external key, fulfilling each match. Called from
-// BookHandler.createBook only after a genuinely new Book row is inserted —
-// never on the upsert-return-existing path.
+// WishlistRequests, in two tiers. First by external key, fulfilling each
+// match; then, via matchNear, by ISBN and by title/author similarity.
+// Called from BookHandler.createBook only after a genuinely new Book row is
+// inserted — never on the upsert-return-existing path.
 func (w *WishlistWorkflow) OnBookCreated(ctx context.Context, book *models.Book) {
 	var matches []models.WishlistRequest
 	if book.OLKey != "" {
@@ -53,21 +56,54 @@ func (w *WishlistWorkflow) OnBookCreated(ctx context.Context, book *models.Book)
 			zerolog.Ctx(ctx).Warn().Err(err).Msg("OnBookCreated: find by google_books_id")
 		}
 	}
+	// A request carrying both keys comes back from both lookups; fulfill it once.
+	fulfilled := map[uint]bool{}
 	for i := range matches {
+		if fulfilled[matches[i].ID] {
+			continue
+		}
+		fulfilled[matches[i].ID] = true
 		w.fulfill(ctx, &matches[i], book)
 	}
+	w.matchNear(ctx, book, fulfilled)
+}
+
+// matchNear is OnBookCreated's second tier, over the open requests no
+// external key matched. A request whose ISBN normalizes to the book's names
+// the same edition (ISBN-10 and ISBN-13 alike) and is fulfilled outright.
+// One that merely names the same work — bookmatch.SimilarWork — is only put
+// to its requester as a possible match: another edition, or a sequel with a
+// near-identical title, is a call only they can make.
+func (w *WishlistWorkflow) matchNear(ctx context.Context, book *models.Book, skip map[uint]bool) {
+	open, err := w.requests.ListOpen()
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("OnBookCreated: list open requests")
+		return
+	}
+	isbn := bookmatch.NormalizeISBN(book.ISBN)
+	for i := range open {
+		req := &open[i]
+		switch {
+		case skip[req.ID]:
+		case isbn != "" && bookmatch.NormalizeISBN(req.ISBN) == isbn:
+			w.fulfill(ctx, req, book)
+		case bookmatch.SimilarWork(req.Title, req.Author, book.Title, book.Author):
+			w.suggest(ctx, req, book)
+		}
+	}
 }
 
 // OnFulfilled handles the manual-link path: a member or admin ties an open
 // request to an existing Book that wasn't an automatic key match (e.g. a
-// different edition/ISBN). Shares the same notify side effect as auto-match.
+// different edition/ISBN), or the requester confirms a possible match.
+// Shares the same notify side effect as auto-match.
 func (w *WishlistWorkflow) OnFulfilled(ctx context.Context, req *models.WishlistRequest, book *models.Book) {
 	w.fulfill(ctx, req, book)
 }
 
 // fulfill is the single write site for a request transitioning to
-// "fulfilled" — marks it, notifies the requester in-app, and best-effort
-// emails them. Never called concurrently for the same request in practice
+// "fulfilled" — marks it, then notifies the requester and every
+// co-requester. Never called concurrently for the same request in practice
 // (each request can only match once per external key per book-creation
 // call), so no additional locking is needed here.
 func (w *WishlistWorkflow) fulfill(ctx context.Context, req *models.WishlistRequest, book *models.Book) {
@@ -80,26 +116,92 @@ func (w *WishlistWorkflow) fulfill(ctx context.Context, req *models.WishlistRequ
 		return
 	}
 
+	w.notifyFulfilled(ctx, req.RequesterID, req, book, "")
+
+	coRequesters, err := w.requests.ListCoRequesters(req.ID)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Uint("wishlist_id", req.ID).Msg("fulfill: list co-requesters")
+		return
+	}
+	if len(coRequesters) == 0 {
+		return
+	}
+	// Co-requesters are told whose request they joined — unless it was
+	// posted anonymously, which hides the poster from them as from the board.
+	poster := "another member"
+	if !req.IsAnonymous {
+		if requester, err := w.users.FindByID(req.RequesterID); err == nil {
+			poster = requester.Name
+		}
+	}
+	for _, co := range coRequesters {
+		w.notifyFulfilled(ctx, co.UserID, req, book, poster)
+	}
+}
+
+// notifyFulfilled tells recipientID that req has been fulfilled by book: a
+// wishlist_fulfilled notification, and a best-effort email. poster is empty
+// for the requester themselves, else the name of whoever posted the request
+// the recipient joined.
+func (w *WishlistWorkflow) notifyFulfilled(ctx context.Context, recipientID uint, req *models.WishlistRequest, book *models.Book, poster string) {
 	n := models.Notification{
-		RecipientID:       req.RequesterID,
+		RecipientID:       recipientID,
 		Type:              "wishlist_fulfilled",
 		WishlistRequestID: &req.ID,
 	}
 	if err := w.notifs.Create(&n); err != nil {
-		zerolog.Ctx(ctx).Warn().Err(err).Msg("fulfill: create notification")
+		zerolog.Ctx(ctx).Warn().Err(err).Uint("recipient_id", recipientID).Msg("fulfill: create notification")
 	}
 
-	requester, err := w.users.FindByID(req.RequesterID)
+	recipient, err := w.users.FindByID(recipientID)
 	if err != nil {
-		zerolog.Ctx(ctx).Warn().Err(err).Uint("requester_id", req.RequesterID).Msg("fulfill: load requester")
+		zerolog.Ctx(ctx).Warn().Err(err).Uint("recipient_id", recipientID).Msg("fulfill: load recipient")
 		return // email is best-effort; don't fail the fulfillment
 	}
 
+	wanted := "which you were looking for"
+	if poster != "" {
+		wanted = fmt.Sprintf("which you joined %s in looking for", html.EscapeString(poster))
+	}
 	subject := "A book you were looking for is now in the catalog"
 	body := fmt.Sprintf(
-		"<p>Hi %s,</p><p><strong>%s</strong> by %s, which you were looking for, has been added to the catalog. "+
+		"<p>Hi %s,</p><p><strong>%s</strong> by %s, %s, has been added to the catalog. "+
 			"Visit the book's page to request to borrow it.</p>",
+		html.EscapeString(recipient.Name), html.EscapeString(book.Title), html.EscapeString(book.Author), wanted,
+	) + w.email.Button(fmt.Sprintf("/catalog/%d", book.ID), "View book")
+	if recipient.EmailNotificationsEnabled {
+		w.email.SendEmailAsync(ctx, recipient.Email, subject, body)
+	}
+}
+
+// suggest tells req's requester that book may be the one they asked for:
+// in-app, carrying BookID so they can confirm it (POST
+// /wishlist/{id}/fulfill), and best-effort by email. req stays open until
+// they do.
+func (w *WishlistWorkflow) suggest(ctx context.Context, req *models.WishlistRequest, book *models.Book) {
+	n := models.Notification{
+		RecipientID:       req.RequesterID,
+		Type:              "wishlist_possible_match",
+		WishlistRequestID: &req.ID,
+		BookID:            &book.ID,
+	}
+	if err := w.notifs.Create(&n); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Uint("wishlist_id", req.ID).Msg("suggest: create notification")
+	}
+
+	requester, err := w.users.FindByID(req.RequesterID)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Uint("requester_id", req.RequesterID).Msg("suggest: load requester")
+		return
+	}
+
+	subject := "A possible match for a book you were looking for"
+	body := fmt.Sprintf(
+		"<p>Hi %s,</p><p><strong>%s</strong> by %s has been added to the catalog, and may be the "+
+			"<strong>%s</strong> you were looking for. If it is, confirm it from your notifications "+
+			"to close your wishlist request.</p>",
 		html.EscapeString(requester.Name), html.EscapeString(book.Title), html.EscapeString(book.Author),
+		html.EscapeString(req.Title),
 	) + w.email.Button(fmt.Sprintf("/catalog/%d", book.ID), "View book")
 	if requester.EmailNotificationsEnabled {
 		w.email.SendEmailAsync(ctx, requester.Email, subject, body)
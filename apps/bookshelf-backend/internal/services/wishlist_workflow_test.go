@@ -95,6 +95,92 @@ func TestOnBookCreated_IgnoresNonOpenRequests(t *testing.T) {
 	assert.Equal(t, 0, d.notifs.Count())
 }
 
+func TestOnBookCreated_RequestWithBothKeysFulfilledOnce(t *testing.T) {
+	d := newWishlistWorkflow()
+	requester := &models.User{Name: "Requester", Email: "req@example.com"}
+	require.NoError(t, d.users.Create(requester))
+	req := &models.WishlistRequest{RequesterID: requester.ID, Title: "Wanted Book", Author: "A", OLKey: "OL123", GoogleBooksID: "GB1", Status: "open"}
+	require.NoError(t, d.requests.Create(req))
+
+	book := &models.Book{ID: 42, Title: "Wanted Book", Author: "A", OLKey: "OL123", GoogleBooksID: "GB1"}
+	d.workflow.OnBookCreated(context.Background(), book)
+
+	assert.Equal(t, 1, d.notifs.Count())
+}
+
+func TestOnBookCreated_AutoMatchesByNormalizedISBN(t *testing.T) {
+	d := newWishlistWorkflow()
+	requester := &models.User{Name: "Requester", Email: "req@example.com"}
+	require.NoError(t, d.users.Create(requester))
+	req := &models.WishlistRequest{RequesterID: requester.ID, Title: "Wanted Book", Author: "A", ISBN: "0-306-40615-2", Status: "open"}
+	require.NoError(t, d.requests.Create(req))
+
+	book := &models.Book{ID: 42, Title: "Wanted Book (Reissue)", Author: "A", ISBN: "9780306406157"}
+	d.workflow.OnBookCreated(context.Background(), book)
+
+	reloaded, err := d.requests.GetByID(req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "fulfilled", reloaded.Status)
+	notifs, err := d.notifs.FindByRecipient(requester.ID, false)
+	require.NoError(t, err)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "wishlist_fulfilled", notifs[0].Type)
+}
+
+func TestOnBookCreated_SimilarWorkSuggestsWithoutFulfilling(t *testing.T) {
+	d := newWishlistWorkflow()
+	requester := &models.User{Name: "Requester", Email: "req@example.com"}
+	require.NoError(t, d.users.Create(requester))
+	req := &models.WishlistRequest{RequesterID: requester.ID, Title: "The Hobbit", Author: "Tolkien", OLKey: "OL1W", Status: "open"}
+	require.NoError(t, d.requests.Create(req))
+	other := &models.WishlistRequest{RequesterID: requester.ID, Title: "Dune", Author: "Frank Herbert", Status: "open"}
+	require.NoError(t, d.requests.Create(other))
+
+	book := &models.Book{ID: 42, Title: "The Hobbit, or There and Back Again", Author: "J. R. R. Tolkien", OLKey: "OL2W"}
+	d.workflow.OnBookCreated(context.Background(), book)
+
+	reloaded, err := d.requests.GetByID(req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "open", reloaded.Status)
+	assert.Nil(t, reloaded.FulfilledBookID)
+
+	notifs, err := d.notifs.FindByRecipient(requester.ID, false)
+	require.NoError(t, err)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "wishlist_possible_match", notifs[0].Type)
+	require.NotNil(t, notifs[0].WishlistRequestID)
+	assert.Equal(t, req.ID, *notifs[0].WishlistRequestID)
+	require.NotNil(t, notifs[0].BookID)
+	assert.Equal(t, book.ID, *notifs[0].BookID)
+}
+
+func TestOnBookCreated_NotifiesCoRequesters(t *testing.T) {
+	d := newWishlistWorkflow()
+	requester := &models.User{Name: "Requester", Email: "req@example.com"}
+	alice := &models.User{Name: "Alice", Email: "alice@example.com"}
+	bob := &models.User{Name: "Bob", Email: "bob@example.com"}
+	require.NoError(t, d.users.Create(requester))
+	require.NoError(t, d.users.Create(alice))
+	require.NoError(t, d.users.Create(bob))
+	req := &models.WishlistRequest{RequesterID: requester.ID, Title: "Wanted Book", Author: "A", OLKey: "OL123", Status: "open", IsAnonymous: true}
+	require.NoError(t, d.requests.Create(req))
+	require.NoError(t, d.requests.Join(req.ID, alice.ID))
+	require.NoError(t, d.requests.Join(req.ID, bob.ID))
+
+	book := &models.Book{ID: 42, Title: "Wanted Book", Author: "A", OLKey: "OL123"}
+	d.workflow.OnBookCreated(context.Background(), book)
+
+	assert.Equal(t, 3, d.notifs.Count())
+	for _, u := range []*models.User{requester, alice, bob} {
+		notifs, err := d.notifs.FindByRecipient(u.ID, false)
+		require.NoError(t, err)
+		require.Len(t, notifs, 1, u.Name)
+		assert.Equal(t, "wishlist_fulfilled", notifs[0].Type)
+		require.NotNil(t, notifs[0].WishlistRequestID)
+		assert.Equal(t, req.ID, *notifs[0].WishlistRequestID)
+	}
+}
+
 func TestOnFulfilled_ManualLink(t *testing.T) {
 	d := newWishlistWorkflow()
 	requester := &models.User{Name: "Requester", Email: "req@example.com"}
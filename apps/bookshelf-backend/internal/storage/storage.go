@@ -0,0 +1,53 @@
+// Package storage is where the server keeps files that aren't rows in the
+// database — cached cover images and backup archives — behind a small
+// object-store interface, so they can live on local disk (Local) or in an
+// S3-compatible bucket (S3) without callers knowing which.
+//
+// A Store is a flat namespace: keys are plain file names, without
+// directories. Each kind of file gets its own Store (its own directory, or
+// its own key prefix in the bucket).
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get and Stat for a key with no object.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrInvalidKey is returned for a key that isn't a plain file name.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+// Store is a flat namespace of objects.
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any existing
+	// object. Readers never see a partially written object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens key's object; the caller closes it. ErrNotFound if absent.
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+	// Stat describes key's object. ErrNotFound if absent.
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// Delete removes key's object. Deleting an absent key isn't an error.
+	Delete(ctx context.Context, key string) error
+	// List describes every object whose key starts with prefix, in key
+	// order.
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ValidKey reports whether key is a plain file name: non-empty, no path
+// separators, and not starting with a dot (Local's temporary files do).
+func ValidKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, ".") && !strings.ContainsAny(key, `/\`)
+}
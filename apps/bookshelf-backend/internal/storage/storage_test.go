@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore runs the behaviour every Store implementation must share.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	put := func(key, body string) {
+		t.Helper()
+		require.NoError(t, store.Put(ctx, key, strings.NewReader(body), int64(len(body)), "image/jpeg"))
+	}
+
+	t.Run("put then get", func(t *testing.T) {
+		put("abc.jpg", "cover bytes")
+
+		r, info, err := store.Get(ctx, "abc.jpg")
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Equal(t, "cover bytes", string(data))
+		assert.Equal(t, int64(11), info.Size)
+		assert.Equal(t, "image/jpeg", info.ContentType)
+		assert.False(t, info.ModTime.IsZero())
+	})
+
+	t.Run("put replaces", func(t *testing.T) {
+		put("replaced.jpg", "first")
+		put("replaced.jpg", "second")
+
+		info, err := store.Stat(ctx, "replaced.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), info.Size)
+	})
+
+	t.Run("empty object", func(t *testing.T) {
+		put("empty.jpg", "")
+
+		info, err := store.Stat(ctx, "empty.jpg")
+		require.NoError(t, err)
+		assert.Zero(t, info.Size)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, _, err := store.Get(ctx, "missing.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = store.Stat(ctx, "missing.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, store.Delete(ctx, "missing.jpg"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		put("gone.jpg", "x")
+		require.NoError(t, store.Delete(ctx, "gone.jpg"))
+
+		_, err := store.Stat(ctx, "gone.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("list by prefix in key order", func(t *testing.T) {
+		put("bookshelf-backup-2.tar.gz", "22")
+		put("bookshelf-backup-1.tar.gz", "1")
+		put("bookshelf-backup-3.tar.gz", "333")
+
+		infos, err := store.List(ctx, "bookshelf-backup-")
+		require.NoError(t, err)
+		var keys []string
+		for _, info := range infos {
+			keys = append(keys, info.Key)
+		}
+		assert.Equal(t, []string{"bookshelf-backup-1.tar.gz", "bookshelf-backup-2.tar.gz", "bookshelf-backup-3.tar.gz"}, keys)
+		assert.Equal(t, int64(3), infos[2].Size)
+		assert.False(t, infos[0].ModTime.IsZero())
+	})
+
+	t.Run("rejects keys that aren't file names", func(t *testing.T) {
+		for _, key := range []string{"", "../escape.jpg", "dir/file.jpg", ".hidden"} {
+			err := store.Put(ctx, key, strings.NewReader("x"), 1, "")
+			assert.ErrorIs(t, err, ErrInvalidKey, key)
+			_, _, err = store.Get(ctx, key)
+			assert.ErrorIs(t, err, ErrInvalidKey, key)
+		}
+	})
+}
+
+func TestLocal(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	testStore(t, store)
+}
@@ -1,9 +1,16 @@
 "use client";
 
 import { useEffect, useState, useCallback, useRef } from "react";
-import { RefreshCw, Download, Trash2, Info } from "lucide-react";
+import { RefreshCw, Download, Trash2, Info, Upload } from "lucide-react";
 import { toast } from "sonner";
-import { api, downloadBackup } from "@/lib/api";
+import {
+  api,
+  downloadBackup,
+  downloadLibraryExport,
+  downloadMARCExport,
+  type LibraryRestoreStats,
+  type MARCExportFormat,
+} from "@/lib/api";
 import type { JobStatus, BackupInfo } from "@/lib/types";
 import { timeAgo } from "@/lib/timeFormat";
 import { Button } from "@/components/ui/button";
@@ -40,6 +47,15 @@ function formatSize(bytes: number): string {
   return `${value.toFixed(1)} ${units[i]}`;
 }
 
+const RESTORE_LABELS: [keyof LibraryRestoreStats, string][] = [
+  ["users", "Members"],
+  ["books", "Books"],
+  ["copies", "Copies"],
+  ["loan_requests", "Loans"],
+  ["wishlist_requests", "Wishlist requests"],
+  ["wishlist_co_requesters", "Wishlist co-requesters"],
+];
+
 export default function AdminBackupsPage() {
   const [job, setJob] = useState<JobStatus | null>(null);
   const [backups, setBackups] = useState<BackupInfo[]>([]);
@@ -57,6 +73,19 @@ export default function AdminBackupsPage() {
 
   const [restoreDialogOpen, setRestoreDialogOpen] = useState(false);
 
+  const [exportingLibrary, setExportingLibrary] = useState(false);
+  const [libraryFile, setLibraryFile] = useState<File | null>(null);
+  const [importingLibrary, setImportingLibrary] = useState(false);
+  const [libraryStats, setLibraryStats] = useState<LibraryRestoreStats | null>(
+    null,
+  );
+  const libraryFileRef = useRef<HTMLInputElement>(null);
+
+  const [marcLocation, setMarcLocation] = useState("");
+  const [exportingMARC, setExportingMARC] = useState<MARCExportFormat | null>(
+    null,
+  );
+
   const loadJobAndBackups = useCallback(async () => {
     try {
       const [jobs, list] = await Promise.all([
@@ -190,6 +219,42 @@ export default function AdminBackupsPage() {
     }
   }
 
+  async function handleExportLibrary() {
+    setExportingLibrary(true);
+    try {
+      await downloadLibraryExport();
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "Export failed");
+    } finally {
+      setExportingLibrary(false);
+    }
+  }
+
+  async function handleExportMARC(format: MARCExportFormat) {
+    setExportingMARC(format);
+    try {
+      await downloadMARCExport(format, marcLocation.trim());
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "Export failed");
+    } finally {
+      setExportingMARC(null);
+    }
+  }
+
+  async function handleImportLibrary() {
+    if (!libraryFile) return;
+    setImportingLibrary(true);
+    try {
+      const stats = await api.adminImportLibrary(await libraryFile.text());
+      setLibraryFile(null);
+      setLibraryStats(stats);
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "Import failed");
+    } finally {
+      setImportingLibrary(false);
+    }
+  }
+
   if (loading) {
     return (
       <div className="flex flex-col gap-3">
@@ -363,6 +428,159 @@ export default function AdminBackupsPage() {
         </table>
       </div>
 
+      {/* Library migration */}
+      <div className="rounded-lg border bg-card p-4 flex flex-col gap-3">
+        <div>
+          <h3 className="font-medium">Move to another instance</h3>
+          <p className="text-sm text-muted-foreground">
+            Export members, books, copies, loan history and the wishlist as one
+            JSON file, then import it on the new instance. Anything it already
+            has is updated from the file rather than duplicated, so importing
+            a newer export brings it up to date and importing twice is safe.
+            Loans and copies left behind by deleted members or copies are
+            skipped.
+            Cached cover images don&apos;t come along. The file contains
+            members&apos; password hashes — keep it as safe as a backup.
+          </p>
+        </div>
+        <div className="flex flex-wrap gap-2">
+          <Button
+            size="sm"
+            variant="outline"
+            onClick={handleExportLibrary}
+            disabled={exportingLibrary}
+          >
+            <Download className="size-3" />
+            {exportingLibrary ? "Exporting…" : "Export library"}
+          </Button>
+          <Button
+            size="sm"
+            variant="outline"
+            onClick={() => libraryFileRef.current?.click()}
+          >
+            <Upload className="size-3" /> Import library…
+          </Button>
+          <input
+            ref={libraryFileRef}
+            type="file"
+            accept=".json,application/json"
+            className="hidden"
+            onChange={(e) => {
+              setLibraryFile(e.target.files?.[0] ?? null);
+              e.target.value = "";
+            }}
+          />
+        </div>
+      </div>
+
+      {/* MARC catalog export */}
+      <div className="rounded-lg border bg-card p-4 flex flex-col gap-3">
+        <div>
+          <h3 className="font-medium">Share the catalog with another library</h3>
+          <p className="text-sm text-muted-foreground">
+            Download every book as a MARC record, with each copy as a holding,
+            to load into another library&apos;s catalog system. Owners
+            aren&apos;t included.
+          </p>
+        </div>
+        <div className="flex flex-wrap items-center gap-2">
+          <Input
+            value={marcLocation}
+            onChange={(e) => setMarcLocation(e.target.value)}
+            placeholder="Location (default: Bookshelf)"
+            maxLength={200}
+            className="h-8 text-sm w-64"
+          />
+          <Button
+            size="sm"
+            variant="outline"
+            onClick={() => handleExportMARC("marcxml")}
+            disabled={exportingMARC !== null}
+          >
+            <Download className="size-3" />
+            {exportingMARC === "marcxml" ? "Exporting…" : "MARCXML"}
+          </Button>
+          <Button
+            size="sm"
+            variant="outline"
+            onClick={() => handleExportMARC("marc21")}
+            disabled={exportingMARC !== null}
+          >
+            <Download className="size-3" />
+            {exportingMARC === "marc21" ? "Exporting…" : "MARC21"}
+          </Button>
+        </div>
+      </div>
+
+      {/* Library import confirm dialog */}
+      <Dialog
+        open={!!libraryFile}
+        onOpenChange={(open) => !open && setLibraryFile(null)}
+      >
+        <DialogContent>
+          <DialogHeader>
+            <DialogTitle>Import this library?</DialogTitle>
+            <DialogDescription>
+              {libraryFile
+                ? `Adds the members, books, copies, loans and wishlist in "${libraryFile.name}" that this instance doesn't already have. Existing records are left as they are.`
+                : ""}
+            </DialogDescription>
+          </DialogHeader>
+          <DialogFooter showCloseButton>
+            <Button onClick={handleImportLibrary} disabled={importingLibrary}>
+              {importingLibrary ? "Importing…" : "Import"}
+            </Button>
+          </DialogFooter>
+        </DialogContent>
+      </Dialog>
+
+      {/* Library import result dialog */}
+      <Dialog
+        open={!!libraryStats}
+        onOpenChange={(open) => !open && setLibraryStats(null)}
+      >
+        <DialogContent>
+          <DialogHeader>
+            <DialogTitle>Library imported</DialogTitle>
+          </DialogHeader>
+          {libraryStats && (
+            <table className="w-full text-sm">
+              <thead>
+                <tr className="border-b">
+                  <th className="py-2 text-left font-medium" />
+                  <th className="py-2 text-right font-medium">Added</th>
+                  <th className="py-2 text-right font-medium">Updated</th>
+                  <th className="py-2 text-right font-medium">
+                    Already here
+                  </th>
+                  <th className="py-2 text-right font-medium">Skipped</th>
+                </tr>
+              </thead>
+              <tbody>
+                {RESTORE_LABELS.map(([key, label]) => (
+                  <tr key={key} className="border-b last:border-0">
+                    <td className="py-2">{label}</td>
+                    <td className="py-2 text-right">
+                      {libraryStats[key].created}
+                    </td>
+                    <td className="py-2 text-right">
+                      {libraryStats[key].updated}
+                    </td>
+                    <td className="py-2 text-right text-muted-foreground">
+                      {libraryStats[key].matched}
+                    </td>
+                    <td className="py-2 text-right text-muted-foreground">
+                      {libraryStats[key].skipped}
+                    </td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          )}
+          <DialogFooter showCloseButton />
+        </DialogContent>
+      </Dialog>
+
       {/* Delete confirm dialog */}
       <Dialog
         open={!!deleteTarget}
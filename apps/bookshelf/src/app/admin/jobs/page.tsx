@@ -20,6 +20,31 @@ const JOB_META: Record<string, { label: string; description: string }> = {
     description:
       "Fills in missing book descriptions from other editions of the same book. Runs automatically on the configured interval.",
   },
+  recommendations: {
+    label: "Borrowed-Together Recommendations",
+    description:
+      "Recomputes related books and per-member suggestions from loan history. Runs automatically on the configured interval.",
+  },
+  contributors: {
+    label: "Author Linking",
+    description:
+      "Links books that have no structured authors yet to author records, parsing co-authors, editors and translators from the author line.",
+  },
+  "metadata-backfill": {
+    label: "Metadata Backfill",
+    description:
+      "Looks up books missing an ISBN, publisher, publication date, page count, language or description in the metadata providers and fills in the empty fields, a batch per run. Existing values are never overwritten.",
+  },
+  "cover-gc": {
+    label: "Orphaned Cover Cleanup",
+    description:
+      "Deletes cached cover images no book uses any more, once they are older than the grace period (7 days unless cover_gc_grace_period is set). Use Dry Run to see what would be deleted.",
+  },
+  "account-deletion": {
+    label: "Account Deletion",
+    description:
+      "Deletes the accounts members asked to delete once their 14-day grace period is over, anonymizing them on loan history. A member with a copy out on loan is retried on the next run.",
+  },
 };
 
 const INTERVAL_PRESETS = ["1h", "6h", "12h", "24h", "48h", "168h"];
@@ -35,6 +60,10 @@ const INTERVAL_LABELS: Record<string, string> = {
 const JOB_SETTING_KEYS: Record<string, string> = {
   "cover-refresh": "cover_refresh_interval",
   "description-reconciliation": "description_reconciliation_interval",
+  recommendations: "recommendations_interval",
+  contributors: "contributors_interval",
+  "metadata-backfill": "metadata_backfill_interval",
+  "cover-gc": "cover_gc_interval",
 };
 
 export default function AdminJobsPage() {
@@ -42,6 +71,7 @@ export default function AdminJobsPage() {
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState("");
   const [triggering, setTriggering] = useState<string | null>(null);
+  const [dryRunning, setDryRunning] = useState<string | null>(null);
 
   const loadJobs = useCallback(async () => {
     try {
@@ -102,6 +132,18 @@ export default function AdminJobsPage() {
     }
   }
 
+  async function handleDryRun(jobName: string) {
+    setDryRunning(jobName);
+    try {
+      const { result } = await api.adminDryRunJob(jobName);
+      toast.info(result);
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "Dry run failed");
+    } finally {
+      setDryRunning(null);
+    }
+  }
+
   async function handleSaveInterval(jobName: string, value: string) {
     const settingKey = JOB_SETTING_KEYS[jobName];
     if (!settingKey) return;
@@ -165,6 +207,8 @@ export default function AdminJobsPage() {
               status={job}
               onRunNow={() => handleRun(job.name)}
               triggering={triggering === job.name}
+              onDryRun={() => handleDryRun(job.name)}
+              dryRunning={dryRunning === job.name}
               intervalPresets={INTERVAL_PRESETS}
               intervalLabels={INTERVAL_LABELS}
               onSaveInterval={(value) => handleSaveInterval(job.name, value)}
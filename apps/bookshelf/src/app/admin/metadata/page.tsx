@@ -1,18 +1,20 @@
 "use client";
 
 import { useEffect, useState, useCallback, useRef } from "react";
-import { RefreshCw } from "lucide-react";
+import { ArrowDown, ArrowUp, RefreshCw, Trash2 } from "lucide-react";
 import { api } from "@/lib/api";
-import type { MetadataProviderStatus } from "@/lib/types";
+import type { MetadataCacheStats, MetadataProviderStatus } from "@/lib/types";
 import { Button } from "@/components/ui/button";
 import { Badge } from "@/components/ui/badge";
 import { Skeleton } from "@/components/ui/skeleton";
+import { Switch } from "@/components/ui/switch";
+import { timeAgo } from "@/lib/timeFormat";
 
 const PROVIDER_META: Record<string, { label: string; description: string }> = {
   openlibrary: {
     label: "Open Library",
     description:
-      "Free, open book catalog. Always enabled. Provides title, author, ISBN, and cover images.",
+      "Free, open book catalog. Provides title, author, ISBN, and cover images.",
   },
   google_books: {
     label: "Google Books",
@@ -22,20 +24,44 @@ const PROVIDER_META: Record<string, { label: string; description: string }> = {
   bookbrainz: {
     label: "BookBrainz",
     description:
-      "MusicBrainz's open book database. Always enabled. No cover images.",
+      "MusicBrainz's open book database. No cover images.",
   },
 };
 
+// Mirrors the metadata.SettingPriority / metadata.SettingDisabled keys in
+// internal/metadata/registry.go.
+const PRIORITY_SETTING = "metadata_provider_priority";
+const DISABLED_SETTING = "metadata_providers_disabled";
+
+function formatTTL(seconds: number): string {
+  if (seconds % 86400 === 0) return `${seconds / 86400}d`;
+  if (seconds % 3600 === 0) return `${seconds / 3600}h`;
+  return `${Math.round(seconds / 60)}m`;
+}
+
+function capabilityLabels(s: MetadataProviderStatus): string[] {
+  const labels: string[] = [];
+  if (s.capabilities.isbn) labels.push("ISBN");
+  if (s.capabilities.free_text) labels.push("Title/author");
+  if (s.capabilities.descriptions) labels.push("Descriptions");
+  if (s.capabilities.requires_api_key) labels.push("API key");
+  return labels;
+}
+
 export default function AdminMetadataPage() {
   const [statuses, setStatuses] = useState<MetadataProviderStatus[]>([]);
+  const [cache, setCache] = useState<MetadataCacheStats | null>(null);
+  const [purging, setPurging] = useState(false);
   const [loading, setLoading] = useState(true);
   const [checking, setChecking] = useState(false);
   const [error, setError] = useState("");
+  const [saving, setSaving] = useState(false);
 
   const loadStatuses = useCallback(async () => {
     try {
       const data = await api.adminGetMetadataStatus();
-      setStatuses(data);
+      setStatuses(data.providers);
+      setCache(data.cache);
       setError("");
     } catch (err) {
       setError(
@@ -59,6 +85,59 @@ export default function AdminMetadataPage() {
     await loadStatuses();
   }
 
+  // Saves the provider order and disabled set, then re-probes so the page
+  // shows the providers as searches will now use them.
+  async function saveProviders(next: MetadataProviderStatus[]) {
+    setSaving(true);
+    setStatuses(next);
+    try {
+      await api.adminUpdateSettings([
+        { key: PRIORITY_SETTING, value: next.map((s) => s.name).join(",") },
+        {
+          key: DISABLED_SETTING,
+          value: next
+            .filter((s) => s.disabled)
+            .map((s) => s.name)
+            .join(","),
+        },
+      ]);
+      setChecking(true);
+      await loadStatuses();
+    } catch (err) {
+      setError(
+        err instanceof Error ? err.message : "Failed to save provider settings",
+      );
+    } finally {
+      setSaving(false);
+    }
+  }
+
+  async function handlePurge() {
+    if (!confirm("Drop every cached metadata search?")) return;
+    setPurging(true);
+    try {
+      await api.adminPurgeMetadataCache();
+      await loadStatuses();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to purge cache");
+    } finally {
+      setPurging(false);
+    }
+  }
+
+  function handleMove(index: number, delta: number) {
+    const next = [...statuses];
+    const [moved] = next.splice(index, 1);
+    next.splice(index + delta, 0, moved);
+    saveProviders(next);
+  }
+
+  function handleToggle(index: number, enabled: boolean) {
+    saveProviders(
+      statuses.map((s, i) => (i === index ? { ...s, disabled: !enabled } : s)),
+    );
+  }
+
   if (loading) {
     return (
       <div className="flex flex-col gap-3">
@@ -89,8 +168,9 @@ export default function AdminMetadataPage() {
     <div className="flex flex-col gap-4">
       <div className="flex items-center justify-between">
         <p className="text-sm text-muted-foreground">
-          Live reachability check for each metadata provider. Results are not
-          cached.
+          Live reachability check for each metadata provider, in search
+          priority order: where providers disagree, the higher one wins.
+          Reachability checks are not cached.
         </p>
         <Button
           variant="ghost"
@@ -103,8 +183,39 @@ export default function AdminMetadataPage() {
         </Button>
       </div>
 
+      {cache && (
+        <div className="rounded-lg border bg-card p-4 flex items-start justify-between gap-4">
+          <div className="flex flex-col gap-1 min-w-0">
+            <p className="font-medium text-sm">Search cache</p>
+            <p className="text-xs text-muted-foreground">
+              {cache.entries.toLocaleString()}
+              {cache.max_entries > 0 &&
+                ` / ${cache.max_entries.toLocaleString()}`}{" "}
+              cached searches · {formatTTL(cache.ttl_seconds)} TTL ·{" "}
+              {cache.backend === "sqlite" ? "kept across restarts" : "in memory"}
+            </p>
+            <p className="text-xs text-muted-foreground">
+              {cache.hits.toLocaleString()} hits ·{" "}
+              {cache.misses.toLocaleString()} misses since startup
+              {cache.hits + cache.misses > 0 &&
+                ` (${Math.round((cache.hits / (cache.hits + cache.misses)) * 100)}% hit rate)`}
+            </p>
+          </div>
+          <Button
+            variant="outline"
+            size="sm"
+            onClick={handlePurge}
+            disabled={purging || cache.entries === 0}
+            className="shrink-0"
+          >
+            <Trash2 className="size-3.5" />
+            {purging ? "Purging…" : "Purge"}
+          </Button>
+        </div>
+      )}
+
       <div className="flex flex-col gap-3">
-        {statuses.map((s) => {
+        {statuses.map((s, i) => {
           const meta = PROVIDER_META[s.name];
           return (
             <div
@@ -131,20 +242,80 @@ export default function AdminMetadataPage() {
                         {s.reachable ? "Reachable" : "Unreachable"}
                       </Badge>
                     )}
+                    {s.health.state !== "closed" && (
+                      <Badge
+                        variant="destructive"
+                        className="text-[10px] px-1.5 py-0"
+                        title={
+                          s.health.open_until &&
+                          `Next trial search after ${new Date(s.health.open_until).toLocaleTimeString()}`
+                        }
+                      >
+                        {s.health.state === "open"
+                          ? "Circuit open — skipped"
+                          : "Circuit half-open"}
+                      </Badge>
+                    )}
                   </div>
                   {meta?.description && (
                     <p className="text-xs text-muted-foreground">
                       {meta.description}
                     </p>
                   )}
+                  <p className="text-xs text-muted-foreground">
+                    {capabilityLabels(s).join(" · ")} · {s.timeout_ms / 1000}s
+                    timeout
+                  </p>
+                </div>
+                <div className="flex items-center gap-2 shrink-0">
+                  {s.enabled && s.latency_ms > 0 && (
+                    <span className="text-xs text-muted-foreground">
+                      {s.latency_ms}ms
+                    </span>
+                  )}
+                  <Button
+                    variant="ghost"
+                    size="icon-sm"
+                    onClick={() => handleMove(i, -1)}
+                    disabled={saving || i === 0}
+                    aria-label="Move up"
+                  >
+                    <ArrowUp className="size-3.5" />
+                  </Button>
+                  <Button
+                    variant="ghost"
+                    size="icon-sm"
+                    onClick={() => handleMove(i, 1)}
+                    disabled={saving || i === statuses.length - 1}
+                    aria-label="Move down"
+                  >
+                    <ArrowDown className="size-3.5" />
+                  </Button>
+                  <Switch
+                    checked={!s.disabled}
+                    onCheckedChange={(checked) => handleToggle(i, checked)}
+                    disabled={saving}
+                    aria-label={`Enable ${meta?.label ?? s.name}`}
+                  />
                 </div>
-                {s.enabled && s.latency_ms > 0 && (
-                  <span className="text-xs text-muted-foreground shrink-0">
-                    {s.latency_ms}ms
-                  </span>
-                )}
               </div>
 
+              {s.health.requests > 0 && (
+                <p className="text-xs text-muted-foreground border-t pt-2">
+                  Last {s.health.requests} searches:{" "}
+                  {Math.round(s.health.error_rate * 100)}% errors · avg{" "}
+                  {s.health.avg_latency_ms}ms
+                  {s.health.skipped > 0 &&
+                    ` · ${s.health.skipped} skipped by the circuit breaker`}
+                  {s.health.last_failure_at && (
+                    <span title={s.health.last_error}>
+                      {" "}
+                      · last failure {timeAgo(s.health.last_failure_at)}
+                    </span>
+                  )}
+                </p>
+              )}
+
               {s.error && (
                 <p className="text-xs text-destructive border-t pt-2">
                   {s.error}
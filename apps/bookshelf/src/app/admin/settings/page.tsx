@@ -60,6 +60,12 @@ const SETTING_LABELS: Record<string, SettingMeta> = {
       "Require users to verify ownership of a new email address (via a code sent to it) before it replaces their current one. Recommended to stay OFF until SMTP delivery is confirmed reliable in this deployment.",
     type: "bool",
   },
+  public_feed_enabled: {
+    label: "Public New Arrivals Feed",
+    description:
+      "Publish an Atom feed of newly added books at /api/feeds/new-books.atom that anyone can subscribe to without logging in",
+    type: "bool",
+  },
 };
 
 // Grouped the same way Sonarr/Jellyfin group related settings under one
@@ -69,7 +75,11 @@ const SETTING_LABELS: Record<string, SettingMeta> = {
 const SETTING_GROUPS: { title: string; keys: string[] }[] = [
   {
     title: "Access & Registration",
-    keys: ["allow_registration", "require_registration_approval"],
+    keys: [
+      "allow_registration",
+      "require_registration_approval",
+      "public_feed_enabled",
+    ],
   },
   {
     title: "Borrowing Eligibility",
@@ -22,9 +22,18 @@ async function proxy(
     ...(hasBody && { duplex: "half" }),
   } as RequestInit);
 
+  // content-type plus the caching headers conditional GETs rely on (e.g. the
+  // public Atom feed) — a 304 from upstream is passed through as-is.
   const resHeaders = new Headers();
-  const ct = upstream.headers.get("content-type");
-  if (ct) resHeaders.set("content-type", ct);
+  for (const name of [
+    "content-type",
+    "etag",
+    "last-modified",
+    "cache-control",
+  ]) {
+    const value = upstream.headers.get(name);
+    if (value) resHeaders.set(name, value);
+  }
 
   return new NextResponse(upstream.body, {
     status: upstream.status,
@@ -0,0 +1,89 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useParams, useRouter } from "next/navigation";
+import { ArrowLeft } from "lucide-react";
+import { api } from "@/lib/api";
+import type { AuthorPage } from "@/lib/types";
+import { BookCard } from "@/components/BookCard";
+import { Button } from "@/components/ui/button";
+import { Skeleton } from "@/components/ui/skeleton";
+
+export default function AuthorDetailPage() {
+  const params = useParams();
+  const router = useRouter();
+  const authorId = Number(params.authorId);
+
+  const [author, setAuthor] = useState<AuthorPage | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    if (!authorId) return;
+    api
+      .getAuthor(authorId)
+      .then(setAuthor)
+      .catch((err) =>
+        setError(err instanceof Error ? err.message : "Failed to load author"),
+      )
+      .finally(() => setLoading(false));
+  }, [authorId]);
+
+  if (loading) {
+    return (
+      <div className="flex flex-col gap-6">
+        <Skeleton className="h-8 w-48" />
+        <div className="grid grid-cols-2 sm:grid-cols-3 lg:grid-cols-5 gap-4">
+          {Array.from({ length: 5 }).map((_, i) => (
+            <Skeleton key={i} className="aspect-[2/3] rounded-lg" />
+          ))}
+        </div>
+      </div>
+    );
+  }
+
+  if (error || !author) {
+    return (
+      <div className="flex flex-col gap-4">
+        <p className="text-destructive">{error || "Author not found"}</p>
+        <Button variant="outline" onClick={() => router.back()}>
+          <ArrowLeft className="size-4" /> Go back
+        </Button>
+      </div>
+    );
+  }
+
+  return (
+    <div className="flex flex-col gap-6">
+      <Button
+        variant="ghost"
+        size="sm"
+        onClick={() => router.back()}
+        className="self-start -ml-1"
+      >
+        <ArrowLeft className="size-4" /> Back
+      </Button>
+
+      <h1 className="text-2xl font-bold leading-tight">{author.name}</h1>
+
+      {author.books.length === 0 ? (
+        <p className="text-sm text-muted-foreground">
+          No books by this author are in the catalog right now.
+        </p>
+      ) : (
+        <div className="grid grid-cols-2 sm:grid-cols-3 lg:grid-cols-5 gap-4">
+          {author.books.map((book) => (
+            <div key={book.id} className="flex flex-col gap-1">
+              <BookCard book={book} />
+              {book.roles.some((r) => r !== "author") && (
+                <p className="text-xs text-muted-foreground capitalize">
+                  {book.roles.join(", ")}
+                </p>
+              )}
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,9 @@
+import { AuthGuard } from "@/components/auth/AuthGuard";
+
+export default function AuthorsLayout({
+  children,
+}: {
+  children: React.ReactNode;
+}) {
+  return <AuthGuard>{children}</AuthGuard>;
+}
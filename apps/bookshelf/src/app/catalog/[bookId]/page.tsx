@@ -1,11 +1,12 @@
 "use client";
 
 import { useEffect, useState, useRef } from "react";
+import Link from "next/link";
 import { useParams, useRouter } from "next/navigation";
 import { toast } from "sonner";
 import { ArrowLeft } from "lucide-react";
 import { api } from "@/lib/api";
-import type { Book, User, Copy } from "@/lib/types";
+import type { Book, User, Copy, WishlistRequest } from "@/lib/types";
 import { Input } from "@/components/ui/input";
 import { CopyCard } from "@/components/CopyCard";
 import { BookCover } from "@/components/BookCover";
@@ -54,6 +55,29 @@ export default function BookDetailPage() {
     setCurrentUser(user);
   }, []);
 
+  // A wishlist possible-match notification lands here as ?wishlist=<id>
+  // (see notificationDestination) so the requester can confirm this is the
+  // book they wanted. Read via window.location on mount, as share/page.tsx
+  // does, rather than useSearchParams and its Suspense boundary.
+  const [possibleMatch, setPossibleMatch] = useState<WishlistRequest | null>(
+    null,
+  );
+  const [confirmingMatch, setConfirmingMatch] = useState(false);
+  useEffect(() => {
+    const id = Number(
+      new URLSearchParams(window.location.search).get("wishlist"),
+    );
+    if (!id) return;
+    api
+      .getWishlistRequest(id)
+      .then((req) => {
+        if (req.status === "open") setPossibleMatch(req);
+      })
+      .catch(() => {
+        // the banner is optional — the book page works without it
+      });
+  }, []);
+
   useEffect(() => {
     if (!bookId) return;
     api
@@ -96,6 +120,41 @@ export default function BookDetailPage() {
     }
   }
 
+  async function handleConfirmMatch() {
+    if (!possibleMatch) return;
+    setConfirmingMatch(true);
+    try {
+      await api.fulfillWishlistRequest(possibleMatch.id, bookId);
+      toast.success("Wishlist request marked as fulfilled");
+      setPossibleMatch(null);
+    } catch (err) {
+      toast.error(
+        err instanceof Error ? err.message : "Failed to confirm match",
+      );
+    } finally {
+      setConfirmingMatch(false);
+    }
+  }
+
+  // Borrow whichever copy of any edition of this work is free — the server
+  // picks one, so a shelf-full of the same title in other editions isn't
+  // missed just because this edition is out.
+  async function handleRequestAnyEdition() {
+    setRequesting(true);
+    try {
+      await api.createLoanRequest({ book_id: bookId, any_edition: true });
+      toast.success("Borrow request sent for another edition!");
+      const updated = await api.getBook(bookId);
+      setBook(updated);
+    } catch (err) {
+      toast.error(
+        err instanceof Error ? err.message : "Failed to send request",
+      );
+    } finally {
+      setRequesting(false);
+    }
+  }
+
   if (loading) {
     return (
       <div className="flex flex-col gap-6">
@@ -136,6 +195,32 @@ export default function BookDetailPage() {
         <ArrowLeft className="size-4" /> Back
       </Button>
 
+      {possibleMatch && currentUser?.id === possibleMatch.requester_id && (
+        <div className="flex flex-col gap-3 rounded-lg border border-primary/20 bg-muted/60 p-4 sm:flex-row sm:items-center sm:justify-between">
+          <p className="text-sm">
+            Is this the{" "}
+            <span className="font-medium">{possibleMatch.title}</span> you
+            asked for on the wishlist?
+          </p>
+          <div className="flex gap-2 shrink-0">
+            <Button
+              size="sm"
+              onClick={handleConfirmMatch}
+              disabled={confirmingMatch}
+            >
+              {confirmingMatch ? "Confirming…" : "Yes, that's it"}
+            </Button>
+            <Button
+              size="sm"
+              variant="ghost"
+              onClick={() => setPossibleMatch(null)}
+            >
+              Not this one
+            </Button>
+          </div>
+        </div>
+      )}
+
       {/* Book header */}
       <div className="flex flex-col sm:flex-row gap-6">
         <div className="relative w-36 aspect-[2/3] rounded-lg overflow-hidden bg-muted shrink-0">
@@ -143,14 +228,34 @@ export default function BookDetailPage() {
             title={book.title}
             author={book.author}
             coverUrl={book.cover_url}
+            blurhash={book.cover_blurhash}
             sizes="144px"
           />
         </div>
 
         <div className="flex flex-col gap-2">
           <h1 className="text-2xl font-bold leading-tight">{book.title}</h1>
-          {book.author && (
-            <p className="text-muted-foreground">{book.author}</p>
+          {book.contributors && book.contributors.length > 0 ? (
+            <p className="text-muted-foreground">
+              {book.contributors.map((c, i) => (
+                <span key={`${c.author_id}-${c.role}`}>
+                  {i > 0 && ", "}
+                  <Link
+                    href={`/authors/${c.author_id}`}
+                    className="hover:underline"
+                  >
+                    {c.author.name}
+                  </Link>
+                  {c.role !== "author" && (
+                    <span className="text-xs"> ({c.role})</span>
+                  )}
+                </span>
+              ))}
+            </p>
+          ) : (
+            book.author && (
+              <p className="text-muted-foreground">{book.author}</p>
+            )
           )}
           {book.isbn && (
             <p className="text-xs text-muted-foreground">ISBN: {book.isbn}</p>
@@ -165,6 +270,17 @@ export default function BookDetailPage() {
                 : "No copies available"}
             </Badge>
           )}
+          {book.available_copies === 0 && currentUser && (
+            <Button
+              variant="outline"
+              size="sm"
+              className="self-start"
+              disabled={requesting}
+              onClick={handleRequestAnyEdition}
+            >
+              Borrow any edition
+            </Button>
+          )}
           {book.description && (
             <div className="flex flex-col gap-1 mt-2">
               <p className="text-sm text-muted-foreground leading-relaxed max-w-prose">
@@ -37,6 +37,7 @@ export default function CatalogPage() {
   const [search, setSearch] = useState("");
   const [sort, setSort] = useState("title");
   const [availableOnly, setAvailableOnly] = useState(false);
+  const [groupEditions, setGroupEditions] = useState(false);
   const [page, setPage] = useState(1);
   const [loading, setLoading] = useState(true);
   const [fetching, setFetching] = useState(false);
@@ -50,6 +51,7 @@ export default function CatalogPage() {
     q: string,
     s: string,
     avail: boolean,
+    grouped: boolean,
     p: number,
     isInitial = false,
   ) {
@@ -63,6 +65,7 @@ export default function CatalogPage() {
         available_only: avail || undefined,
         page: p,
         page_size: PAGE_SIZE,
+        group_by: grouped ? "work" : undefined,
       });
       setResult(data);
     } catch (err) {
@@ -78,7 +81,7 @@ export default function CatalogPage() {
   useEffect(() => {
     if (loadedRef.current) return;
     loadedRef.current = true;
-    fetchBooks("", "title", false, 1, true);
+    fetchBooks("", "title", false, false, 1, true);
   }, []);
 
   // Debounced search/sort/filter — reset to page 1. Skips the mount pass
@@ -92,12 +95,12 @@ export default function CatalogPage() {
     if (debounceRef.current) clearTimeout(debounceRef.current);
     debounceRef.current = setTimeout(() => {
       setPage(1);
-      fetchBooks(search, sort, availableOnly, 1);
+      fetchBooks(search, sort, availableOnly, groupEditions, 1);
     }, 300);
     return () => {
       if (debounceRef.current) clearTimeout(debounceRef.current);
     };
-  }, [search, sort, availableOnly]);
+  }, [search, sort, availableOnly, groupEditions]);
 
   function handleSearchChange(value: string) {
     setSearch(value);
@@ -123,18 +126,20 @@ export default function CatalogPage() {
     setSearch("");
     setSort("title");
     setAvailableOnly(false);
+    setGroupEditions(false);
   }
 
   function handlePageChange(p: number) {
     setPage(p);
-    fetchBooks(search, sort, availableOnly, p);
+    fetchBooks(search, sort, availableOnly, groupEditions, p);
     window.scrollTo({ top: 0, behavior: "smooth" });
   }
 
   const books = result?.items ?? [];
   const totalPages = result?.total_pages ?? 1;
   const total = result?.total ?? 0;
-  const hasActiveFilters = !!search.trim() || availableOnly || sort !== "title";
+  const hasActiveFilters =
+    !!search.trim() || availableOnly || groupEditions || sort !== "title";
 
   return (
     <div className="flex flex-col gap-8">
@@ -206,6 +211,20 @@ export default function CatalogPage() {
               Available only
             </Label>
           </div>
+
+          <div className="flex items-center gap-2">
+            <Switch
+              id="group-editions"
+              checked={groupEditions}
+              onCheckedChange={setGroupEditions}
+            />
+            <Label
+              htmlFor="group-editions"
+              className="text-sm cursor-pointer select-none"
+            >
+              Group editions
+            </Label>
+          </div>
         </div>
       </div>
 
@@ -240,6 +259,19 @@ export default function CatalogPage() {
               </button>
             </Badge>
           )}
+          {groupEditions && (
+            <Badge variant="secondary" className="gap-1 pr-1">
+              Editions grouped
+              <button
+                type="button"
+                aria-label="Stop grouping editions"
+                onClick={() => setGroupEditions(false)}
+                className="rounded-full hover:bg-background/60 p-0.5"
+              >
+                <X className="size-3" />
+              </button>
+            </Badge>
+          )}
           {sort !== "title" && (
             <Badge variant="secondary" className="gap-1 pr-1">
               Sort: {SORT_LABELS[sort] ?? sort}
@@ -277,6 +309,19 @@ export default function CatalogPage() {
       ) : books.length === 0 ? (
         <div className="flex flex-col items-center justify-center py-16 text-center gap-4">
           <p className="text-muted-foreground">No books found.</p>
+          {result?.did_you_mean && (
+            <p className="text-sm">
+              Did you mean{" "}
+              <button
+                type="button"
+                onClick={() => handleSearchChange(result.did_you_mean!)}
+                className="font-medium text-primary hover:underline"
+              >
+                {result.did_you_mean}
+              </button>
+              ?
+            </p>
+          )}
           <div className="flex flex-wrap items-center justify-center gap-2">
             {hasActiveFilters && (
               <Button variant="outline" size="sm" onClick={clearFilters}>
@@ -309,16 +354,30 @@ export default function CatalogPage() {
           >
             {total > 0 && (
               <p className="text-sm text-muted-foreground mb-4">
-                {total} {total === 1 ? "book" : "books"} found
+                {total}{" "}
+                {groupEditions
+                  ? total === 1
+                    ? "work"
+                    : "works"
+                  : total === 1
+                    ? "book"
+                    : "books"}{" "}
+                found
               </p>
             )}
             <div className="grid grid-cols-2 sm:grid-cols-3 md:grid-cols-4 lg:grid-cols-5 gap-4">
               {books.map((book) => (
-                <BookCard
-                  key={book.id}
-                  book={book}
-                  ownedByMe={ownedBookIds.has(book.id)}
-                />
+                <div key={book.id} className="flex flex-col gap-1">
+                  <BookCard
+                    book={book}
+                    ownedByMe={ownedBookIds.has(book.id)}
+                  />
+                  {(book.editions?.length ?? 0) > 1 && (
+                    <p className="text-xs text-muted-foreground">
+                      {book.editions!.length} editions
+                    </p>
+                  )}
+                </div>
               ))}
             </div>
           </div>
@@ -16,8 +16,11 @@ import {
 import { api, downloadMyCopiesExport } from "@/lib/api";
 import type {
   MyCopiesExportFormat,
+  ImportFormat,
+  ImportJob,
   ImportResult,
   ImportRowAction,
+  ImportSource,
   ImportSummary,
   ImportDecision,
 } from "@/lib/api";
@@ -94,6 +97,13 @@ const importActionLabel: Record<ImportRowAction, string> = {
   skipped: "Skipped",
 };
 
+const importSourceLabel: Record<ImportSource, string> = {
+  bookshelf: "Bookshelf",
+  goodreads: "Goodreads",
+  storygraph: "StoryGraph",
+  librarything: "LibraryThing",
+};
+
 function importSummaryText(summary: ImportSummary, isResult: boolean): string {
   const parts: string[] = [];
   if (summary.books_created > 0) {
@@ -158,7 +168,9 @@ export default function MyBooksPage() {
   const [importOpen, setImportOpen] = useState(false);
   const [importFile, setImportFile] = useState<File | null>(null);
   const [importPreview, setImportPreview] = useState<ImportResult | null>(null);
-  const [importResult, setImportResult] = useState<ImportResult | null>(null);
+  // The queued import, once committed; its rows accumulate as polling
+  // fetches each batch.
+  const [importResult, setImportResult] = useState<ImportJob | null>(null);
   const [importBusy, setImportBusy] = useState(false);
   const [importError, setImportError] = useState("");
   // Per-row resolution for possible_match rows, keyed by 1-based row number
@@ -168,6 +180,12 @@ export default function MyBooksPage() {
     Record<number, ImportDecision>
   >({});
   const importInputRef = useRef<HTMLInputElement>(null);
+  // Last row number already fetched for importResult, so each poll asks
+  // only for the rows after it.
+  const importLastRowRef = useRef(0);
+  const importRunning =
+    importResult !== null &&
+    (importResult.status === "queued" || importResult.status === "running");
 
   // Fetched separately, after the main copies list renders — per-copy
   // pending-request counts and active-loan details aren't returned by
@@ -319,15 +337,18 @@ export default function MyBooksPage() {
 
   const MAX_IMPORT_FILE_BYTES = 2_000_000; // mirrors the backend's Content maxLength
 
-  function importFormatFromFilename(name: string): MyCopiesExportFormat | null {
+  function importFormatFromFilename(name: string): ImportFormat | null {
     const ext = name.split(".").pop()?.toLowerCase();
     if (ext === "json") return "json";
     if (ext === "yaml" || ext === "yml") return "yaml";
     if (ext === "csv") return "csv";
+    // LibraryThing names its tab-separated export .tsv or .txt.
+    if (ext === "tsv" || ext === "txt") return "tsv";
     return null;
   }
 
   function resetImportDialog() {
+    importLastRowRef.current = 0;
     setImportFile(null);
     setImportPreview(null);
     setImportResult(null);
@@ -343,7 +364,7 @@ export default function MyBooksPage() {
     setImportDecisions({});
     const format = importFormatFromFilename(file.name);
     if (!format) {
-      setImportError("File must be .json, .yaml, .yml, or .csv");
+      setImportError("File must be .json, .yaml, .yml, .csv, or .tsv");
       return;
     }
     if (file.size > MAX_IMPORT_FILE_BYTES) {
@@ -370,16 +391,10 @@ export default function MyBooksPage() {
     setImportBusy(true);
     try {
       const content = await importFile.text();
-      const result = await api.importBooks(format, content, importDecisions);
-      setImportResult(result);
+      const job = await api.importBooks(format, content, importDecisions);
+      importLastRowRef.current = 0;
+      setImportResult(job);
       setImportPreview(null);
-      const added = result.summary.books_created + result.summary.books_matched;
-      toast.success(
-        added > 0
-          ? `Imported ${added} book${added === 1 ? "" : "s"}`
-          : "Import finished — nothing new to add",
-      );
-      loadMyCopies();
     } catch (err) {
       toast.error(err instanceof Error ? err.message : "Import failed");
     } finally {
@@ -387,6 +402,83 @@ export default function MyBooksPage() {
     }
   }
 
+  // Reattaches the dialog to an import still running from an earlier visit
+  // — closing the dialog or the page doesn't stop one.
+  async function resumeUnfinishedImport() {
+    try {
+      const jobs = await api.listImportJobs();
+      const running = jobs.find(
+        (j) => j.status === "queued" || j.status === "running",
+      );
+      if (running) {
+        importLastRowRef.current = 0;
+        setImportResult({ ...running, rows: [] });
+      }
+    } catch {
+      // Non-critical: the dialog just starts empty.
+    }
+  }
+
+  async function handleImportCancel() {
+    if (!importResult) return;
+    setImportBusy(true);
+    try {
+      await api.cancelImportJob(importResult.id);
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "Could not cancel");
+    } finally {
+      setImportBusy(false);
+    }
+  }
+
+  const importJobId = importRunning ? importResult.id : null;
+  useEffect(() => {
+    if (importJobId === null) return;
+    let stopped = false;
+
+    async function poll(id: number) {
+      let job: ImportJob;
+      try {
+        job = await api.getImportJob(id, importLastRowRef.current);
+      } catch {
+        return; // try again on the next tick
+      }
+      if (stopped) return;
+      if (job.rows.length > 0) {
+        importLastRowRef.current = job.rows[job.rows.length - 1].row;
+      }
+      setImportResult((prev) =>
+        prev && prev.id === job.id
+          ? { ...job, rows: [...prev.rows, ...job.rows] }
+          : job,
+      );
+      if (job.status === "queued" || job.status === "running") return;
+
+      const added = job.summary.books_created + job.summary.books_matched;
+      if (job.status === "failed") {
+        toast.error(job.error || "Import failed");
+      } else if (job.status === "cancelled") {
+        toast.info(
+          `Import cancelled — ${added} book${added === 1 ? " was" : "s were"} added before it stopped`,
+        );
+      } else {
+        toast.success(
+          added > 0
+            ? `Imported ${added} book${added === 1 ? "" : "s"}`
+            : "Import finished — nothing new to add",
+        );
+      }
+      loadMyCopies();
+    }
+
+    poll(importJobId);
+    const interval = setInterval(() => poll(importJobId), 1_500);
+    return () => {
+      stopped = true;
+      clearInterval(interval);
+    };
+  }, [importJobId, loadMyCopies]);
+
   async function handleTransfer() {
     if (!transferCopy || !transferEmail.trim()) return;
     setTransferSubmitting(true);
@@ -445,6 +537,7 @@ export default function MyBooksPage() {
             onClick={() => {
               resetImportDialog();
               setImportOpen(true);
+              resumeUnfinishedImport();
             }}
           >
             <Upload className="size-4" />
@@ -864,7 +957,8 @@ export default function MyBooksPage() {
             <DialogDescription>
               Upload a JSON, YAML, or CSV file exported from this or another
               bookshelf instance. You&apos;ll see what will happen before
-              anything is added.
+              anything is added. Large imports keep running if you close
+              this dialog.
             </DialogDescription>
           </DialogHeader>
 
@@ -877,14 +971,15 @@ export default function MyBooksPage() {
                 <Upload className="size-6" />
                 {importFile ? importFile.name : "Click to choose a file"}
                 <span className="text-xs">
-                  .json, .yaml, .yml, or .csv — max 2MB
+                  .json, .yaml, .yml, .csv, or .tsv — max 2MB. Goodreads,
+                  StoryGraph, and LibraryThing exports work as-is.
                 </span>
               </label>
               <input
                 id="import-file-input"
                 ref={importInputRef}
                 type="file"
-                accept=".json,.yaml,.yml,.csv"
+                accept=".json,.yaml,.yml,.csv,.tsv,.txt"
                 className="sr-only"
                 onChange={(e) => {
                   const file = e.target.files?.[0];
@@ -902,6 +997,36 @@ export default function MyBooksPage() {
 
           {(importPreview || importResult) && (
             <div className="flex flex-col gap-3">
+              {(importPreview ?? importResult)!.source !== "bookshelf" && (
+                <p className="text-xs text-muted-foreground">
+                  Detected a{" "}
+                  {importSourceLabel[(importPreview ?? importResult)!.source]}{" "}
+                  export. Only books marked as owned are added.
+                </p>
+              )}
+              {importResult && importRunning && (
+                <div className="flex flex-col gap-1.5">
+                  <p className="text-sm text-muted-foreground">
+                    {importResult.status === "queued"
+                      ? "Waiting to start…"
+                      : `Importing… ${importResult.processed_rows} of ${importResult.total_rows} rows`}
+                  </p>
+                  <div className="h-2 w-full overflow-hidden rounded-full bg-muted">
+                    <div
+                      className="h-full bg-primary transition-all"
+                      style={{
+                        width: `${
+                          importResult.total_rows > 0
+                            ? (importResult.processed_rows /
+                                importResult.total_rows) *
+                              100
+                            : 0
+                        }%`,
+                      }}
+                    />
+                  </div>
+                </div>
+              )}
               <p className="text-sm font-medium">
                 {importSummaryText(
                   (importPreview ?? importResult)!.summary,
@@ -984,7 +1109,15 @@ export default function MyBooksPage() {
           )}
 
           <DialogFooter showCloseButton>
-            {importResult ? (
+            {importRunning ? (
+              <Button
+                variant="outline"
+                onClick={handleImportCancel}
+                disabled={importBusy}
+              >
+                Cancel import
+              </Button>
+            ) : importResult ? (
               <Button onClick={() => setImportOpen(false)}>Done</Button>
             ) : importPreview ? (
               <>
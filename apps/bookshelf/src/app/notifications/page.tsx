@@ -167,6 +167,12 @@ export default function NotificationsPage() {
                       A copy you waitlisted is now available — go request it!
                     </p>
                   )}
+                  {n.type === "wishlist_possible_match" && (
+                    <p className="text-xs text-muted-foreground">
+                      A newly added book may be one you asked for — open it to
+                      confirm.
+                    </p>
+                  )}
                   {n.loan_request_id && n.type !== "waitlist_available" && (
                     <p className="text-xs text-muted-foreground">
                       Request #{n.loan_request_id}
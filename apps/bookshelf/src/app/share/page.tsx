@@ -6,7 +6,7 @@ import Link from "next/link";
 import { toast } from "sonner";
 import { ArrowLeft, BookPlus, ScanLine } from "lucide-react";
 import { api } from "@/lib/api";
-import type { BookMetadataResult } from "@/lib/types";
+import type { BookMetadataResult, Contributor } from "@/lib/types";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
 import { Separator } from "@/components/ui/separator";
@@ -37,6 +37,7 @@ interface SelectedBook {
   publishedDate: string;
   pageCount: number;
   language: string;
+  contributors?: Contributor[];
 }
 
 export default function SharePage() {
@@ -90,6 +91,7 @@ export default function SharePage() {
     setSelected({
       olKey: result.ol_key,
       googleBooksId: result.google_books_id,
+      contributors: result.contributors,
       source: result.source,
       title: result.title,
       author: result.author,
@@ -120,6 +122,7 @@ export default function SharePage() {
         page_count: selected.pageCount || undefined,
         language: selected.language || undefined,
         google_books_id: selected.googleBooksId || undefined,
+        contributors: selected.contributors,
       });
 
       await api.createCopy({
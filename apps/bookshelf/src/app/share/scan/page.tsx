@@ -6,7 +6,13 @@ import Link from "next/link";
 import { toast } from "sonner";
 import { BrowserMultiFormatReader, BarcodeFormat } from "@zxing/browser";
 import type { IScannerControls } from "@zxing/browser";
-import { ArrowLeft, ListChecks, Search, Trash2 } from "lucide-react";
+import {
+  ArrowLeft,
+  ImageUp,
+  ListChecks,
+  Search,
+  Trash2,
+} from "lucide-react";
 import { api } from "@/lib/api";
 import type { BookMetadataResult } from "@/lib/types";
 import { Button } from "@/components/ui/button";
@@ -58,6 +64,8 @@ export default function ScanPage() {
   const [cameraError, setCameraError] = useState("");
   const [submitting, setSubmitting] = useState(false);
   const [manualTargetId, setManualTargetId] = useState<string | null>(null);
+  const [scanningPhoto, setScanningPhoto] = useState(false);
+  const photoInputRef = useRef<HTMLInputElement>(null);
 
   const videoRef = useRef<HTMLVideoElement>(null);
   const controlsRef = useRef<IScannerControls | null>(null);
@@ -201,6 +209,54 @@ export default function ScanPage() {
     };
   }, [view, handleDecode]);
 
+  // A photo of a stack of books is decoded server-side, where every barcode
+  // in it is read and looked up in one request — the live camera reader
+  // only ever sees one at a time.
+  async function handlePhoto(file: File) {
+    setScanningPhoto(true);
+    try {
+      const { barcodes } = await api.scanBarcodes(file);
+      const books = barcodes.filter((b) => b.isbn);
+      if (books.length === 0) {
+        toast.error(
+          barcodes.length > 0
+            ? "The barcode in that photo isn't an ISBN"
+            : "No barcodes found — try a closer, sharper photo",
+        );
+        return;
+      }
+      const existing = new Set(itemsRef.current.map((it) => it.isbn));
+      const added = books.filter((b) => !existing.has(b.isbn!));
+      setItems((prev) => [
+        ...prev,
+        ...added.map((b) => ({
+          id: crypto.randomUUID(),
+          isbn: b.isbn!,
+          status: b.candidates[0]
+            ? ("resolved" as const)
+            : ("unresolved" as const),
+          result: b.candidates[0],
+          condition: "good" as Condition,
+          notes: "",
+          autoApprove: false,
+          returnDateRequired: false,
+          hideOwner: false,
+        })),
+      ]);
+      toast.success(
+        `Found ${books.length} book${books.length === 1 ? "" : "s"}` +
+          (added.length < books.length
+            ? ` (${books.length - added.length} already scanned)`
+            : ""),
+      );
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "Could not scan photo");
+    } finally {
+      setScanningPhoto(false);
+      if (photoInputRef.current) photoInputRef.current.value = "";
+    }
+  }
+
   function handleRemove(id: string) {
     setItems((prev) => {
       const removed = prev.find((it) => it.id === id);
@@ -247,6 +303,7 @@ export default function ScanPage() {
           page_count: r.page_count || undefined,
           language: r.language || undefined,
           google_books_id: r.google_books_id || undefined,
+          contributors: r.contributors,
         });
         await api.createCopy({
           book_id: created.id,
@@ -419,6 +476,24 @@ export default function ScanPage() {
         className="p-4 flex flex-col gap-2"
         style={{ paddingBottom: "calc(env(safe-area-inset-bottom) + 1rem)" }}
       >
+        <input
+          ref={photoInputRef}
+          type="file"
+          accept="image/jpeg,image/png,image/gif"
+          className="hidden"
+          onChange={(e) => {
+            const file = e.target.files?.[0];
+            if (file) handlePhoto(file);
+          }}
+        />
+        <Button
+          variant="outline"
+          onClick={() => photoInputRef.current?.click()}
+          disabled={scanningPhoto}
+        >
+          <ImageUp className="size-4" />
+          {scanningPhoto ? "Scanning photo…" : "Scan a photo"}
+        </Button>
         <Button
           onClick={() => setView("review")}
           disabled={items.length === 0}
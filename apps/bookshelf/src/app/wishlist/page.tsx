@@ -2,7 +2,7 @@
 
 import { useState, useEffect, useRef } from "react";
 import { toast } from "sonner";
-import { Search, Plus, Link2, X } from "lucide-react";
+import { Search, Plus, Link2, X, Users } from "lucide-react";
 import { api } from "@/lib/api";
 import type {
   BookMetadataResult,
@@ -178,7 +178,9 @@ export default function WishlistPage() {
                   key={req.id}
                   request={req}
                   canManage={isAdmin || req.requester_id === currentUser?.id}
+                  canJoin={req.requester_id !== currentUser?.id}
                   onCancelled={reload}
+                  onJoinChanged={reload}
                   onLink={() => setLinkTarget(req)}
                 />
               ))}
@@ -210,21 +212,50 @@ export default function WishlistPage() {
 function WishlistCard({
   request,
   canManage,
+  canJoin,
   onCancelled,
+  onJoinChanged,
   onLink,
 }: {
   request: WishlistRequest;
   canManage: boolean;
+  canJoin: boolean;
   onCancelled: () => void;
+  onJoinChanged: () => void;
   onLink: () => void;
 }) {
   const [cancelling, setCancelling] = useState(false);
+  const [joining, setJoining] = useState(false);
+
+  async function handleToggleJoin() {
+    setJoining(true);
+    try {
+      if (request.joined) {
+        await api.leaveWishlistRequest(request.id);
+        toast.success("You've left this request");
+      } else {
+        await api.joinWishlistRequest(request.id);
+        toast.success("Added — we'll let you know too if it turns up.");
+      }
+      onJoinChanged();
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "Update failed");
+    } finally {
+      setJoining(false);
+    }
+  }
 
   async function handleCancel() {
     setCancelling(true);
     try {
       await api.cancelWishlistRequest(request.id);
-      toast.success("Request cancelled");
+      // A requester withdrawing passes the request on to whoever joined it
+      // first; only an admin (or a request nobody joined) cancels it.
+      toast.success(
+        !canJoin && request.requester_count > 1
+          ? "You've left this request; the next member who wants it keeps it open"
+          : "Request cancelled",
+      );
       onCancelled();
     } catch (err) {
       toast.error(err instanceof Error ? err.message : "Cancel failed");
@@ -270,8 +301,24 @@ function WishlistCard({
                 </span>
               )
             )}
+            {request.requester_count > 1 && (
+              <span className="text-xs text-muted-foreground inline-flex items-center gap-1">
+                <Users className="size-3" />
+                {request.requester_count} members want this
+              </span>
+            )}
           </div>
           <div className="flex items-center gap-3 mt-1">
+            {canJoin && request.status === "open" && (
+              <button
+                onClick={handleToggleJoin}
+                disabled={joining}
+                className="text-xs text-primary hover:underline inline-flex items-center gap-1"
+              >
+                <Users className="size-3" />
+                {joining ? "Saving…" : request.joined ? "Leave" : "Me too"}
+              </button>
+            )}
             <button
               onClick={onLink}
               className="text-xs text-primary hover:underline inline-flex items-center gap-1"
@@ -394,11 +441,25 @@ function CreateRequestDialog({
     setBypassMatch(false);
   }
 
-  function handleJoinExisting() {
-    toast.success(
-      "You're not the only one — no need to add it again. The original poster will be notified if this turns up.",
-    );
-    handleOpenChange(false);
+  // Join the existing post instead of adding a duplicate, so this member is
+  // notified alongside the original poster when the book turns up.
+  async function handleJoinExisting() {
+    if (!match) return;
+    setSubmitting(true);
+    try {
+      await api.joinWishlistRequest(match.id);
+      toast.success(
+        "You're not the only one — we've added you to this request and will let you know if it turns up.",
+      );
+      handleOpenChange(false);
+      onCreated();
+    } catch (err) {
+      toast.error(
+        err instanceof Error ? err.message : "Failed to join request",
+      );
+    } finally {
+      setSubmitting(false);
+    }
   }
 
   function handleOpenChange(next: boolean) {
@@ -488,10 +549,25 @@ function CreateRequestDialog({
                   {new Date(match.created_at).toLocaleDateString()}
                   {match.notes ? ` — "${match.notes}"` : ""}
                 </p>
+                {match.requester_count > 1 && (
+                  <p className="text-xs text-muted-foreground">
+                    {match.requester_count} members want it so far.
+                  </p>
+                )}
                 <div className="flex items-center gap-3 mt-1">
-                  <Button size="sm" onClick={handleJoinExisting}>
-                    I want this too
-                  </Button>
+                  {match.joined ? (
+                    <span className="text-xs text-muted-foreground">
+                      You&apos;ve already joined this request.
+                    </span>
+                  ) : (
+                    <Button
+                      size="sm"
+                      onClick={handleJoinExisting}
+                      disabled={submitting}
+                    >
+                      {submitting ? "Joining…" : "I want this too"}
+                    </Button>
+                  )}
                   <button
                     onClick={() => setBypassMatch(true)}
                     className="text-xs text-muted-foreground hover:text-foreground"
@@ -7,6 +7,9 @@ jest.mock("next/image", () => ({
   default: (props: Record<string, unknown>) => {
     const imgProps = { ...props };
     delete imgProps.fill;
+    delete imgProps.unoptimized;
+    delete imgProps.placeholder;
+    delete imgProps.blurDataURL;
 
     return <img {...imgProps} alt={props.alt as string} />;
   },
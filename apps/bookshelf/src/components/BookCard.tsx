@@ -23,6 +23,7 @@ export function BookCard({ book, ownedByMe }: BookCardProps) {
             title={book.title}
             author={book.author}
             coverUrl={book.cover_url}
+            blurhash={book.cover_blurhash}
             sizes="(max-width: 640px) 50vw, (max-width: 1024px) 33vw, 20vw"
             className="transition-transform group-hover:scale-105"
           />
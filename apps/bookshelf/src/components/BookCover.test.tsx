@@ -6,6 +6,9 @@ jest.mock("next/image", () => ({
   default: (props: Record<string, unknown>) => {
     const imgProps = { ...props };
     delete imgProps.fill;
+    delete imgProps.unoptimized;
+    delete imgProps.placeholder;
+    delete imgProps.blurDataURL;
 
     return <img {...imgProps} alt={props.alt as string} />;
   },
@@ -27,6 +30,22 @@ describe("BookCover", () => {
     expect(container.querySelector("svg")).toBeNull();
   });
 
+  it("requests the chosen size of a locally cached cover", () => {
+    const { container } = render(
+      <BookCover
+        title="Dune"
+        coverUrl="/api/covers/abc.jpg"
+        coverSize="thumb"
+        sizes="56px"
+      />,
+    );
+
+    expect(container.querySelector("img")).toHaveAttribute(
+      "src",
+      "/api/covers/abc.jpg?size=thumb",
+    );
+  });
+
   it("renders a generated SVG fallback when coverUrl is empty", () => {
     const { container } = render(
       <BookCover title="Dune" author="Frank Herbert" sizes="100px" />,
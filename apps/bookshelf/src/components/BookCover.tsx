@@ -1,7 +1,8 @@
 "use client";
 
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import Image from "next/image";
+import { blurhashToDataURL } from "@/lib/blurhash";
 import { cn } from "@/lib/utils";
 import { gradientForTitle, hashString, wrapLines } from "@/lib/bookCoverColors";
 
@@ -153,10 +154,21 @@ export function BookCoverFallback({
   );
 }
 
+// Prefix of covers the backend has cached locally — the only ones it stores
+// in several sizes (see the backend's internal/covers).
+const LOCAL_COVER_PREFIX = "/api/covers/";
+
+export type CoverSize = "thumb" | "medium" | "full";
+
 interface BookCoverProps {
   title: string;
   author?: string | null;
   coverUrl?: string | null;
+  // BlurHash shown while a locally cached cover loads (Book.cover_blurhash).
+  blurhash?: string | null;
+  // Which stored size of a locally cached cover to load: thumb is 160px
+  // wide, medium 400px, full up to 1200px. Ignored for external covers.
+  coverSize?: CoverSize;
   alt?: string;
   sizes: string;
   className?: string;
@@ -170,25 +182,40 @@ interface BookCoverProps {
 // dead host, CORS), rather than leaving a broken image in place. Client
 // Component (needs the load-error state), so every call site must already
 // be within a Client Component boundary.
+//
+// A locally cached cover is loaded at coverSize straight from the backend,
+// which has already resized it, so it skips Next's image optimizer (which
+// would also reject the ?size= query on a local image).
 export function BookCover({
   title,
   author,
   coverUrl,
+  blurhash,
+  coverSize = "medium",
   alt,
   sizes,
   className,
   priority,
 }: BookCoverProps) {
   const [failed, setFailed] = useState(false);
+  // Decoded after mount: it needs a canvas, which server rendering lacks.
+  const [blurDataURL, setBlurDataURL] = useState<string | undefined>();
+  useEffect(() => {
+    setBlurDataURL(blurhash ? blurhashToDataURL(blurhash) : undefined);
+  }, [blurhash]);
 
   if (coverUrl && !failed) {
+    const local = coverUrl.startsWith(LOCAL_COVER_PREFIX);
     return (
       <Image
-        src={coverUrl}
+        src={local ? `${coverUrl}?size=${coverSize}` : coverUrl}
         alt={alt ?? `Cover of ${title}`}
         fill
         sizes={sizes}
         priority={priority}
+        unoptimized={local}
+        placeholder={blurDataURL ? "blur" : "empty"}
+        blurDataURL={blurDataURL}
         className={cn("object-cover", className)}
         onError={() => setFailed(true)}
       />
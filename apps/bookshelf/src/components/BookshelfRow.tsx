@@ -25,6 +25,7 @@ function BookSpine({ book, ownedByMe }: { book: Book; ownedByMe: boolean }) {
           title={book.title}
           author={book.author}
           coverUrl={book.cover_url}
+          blurhash={book.cover_blurhash}
           sizes="112px"
         />
         {/* Spine highlight (simulates book edge) */}
@@ -17,6 +17,8 @@ export function CurrentlyBorrowedCard({ request }: { request: LoanRequest }) {
             title={book?.title ?? "Unknown book"}
             author={book?.author}
             coverUrl={book?.cover_url}
+            blurhash={book?.cover_blurhash}
+            coverSize="thumb"
             sizes="56px"
           />
         </div>
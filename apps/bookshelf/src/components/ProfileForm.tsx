@@ -5,7 +5,12 @@ import Link from "next/link";
 import { CheckCircle2, XCircle } from "lucide-react";
 import { useRouter } from "next/navigation";
 import { toast } from "sonner";
-import { api, emailLocalPart, validatePassword } from "@/lib/api";
+import {
+  api,
+  downloadMyData,
+  emailLocalPart,
+  validatePassword,
+} from "@/lib/api";
 import { PasswordStrengthMeter } from "@/components/PasswordStrengthMeter";
 import type { User, VerificationStatus } from "@/lib/types";
 import { Badge } from "@/components/ui/badge";
@@ -13,6 +18,7 @@ import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
 import { Separator } from "@/components/ui/separator";
 import { Switch } from "@/components/ui/switch";
+import { RadioGroup, RadioGroupItem } from "@/components/ui/radio-group";
 import { Tabs, TabsContent, TabsList, TabsTrigger } from "@/components/ui/tabs";
 import {
   Card,
@@ -45,6 +51,15 @@ export function ProfileForm() {
   const [confirmNewPassword, setConfirmNewPassword] = useState("");
   const [pwError, setPwError] = useState("");
   const [changingPw, setChangingPw] = useState(false);
+  const [exportingData, setExportingData] = useState(false);
+
+  // Account deletion
+  const [deletePassword, setDeletePassword] = useState("");
+  const [deleteCopies, setDeleteCopies] = useState<"transfer" | "delete">(
+    "transfer",
+  );
+  const [deleteTransferEmail, setDeleteTransferEmail] = useState("");
+  const [schedulingDeletion, setSchedulingDeletion] = useState(false);
 
   // Google Books API key
   const [gbKey, setGbKey] = useState("");
@@ -255,6 +270,56 @@ export function ProfileForm() {
     }
   }
 
+  async function handleDownloadMyData() {
+    setExportingData(true);
+    try {
+      await downloadMyData();
+    } catch (err) {
+      toast.error(
+        err instanceof Error ? err.message : "Failed to download your data",
+      );
+    } finally {
+      setExportingData(false);
+    }
+  }
+
+  async function handleScheduleDeletion(e: FormEvent) {
+    e.preventDefault();
+    setSchedulingDeletion(true);
+    try {
+      const { deletion_scheduled_at } = await api.scheduleAccountDeletion({
+        password: deletePassword,
+        copies: deleteCopies,
+        transfer_to_email:
+          deleteCopies === "transfer" ? deleteTransferEmail.trim() : undefined,
+      });
+      setUser((u) => (u ? { ...u, deletion_scheduled_at } : u));
+      setDeletePassword("");
+      toast.success("Account deletion scheduled");
+    } catch (err) {
+      toast.error(
+        err instanceof Error ? err.message : "Failed to schedule deletion",
+      );
+    } finally {
+      setSchedulingDeletion(false);
+    }
+  }
+
+  async function handleCancelDeletion() {
+    setSchedulingDeletion(true);
+    try {
+      await api.cancelAccountDeletion();
+      setUser((u) => (u ? { ...u, deletion_scheduled_at: undefined } : u));
+      toast.success("Account deletion cancelled");
+    } catch (err) {
+      toast.error(
+        err instanceof Error ? err.message : "Failed to cancel deletion",
+      );
+    } finally {
+      setSchedulingDeletion(false);
+    }
+  }
+
   async function submitOtpVerification(
     data: { code: string } | { token: string },
   ) {
@@ -564,7 +629,7 @@ export function ProfileForm() {
         </TabsContent>
 
         {/* Security tab */}
-        <TabsContent value="security" className="mt-4">
+        <TabsContent value="security" className="mt-4 flex flex-col gap-4">
           <Card>
             <CardHeader>
               <CardTitle className="text-base">Change password</CardTitle>
@@ -649,6 +714,152 @@ export function ProfileForm() {
               </form>
             </CardContent>
           </Card>
+
+          <Card>
+            <CardHeader>
+              <CardTitle className="text-base">Your data</CardTitle>
+              <CardDescription>
+                Download everything Bookshelf stores about you — your profile,
+                copies, loans, waitlists, wishlist requests, notifications,
+                reading lists and imports — as a zip of JSON files. A README
+                inside explains each file.
+              </CardDescription>
+            </CardHeader>
+            <CardContent>
+              <Button
+                variant="outline"
+                onClick={handleDownloadMyData}
+                disabled={exportingData}
+              >
+                {exportingData ? "Preparing…" : "Download my data"}
+              </Button>
+            </CardContent>
+          </Card>
+
+          <Card>
+            <CardHeader>
+              <CardTitle className="text-base">Delete account</CardTitle>
+              <CardDescription>
+                Your account is deleted 14 days after you ask, and you can
+                cancel until then. Past loans stay in the other member&apos;s
+                history, under &ldquo;Deleted member&rdquo; instead of your
+                name. You can&apos;t delete your account while a copy you
+                borrowed or lent is out on loan.
+              </CardDescription>
+            </CardHeader>
+            <CardContent>
+              {user?.deletion_scheduled_at ? (
+                <div className="flex flex-col gap-4">
+                  <p className="text-sm">
+                    Your account will be deleted on{" "}
+                    <strong>
+                      {new Date(
+                        user.deletion_scheduled_at,
+                      ).toLocaleDateString()}
+                    </strong>
+                    .
+                  </p>
+                  <div>
+                    <Button
+                      variant="outline"
+                      onClick={handleCancelDeletion}
+                      disabled={schedulingDeletion}
+                    >
+                      {schedulingDeletion ? "Cancelling…" : "Keep my account"}
+                    </Button>
+                  </div>
+                </div>
+              ) : (
+                <form
+                  onSubmit={handleScheduleDeletion}
+                  className="flex flex-col gap-4"
+                >
+                  <div className="flex flex-col gap-1.5">
+                    <span className="text-sm font-medium">Your copies</span>
+                    <RadioGroup
+                      value={deleteCopies}
+                      onValueChange={(v) =>
+                        setDeleteCopies(v as "transfer" | "delete")
+                      }
+                      className="flex flex-col gap-2"
+                    >
+                      <div className="flex items-center gap-1.5">
+                        <RadioGroupItem
+                          value="transfer"
+                          id="delete-copies-transfer"
+                        />
+                        <label
+                          htmlFor="delete-copies-transfer"
+                          className="text-sm cursor-pointer"
+                        >
+                          Give them to another member
+                        </label>
+                      </div>
+                      <div className="flex items-center gap-1.5">
+                        <RadioGroupItem
+                          value="delete"
+                          id="delete-copies-delete"
+                        />
+                        <label
+                          htmlFor="delete-copies-delete"
+                          className="text-sm cursor-pointer"
+                        >
+                          Remove them from the library
+                        </label>
+                      </div>
+                    </RadioGroup>
+                  </div>
+                  {deleteCopies === "transfer" && (
+                    <div className="flex flex-col gap-1.5">
+                      <label
+                        htmlFor="delete-transfer-email"
+                        className="text-sm font-medium"
+                      >
+                        Member&apos;s email
+                      </label>
+                      <Input
+                        id="delete-transfer-email"
+                        type="email"
+                        value={deleteTransferEmail}
+                        onChange={(e) => setDeleteTransferEmail(e.target.value)}
+                        placeholder="Who should get your copies"
+                      />
+                    </div>
+                  )}
+                  <div className="flex flex-col gap-1.5">
+                    <label
+                      htmlFor="delete-password"
+                      className="text-sm font-medium"
+                    >
+                      Current password
+                    </label>
+                    <Input
+                      id="delete-password"
+                      type="password"
+                      autoComplete="current-password"
+                      value={deletePassword}
+                      onChange={(e) => setDeletePassword(e.target.value)}
+                      placeholder="Confirm it's you"
+                    />
+                  </div>
+                  <div>
+                    <Button
+                      type="submit"
+                      variant="destructive"
+                      disabled={
+                        schedulingDeletion ||
+                        !deletePassword ||
+                        (deleteCopies === "transfer" &&
+                          !deleteTransferEmail.trim())
+                      }
+                    >
+                      {schedulingDeletion ? "Scheduling…" : "Delete my account"}
+                    </Button>
+                  </div>
+                </form>
+              )}
+            </CardContent>
+          </Card>
         </TabsContent>
 
         {/* Integrations tab */}
@@ -1,7 +1,7 @@
 "use client";
 
 import { useState, type ReactNode } from "react";
-import { Play, Clock } from "lucide-react";
+import { Play, Clock, FlaskConical } from "lucide-react";
 import { toast } from "sonner";
 import type { JobStatus } from "@/lib/types";
 import { timeAgo, timeUntil } from "@/lib/timeFormat";
@@ -25,6 +25,9 @@ export interface ScheduledTaskCardProps {
   status: JobStatus | null;
   onRunNow: () => Promise<void>;
   triggering: boolean;
+  /** Shown as a "Dry Run" button when the job supports one. */
+  onDryRun?: () => Promise<void>;
+  dryRunning?: boolean;
   intervalPresets: string[];
   intervalLabels: Record<string, string>;
   onSaveInterval: (value: string) => Promise<void>;
@@ -44,6 +47,8 @@ export function ScheduledTaskCard({
   status,
   onRunNow,
   triggering,
+  onDryRun,
+  dryRunning = false,
   intervalPresets,
   intervalLabels,
   onSaveInterval,
@@ -95,16 +100,28 @@ export function ScheduledTaskCard({
           )}
         </div>
 
-        <Button
-          size="sm"
-          variant="outline"
-          onClick={onRunNow}
-          disabled={!status || status.running || triggering}
-          className="shrink-0"
-        >
-          <Play className="size-3.5" />
-          {triggering ? "Queuing…" : "Run Now"}
-        </Button>
+        <div className="flex shrink-0 gap-2">
+          {onDryRun && status?.supports_dry_run && (
+            <Button
+              size="sm"
+              variant="ghost"
+              onClick={onDryRun}
+              disabled={dryRunning}
+            >
+              <FlaskConical className="size-3.5" />
+              {dryRunning ? "Checking…" : "Dry Run"}
+            </Button>
+          )}
+          <Button
+            size="sm"
+            variant="outline"
+            onClick={onRunNow}
+            disabled={!status || status.running || triggering}
+          >
+            <Play className="size-3.5" />
+            {triggering ? "Queuing…" : "Run Now"}
+          </Button>
+        </div>
       </div>
 
       {/* Stats row */}
@@ -11,6 +11,7 @@ import type {
   AppSetting,
   BookMetadataResult,
   MetadataProviderStatus,
+  MetadataStatus,
   WaitlistStatus,
   PaginatedResult,
   JobStatus,
@@ -18,6 +19,9 @@ import type {
   VerificationStatus,
   DashboardStats,
   WishlistRequest,
+  Contributor,
+  AuthorPage,
+  ScannedBarcode,
 } from "./types";
 
 export type {
@@ -258,6 +262,69 @@ export async function downloadBackup(filename: string): Promise<void> {
   );
 }
 
+/**
+ * Downloads the whole library — members (with password hashes), books,
+ * copies, loans and wishlist — for moving to another instance.
+ */
+export async function downloadLibraryExport(): Promise<void> {
+  const date = new Date().toISOString().slice(0, 10);
+  return downloadAuthed(
+    `${BASE}/admin/library/export`,
+    `bookshelf-library-${date}.json`,
+  );
+}
+
+/**
+ * Downloads everything stored about the signed-in member, as a zip of JSON
+ * files documented by the README.md inside it.
+ */
+export async function downloadMyData(): Promise<void> {
+  const date = new Date().toISOString().slice(0, 10);
+  return downloadAuthed(
+    `${BASE}/auth/me/export`,
+    `bookshelf-my-data-${date}.zip`,
+  );
+}
+
+export type MARCExportFormat = "marcxml" | "marc21";
+
+/**
+ * Downloads the catalog as MARC records, each copy a holding at location —
+ * for loading into another library's catalog system.
+ */
+export async function downloadMARCExport(
+  format: MARCExportFormat,
+  location: string,
+): Promise<void> {
+  const date = new Date().toISOString().slice(0, 10);
+  const params = new URLSearchParams({ format });
+  if (location) params.set("location", location);
+  return downloadAuthed(
+    `${BASE}/admin/library/marc?${params}`,
+    `bookshelf-catalog-${date}.${format === "marc21" ? "mrc" : "xml"}`,
+  );
+}
+
+export interface RestoreCount {
+  created: number;
+  /** Already here and brought up to date from the export. */
+  updated: number;
+  /** Already here and unchanged. */
+  matched: number;
+  /** Rows referring to records missing from the export, left out. */
+  skipped: number;
+}
+
+/** Per-record-type outcome of a library import. */
+export interface LibraryRestoreStats {
+  users: RestoreCount;
+  books: RestoreCount;
+  copies: RestoreCount;
+  loan_requests: RestoreCount;
+  wishlist_requests: RestoreCount;
+  wishlist_co_requesters: RestoreCount;
+}
+
 export type MyCopiesExportFormat = "json" | "yaml" | "csv";
 
 /** Downloads the caller's owned copies in the given export format. */
@@ -270,6 +337,19 @@ export async function downloadMyCopiesExport(
   );
 }
 
+/**
+ * Import also reads the tab-separated export LibraryThing gives, besides
+ * everything Bookshelf exports.
+ */
+export type ImportFormat = MyCopiesExportFormat | "tsv";
+
+/** Which app an imported file came from, as the backend detected it. */
+export type ImportSource =
+  | "bookshelf"
+  | "goodreads"
+  | "storygraph"
+  | "librarything";
+
 export type ImportRowAction =
   | "create_book"
   | "match_existing_book"
@@ -295,13 +375,53 @@ export interface ImportSummary {
 }
 
 export interface ImportResult {
+  source: ImportSource;
   summary: ImportSummary;
   rows: ImportRowResult[];
 }
 
+export type ImportJobStatus =
+  | "queued"
+  | "running"
+  | "completed"
+  | "failed"
+  | "cancelled";
+
+/**
+ * A books import running in the background. summary covers every row
+ * processed so far; rows holds only those after the `after` row asked for.
+ */
+export interface ImportJob extends ImportResult {
+  id: number;
+  status: ImportJobStatus;
+  total_rows: number;
+  processed_rows: number;
+  error?: string;
+  created_at: string;
+  finished_at?: string;
+}
+
 /** Per-row resolution for a possible_match row, keyed by 1-based row number. */
 export type ImportDecision = "accept_match" | "create_new";
 
+/**
+ * Uploads a form as multipart/form-data — request<T>() always sends JSON,
+ * and the browser must set the multipart boundary itself.
+ */
+async function uploadForm<T>(path: string, form: FormData): Promise<T> {
+  const token = getToken();
+  const res = await fetch(`${BASE}${path}`, {
+    method: "POST",
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+    body: form,
+  });
+  if (!res.ok) {
+    const err = await res.json().catch(() => ({ detail: res.statusText }));
+    throw new Error(err.detail ?? "Upload failed");
+  }
+  return (await res.json()) as T;
+}
+
 async function request<T>(path: string, options?: RequestInit): Promise<T> {
   const token = getToken();
   const headers: HeadersInit = {
@@ -398,6 +518,17 @@ export const api = {
       method: "POST",
       body: JSON.stringify(data),
     }),
+  scheduleAccountDeletion: (data: {
+    password: string;
+    copies: "transfer" | "delete";
+    transfer_to_email?: string;
+  }) =>
+    request<{ deletion_scheduled_at: string }>("/auth/me/deletion", {
+      method: "POST",
+      body: JSON.stringify(data),
+    }),
+  cancelAccountDeletion: () =>
+    request<void>("/auth/me/deletion", { method: "DELETE" }),
   testGoogleBooksKey: (key?: string) =>
     request<{ ok: boolean; message?: string }>(
       "/auth/me/google-books-key/test",
@@ -429,6 +560,7 @@ export const api = {
     available_only?: boolean;
     page?: number;
     page_size?: number;
+    group_by?: "work";
   }) => {
     const p: Record<string, string> = {};
     if (params?.q) p.q = params.q;
@@ -437,20 +569,33 @@ export const api = {
     if (params?.available_only) p.available_only = "true";
     if (params?.page) p.page = String(params.page);
     if (params?.page_size) p.page_size = String(params.page_size);
+    if (params?.group_by) p.group_by = params.group_by;
     const qs = new URLSearchParams(p).toString();
     return request<PaginatedResult<Book>>(`/books${qs ? "?" + qs : ""}`);
   },
   getRecentBooks: (limit?: number) =>
     request<Book[]>(`/books/recent${limit ? "?limit=" + limit : ""}`),
   getBook: (id: number) => request<Book>(`/books/${id}`),
-  createBook: (data: Partial<Book>) =>
-    request<Book>("/books", { method: "POST", body: JSON.stringify(data) }),
+  createBook: (
+    data: Omit<Partial<Book>, "contributors"> & {
+      contributors?: Contributor[];
+    },
+  ) => request<Book>("/books", { method: "POST", body: JSON.stringify(data) }),
+  getAuthor: (id: number) => request<AuthorPage>(`/authors/${id}`),
 
   // Metadata search (proxied through backend)
   searchMetadata: (q: string) =>
     request<BookMetadataResult[]>(
       `/books/metadata/search?q=${encodeURIComponent(q)}`,
     ),
+  scanBarcodes: (image: File) => {
+    const form = new FormData();
+    form.append("image", image);
+    return uploadForm<{ barcodes: ScannedBarcode[] }>(
+      "/books/metadata/scan",
+      form,
+    );
+  },
   getOLDescription: (olKey: string) =>
     request<{ description: string }>(
       `/books/metadata/ol-description?ol_key=${encodeURIComponent(olKey)}`,
@@ -491,20 +636,27 @@ export const api = {
       method: "POST",
       body: JSON.stringify({ email }),
     }),
-  previewImportBooks: (format: MyCopiesExportFormat, content: string) =>
+  previewImportBooks: (format: ImportFormat, content: string) =>
     request<ImportResult>("/copies/mine/import/preview", {
       method: "POST",
       body: JSON.stringify({ format, content }),
     }),
   importBooks: (
-    format: MyCopiesExportFormat,
+    format: ImportFormat,
     content: string,
     decisions?: Record<number, ImportDecision>,
   ) =>
-    request<ImportResult>("/copies/mine/import", {
+    request<ImportJob>("/copies/mine/import", {
       method: "POST",
       body: JSON.stringify({ format, content, decisions }),
     }),
+  listImportJobs: () => request<ImportJob[]>("/copies/mine/imports"),
+  getImportJob: (id: number, after = 0) =>
+    request<ImportJob>(`/copies/mine/imports/${id}?after=${after}`),
+  cancelImportJob: (id: number) =>
+    request<ImportJob>(`/copies/mine/imports/${id}/cancel`, {
+      method: "POST",
+    }),
 
   // Waitlist
   getWaitlistStatus: (copyId: number) =>
@@ -534,7 +686,9 @@ export const api = {
   getLoanRequestsByCopy: (copyId: number) =>
     request<LoanRequest[]>(`/loan-requests?copy_id=${copyId}`),
   createLoanRequest: (data: {
-    copy_id: number;
+    copy_id?: number;
+    book_id?: number;
+    any_edition?: boolean;
     message?: string;
     expected_return_date?: string;
   }) =>
@@ -667,6 +821,10 @@ export const api = {
       method: "POST",
       body: JSON.stringify({ book_id: bookId }),
     }),
+  joinWishlistRequest: (id: number) =>
+    request<WishlistRequest>(`/wishlist/${id}/join`, { method: "POST" }),
+  leaveWishlistRequest: (id: number) =>
+    request<void>(`/wishlist/${id}/join`, { method: "DELETE" }),
 
   // Admin
   adminGetDashboardStats: () => request<DashboardStats>("/admin/dashboard"),
@@ -704,6 +862,10 @@ export const api = {
   adminGetJobs: () => request<JobStatus[]>("/admin/jobs"),
   adminRunJob: (job: string) =>
     request<void>(`/admin/jobs/${job}/run`, { method: "POST" }),
+  adminDryRunJob: (job: string) =>
+    request<{ result: string }>(`/admin/jobs/${job}/dry-run`, {
+      method: "POST",
+    }),
 
   // Backups
   adminListBackups: () => request<BackupInfo[]>("/admin/backups"),
@@ -712,7 +874,16 @@ export const api = {
       method: "DELETE",
     }),
 
+  // Library migration. content is an exported file's text, sent as is.
+  adminImportLibrary: (content: string) =>
+    request<LibraryRestoreStats>("/admin/library/import", {
+      method: "POST",
+      body: content,
+    }),
+
   // Metadata provider status
   adminGetMetadataStatus: () =>
-    request<MetadataProviderStatus[]>("/admin/metadata/status"),
+    request<MetadataStatus>("/admin/metadata/status"),
+  adminPurgeMetadataCache: () =>
+    request<{ purged: number }>("/admin/metadata/cache", { method: "DELETE" }),
 };
@@ -0,0 +1,29 @@
+import { decodeBlurhash } from "./blurhash";
+
+// Encoded by the backend's covers.blurhash from a flat rgb(10, 120, 240)
+// image.
+const SOLID_BLUE = "T91Gp]kvfQgRfkfQfQfQfQgRfkfQ";
+
+describe("decodeBlurhash", () => {
+  it("decodes to opaque pixels averaging the encoded colour", () => {
+    const pixels = decodeBlurhash(SOLID_BLUE, 12, 16);
+
+    expect(pixels).toHaveLength(12 * 16 * 4);
+    const sums = [0, 0, 0];
+    for (let i = 0; i < pixels.length; i += 4) {
+      sums[0] += pixels[i];
+      sums[1] += pixels[i + 1];
+      sums[2] += pixels[i + 2];
+      expect(pixels[i + 3]).toBe(255);
+    }
+    const count = 12 * 16;
+    expect(Math.abs(sums[0] / count - 10)).toBeLessThan(12);
+    expect(Math.abs(sums[1] / count - 120)).toBeLessThan(12);
+    expect(Math.abs(sums[2] / count - 240)).toBeLessThan(12);
+  });
+
+  it("rejects a malformed hash", () => {
+    expect(() => decodeBlurhash("T91G", 4, 4)).toThrow();
+    expect(() => decodeBlurhash(SOLID_BLUE.slice(0, -2), 4, 4)).toThrow();
+  });
+});
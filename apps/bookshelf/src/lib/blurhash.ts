@@ -0,0 +1,121 @@
+// Decoder for the BlurHash placeholders the backend stores on cached covers
+// (Book.cover_blurhash — see internal/covers/blurhash.go for the encoder).
+// A few lines of math rather than the `blurhash` package, since decoding is
+// all the frontend needs.
+
+const BASE83_CHARS =
+  "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~";
+
+function decode83(str: string): number {
+  let value = 0;
+  for (const char of str) {
+    const digit = BASE83_CHARS.indexOf(char);
+    if (digit < 0) {
+      throw new Error(`invalid blurhash character "${char}"`);
+    }
+    value = value * 83 + digit;
+  }
+  return value;
+}
+
+function srgbToLinear(value: number): number {
+  const v = value / 255;
+  return v <= 0.04045 ? v / 12.92 : Math.pow((v + 0.055) / 1.055, 2.4);
+}
+
+function linearToSrgb(value: number): number {
+  const v = Math.max(0, Math.min(1, value));
+  return v <= 0.0031308
+    ? Math.round(v * 12.92 * 255)
+    : Math.round((1.055 * Math.pow(v, 1 / 2.4) - 0.055) * 255);
+}
+
+function signPow(value: number, exp: number): number {
+  return Math.sign(value) * Math.pow(Math.abs(value), exp);
+}
+
+// Decodes hash into width×height RGBA pixels. Throws on a malformed hash.
+export function decodeBlurhash(
+  hash: string,
+  width: number,
+  height: number,
+): Uint8ClampedArray {
+  if (hash.length < 6) {
+    throw new Error("blurhash too short");
+  }
+  const sizeFlag = decode83(hash[0]);
+  const numX = (sizeFlag % 9) + 1;
+  const numY = Math.floor(sizeFlag / 9) + 1;
+  if (hash.length !== 4 + 2 * numX * numY) {
+    throw new Error("blurhash length doesn't match its component count");
+  }
+  const maxValue = (decode83(hash[1]) + 1) / 166;
+
+  const colors: [number, number, number][] = [];
+  const dc = decode83(hash.slice(2, 6));
+  colors.push([
+    srgbToLinear(dc >> 16),
+    srgbToLinear((dc >> 8) & 255),
+    srgbToLinear(dc & 255),
+  ]);
+  for (let i = 1; i < numX * numY; i++) {
+    const ac = decode83(hash.slice(4 + i * 2, 6 + i * 2));
+    const quant = (v: number) => signPow((v - 9) / 9, 2) * maxValue;
+    colors.push([
+      quant(Math.floor(ac / (19 * 19))),
+      quant(Math.floor(ac / 19) % 19),
+      quant(ac % 19),
+    ]);
+  }
+
+  const pixels = new Uint8ClampedArray(width * height * 4);
+  for (let y = 0; y < height; y++) {
+    for (let x = 0; x < width; x++) {
+      let r = 0;
+      let g = 0;
+      let b = 0;
+      for (let j = 0; j < numY; j++) {
+        for (let i = 0; i < numX; i++) {
+          const basis =
+            Math.cos((Math.PI * x * i) / width) *
+            Math.cos((Math.PI * y * j) / height);
+          const color = colors[i + j * numX];
+          r += color[0] * basis;
+          g += color[1] * basis;
+          b += color[2] * basis;
+        }
+      }
+      const offset = 4 * (x + y * width);
+      pixels[offset] = linearToSrgb(r);
+      pixels[offset + 1] = linearToSrgb(g);
+      pixels[offset + 2] = linearToSrgb(b);
+      pixels[offset + 3] = 255;
+    }
+  }
+  return pixels;
+}
+
+// Renders hash as a small PNG data URL, for next/image's blurDataURL.
+// Browser-only (needs a canvas); returns undefined if hash is malformed or
+// there's no canvas.
+export function blurhashToDataURL(
+  hash: string,
+  width = 24,
+  height = 36,
+): string | undefined {
+  try {
+    const canvas = document.createElement("canvas");
+    canvas.width = width;
+    canvas.height = height;
+    const ctx = canvas.getContext("2d");
+    if (!ctx) {
+      return undefined;
+    }
+    const image = ctx.createImageData(width, height);
+    image.data.set(decodeBlurhash(hash, width, height));
+    ctx.putImageData(image, 0, 0);
+    return canvas.toDataURL();
+  } catch {
+    return undefined;
+  }
+}
@@ -12,6 +12,7 @@ export const notificationTypeLabel: Record<Notification["type"], string> = {
   copy_transferred_in: "Copy transferred to you",
   copy_transferred_out: "Copy transfer sent",
   wishlist_fulfilled: "A book you wanted is available",
+  wishlist_possible_match: "Possible match for your wishlist",
   user_pending_approval: "New user awaiting approval",
 };
 
@@ -50,6 +51,11 @@ export async function notificationDestination(
     }
   }
 
+  if (n.type === "wishlist_possible_match") {
+    if (!n.book_id) return "/wishlist";
+    return `/catalog/${n.book_id}?wishlist=${n.wishlist_request_id}`;
+  }
+
   if (!n.loan_request_id) return null;
 
   if (n.type === "request_received") {
@@ -14,6 +14,7 @@ export interface User {
   email_notifications_enabled: boolean;
   telegram_username?: string;
   whatsapp_username?: string;
+  deletion_scheduled_at?: string;
 }
 
 export interface AppSetting {
@@ -40,6 +41,8 @@ export interface Book {
   isbn: string;
   ol_key: string;
   cover_url: string;
+  // BlurHash placeholder for a locally cached cover; absent otherwise.
+  cover_blurhash?: string;
   description: string;
   publisher?: string;
   published_date?: string;
@@ -50,6 +53,33 @@ export interface Book {
   copies?: Copy[];
   available_copies?: number;
   description_enriched?: boolean;
+  contributors?: BookContributor[];
+  // Only in the work-grouped catalog listing (group_by: "work").
+  editions?: Book[];
+}
+
+export type ContributorRole = "author" | "editor" | "translator";
+
+// A person credited on a book, as reported by a metadata source.
+export interface Contributor {
+  name: string;
+  role: ContributorRole;
+}
+
+export interface Author {
+  id: number;
+  name: string;
+  created_at?: string;
+}
+
+export interface BookContributor {
+  author_id: number;
+  role: ContributorRole;
+  author: Author;
+}
+
+export interface AuthorPage extends Author {
+  books: (Book & { roles: ContributorRole[] })[];
 }
 
 export interface Copy {
@@ -96,10 +126,12 @@ export interface Notification {
     | "copy_transferred_in"
     | "copy_transferred_out"
     | "wishlist_fulfilled"
+    | "wishlist_possible_match"
     | "user_pending_approval";
   loan_request_id?: number;
   wishlist_request_id?: number;
   pending_user_id?: number;
+  book_id?: number;
   read: boolean;
   created_at: string;
 }
@@ -123,6 +155,10 @@ export interface WishlistRequest {
   created_at: string;
   requester?: { id: number; name: string };
   fulfilled_book?: Book;
+  // The requester plus every member who joined the request ("me too").
+  requester_count: number;
+  // Whether the current user joined the request.
+  joined: boolean;
 }
 
 export interface WaitlistEntry {
@@ -145,6 +181,7 @@ export interface JobStatus {
   last_run_at: string | null;
   next_run_at: string | null;
   last_result: string;
+  supports_dry_run: boolean;
 }
 
 export interface BackupInfo {
@@ -159,6 +196,10 @@ export interface PaginatedResult<T> {
   page: number;
   page_size: number;
   total_pages: number;
+  /** Pass back as ?cursor= for the next page; absent on the last page. */
+  next_cursor?: string;
+  /** Book searches only: a catalog title to suggest when nothing matched. */
+  did_you_mean?: string;
 }
 
 export interface AuthResponse {
@@ -192,10 +233,19 @@ export interface BookMetadataResult {
   ol_key: string;
   google_books_id: string;
   bookbrainz_id?: string;
+  contributors?: Contributor[];
   enriched_fields?: string[];
   work_key?: string;
 }
 
+// A barcode read from an uploaded photo. isbn is absent (and candidates
+// empty) for an EAN-13 that isn't an ISBN.
+export interface ScannedBarcode {
+  code: string;
+  isbn?: string;
+  candidates: BookMetadataResult[];
+}
+
 export interface VerificationFactor {
   key: "email" | "phone" | "min_books_shared";
   label: string;
@@ -210,12 +260,49 @@ export interface VerificationStatus {
   factors: VerificationFactor[];
 }
 
+export interface MetadataProviderCapabilities {
+  isbn: boolean;
+  free_text: boolean;
+  descriptions: boolean;
+  requires_api_key: boolean;
+}
+
+export interface MetadataProviderHealth {
+  state: "closed" | "open" | "half_open";
+  requests: number;
+  error_rate: number;
+  avg_latency_ms: number;
+  consecutive_failures: number;
+  last_failure_at?: string;
+  last_error?: string;
+  open_until?: string;
+  skipped: number;
+}
+
 export interface MetadataProviderStatus {
   name: string;
   enabled: boolean;
+  disabled: boolean;
+  capabilities: MetadataProviderCapabilities;
+  timeout_ms: number;
   reachable: boolean;
   latency_ms: number;
   error?: string;
+  health: MetadataProviderHealth;
+}
+
+export interface MetadataCacheStats {
+  backend: "memory" | "sqlite";
+  entries: number;
+  max_entries: number;
+  ttl_seconds: number;
+  hits: number;
+  misses: number;
+}
+
+export interface MetadataStatus {
+  providers: MetadataProviderStatus[];
+  cache: MetadataCacheStats;
 }
 
 export interface BookBorrowStat {